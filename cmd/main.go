@@ -17,6 +17,8 @@ import (
 
 func main() {
 	logger := zerolog.New(zerolog.SyncWriter(os.Stdout)).With().Timestamp().Caller().Logger()
+	// the service logs failures it doesn't return, like audit writes, through zerolog.Ctx
+	zerolog.DefaultContextLogger = &logger
 	config, err := config.ReadConfig(&logger)
 	if err != nil {
 		logger.Fatal().Msg(err.Error())
@@ -25,8 +27,9 @@ func main() {
 	if err != nil {
 		logger.Fatal().Msg(err.Error())
 	}
-	ApiError := apierror.NewApiError(&logger)
+	ApiError := apierror.NewApiError(&logger, config)
 	repository := repository.NewRepository(pool)
+	go service.RunAuditRetention(logger.WithContext(context.Background()), repository, config, time.Hour)
 	service := service.NewService(repository, config)
 	MyHandlers := handlers.NewHandlers(service, config, ApiError)
 	srv := &http.Server{
@@ -1,6 +1,11 @@
 package config
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
 	"github.com/rs/zerolog"
 	"github.com/spf13/viper"
 )
@@ -17,6 +22,109 @@ type Config struct {
 	LocalDbUrlTest   string `mapstructure:"LOCAL_DB_URL_TEST"`
 	AllowCredentials bool   `mapstructure:"ALLOW_CREDENTIALS"`
 	SpaUrl           string `mapstructure:"SPA_URL"`
+	// LegacyErrorFormat switches errors back to {"message", "result", "code"} instead of problem+json.
+	LegacyErrorFormat bool `mapstructure:"LEGACY_ERROR_FORMAT"`
+	// HardenedAuth hides whether an email is registered on sign up and sign in.
+	HardenedAuth           bool   `mapstructure:"HARDENED_AUTH"`
+	NoticeTemplateLocation string `mapstructure:"NOTICE_TEMPLATE_LOCATION"`
+	// OpenID Connect provider
+	Issuer                       string `mapstructure:"ISSUER"`
+	OidcPrivateKeyLocation       string `mapstructure:"OIDC_PRIVATE_KEY_LOCATION"`
+	OauthLoginTemplateLocation   string `mapstructure:"OAUTH_LOGIN_TEMPLATE_LOCATION"`
+	OauthConsentTemplateLocation string `mapstructure:"OAUTH_CONSENT_TEMPLATE_LOCATION"`
+	// SocialProvidersJson is a json array of SocialProvider, it is decoded into SocialProviders
+	SocialProvidersJson string           `mapstructure:"SOCIAL_PROVIDERS"`
+	SocialProviders     []SocialProvider `mapstructure:"-"`
+	// PasswordResetTemplateLocation is the email with the password reset link
+	PasswordResetTemplateLocation string `mapstructure:"PASSWORD_RESET_TEMPLATE_LOCATION"`
+	// NewDeviceTemplateLocation is the email sent on a sign in from a new device
+	NewDeviceTemplateLocation string `mapstructure:"NEW_DEVICE_TEMPLATE_LOCATION"`
+	// AuditRetentionDays is how long audit events are kept, 0 keeps them forever
+	AuditRetentionDays int `mapstructure:"AUDIT_RETENTION_DAYS"`
+	// TrustProxyHeaders takes the client IP from X-Forwarded-For, only enable it behind a proxy that sets the header
+	TrustProxyHeaders bool `mapstructure:"TRUST_PROXY_HEADERS"`
+	// EmailLocalPart is EmailLocalPartLowercase or EmailLocalPartPreserve, emails are unique case-insensitively either way
+	EmailLocalPart string `mapstructure:"EMAIL_LOCAL_PART"`
+	// EmailFoldGmail drops dots and "+tag" from Gmail addresses so one mailbox can't hold several accounts
+	EmailFoldGmail bool `mapstructure:"EMAIL_FOLD_GMAIL"`
+	// EmailDomainMode is EmailDomainBlocklist, EmailDomainAllowlist or EmailDomainOff, it decides which domains may sign up
+	EmailDomainMode string `mapstructure:"EMAIL_DOMAIN_MODE"`
+	// EmailDomainListLocation is a file with one domain per line, blocked or allowed depending on the mode.
+	// It is read again whenever it changes.
+	EmailDomainListLocation string `mapstructure:"EMAIL_DOMAIN_LIST_LOCATION"`
+	// EmailDomainCheckMx rejects sign ups from domains without mail servers
+	EmailDomainCheckMx bool `mapstructure:"EMAIL_DOMAIN_CHECK_MX"`
+	// ChallengeSignUp and ChallengeSignIn are ChallengeOff, ChallengePow or ChallengeCaptcha
+	ChallengeSignUp string `mapstructure:"CHALLENGE_SIGN_UP"`
+	ChallengeSignIn string `mapstructure:"CHALLENGE_SIGN_IN"`
+	// ChallengePowDifficulty is the number of leading zero bits a proof-of-work hash needs
+	ChallengePowDifficulty int `mapstructure:"CHALLENGE_POW_DIFFICULTY"`
+	// CaptchaProvider is CaptchaHcaptcha or CaptchaTurnstile, CaptchaVerifyUrl overrides the siteverify url of the provider
+	CaptchaProvider  string `mapstructure:"CAPTCHA_PROVIDER"`
+	CaptchaSiteKey   string `mapstructure:"CAPTCHA_SITE_KEY"`
+	CaptchaSecret    string `mapstructure:"CAPTCHA_SECRET"`
+	CaptchaVerifyUrl string `mapstructure:"CAPTCHA_VERIFY_URL"`
+	// AccessTokenAudience is the aud claim of first-party session tokens, ISSUER if empty. Client tokens never get it.
+	AccessTokenAudience string `mapstructure:"ACCESS_TOKEN_AUDIENCE"`
+	// AccessTokenTtlSeconds is the lifetime of access tokens
+	AccessTokenTtlSeconds int `mapstructure:"ACCESS_TOKEN_TTL_SECONDS"`
+	// RefreshTokenTtlDays is how long a remembered session or an OAuth refresh token lasts, sessions slide it forward on every refresh
+	RefreshTokenTtlDays int `mapstructure:"REFRESH_TOKEN_TTL_DAYS"`
+	// SessionTtlHours is how long a session signed in without remember_me lasts, it slides the same way
+	SessionTtlHours int `mapstructure:"SESSION_TTL_HOURS"`
+	// SessionMaxLifetimeDays caps a session however often it is refreshed, after it the user signs in again
+	SessionMaxLifetimeDays int `mapstructure:"SESSION_MAX_LIFETIME_DAYS"`
+	// ReauthMaxAgeSeconds is how recent a sign in or POST /user/reauth has to be for sensitive endpoints
+	ReauthMaxAgeSeconds int `mapstructure:"REAUTH_MAX_AGE_SECONDS"`
+}
+
+const (
+	EmailLocalPartLowercase = "lowercase"
+	EmailLocalPartPreserve  = "preserve"
+
+	EmailDomainBlocklist = "blocklist"
+	EmailDomainAllowlist = "allowlist"
+	EmailDomainOff       = "off"
+
+	ChallengeOff     = "off"
+	ChallengePow     = "pow"
+	ChallengeCaptcha = "captcha"
+
+	CaptchaHcaptcha  = "hcaptcha"
+	CaptchaTurnstile = "turnstile"
+
+	DefaultChallengePowDifficulty = 20
+	MaxChallengePowDifficulty     = 32
+
+	DefaultAccessTokenTtlSeconds  = 300
+	DefaultRefreshTokenTtlDays    = 30
+	DefaultSessionTtlHours        = 12
+	DefaultSessionMaxLifetimeDays = 180
+	DefaultReauthMaxAgeSeconds    = 600
+)
+
+// SocialProvider is an external identity provider users can sign in with.
+// Type is "oidc" (default, endpoints are discovered from Issuer) or "github",
+// the endpoints only need to be set to override discovery or GitHub defaults.
+type SocialProvider struct {
+	Name                  string   `json:"name"`
+	Type                  string   `json:"type"`
+	Issuer                string   `json:"issuer"`
+	ClientId              string   `json:"client_id"`
+	ClientSecret          string   `json:"client_secret"`
+	Scopes                []string `json:"scopes"`
+	AuthorizationEndpoint string   `json:"authorization_endpoint"`
+	TokenEndpoint         string   `json:"token_endpoint"`
+	UserInfoEndpoint      string   `json:"userinfo_endpoint"`
+}
+
+func (config *Config) SocialProvider(name string) (*SocialProvider, bool) {
+	for i := range config.SocialProviders {
+		if config.SocialProviders[i].Name == name {
+			return &config.SocialProviders[i], true
+		}
+	}
+	return nil, false
 }
 
 func ReadConfig(logger *zerolog.Logger) (*Config, error) {
@@ -33,5 +141,106 @@ func ReadConfig(logger *zerolog.Logger) (*Config, error) {
 	if err := viper.Unmarshal(&config); err != nil {
 		logger.Panic().Msg(err.Error())
 	}
+	if config.SocialProvidersJson != "" {
+		if err := json.Unmarshal([]byte(config.SocialProvidersJson), &config.SocialProviders); err != nil {
+			return nil, err
+		}
+	}
+	switch config.EmailLocalPart {
+	case "":
+		config.EmailLocalPart = EmailLocalPartLowercase
+	case EmailLocalPartLowercase, EmailLocalPartPreserve:
+	default:
+		return nil, fmt.Errorf("EMAIL_LOCAL_PART must be %q or %q", EmailLocalPartLowercase, EmailLocalPartPreserve)
+	}
+	switch config.EmailDomainMode {
+	case "":
+		config.EmailDomainMode = EmailDomainBlocklist
+	case EmailDomainBlocklist, EmailDomainAllowlist, EmailDomainOff:
+	default:
+		return nil, fmt.Errorf("EMAIL_DOMAIN_MODE must be %q, %q or %q", EmailDomainBlocklist, EmailDomainAllowlist, EmailDomainOff)
+	}
+	if config.Issuer == "" {
+		return nil, errors.New("ISSUER is required, access tokens are issued and verified for it")
+	}
+	if err := config.readChallenges(); err != nil {
+		return nil, err
+	}
+	if err := config.readTokenLifetimes(); err != nil {
+		return nil, err
+	}
 	return config, nil
 }
+
+func (config *Config) Audience() string {
+	if config.AccessTokenAudience == "" {
+		return config.Issuer
+	}
+	return config.AccessTokenAudience
+}
+
+// AccessTokenTtl, RefreshTokenTtl, SessionTtl, SessionMaxLifetime and ReauthMaxAge fall back to the defaults while their setting is zero.
+func (config *Config) AccessTokenTtl() time.Duration {
+	return time.Second * time.Duration(orDefault(config.AccessTokenTtlSeconds, DefaultAccessTokenTtlSeconds))
+}
+
+func (config *Config) RefreshTokenTtl() time.Duration {
+	return time.Hour * 24 * time.Duration(orDefault(config.RefreshTokenTtlDays, DefaultRefreshTokenTtlDays))
+}
+
+func (config *Config) SessionTtl() time.Duration {
+	return time.Hour * time.Duration(orDefault(config.SessionTtlHours, DefaultSessionTtlHours))
+}
+
+func (config *Config) SessionMaxLifetime() time.Duration {
+	return time.Hour * 24 * time.Duration(orDefault(config.SessionMaxLifetimeDays, DefaultSessionMaxLifetimeDays))
+}
+
+func (config *Config) ReauthMaxAge() time.Duration {
+	return time.Second * time.Duration(orDefault(config.ReauthMaxAgeSeconds, DefaultReauthMaxAgeSeconds))
+}
+
+func orDefault(value, defaultValue int) int {
+	if value == 0 {
+		return defaultValue
+	}
+	return value
+}
+
+// readTokenLifetimes checks that no lifetime is negative and that the maximum session lifetime isn't shorter than a session.
+func (config *Config) readTokenLifetimes() error {
+	if config.AccessTokenTtlSeconds < 0 || config.RefreshTokenTtlDays < 0 || config.SessionTtlHours < 0 || config.SessionMaxLifetimeDays < 0 || config.ReauthMaxAgeSeconds < 0 {
+		return fmt.Errorf("ACCESS_TOKEN_TTL_SECONDS, REFRESH_TOKEN_TTL_DAYS, SESSION_TTL_HOURS, SESSION_MAX_LIFETIME_DAYS and REAUTH_MAX_AGE_SECONDS can't be negative")
+	}
+	if config.SessionMaxLifetime() < config.RefreshTokenTtl() || config.SessionMaxLifetime() < config.SessionTtl() {
+		return fmt.Errorf("SESSION_MAX_LIFETIME_DAYS can't be shorter than REFRESH_TOKEN_TTL_DAYS or SESSION_TTL_HOURS")
+	}
+	return nil
+}
+
+// readChallenges fills the defaults of the challenge settings and checks that a CAPTCHA is configured if an endpoint uses it.
+func (config *Config) readChallenges() error {
+	for _, mode := range []*string{&config.ChallengeSignUp, &config.ChallengeSignIn} {
+		switch *mode {
+		case "":
+			*mode = ChallengeOff
+		case ChallengeOff, ChallengePow:
+		case ChallengeCaptcha:
+			if config.CaptchaProvider != CaptchaHcaptcha && config.CaptchaProvider != CaptchaTurnstile {
+				return fmt.Errorf("CAPTCHA_PROVIDER must be %q or %q", CaptchaHcaptcha, CaptchaTurnstile)
+			}
+			if config.CaptchaSecret == "" {
+				return fmt.Errorf("CAPTCHA_SECRET is required with the %q challenge", ChallengeCaptcha)
+			}
+		default:
+			return fmt.Errorf("CHALLENGE_SIGN_UP and CHALLENGE_SIGN_IN must be %q, %q or %q", ChallengeOff, ChallengePow, ChallengeCaptcha)
+		}
+	}
+	if config.ChallengePowDifficulty == 0 {
+		config.ChallengePowDifficulty = DefaultChallengePowDifficulty
+	}
+	if config.ChallengePowDifficulty < 1 || config.ChallengePowDifficulty > MaxChallengePowDifficulty {
+		return fmt.Errorf("CHALLENGE_POW_DIFFICULTY must be between 1 and %d", MaxChallengePowDifficulty)
+	}
+	return nil
+}
@@ -1,4 +1,6 @@
+//go:build integration
 // +build integration
+
 package integration_test
 
 import (
@@ -36,12 +38,13 @@ func (suite *IntegrationTestSuite) SetupSuite() {
 		suite.FailNow(err.Error())
 	}
 	config.TemplateLocation = "./../internal/templates/response_template.html"
+	config.NoticeTemplateLocation = "./../internal/templates/signup_notice_template.html"
 	pool ,err := pgxpool.Connect(context.TODO(), config.LocalDbUrlTest)
 	if err != nil {
 		suite.FailNow(err.Error())
 	}
 	suite.pool = pool
-	ApiError := apierror.NewApiError(&logger)
+	ApiError := apierror.NewApiError(&logger, config)
 	MyRepository := repository.NewRepository(pool)
 	MyService := service.NewService(MyRepository, config)
 	MyHandlers := handlers.NewHandlers(MyService, config, ApiError)
@@ -69,10 +72,25 @@ func (suite *IntegrationTestSuite) TearDownTest() {
 }
 
 func (suite *IntegrationTestSuite) TestSignUp() {
+	validationProblem := &apierror.Problem{
+		Type: apierror.ProblemTypePrefix + apierror.CodeValidationFailed,
+		Title: apierror.Catalog[apierror.CodeValidationFailed].Title,
+		Status: 400,
+		Detail: dto.ErrInvalidCredentials.Error(),
+		Instance: "/user",
+		Code: apierror.CodeValidationFailed,
+		Errors: []apierror.FieldError{
+			{Field: "user_name", Code: dto.CodeRequired, Min: dto.MinUserNameLength},
+			{Field: "email", Code: dto.CodeRequired},
+			{Field: "password", Code: dto.CodeRequired, Min: dto.MinPasswordLength},
+		},
+	}
 	testCases := []struct{
 		name 		string
 		input 		*dto.UserSignUpDto
-		result 		apierror.ErrorStruct
+		status 		int
+		result 		*apierror.ErrorStruct
+		problem 	*apierror.Problem
 	}{
 		{
 			name: "good_user_data",
@@ -81,7 +99,8 @@ func (suite *IntegrationTestSuite) TestSignUp() {
 				UserName: "testUserValid",
 				Password: "12345ValidPassword",
 			},
-			result: apierror.ErrorStruct{
+			status: 200,
+			result: &apierror.ErrorStruct{
 				Result: "ok",
 				Message: "email was sent",
 				Code: 200,
@@ -90,20 +109,14 @@ func (suite *IntegrationTestSuite) TestSignUp() {
 		{
 			name: "nil_input",
 			input: nil,
-			result: apierror.ErrorStruct{
-				Result: "error",
-				Message: dto.ErrInvalidCredentials.Error(),
-				Code: 400,
-			},
+			status: 400,
+			problem: validationProblem,
 		},
 		{
 			name: "empty_user",
 			input: &dto.UserSignUpDto{},
-			result: apierror.ErrorStruct{
-				Result: "error",
-				Message: dto.ErrInvalidCredentials.Error(),
-				Code: 400,
-			},
+			status: 400,
+			problem: validationProblem,
 		},
 	}
 	for _, tc := range testCases {
@@ -116,11 +129,20 @@ func (suite *IntegrationTestSuite) TestSignUp() {
 			if err != nil {
 				suite.FailNow(err.Error())
 			}
-			var response apierror.ErrorStruct
+			suite.Equal(tc.status, res.StatusCode)
+			if tc.result != nil {
+				var response apierror.ErrorStruct
+				if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+					suite.FailNow(err.Error())
+				}
+				suite.Equal(*tc.result, response)
+				return
+			}
+			var response apierror.Problem
 			if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
 				suite.FailNow(err.Error())
 			}
-			suite.Equal(tc.result, response)
+			suite.Equal(*tc.problem, response)
 		})
 	}
 }
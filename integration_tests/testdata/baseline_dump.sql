@@ -0,0 +1,21 @@
+-- The users table of the original up.sql with a few users, the upgrade scripts are run on it by TestUpgradeFromBaseline.
+
+CREATE TABLE users(
+    id                UUID                                                                  NOT NULL,
+    user_name         TEXT                                                                  NOT NULL CHECK(user_name != ''),
+    email             TEXT                                                                  NOT NULL CHECK(email != ''),
+    password          TEXT                                                                  NOT NULL CHECK(password != ''),
+    registration_date TIMESTAMP                                                             NOT NULL, 
+    refresh_token     TEXT,
+    expiration_time   TIMESTAMP,  
+    verification_code TEXT                                                                  NOT NULL,
+    verified          BOOL                                                                  NOT NULL,
+    UNIQUE (id),
+    UNIQUE(user_name),
+    UNIQUE (email)
+);
+
+INSERT INTO users(id, user_name, email, password, registration_date, refresh_token, expiration_time, verification_code, verified) VALUES
+    ('5f0c1ab2-6d7e-4b53-9a0f-0d3c1f8a1b01', 'alice', 'Alice@Example.COM', '$2a$10$abcdefghijklmnopqrstuv', '2022-10-01 10:00:00', 'refresh-alice', '2022-11-01 10:00:00', '', true),
+    ('5f0c1ab2-6d7e-4b53-9a0f-0d3c1f8a1b02', 'Bob_Smith', 'bob@example.com', '$2a$10$abcdefghijklmnopqrstuv', '2022-10-02 10:00:00', NULL, NULL, 'verify-bob', false),
+    ('5f0c1ab2-6d7e-4b53-9a0f-0d3c1f8a1b03', 'carol', 'c.a.r.o.l+news@gmail.com', '$2a$10$abcdefghijklmnopqrstuv', '2022-10-03 10:00:00', NULL, NULL, '', true);
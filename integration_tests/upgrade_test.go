@@ -0,0 +1,133 @@
+package integration_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	config "github.com/Kin-dza-dzaa/userApi/configs"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/rs/zerolog"
+)
+
+const (
+	upgradeSchema = "upgrade_test"
+
+	queryColumns = `SELECT table_name || '.' || column_name || ' ' || data_type || ' ' || is_nullable || ' ' || COALESCE(column_default, '')
+		FROM information_schema.columns WHERE table_schema = $1;`
+	queryIndexes = "SELECT indexname || ' ' || replace(indexdef, schemaname || '.', '') FROM pg_indexes WHERE schemaname = $1;"
+	queryStatus  = "SELECT status, user_name_canonical FROM upgrade_test.users WHERE id = $1;"
+	queryEmail   = "SELECT email FROM upgrade_test.users WHERE id = $1;"
+)
+
+// TestUpgradeFromBaseline runs every script of migrations/upgrades in order on a database of the original up.sql,
+// the tables, columns and indexes have to end up the same as the ones up.sql creates in public.
+func TestUpgradeFromBaseline(t *testing.T) {
+	logger := zerolog.New(nil)
+	config, err := config.ReadConfig(&logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool, err := pgxpool.Connect(context.TODO(), config.LocalDbUrlTest)
+	if err != nil {
+		t.Skipf("LOCAL_DB_URL_TEST isn't reachable: %v", err)
+	}
+	defer pool.Close()
+	conn, err := pool.Acquire(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Release()
+	if _, err := conn.Exec(context.TODO(), "DROP SCHEMA IF EXISTS "+upgradeSchema+" CASCADE; CREATE SCHEMA "+upgradeSchema+"; SET search_path TO "+upgradeSchema+";"); err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Exec(context.TODO(), "RESET search_path; DROP SCHEMA IF EXISTS "+upgradeSchema+" CASCADE;")
+
+	scripts, err := filepath.Glob("./../migrations/upgrades/*.sql")
+	if err != nil || len(scripts) == 0 {
+		t.Fatalf("no upgrade scripts: %v", err)
+	}
+	sort.Strings(scripts)
+	for _, script := range append([]string{"./testdata/baseline_dump.sql"}, scripts...) {
+		sql, err := os.ReadFile(script)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := conn.Exec(context.TODO(), string(sql)); err != nil {
+			t.Fatalf("%s: %v", filepath.Base(script), err)
+		}
+	}
+
+	for _, query := range []string{queryColumns, queryIndexes} {
+		expected, upgraded := schemaLines(t, pool, query, "public"), schemaLines(t, pool, query, upgradeSchema)
+		if strings.Join(expected, "\n") != strings.Join(upgraded, "\n") {
+			t.Fatalf("upgraded schema differs from up.sql\nup.sql:\n%s\n\nupgraded:\n%s", strings.Join(expected, "\n"), strings.Join(upgraded, "\n"))
+		}
+	}
+
+	testCases := []struct {
+		id        string
+		status    string
+		canonical string
+	}{
+		{id: "5f0c1ab2-6d7e-4b53-9a0f-0d3c1f8a1b01", status: "active", canonical: "alice"},
+		{id: "5f0c1ab2-6d7e-4b53-9a0f-0d3c1f8a1b02", status: "pending", canonical: "bob_smith"},
+	}
+	for _, tc := range testCases {
+		var status, canonical string
+		if err := pool.QueryRow(context.TODO(), queryStatus, tc.id).Scan(&status, &canonical); err != nil {
+			t.Fatal(err)
+		}
+		if status != tc.status || canonical != tc.canonical {
+			t.Fatalf("%s: expected %s %s, got %s %s", tc.id, tc.status, tc.canonical, status, canonical)
+		}
+	}
+
+	foldGmail, err := os.ReadFile("./../migrations/fold_gmail_emails.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Exec(context.TODO(), string(foldGmail)); err != nil {
+		t.Fatalf("fold_gmail_emails.sql: %v", err)
+	}
+	var email string
+	if err := pool.QueryRow(context.TODO(), queryEmail, "5f0c1ab2-6d7e-4b53-9a0f-0d3c1f8a1b03").Scan(&email); err != nil {
+		t.Fatal(err)
+	}
+	if expected := models.NormalizeEmail("c.a.r.o.l+news@gmail.com", models.EmailPolicy{FoldGmail: true}); email != expected {
+		t.Fatalf("expected folded email %s, got %s", expected, email)
+	}
+	if _, err := conn.Exec(context.TODO(), "UPDATE users SET email = 'Ca.rol@googlemail.com' WHERE id = '5f0c1ab2-6d7e-4b53-9a0f-0d3c1f8a1b01';"); err != nil {
+		t.Fatal(err)
+	}
+	_, err = conn.Exec(context.TODO(), string(foldGmail))
+	conn.Exec(context.TODO(), "ROLLBACK;")
+	if err == nil || !strings.Contains(err.Error(), "carol@gmail.com") {
+		t.Fatalf("expected the folded collision to be reported, got %v", err)
+	}
+}
+
+func schemaLines(t *testing.T, pool *pgxpool.Pool, query string, schema string) []string {
+	rows, err := pool.Query(context.TODO(), query, schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var lines []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, line)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(lines)
+	return lines
+}
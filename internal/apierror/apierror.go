@@ -1,9 +1,11 @@
 package apierror
 
 import (
+	"encoding/json"
 	"errors"
 	"net/http"
 
+	config "github.com/Kin-dza-dzaa/userApi/configs"
 	"github.com/jackc/puddle"
 	"github.com/rs/zerolog"
 )
@@ -12,6 +14,7 @@ type UserHandler func(w http.ResponseWriter, r *http.Request) error
 
 type ApiError struct {
 	logger *zerolog.Logger
+	config *config.Config
 }
 
 func (apierror *ApiError) ErrorMiddleWare(next UserHandler) http.Handler {
@@ -20,36 +23,55 @@ func (apierror *ApiError) ErrorMiddleWare(next UserHandler) http.Handler {
 			switch err := err.(type) {
 
 			case *ErrorStruct:
-				w.WriteHeader(err.Code)
+				apierror.write(w, r, err)
+
+			case *OAuthError:
+				w.Header().Set("Content-type", "application/json")
+				w.Header().Set("Cache-Control", "no-store")
+				if err.Status == http.StatusUnauthorized {
+					w.Header().Set("WWW-Authenticate", `Basic realm="userapi"`)
+				}
+				w.WriteHeader(err.Status)
 				w.Write(err.Marshal())
 
 			default:
 
 				if errors.Is(err, puddle.ErrNotAvailable) {
 					apierror.logger.Err(err).Msg(err.Error())
-					w.WriteHeader(http.StatusTooManyRequests)
-					w.Write(NewErrorStruct("too many requests", "error", http.StatusTooManyRequests).Marshal())
+					apierror.write(w, r, NewCatalogError(CodeTooManyRequests, "too many requests"))
 					return
 				}
 
 				if errors.Is(err, puddle.ErrClosedPool) {
 					apierror.logger.Err(err).Msg(err.Error())
-					w.WriteHeader(http.StatusInternalServerError)
-					w.Write(NewErrorStruct("internal server error", "error", http.StatusInternalServerError).Marshal())
+					apierror.write(w, r, NewCatalogError(CodeInternalError, "internal server error"))
 					return
 				}
-				
+
 				apierror.logger.Err(err).Msg(err.Error())
-				w.WriteHeader(http.StatusInternalServerError)
-				w.Write(NewErrorStruct("unexpected error", "error", http.StatusInternalServerError).Marshal())
+				apierror.write(w, r, NewCatalogError(CodeUnexpectedError, "unexpected error"))
 			}
 
 		}
 	})
 }
 
-func NewApiError(logger *zerolog.Logger) *ApiError {
+// write renders err as application/problem+json unless the legacy format is enabled.
+func (apierror *ApiError) write(w http.ResponseWriter, r *http.Request, err *ErrorStruct) {
+	if apierror.config.LegacyErrorFormat {
+		w.Header().Set("Content-type", "application/json")
+		w.WriteHeader(err.Code)
+		w.Write(err.Marshal())
+		return
+	}
+	w.Header().Set("Content-type", "application/problem+json")
+	w.WriteHeader(err.Code)
+	json.NewEncoder(w).Encode(err.Problem(r.URL.Path))
+}
+
+func NewApiError(logger *zerolog.Logger, config *config.Config) *ApiError {
 	return &ApiError{
 		logger: logger,
+		config: config,
 	}
 }
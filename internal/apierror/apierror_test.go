@@ -2,22 +2,26 @@ package apierror
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	config "github.com/Kin-dza-dzaa/userApi/configs"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/suite"
 )
 
 type TestSuite struct {
 	suite.Suite
-	apiError *ApiError
+	apiError       *ApiError
+	legacyApiError *ApiError
 }
 
 func (suite *TestSuite) SetupSuite() {
 	logger := zerolog.New(nil).With().Timestamp().Caller().Logger()
-	suite.apiError = NewApiError(&logger)
+	suite.apiError = NewApiError(&logger, &config.Config{})
+	suite.legacyApiError = NewApiError(&logger, &config.Config{LegacyErrorFormat: true})
 }
 
 func (suite *TestSuite) TestMiddleware() {
@@ -62,7 +66,7 @@ func (suite *TestSuite) TestMiddleware() {
 		suite.T().Run(tc.name, func(t *testing.T) {
 			r := httptest.NewRequest("POST", "/test", nil)
 			w := httptest.NewRecorder()
-			suite.apiError.ErrorMiddleWare(func(w http.ResponseWriter, r *http.Request) error { return tc.expectedResponse }).ServeHTTP(w, r)
+			suite.legacyApiError.ErrorMiddleWare(func(w http.ResponseWriter, r *http.Request) error { return tc.expectedResponse }).ServeHTTP(w, r)
 			var response ErrorStruct
 			err := json.NewDecoder(w.Body).Decode(&response)
 			if err != nil {
@@ -73,6 +77,74 @@ func (suite *TestSuite) TestMiddleware() {
 	}
 }
 
+func (suite *TestSuite) TestProblemMiddleware() {
+	testCases := []struct {
+		name             string
+		err              error
+		expectedResponse Problem
+	}{
+		{
+			name: "catalog_error",
+			err:  NewCatalogError(CodeWrongPassword, "wrong password"),
+			expectedResponse: Problem{
+				Type:     ProblemTypePrefix + CodeWrongPassword,
+				Title:    Catalog[CodeWrongPassword].Title,
+				Status:   http.StatusBadRequest,
+				Detail:   "wrong password",
+				Instance: "/test",
+				Code:     CodeWrongPassword,
+			},
+		},
+		{
+			name: "validation_error",
+			err:  NewValidationError("invalid credentials", []FieldError{{Field: "user_name", Code: "too_short", Min: 8}}),
+			expectedResponse: Problem{
+				Type:     ProblemTypePrefix + CodeValidationFailed,
+				Title:    Catalog[CodeValidationFailed].Title,
+				Status:   http.StatusBadRequest,
+				Detail:   "invalid credentials",
+				Instance: "/test",
+				Code:     CodeValidationFailed,
+				Errors:   []FieldError{{Field: "user_name", Code: "too_short", Min: 8}},
+			},
+		},
+		{
+			name: "not_validated_error",
+			err:  errors.New("boom"),
+			expectedResponse: Problem{
+				Type:     ProblemTypePrefix + CodeUnexpectedError,
+				Title:    Catalog[CodeUnexpectedError].Title,
+				Status:   http.StatusInternalServerError,
+				Detail:   "unexpected error",
+				Instance: "/test",
+				Code:     CodeUnexpectedError,
+			},
+		},
+	}
+	for _, tc := range testCases {
+		suite.T().Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/test", nil)
+			w := httptest.NewRecorder()
+			suite.apiError.ErrorMiddleWare(func(w http.ResponseWriter, r *http.Request) error { return tc.err }).ServeHTTP(w, r)
+			var response Problem
+			err := json.NewDecoder(w.Body).Decode(&response)
+			if err != nil {
+				suite.FailNow(err.Error())
+			}
+			suite.Equal("application/problem+json", w.Header().Get("Content-type"))
+			suite.Equal(tc.expectedResponse.Status, w.Code)
+			suite.Equal(tc.expectedResponse, response)
+		})
+	}
+}
+
+func (suite *TestSuite) TestCatalog() {
+	for code, entry := range Catalog {
+		suite.NotEmpty(entry.Title, code)
+		suite.Equal(entry.Status, NewCatalogError(code, "").Code, code)
+	}
+}
+
 func TestMiddleware(t *testing.T) {
 	suite.Run(t, new(TestSuite))
 }
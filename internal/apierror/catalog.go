@@ -0,0 +1,139 @@
+package apierror
+
+import "net/http"
+
+// ProblemTypePrefix is prepended to a catalog code to build the "type" member of a problem.
+const ProblemTypePrefix = "urn:userapi:problem:"
+
+// Stable error codes, clients must rely on them instead of the message text.
+const (
+	CodeMalformedBody                = "malformed_body"
+	CodeValidationFailed             = "validation_failed"
+	CodeInvalidCredentials           = "invalid_credentials"
+	CodeCookieNotPresent             = "cookie_not_present"
+	CodeUserAlreadyExists            = "user_already_exists"
+	CodeUserNameTaken                = "username_taken"
+	CodeEmailTaken                   = "email_taken"
+	CodeRefreshTokenInvalid          = "refresh_token_invalid"
+	CodeWrongEmail                   = "wrong_email"
+	CodeWrongPassword                = "wrong_password"
+	CodeWrongVerificationCode        = "wrong_verification_code"
+	CodeAccessTokenInvalid           = "access_token_invalid"
+	CodeUserNotFound                 = "user_not_found"
+	CodeCsrfTokenMismatch            = "csrf_token_mismatch"
+	CodeSocialProviderUnknown        = "social_provider_unknown"
+	CodeSocialLoginFailed            = "social_login_failed"
+	CodeSocialEmailUnverified        = "social_email_unverified"
+	CodePersonalAccessTokenNotFound  = "personal_access_token_not_found"
+	CodePersonalAccessTokenForbidden = "personal_access_token_forbidden"
+	CodeUserCodeInvalid              = "user_code_invalid"
+	CodeDeviceNotConfirmed           = "device_not_confirmed"
+	CodeRoleNotFound                 = "role_not_found"
+	CodePermissionNotFound           = "permission_not_found"
+	CodePermissionDenied             = "permission_denied"
+	CodePasswordResetInvalid         = "password_reset_invalid"
+	CodeSecureAccountInvalid         = "secure_account_invalid"
+	CodeEmailDomainNotAllowed        = "email_domain_not_allowed"
+	CodeEmailDomainRuleNotFound      = "email_domain_rule_not_found"
+	CodeChallengeRequired            = "challenge_required"
+	CodeChallengeFailed              = "challenge_failed"
+	CodeReauthenticationRequired     = "reauthentication_required"
+	CodeAdminSelfAction              = "admin_self_action"
+	CodeAccountSuspended             = "account_suspended"
+	CodeAccountLocked                = "account_locked"
+	CodeAccountInactive              = "account_inactive"
+	CodeStatusTransitionInvalid      = "status_transition_invalid"
+	CodeTooManyRequests              = "too_many_requests"
+	CodeInternalError                = "internal_error"
+	CodeUnexpectedError              = "unexpected_error"
+)
+
+type CatalogEntry struct {
+	Title  string
+	Status int
+}
+
+// Catalog describes every error this service can return.
+var Catalog = map[string]CatalogEntry{
+	CodeMalformedBody:                {Title: "Request body is malformed", Status: http.StatusBadRequest},
+	CodeValidationFailed:             {Title: "Validation failed", Status: http.StatusBadRequest},
+	CodeInvalidCredentials:           {Title: "Invalid credentials", Status: http.StatusBadRequest},
+	CodeCookieNotPresent:             {Title: "Cookie not present", Status: http.StatusBadRequest},
+	CodeUserAlreadyExists:            {Title: "User already exists", Status: http.StatusConflict},
+	CodeUserNameTaken:                {Title: "User name already taken", Status: http.StatusConflict},
+	CodeEmailTaken:                   {Title: "Email already taken", Status: http.StatusConflict},
+	CodeRefreshTokenInvalid:          {Title: "Refresh token expired or user doesn't exist", Status: http.StatusBadRequest},
+	CodeWrongEmail:                   {Title: "Wrong email", Status: http.StatusBadRequest},
+	CodeWrongPassword:                {Title: "Wrong password", Status: http.StatusBadRequest},
+	CodeWrongVerificationCode:        {Title: "Wrong verification code", Status: http.StatusBadRequest},
+	CodeAccessTokenInvalid:           {Title: "Access token expired or invalid", Status: http.StatusUnauthorized},
+	CodeUserNotFound:                 {Title: "User not found", Status: http.StatusNotFound},
+	CodeCsrfTokenMismatch:            {Title: "CSRF token mismatch", Status: http.StatusForbidden},
+	CodeSocialProviderUnknown:        {Title: "Social login provider is not configured", Status: http.StatusNotFound},
+	CodeSocialLoginFailed:            {Title: "Social login failed", Status: http.StatusBadRequest},
+	CodeSocialEmailUnverified:        {Title: "Email is not verified by the social login provider", Status: http.StatusForbidden},
+	CodePersonalAccessTokenNotFound:  {Title: "Personal access token not found", Status: http.StatusNotFound},
+	CodePersonalAccessTokenForbidden: {Title: "Personal access tokens can't be used on this endpoint", Status: http.StatusForbidden},
+	CodeUserCodeInvalid:              {Title: "User code expired, used or doesn't exist", Status: http.StatusBadRequest},
+	CodeDeviceNotConfirmed:           {Title: "The approval doesn't confirm the client of the user code", Status: http.StatusBadRequest},
+	CodeRoleNotFound:                 {Title: "Role not found", Status: http.StatusNotFound},
+	CodePermissionNotFound:           {Title: "Permission not found", Status: http.StatusNotFound},
+	CodePermissionDenied:             {Title: "Permission denied", Status: http.StatusForbidden},
+	CodePasswordResetInvalid:         {Title: "Password reset code expired, used or doesn't exist", Status: http.StatusBadRequest},
+	CodeSecureAccountInvalid:         {Title: "Secure account link expired, used or doesn't exist", Status: http.StatusBadRequest},
+	CodeEmailDomainNotAllowed:        {Title: "Email domain isn't allowed", Status: http.StatusBadRequest},
+	CodeEmailDomainRuleNotFound:      {Title: "Email domain rule not found", Status: http.StatusNotFound},
+	CodeChallengeRequired:            {Title: "Solve the challenge of GET /user/challenge first", Status: http.StatusBadRequest},
+	CodeChallengeFailed:              {Title: "Challenge expired, used or not solved", Status: http.StatusForbidden},
+	CodeReauthenticationRequired:     {Title: "Sign in again with POST /user/reauth to continue", Status: http.StatusForbidden},
+	CodeAdminSelfAction:              {Title: "Admins can't suspend, lock or delete themselves", Status: http.StatusConflict},
+	CodeAccountSuspended:             {Title: "Account is suspended", Status: http.StatusForbidden},
+	CodeAccountLocked:                {Title: "Account is locked, reset the password to unlock it", Status: http.StatusForbidden},
+	CodeAccountInactive:              {Title: "Account is not active", Status: http.StatusForbidden},
+	CodeStatusTransitionInvalid:      {Title: "Account status can't change that way", Status: http.StatusConflict},
+	CodeTooManyRequests:              {Title: "Too many requests", Status: http.StatusTooManyRequests},
+	CodeInternalError:                {Title: "Internal server error", Status: http.StatusInternalServerError},
+	CodeUnexpectedError:              {Title: "Unexpected error", Status: http.StatusInternalServerError},
+}
+
+// NewCatalogError builds an error for a catalog code, Message becomes the problem detail.
+func NewCatalogError(code string, Message string) *ErrorStruct {
+	entry, ok := Catalog[code]
+	if !ok {
+		entry = Catalog[CodeUnexpectedError]
+	}
+	return &ErrorStruct{
+		Result:    "error",
+		Message:   Message,
+		Code:      entry.Status,
+		ErrorCode: code,
+	}
+}
+
+type Problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Code     string       `json:"code,omitempty"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+// Problem converts the error into an RFC 7807 problem for the given instance.
+func (errorStruct *ErrorStruct) Problem(instance string) *Problem {
+	problem := &Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(errorStruct.Code),
+		Status:   errorStruct.Code,
+		Detail:   errorStruct.Message,
+		Instance: instance,
+		Code:     errorStruct.ErrorCode,
+		Errors:   errorStruct.Errors,
+	}
+	if entry, ok := Catalog[errorStruct.ErrorCode]; ok {
+		problem.Type = ProblemTypePrefix + errorStruct.ErrorCode
+		problem.Title = entry.Title
+	}
+	return problem
+}
@@ -2,12 +2,21 @@ package apierror
 
 import (
 	"encoding/json"
+	"net/http"
 )
 
+type FieldError struct {
+	Field string `json:"field"`
+	Code  string `json:"code"`
+	Min   int    `json:"min,omitempty"`
+}
+
 type ErrorStruct struct {
-	Message string `json:"message,omitempty"`
-	Result  string `json:"result,omitempty"`
-	Code    int    `json:"code"`
+	Message   string       `json:"message,omitempty"`
+	Result    string       `json:"result,omitempty"`
+	Code      int          `json:"code"`
+	Errors    []FieldError `json:"errors,omitempty"`
+	ErrorCode string       `json:"-"`
 }
 
 func (errorStruct ErrorStruct) Error() string {
@@ -29,3 +38,14 @@ func NewErrorStruct(Message string, Result string, code int) *ErrorStruct {
 		Code:    code,
 	}
 }
+
+// NewValidationError returns a 400 error listing every field that failed validation.
+func NewValidationError(Message string, errors []FieldError) *ErrorStruct {
+	return &ErrorStruct{
+		Result:    "error",
+		Message:   Message,
+		Code:      http.StatusBadRequest,
+		Errors:    errors,
+		ErrorCode: CodeValidationFailed,
+	}
+}
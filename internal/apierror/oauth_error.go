@@ -0,0 +1,60 @@
+package apierror
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// OAuth 2.0 error codes (RFC 6749 section 5.2 and 4.1.2.1), they are sent as is instead of problem+json.
+const (
+	OAuthInvalidRequest          = "invalid_request"
+	OAuthInvalidClient           = "invalid_client"
+	OAuthInvalidGrant            = "invalid_grant"
+	OAuthUnauthorizedClient      = "unauthorized_client"
+	OAuthUnsupportedGrantType    = "unsupported_grant_type"
+	OAuthUnsupportedResponseType = "unsupported_response_type"
+	OAuthInvalidScope            = "invalid_scope"
+	OAuthAccessDenied            = "access_denied"
+	OAuthInvalidToken            = "invalid_token"
+	OAuthServerError             = "server_error"
+	// device authorization grant (RFC 8628 section 3.5)
+	OAuthAuthorizationPending = "authorization_pending"
+	OAuthSlowDown             = "slow_down"
+	OAuthExpiredToken         = "expired_token"
+)
+
+var oauthStatuses = map[string]int{
+	OAuthInvalidClient: http.StatusUnauthorized,
+	OAuthInvalidToken:  http.StatusUnauthorized,
+	OAuthServerError:   http.StatusInternalServerError,
+}
+
+type OAuthError struct {
+	ErrorCode   string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+	Status      int    `json:"-"`
+}
+
+func (oauthError OAuthError) Error() string {
+	return oauthError.Description
+}
+
+func (oauthError *OAuthError) Marshal() []byte {
+	responseBytes, err := json.Marshal(oauthError)
+	if err != nil {
+		return nil
+	}
+	return responseBytes
+}
+
+func NewOAuthError(code string, description string) *OAuthError {
+	status, ok := oauthStatuses[code]
+	if !ok {
+		status = http.StatusBadRequest
+	}
+	return &OAuthError{
+		ErrorCode:   code,
+		Description: description,
+		Status:      status,
+	}
+}
@@ -0,0 +1,122 @@
+package dto
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+)
+
+const (
+	DefaultPerPage  = 20
+	MaxPerPage      = 100
+	MaxReasonLength = 512
+
+	CodeInvalidBool   = "invalid_bool"
+	CodeInvalidDate   = "invalid_date"
+	CodeInvalidStatus = "invalid_status"
+)
+
+var (
+	ErrInvalidUserFilter    = errors.New("invalid user filter")
+	ErrInvalidAdminAction   = errors.New("invalid admin action")
+	ErrInvalidPasswordReset = errors.New("invalid password reset")
+)
+
+// UserSearchDto is filled from the query string of GET /admin/users, dates are RFC 3339 or YYYY-MM-DD.
+type UserSearchDto struct {
+	Email            string
+	UserName         string
+	Verified         string
+	Status           string
+	RegisteredAfter  string
+	RegisteredBefore string
+	Page             string
+	PerPage          string
+}
+
+func (dto UserSearchDto) IntoUserFilter() (*models.UserFilter, error) {
+	var fieldErrors []apierror.FieldError
+	filter := &models.UserFilter{Email: dto.Email, UserName: dto.UserName, Status: dto.Status, Page: 1, PerPage: DefaultPerPage}
+	if dto.Verified != "" {
+		verified, err := strconv.ParseBool(dto.Verified)
+		if err != nil {
+			fieldErrors = append(fieldErrors, apierror.FieldError{Field: "verified", Code: CodeInvalidBool})
+		}
+		filter.Verified = &verified
+	}
+	if dto.Status != "" && !models.IsUserStatus(dto.Status) {
+		fieldErrors = append(fieldErrors, apierror.FieldError{Field: "status", Code: CodeInvalidStatus})
+	}
+	var fieldError *apierror.FieldError
+	if filter.RegisteredAfter, fieldError = parseDate("registered_after", dto.RegisteredAfter); fieldError != nil {
+		fieldErrors = append(fieldErrors, *fieldError)
+	}
+	if filter.RegisteredBefore, fieldError = parseDate("registered_before", dto.RegisteredBefore); fieldError != nil {
+		fieldErrors = append(fieldErrors, *fieldError)
+	}
+	if dto.Page != "" {
+		page, err := strconv.Atoi(dto.Page)
+		if err != nil || page < 1 {
+			fieldErrors = append(fieldErrors, apierror.FieldError{Field: "page", Code: CodeOutOfRange})
+		}
+		filter.Page = page
+	}
+	if dto.PerPage != "" {
+		perPage, err := strconv.Atoi(dto.PerPage)
+		if err != nil || perPage < 1 || perPage > MaxPerPage {
+			fieldErrors = append(fieldErrors, apierror.FieldError{Field: "per_page", Code: CodeOutOfRange})
+		}
+		filter.PerPage = perPage
+	}
+	if len(fieldErrors) != 0 {
+		return nil, apierror.NewValidationError(ErrInvalidUserFilter.Error(), fieldErrors)
+	}
+	return filter, nil
+}
+
+func parseDate(field string, value string) (*time.Time, *apierror.FieldError) {
+	if value == "" {
+		return nil, nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if date, err := time.Parse(layout, value); err == nil {
+			date = date.UTC()
+			return &date, nil
+		}
+	}
+	return nil, &apierror.FieldError{Field: field, Code: CodeInvalidDate}
+}
+
+// AdminActionDto is the optional body of admin actions, the reason is kept in the audit trail.
+type AdminActionDto struct {
+	Reason string `json:"reason"`
+}
+
+func (dto AdminActionDto) IntoAdminAction() (*models.AdminAction, error) {
+	if len(dto.Reason) > MaxReasonLength {
+		return nil, apierror.NewValidationError(ErrInvalidAdminAction.Error(), []apierror.FieldError{{Field: "reason", Code: CodeTooLong}})
+	}
+	return &models.AdminAction{Reason: dto.Reason}, nil
+}
+
+type PasswordResetDto struct {
+	Code     string `json:"code"`
+	Password string `json:"password"`
+}
+
+func (dto PasswordResetDto) IntoPasswordReset() (*models.PasswordReset, error) {
+	var fieldErrors []apierror.FieldError
+	if dto.Code == "" {
+		fieldErrors = append(fieldErrors, apierror.FieldError{Field: "code", Code: CodeRequired})
+	}
+	if fieldError := validateLength("password", dto.Password, MinPasswordLength); fieldError != nil {
+		fieldErrors = append(fieldErrors, *fieldError)
+	}
+	if len(fieldErrors) != 0 {
+		return nil, apierror.NewValidationError(ErrInvalidPasswordReset.Error(), fieldErrors)
+	}
+	return &models.PasswordReset{Code: dto.Code, Password: dto.Password}, nil
+}
@@ -0,0 +1,18 @@
+package dto
+
+import (
+	"testing"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+)
+
+func TestIntoUserFilter(t *testing.T) {
+	filter, err := UserSearchDto{Verified: "true", Status: "locked", RegisteredBefore: "2022-10-01T10:00:00+03:00"}.IntoUserFilter()
+	if err != nil || !*filter.Verified || filter.Status != "locked" || filter.RegisteredBefore.Hour() != 7 || filter.Page != 1 || filter.PerPage != DefaultPerPage {
+		t.Fatalf("unexpected filter %+v, %v", filter, err)
+	}
+	_, err = UserSearchDto{Verified: "yes!", Status: "banned", RegisteredAfter: "yesterday", Page: "0", PerPage: "101"}.IntoUserFilter()
+	if err == nil || len(err.(*apierror.ErrorStruct).Errors) != 5 {
+		t.Fatalf("expected 5 field errors, got %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+package dto
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultAuditLimit = 50
+	MaxAuditLimit     = 200
+
+	CodeInvalidUuid    = "invalid_uuid"
+	CodeInvalidOutcome = "invalid_outcome"
+	CodeInvalidCursor  = "invalid_cursor"
+)
+
+var ErrInvalidAuditFilter = errors.New("invalid audit filter")
+
+// AuditSearchDto is filled from the query string of GET /admin/audit and GET /user/activity, dates are RFC 3339 or YYYY-MM-DD.
+// Cursor is the next_cursor of the previous page.
+type AuditSearchDto struct {
+	Type     string
+	ActorId  string
+	TargetId string
+	Outcome  string
+	From     string
+	To       string
+	Cursor   string
+	Limit    string
+}
+
+func (dto AuditSearchDto) IntoAuditFilter() (*models.AuditFilter, error) {
+	var fieldErrors []apierror.FieldError
+	filter := &models.AuditFilter{Type: dto.Type, Outcome: dto.Outcome, Limit: DefaultAuditLimit}
+	var fieldError *apierror.FieldError
+	if filter.ActorId, fieldError = parseUuid("actor_id", dto.ActorId); fieldError != nil {
+		fieldErrors = append(fieldErrors, *fieldError)
+	}
+	if filter.TargetId, fieldError = parseUuid("target_id", dto.TargetId); fieldError != nil {
+		fieldErrors = append(fieldErrors, *fieldError)
+	}
+	if dto.Outcome != "" && dto.Outcome != models.AuditOutcomeSuccess && dto.Outcome != models.AuditOutcomeFailure {
+		fieldErrors = append(fieldErrors, apierror.FieldError{Field: "outcome", Code: CodeInvalidOutcome})
+	}
+	if filter.From, fieldError = parseDate("from", dto.From); fieldError != nil {
+		fieldErrors = append(fieldErrors, *fieldError)
+	}
+	if filter.To, fieldError = parseDate("to", dto.To); fieldError != nil {
+		fieldErrors = append(fieldErrors, *fieldError)
+	}
+	if dto.Cursor != "" {
+		cursor, err := models.DecodeAuditCursor(dto.Cursor)
+		if err != nil {
+			fieldErrors = append(fieldErrors, apierror.FieldError{Field: "cursor", Code: CodeInvalidCursor})
+		}
+		filter.After = cursor
+	}
+	if dto.Limit != "" {
+		limit, err := strconv.Atoi(dto.Limit)
+		if err != nil || limit < 1 || limit > MaxAuditLimit {
+			fieldErrors = append(fieldErrors, apierror.FieldError{Field: "limit", Code: CodeOutOfRange})
+		}
+		filter.Limit = limit
+	}
+	if len(fieldErrors) != 0 {
+		return nil, apierror.NewValidationError(ErrInvalidAuditFilter.Error(), fieldErrors)
+	}
+	return filter, nil
+}
+
+func parseUuid(field string, value string) (*uuid.UUID, *apierror.FieldError) {
+	if value == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return nil, &apierror.FieldError{Field: field, Code: CodeInvalidUuid}
+	}
+	return &id, nil
+}
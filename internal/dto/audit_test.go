@@ -0,0 +1,23 @@
+package dto
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/google/uuid"
+)
+
+func TestIntoAuditFilter(t *testing.T) {
+	cursor := &models.AuditCursor{CreatedAt: time.Date(2022, 10, 1, 10, 0, 0, 5, time.UTC), EventId: uuid.New()}
+	actorId := uuid.New()
+	filter, err := AuditSearchDto{ActorId: actorId.String(), Outcome: "failure", From: "2022-09-01", Cursor: cursor.Encode()}.IntoAuditFilter()
+	if err != nil || *filter.ActorId != actorId || filter.Outcome != "failure" || filter.From.Month() != 9 || *filter.After != *cursor || filter.Limit != DefaultAuditLimit {
+		t.Fatalf("unexpected filter %+v, %v", filter, err)
+	}
+	_, err = AuditSearchDto{ActorId: "me", TargetId: "you", Outcome: "maybe", To: "tomorrow", Cursor: "abc", Limit: "201"}.IntoAuditFilter()
+	if err == nil || len(err.(*apierror.ErrorStruct).Errors) != 6 {
+		t.Fatalf("expected 6 field errors, got %v", err)
+	}
+}
@@ -0,0 +1,28 @@
+package dto
+
+import (
+	"errors"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+)
+
+const CodeInvalidEndpoint = "invalid_endpoint"
+
+var ErrInvalidChallengeEndpoint = errors.New("invalid challenge endpoint")
+
+// ChallengeDto is the query of GET /user/challenge, Endpoint defaults to sign up.
+type ChallengeDto struct {
+	Endpoint string
+}
+
+func (dto ChallengeDto) IntoEndpoint() (string, error) {
+	switch dto.Endpoint {
+	case "":
+		return models.ChallengeSignUp, nil
+	case models.ChallengeSignUp, models.ChallengeSignIn:
+		return dto.Endpoint, nil
+	default:
+		return "", apierror.NewValidationError(ErrInvalidChallengeEndpoint.Error(), []apierror.FieldError{{Field: "endpoint", Code: CodeInvalidEndpoint}})
+	}
+}
@@ -0,0 +1,35 @@
+package dto
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/google/uuid"
+)
+
+var ErrInvalidDeviceApproval = errors.New("invalid device approval")
+
+// DeviceApprovalDto approves (or with approve=false denies) the device that shows user_code. An approval confirms
+// client_id, the client GET /user/device showed for the user code.
+type DeviceApprovalDto struct {
+	UserCode string `json:"user_code"`
+	ClientId string `json:"client_id"`
+	Approve  bool   `json:"approve"`
+}
+
+func (dto DeviceApprovalDto) IntoDeviceApproval(userId uuid.UUID) (*models.DeviceApproval, error) {
+	var fieldErrors []apierror.FieldError
+	userCode := strings.TrimSpace(dto.UserCode)
+	if userCode == "" {
+		fieldErrors = append(fieldErrors, apierror.FieldError{Field: "user_code", Code: CodeRequired})
+	}
+	if dto.Approve && dto.ClientId == "" {
+		fieldErrors = append(fieldErrors, apierror.FieldError{Field: "client_id", Code: CodeRequired})
+	}
+	if len(fieldErrors) != 0 {
+		return nil, apierror.NewValidationError(ErrInvalidDeviceApproval.Error(), fieldErrors)
+	}
+	return &models.DeviceApproval{UserCode: userCode, UserId: userId, ClientId: dto.ClientId, Approve: dto.Approve}, nil
+}
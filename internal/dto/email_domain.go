@@ -0,0 +1,40 @@
+package dto
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+)
+
+const (
+	CodeInvalidDomain = "invalid_domain"
+	CodeInvalidKind   = "invalid_kind"
+)
+
+var ErrInvalidEmailDomainRule = errors.New("invalid email domain rule")
+
+// domains are written in ASCII, internationalized ones in their punycode form
+var domainPattern = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)*[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// EmailDomainRuleDto sets the rule of the domain named in the path, Kind is "allow" or "block".
+type EmailDomainRuleDto struct {
+	Kind string `json:"kind"`
+}
+
+func (dto EmailDomainRuleDto) IntoEmailDomainRule(domain string) (*models.EmailDomainRule, error) {
+	var fieldErrors []apierror.FieldError
+	domain = strings.ToLower(domain)
+	if len(domain) > 253 || !domainPattern.MatchString(domain) {
+		fieldErrors = append(fieldErrors, apierror.FieldError{Field: "domain", Code: CodeInvalidDomain})
+	}
+	if dto.Kind != models.EmailDomainAllow && dto.Kind != models.EmailDomainBlock {
+		fieldErrors = append(fieldErrors, apierror.FieldError{Field: "kind", Code: CodeInvalidKind})
+	}
+	if len(fieldErrors) != 0 {
+		return nil, apierror.NewValidationError(ErrInvalidEmailDomainRule.Error(), fieldErrors)
+	}
+	return &models.EmailDomainRule{Domain: domain, Kind: dto.Kind}, nil
+}
@@ -0,0 +1,65 @@
+package dto
+
+import (
+	"errors"
+	"time"
+	// timezones are validated against the embedded database, the image doesn't ship one
+	_ "time/tzdata"
+	"unicode/utf8"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"golang.org/x/text/language"
+)
+
+const (
+	MaxDisplayNameLength = 64
+
+	CodeInvalidLocale   = "invalid_locale"
+	CodeInvalidTimezone = "invalid_timezone"
+)
+
+var ErrInvalidProfile = errors.New("invalid profile")
+
+// ProfileUpdateDto is the body of PATCH /user/me, fields that are left out don't change.
+// Locales are BCP 47 tags and timezones IANA names.
+type ProfileUpdateDto struct {
+	UserName    *string `json:"user_name"`
+	DisplayName *string `json:"display_name"`
+	Locale      *string `json:"locale"`
+	Timezone    *string `json:"timezone"`
+}
+
+func (dto ProfileUpdateDto) IntoProfileUpdate() (*models.ProfileUpdate, error) {
+	var fieldErrors []apierror.FieldError
+	update := &models.ProfileUpdate{DisplayName: dto.DisplayName}
+	if dto.UserName != nil {
+		userName, fieldError := validateUserName(*dto.UserName)
+		if fieldError != nil {
+			fieldErrors = append(fieldErrors, *fieldError)
+		}
+		update.UserName = &userName
+	}
+	if dto.DisplayName != nil && utf8.RuneCountInString(*dto.DisplayName) > MaxDisplayNameLength {
+		fieldErrors = append(fieldErrors, apierror.FieldError{Field: "display_name", Code: CodeTooLong})
+	}
+	if dto.Locale != nil {
+		tag, err := language.Parse(*dto.Locale)
+		if err != nil {
+			fieldErrors = append(fieldErrors, apierror.FieldError{Field: "locale", Code: CodeInvalidLocale})
+		}
+		locale := tag.String()
+		update.Locale = &locale
+	}
+	if dto.Timezone != nil {
+		// an empty name and "Local" load the server's zone, not one the user picked
+		if _, err := time.LoadLocation(*dto.Timezone); err != nil || *dto.Timezone == "" || *dto.Timezone == "Local" {
+			fieldErrors = append(fieldErrors, apierror.FieldError{Field: "timezone", Code: CodeInvalidTimezone})
+		}
+		update.Timezone = dto.Timezone
+	}
+	if len(fieldErrors) != 0 {
+		return nil, apierror.NewValidationError(ErrInvalidProfile.Error(), fieldErrors)
+	}
+	return update, nil
+}
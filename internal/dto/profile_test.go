@@ -0,0 +1,21 @@
+package dto
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+)
+
+func TestIntoProfileUpdate(t *testing.T) {
+	userName, locale, timezone := "new_user_name", "EN-gb", "Europe/Berlin"
+	update, err := ProfileUpdateDto{UserName: &userName, Locale: &locale, Timezone: &timezone}.IntoProfileUpdate()
+	if err != nil || *update.UserName != userName || *update.Locale != "en-GB" || *update.Timezone != timezone || update.DisplayName != nil {
+		t.Fatalf("unexpected update %+v, %v", update, err)
+	}
+	short, displayName, badLocale, badTimezone := "short", strings.Repeat("ы", MaxDisplayNameLength+1), "not a locale", "Local"
+	_, err = ProfileUpdateDto{UserName: &short, DisplayName: &displayName, Locale: &badLocale, Timezone: &badTimezone}.IntoProfileUpdate()
+	if err == nil || len(err.(*apierror.ErrorStruct).Errors) != 4 {
+		t.Fatalf("expected 4 field errors, got %v", err)
+	}
+}
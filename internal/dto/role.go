@@ -0,0 +1,71 @@
+package dto
+
+import (
+	"errors"
+	"regexp"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+)
+
+const (
+	MaxDescriptionLength = 256
+
+	CodeInvalidName = "invalid_name"
+)
+
+var (
+	ErrInvalidRole       = errors.New("invalid role")
+	ErrInvalidPermission = errors.New("invalid permission")
+)
+
+// role and permission names are also used as scopes, so they can't contain spaces
+var accessNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_.:-]{0,63}$`)
+
+// RoleDto replaces the role named in the path, Permissions are the names of existing permissions.
+type RoleDto struct {
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+func (dto RoleDto) IntoRole(name string) (*models.Role, error) {
+	var fieldErrors []apierror.FieldError
+	if !accessNamePattern.MatchString(name) {
+		fieldErrors = append(fieldErrors, apierror.FieldError{Field: "name", Code: CodeInvalidName})
+	}
+	if len(dto.Description) > MaxDescriptionLength {
+		fieldErrors = append(fieldErrors, apierror.FieldError{Field: "description", Code: CodeTooLong})
+	}
+	for _, permission := range dto.Permissions {
+		if !accessNamePattern.MatchString(permission) {
+			fieldErrors = append(fieldErrors, apierror.FieldError{Field: "permissions", Code: CodeInvalidName})
+			break
+		}
+	}
+	if len(fieldErrors) != 0 {
+		return nil, apierror.NewValidationError(ErrInvalidRole.Error(), fieldErrors)
+	}
+	role := &models.Role{Name: name, Description: dto.Description, Permissions: dto.Permissions}
+	if role.Permissions == nil {
+		role.Permissions = []string{}
+	}
+	return role, nil
+}
+
+type PermissionDto struct {
+	Description string `json:"description"`
+}
+
+func (dto PermissionDto) IntoPermission(name string) (*models.Permission, error) {
+	var fieldErrors []apierror.FieldError
+	if !accessNamePattern.MatchString(name) {
+		fieldErrors = append(fieldErrors, apierror.FieldError{Field: "name", Code: CodeInvalidName})
+	}
+	if len(dto.Description) > MaxDescriptionLength {
+		fieldErrors = append(fieldErrors, apierror.FieldError{Field: "description", Code: CodeTooLong})
+	}
+	if len(fieldErrors) != 0 {
+		return nil, apierror.NewValidationError(ErrInvalidPermission.Error(), fieldErrors)
+	}
+	return &models.Permission{Name: name, Description: dto.Description}, nil
+}
@@ -0,0 +1,58 @@
+package dto
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+)
+
+const (
+	MaxTokenNameLength = 64
+
+	CodeTooLong      = "too_long"
+	CodeInvalidScope = "invalid_scope"
+	CodeOutOfRange   = "out_of_range"
+)
+
+var ErrInvalidToken = errors.New("invalid personal access token")
+
+// PersonalAccessTokenDto creates a token, ExpiresInDays = 0 means the token never expires.
+type PersonalAccessTokenDto struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays int      `json:"expires_in_days"`
+}
+
+func (dto PersonalAccessTokenDto) IntoPersonalAccessToken() (*models.PersonalAccessToken, error) {
+	var fieldErrors []apierror.FieldError
+	name := strings.TrimSpace(dto.Name)
+	if name == "" {
+		fieldErrors = append(fieldErrors, apierror.FieldError{Field: "name", Code: CodeRequired})
+	} else if len(name) > MaxTokenNameLength {
+		fieldErrors = append(fieldErrors, apierror.FieldError{Field: "name", Code: CodeTooLong})
+	}
+	for _, scope := range dto.Scopes {
+		if scope == "" || strings.ContainsAny(scope, " \t\r\n") {
+			fieldErrors = append(fieldErrors, apierror.FieldError{Field: "scopes", Code: CodeInvalidScope})
+			break
+		}
+	}
+	if dto.ExpiresInDays < 0 || dto.ExpiresInDays > 366 {
+		fieldErrors = append(fieldErrors, apierror.FieldError{Field: "expires_in_days", Code: CodeOutOfRange})
+	}
+	if len(fieldErrors) != 0 {
+		return nil, apierror.NewValidationError(ErrInvalidToken.Error(), fieldErrors)
+	}
+	token := &models.PersonalAccessToken{Name: name, Scopes: dto.Scopes}
+	if token.Scopes == nil {
+		token.Scopes = []string{}
+	}
+	if dto.ExpiresInDays != 0 {
+		expirationTime := time.Now().UTC().AddDate(0, 0, dto.ExpiresInDays)
+		token.ExpirationTime = &expirationTime
+	}
+	return token, nil
+}
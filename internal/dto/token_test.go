@@ -0,0 +1,50 @@
+package dto
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+)
+
+func TestPersonalAccessTokenDto_FieldErrors(t *testing.T) {
+	testCases := []struct {
+		name   string
+		dto    PersonalAccessTokenDto
+		errors []apierror.FieldError
+	}{
+		{
+			name: "valid",
+			dto:  PersonalAccessTokenDto{Name: "import", Scopes: []string{"words:write"}, ExpiresInDays: 30},
+		},
+		{
+			name:   "missing_name",
+			dto:    PersonalAccessTokenDto{Name: "  "},
+			errors: []apierror.FieldError{{Field: "name", Code: CodeRequired}},
+		},
+		{
+			name: "everything_wrong",
+			dto:  PersonalAccessTokenDto{Name: strings.Repeat("a", MaxTokenNameLength+1), Scopes: []string{"words write"}, ExpiresInDays: -1},
+			errors: []apierror.FieldError{
+				{Field: "name", Code: CodeTooLong},
+				{Field: "scopes", Code: CodeInvalidScope},
+				{Field: "expires_in_days", Code: CodeOutOfRange},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			token, err := tc.dto.IntoPersonalAccessToken()
+			if tc.errors == nil {
+				if err != nil || token.ExpirationTime == nil {
+					t.FailNow()
+				}
+				return
+			}
+			if !reflect.DeepEqual(tc.errors, err.(*apierror.ErrorStruct).Errors) {
+				t.Fatalf("got %v", err.(*apierror.ErrorStruct).Errors)
+			}
+		})
+	}
+}
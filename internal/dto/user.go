@@ -2,33 +2,51 @@ package dto
 
 import (
 	"errors"
-	"net/http"
 	"net/mail"
 
 	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
 	"github.com/Kin-dza-dzaa/userApi/internal/models"
 )
 
+const (
+	MinPasswordLength = 8
+	MinUserNameLength = 8
+)
+
+// machine-readable codes of apierror.FieldError
+const (
+	CodeRequired     = "required"
+	CodeInvalidEmail = "invalid_email"
+	CodeTooShort     = "too_short"
+)
+
 var (
-	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrInvalidCredentials   = errors.New("invalid credentials")
+	ErrInvalidSecureAccount = errors.New("invalid secure account request")
 )
 
 type UserSignInDto struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email      string `json:"email"`
+	Password   string `json:"password"`
+	RememberMe bool   `json:"remember_me"`
 }
 
 func (dto UserSignInDto) IntoUser() (*models.User, error) {
-	email, err := mail.ParseAddress(dto.Email)
-	if err != nil {
-		return nil, apierror.NewErrorStruct(ErrInvalidCredentials.Error(), "error", http.StatusBadRequest)
+	var fieldErrors []apierror.FieldError
+	email, fieldError := validateEmail(dto.Email)
+	if fieldError != nil {
+		fieldErrors = append(fieldErrors, *fieldError)
 	}
-	if len(dto.Password) < 8 {
-		return nil, apierror.NewErrorStruct(ErrInvalidCredentials.Error(), "error", http.StatusBadRequest)
+	if fieldError := validateLength("password", dto.Password, MinPasswordLength); fieldError != nil {
+		fieldErrors = append(fieldErrors, *fieldError)
+	}
+	if len(fieldErrors) != 0 {
+		return nil, apierror.NewValidationError(ErrInvalidCredentials.Error(), fieldErrors)
 	}
 	var User models.User
-	User.Email = email.Address
+	User.Email = email
 	User.Password = dto.Password
+	User.RememberMe = dto.RememberMe
 	return &User, nil
 }
 
@@ -39,20 +57,73 @@ type UserSignUpDto struct {
 }
 
 func (dto UserSignUpDto) IntoUser() (*models.User, error) {
-	email, err := mail.ParseAddress(dto.Email)
-	if err != nil {
-		return nil, apierror.NewErrorStruct(ErrInvalidCredentials.Error(), "error", http.StatusBadRequest)
+	var fieldErrors []apierror.FieldError
+	userName, fieldError := validateUserName(dto.UserName)
+	if fieldError != nil {
+		fieldErrors = append(fieldErrors, *fieldError)
 	}
-
-	if len(dto.Password) < 8 {
-		return nil, apierror.NewErrorStruct(ErrInvalidCredentials.Error(), "error", http.StatusBadRequest)
+	email, fieldError := validateEmail(dto.Email)
+	if fieldError != nil {
+		fieldErrors = append(fieldErrors, *fieldError)
+	}
+	if fieldError := validateLength("password", dto.Password, MinPasswordLength); fieldError != nil {
+		fieldErrors = append(fieldErrors, *fieldError)
 	}
-	if len(dto.UserName) < 8 {
-		return nil, apierror.NewErrorStruct(ErrInvalidCredentials.Error(), "error", http.StatusBadRequest)
+	if len(fieldErrors) != 0 {
+		return nil, apierror.NewValidationError(ErrInvalidCredentials.Error(), fieldErrors)
 	}
 	var User models.User
-	User.Email = email.Address
-	User.UserName = dto.UserName
+	User.Email = email
+	User.UserName = userName
 	User.Password = dto.Password
 	return &User, nil
 }
+
+func validateEmail(value string) (string, *apierror.FieldError) {
+	if value == "" {
+		return "", &apierror.FieldError{Field: "email", Code: CodeRequired}
+	}
+	email, err := mail.ParseAddress(value)
+	if err != nil {
+		return "", &apierror.FieldError{Field: "email", Code: CodeInvalidEmail}
+	}
+	return email.Address, nil
+}
+
+func validateLength(field string, value string, min int) *apierror.FieldError {
+	if value == "" {
+		return &apierror.FieldError{Field: field, Code: CodeRequired, Min: min}
+	}
+	if len(value) < min {
+		return &apierror.FieldError{Field: field, Code: CodeTooShort, Min: min}
+	}
+	return nil
+}
+
+// ReauthDto is the password a signed in user confirms before a sensitive operation.
+type ReauthDto struct {
+	Password string `json:"password"`
+}
+
+func (dto ReauthDto) IntoUser() (*models.User, error) {
+	if fieldError := validateLength("password", dto.Password, MinPasswordLength); fieldError != nil {
+		return nil, apierror.NewValidationError(ErrInvalidCredentials.Error(), []apierror.FieldError{*fieldError})
+	}
+	return &models.User{Password: dto.Password}, nil
+}
+
+type RefreshTokenDto struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// SecureAccountDto carries the code of the "secure my account" link in new device emails.
+type SecureAccountDto struct {
+	Code string `json:"code"`
+}
+
+func (dto SecureAccountDto) IntoSecureAccountCode() (*models.SecureAccountCode, error) {
+	if dto.Code == "" {
+		return nil, apierror.NewValidationError(ErrInvalidSecureAccount.Error(), []apierror.FieldError{{Field: "code", Code: CodeRequired}})
+	}
+	return &models.SecureAccountCode{Code: dto.Code}, nil
+}
@@ -0,0 +1,83 @@
+package dto
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+)
+
+const (
+	MaxUserNameLength = 32
+
+	CodeInvalidCharacters = "invalid_characters"
+	CodeReserved          = "reserved"
+	CodeConfusable        = "confusable"
+)
+
+var ErrInvalidUserName = errors.New("invalid user name")
+
+// reservedUserNames can't be taken by anyone, they are compared in canonical form so "Admin" and "аdmin" are taken too.
+var reservedUserNames = []string{
+	"admin", "administrator", "root", "superuser", "support", "help", "helpdesk", "security", "system", "sysadmin",
+	"moderator", "staff", "official", "team", "api", "oauth", "www", "mail", "email", "postmaster", "hostmaster",
+	"webmaster", "abuse", "noreply", "no-reply", "null", "undefined", "anonymous", "settings", "account", "accounts",
+	"user", "users", "me", "deleted", "worddict",
+}
+
+// deleted users are renamed to deleted-<id>
+const deletedUserNamePrefix = "deleted-"
+
+// validateUserName returns the normalized user name, it is shared by sign up and profile updates. A user name has
+// letters, digits, ".", "_" and "-", starts and ends with a letter or a digit and doesn't mix scripts.
+func validateUserName(value string) (string, *apierror.FieldError) {
+	userName := models.NormalizeUserName(value)
+	if userName == "" {
+		return "", &apierror.FieldError{Field: "user_name", Code: CodeRequired, Min: MinUserNameLength}
+	}
+	length := utf8.RuneCountInString(userName)
+	if length < MinUserNameLength {
+		return "", &apierror.FieldError{Field: "user_name", Code: CodeTooShort, Min: MinUserNameLength}
+	}
+	if length > MaxUserNameLength {
+		return "", &apierror.FieldError{Field: "user_name", Code: CodeTooLong}
+	}
+	for i, r := range userName {
+		alphanumeric := unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r)
+		if !alphanumeric && !strings.ContainsRune("._-", r) {
+			return "", &apierror.FieldError{Field: "user_name", Code: CodeInvalidCharacters}
+		}
+		if !alphanumeric && (i == 0 || i+utf8.RuneLen(r) == len(userName)) {
+			return "", &apierror.FieldError{Field: "user_name", Code: CodeInvalidCharacters}
+		}
+	}
+	if models.MixesScripts(userName) {
+		return "", &apierror.FieldError{Field: "user_name", Code: CodeConfusable}
+	}
+	canonical := models.CanonicalUserName(userName)
+	if strings.HasPrefix(canonical, deletedUserNamePrefix) {
+		return "", &apierror.FieldError{Field: "user_name", Code: CodeReserved}
+	}
+	for _, reserved := range reservedUserNames {
+		if canonical == models.CanonicalUserName(reserved) {
+			return "", &apierror.FieldError{Field: "user_name", Code: CodeReserved}
+		}
+	}
+	return userName, nil
+}
+
+// UserNameDto is the query of GET /user/name-available.
+type UserNameDto struct {
+	UserName string
+}
+
+func (dto UserNameDto) IntoUser() (*models.User, error) {
+	userName, fieldError := validateUserName(dto.UserName)
+	if fieldError != nil {
+		return nil, apierror.NewValidationError(ErrInvalidUserName.Error(), []apierror.FieldError{*fieldError})
+	}
+	return &models.User{UserName: userName}, nil
+}
@@ -0,0 +1,56 @@
+package dto
+
+import (
+	"testing"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+)
+
+func TestValidateUserName(t *testing.T) {
+	testCases := []struct {
+		name     string
+		value    string
+		userName string
+		code     string
+	}{
+		{name: "valid", value: "alice_smith", userName: "alice_smith"},
+		{name: "normalized", value: " Ａｌｉｃｅ_ｓｍｉｔｈ ", userName: "Alice_smith"},
+		{name: "not_latin", value: "Юрий.Гагарин", userName: "Юрий.Гагарин"},
+		{name: "empty", value: "   ", code: CodeRequired},
+		{name: "too_short", value: "alice", code: CodeTooShort},
+		{name: "too_long", value: "alice_smith_alice_smith_alice_smi", code: CodeTooLong},
+		{name: "space", value: "alice smith", code: CodeInvalidCharacters},
+		{name: "symbol", value: "alice@smith", code: CodeInvalidCharacters},
+		{name: "leading_dot", value: ".alice_smith", code: CodeInvalidCharacters},
+		{name: "trailing_dash", value: "alice_smith-", code: CodeInvalidCharacters},
+		{name: "mixed_scripts", value: "аlice_smith", code: CodeConfusable},
+		{name: "reserved", value: "Administrator", code: CodeReserved},
+		{name: "reserved_lookalike", value: "P0stmaster", code: CodeReserved},
+		{name: "deleted_prefix", value: "Deleted-12345", code: CodeReserved},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			userName, fieldError := validateUserName(tc.value)
+			if tc.code != "" {
+				if fieldError == nil || fieldError.Code != tc.code {
+					t.Fatalf("expected %s, got %+v", tc.code, fieldError)
+				}
+				return
+			}
+			if fieldError != nil || userName != tc.userName {
+				t.Fatalf("expected %q, got %q, %+v", tc.userName, userName, fieldError)
+			}
+		})
+	}
+}
+
+func TestUserNameDtoIntoUser(t *testing.T) {
+	user, err := UserNameDto{UserName: "Ａｌｉｃｅ_smith"}.IntoUser()
+	if err != nil || user.UserName != "Alice_smith" {
+		t.Fatalf("unexpected user %+v, %v", user, err)
+	}
+	_, err = UserNameDto{UserName: "webmaster"}.IntoUser()
+	if err == nil || err.(*apierror.ErrorStruct).Errors[0].Code != CodeReserved {
+		t.Fatalf("expected %s, got %v", CodeReserved, err)
+	}
+}
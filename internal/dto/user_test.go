@@ -1,9 +1,11 @@
 package dto
 
 import (
+	"net/http"
 	"reflect"
 	"testing"
 
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
 	"github.com/Kin-dza-dzaa/userApi/internal/models"
 )
 
@@ -150,3 +152,47 @@ func TestUserSignUpDto_IntoUser(t *testing.T) {
 	}
 }
 
+
+func TestUserSignUpDto_FieldErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		dto    UserSignUpDto
+		errors []apierror.FieldError
+	}{
+		{
+			name: "every_field_invalid",
+			dto: UserSignUpDto{
+				Email:    "bad",
+				UserName: "short",
+				Password: "bad",
+			},
+			errors: []apierror.FieldError{
+				{Field: "user_name", Code: CodeTooShort, Min: MinUserNameLength},
+				{Field: "email", Code: CodeInvalidEmail},
+				{Field: "password", Code: CodeTooShort, Min: MinPasswordLength},
+			},
+		},
+		{
+			name: "missing_email",
+			dto: UserSignUpDto{
+				UserName: "TestUser",
+				Password: "123456789",
+			},
+			errors: []apierror.FieldError{
+				{Field: "email", Code: CodeRequired},
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.dto.IntoUser()
+			Err, ok := err.(*apierror.ErrorStruct)
+			if !ok {
+				t.FailNow()
+			}
+			if Err.Code != http.StatusBadRequest || !reflect.DeepEqual(tc.errors, Err.Errors) {
+				t.FailNow()
+			}
+		})
+	}
+}
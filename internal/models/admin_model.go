@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RoleAdmin is required for every /admin endpoint.
+const RoleAdmin = "admin"
+
+// UserFilter searches users, empty fields don't filter. Email and UserName match substrings case-insensitively.
+type UserFilter struct {
+	Email            string
+	UserName         string
+	Verified         *bool
+	Status           string
+	RegisteredAfter  *time.Time
+	RegisteredBefore *time.Time
+	Page             int
+	PerPage          int
+}
+
+// AdminUser is what support staff see about a user.
+type AdminUser struct {
+	UserId            uuid.UUID  `json:"id"`
+	UserName          string     `json:"user_name"`
+	Email             string     `json:"email"`
+	Status            string     `json:"status"`
+	StatusReason      string     `json:"status_reason"`
+	StatusChangedAt   time.Time  `json:"status_changed_at"`
+	RegistrationTime  time.Time  `json:"registration_date"`
+	SessionsRevokedAt *time.Time `json:"sessions_revoked_at"`
+	Roles             []string   `json:"roles,omitempty"`
+}
+
+type UserPage struct {
+	Users   []AdminUser `json:"users"`
+	Total   int         `json:"total"`
+	Page    int         `json:"page"`
+	PerPage int         `json:"per_page"`
+}
+
+// AdminAction is an action of the admin ActorId on the user TargetId.
+type AdminAction struct {
+	ActorId  uuid.UUID
+	TargetId uuid.UUID
+	Reason   string
+}
+
+// PasswordReset is a single-use reset code, only its hash is stored.
+type PasswordReset struct {
+	Code           string
+	CodeHash       string
+	UserId         uuid.UUID
+	Password       string
+	ExpirationTime time.Time
+}
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	AuditOutcomeSuccess = "success"
+	AuditOutcomeFailure = "failure"
+)
+
+var ErrAuditCursorInvalid = errors.New("invalid audit cursor")
+
+type AuditEvent struct {
+	EventId   uuid.UUID              `json:"id"`
+	Type      string                 `json:"type"`
+	ActorId   *uuid.UUID             `json:"actor_id"`
+	TargetId  *uuid.UUID             `json:"target_id"`
+	Ip        string                 `json:"ip"`
+	UserAgent string                 `json:"user_agent"`
+	RequestId string                 `json:"request_id"`
+	Outcome   string                 `json:"outcome"`
+	Metadata  map[string]interface{} `json:"metadata"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+// AuditFilter searches audit events newest first, empty fields don't filter. After continues a previous page.
+type AuditFilter struct {
+	Type     string
+	ActorId  *uuid.UUID
+	TargetId *uuid.UUID
+	Outcome  string
+	From     *time.Time
+	To       *time.Time
+	After    *AuditCursor
+	Limit    int
+}
+
+type AuditPage struct {
+	Events     []AuditEvent `json:"events"`
+	NextCursor string       `json:"next_cursor,omitempty"`
+}
+
+// AuditCursor points at the last event of a page, events are ordered by CreatedAt and EventId.
+type AuditCursor struct {
+	CreatedAt time.Time
+	EventId   uuid.UUID
+}
+
+func (cursor *AuditCursor) Encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursor.CreatedAt.Format(time.RFC3339Nano) + "," + cursor.EventId.String()))
+}
+
+func DecodeAuditCursor(value string) (*AuditCursor, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrAuditCursorInvalid
+	}
+	createdAt, eventId, ok := strings.Cut(string(decoded), ",")
+	if !ok {
+		return nil, ErrAuditCursorInvalid
+	}
+	cursor := new(AuditCursor)
+	if cursor.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt); err != nil {
+		return nil, ErrAuditCursorInvalid
+	}
+	if cursor.EventId, err = uuid.Parse(eventId); err != nil {
+		return nil, ErrAuditCursorInvalid
+	}
+	return cursor, nil
+}
+
+// RequestMeta describes the request a service call is made for, audit events record it.
+// ActorId is the signed in user, it is nil until the request is authenticated.
+type RequestMeta struct {
+	Ip        string
+	UserAgent string
+	RequestId string
+	ActorId   *uuid.UUID
+}
+
+type requestMetaKey struct{}
+
+func WithRequestMeta(ctx context.Context, meta *RequestMeta) context.Context {
+	return context.WithValue(ctx, requestMetaKey{}, meta)
+}
+
+// RequestMetaFromContext never returns nil, calls made outside of a request get an empty RequestMeta.
+func RequestMetaFromContext(ctx context.Context) *RequestMeta {
+	if meta, ok := ctx.Value(requestMetaKey{}).(*RequestMeta); ok {
+		return meta
+	}
+	return new(RequestMeta)
+}
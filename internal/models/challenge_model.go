@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// endpoints a challenge can guard
+const (
+	ChallengeSignUp = "sign_up"
+	ChallengeSignIn = "sign_in"
+)
+
+// Challenge tells the client what to solve before calling Endpoint, Type is "none", "pow" or "captcha".
+// A proof-of-work is solved by finding a solution so that sha256(Token + ":" + solution) starts with Difficulty zero bits.
+type Challenge struct {
+	Endpoint   string `json:"endpoint"`
+	Type       string `json:"type"`
+	Token      string `json:"challenge,omitempty"`
+	Difficulty int    `json:"difficulty,omitempty"`
+	ExpiresIn  int    `json:"expires_in,omitempty"`
+	Provider   string `json:"provider,omitempty"`
+	SiteKey    string `json:"site_key,omitempty"`
+}
+
+// ChallengeClaims are signed into the token of a proof-of-work challenge.
+type ChallengeClaims struct {
+	Endpoint   string `json:"endpoint"`
+	Difficulty int    `json:"difficulty"`
+	jwt.StandardClaims
+}
+
+// ChallengeResponse is what the client sent with a guarded request, Token and Solution for a proof-of-work
+// or CaptchaToken for a CAPTCHA.
+type ChallengeResponse struct {
+	Endpoint     string
+	Token        string
+	Solution     string
+	CaptchaToken string
+	Ip           string
+}
+
+// UsedChallenge is a solved proof-of-work, it is kept until ExpirationTime.
+type UsedChallenge struct {
+	Jti            string
+	ExpirationTime time.Time
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DeviceStatusPending  = "pending"
+	DeviceStatusApproved = "approved"
+	DeviceStatusDenied   = "denied"
+)
+
+type DeviceAuthorizationRequest struct {
+	ClientId     string
+	ClientSecret string
+	Scope        string
+}
+
+// DeviceAuthorization is a pending device authorization, only the hash of the device code is stored.
+// SlowDown is set by polling when the client polled faster than Interval.
+type DeviceAuthorization struct {
+	DeviceCode     string
+	DeviceCodeHash string
+	UserCode       string
+	ClientId       string
+	ClientName     string
+	Scope          string
+	UserId         uuid.UUID
+	Status         string
+	Interval       int
+	SlowDown       bool
+	ExpirationTime time.Time
+}
+
+type DeviceAuthorizationResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationUri         string `json:"verification_uri"`
+	VerificationUriComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+// DevicePrompt is what the user is shown before deciding about a user code.
+type DevicePrompt struct {
+	UserCode   string   `json:"user_code"`
+	ClientId   string   `json:"client_id"`
+	ClientName string   `json:"client_name"`
+	Scopes     []string `json:"scopes"`
+}
+
+// DeviceApproval is the decision of a signed in user about a user code. An approval confirms ClientId,
+// the client the user was shown.
+type DeviceApproval struct {
+	UserCode string
+	UserId   uuid.UUID
+	ClientId string
+	Approve  bool
+}
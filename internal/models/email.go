@@ -0,0 +1,32 @@
+package models
+
+import "strings"
+
+// EmailPolicy decides how the local part of an email is normalized, the domain is always lowercased.
+type EmailPolicy struct {
+	// LowercaseLocalPart stores "Bob@x.com" as "bob@x.com", without it the local part keeps its case but
+	// emails are still unique and looked up case-insensitively.
+	LowercaseLocalPart bool
+	// FoldGmail drops dots and "+tag" from Gmail addresses, Gmail delivers "b.o.b+news@gmail.com" to "bob@gmail.com".
+	FoldGmail bool
+}
+
+var gmailDomains = []string{"gmail.com", "googlemail.com"}
+
+// NormalizeEmail is the form emails are stored and looked up in, address is already parsed by mail.ParseAddress.
+func NormalizeEmail(address string, policy EmailPolicy) string {
+	at := strings.LastIndex(address, "@")
+	if at < 0 {
+		return address
+	}
+	local, domain := address[:at], strings.ToLower(address[at+1:])
+	if policy.FoldGmail && containsString(gmailDomains, domain) {
+		local, _, _ = strings.Cut(local, "+")
+		local = strings.ToLower(strings.ReplaceAll(local, ".", ""))
+		domain = gmailDomains[0]
+	}
+	if policy.LowercaseLocalPart {
+		local = strings.ToLower(local)
+	}
+	return local + "@" + domain
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	EmailDomainAllow = "allow"
+	EmailDomainBlock = "block"
+)
+
+// EmailDomainRule is an admin entry of the email domain policy, it covers the subdomains of Domain as well.
+type EmailDomainRule struct {
+	Domain    string    `json:"domain"`
+	Kind      string    `json:"kind"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ParentDomains lists domain and every domain above it, most specific first: "a.b.com", "b.com", "com".
+func ParentDomains(domain string) []string {
+	domains := []string{domain}
+	for {
+		_, parent, ok := strings.Cut(domain, ".")
+		if !ok || parent == "" {
+			return domains
+		}
+		domains = append(domains, parent)
+		domain = parent
+	}
+}
@@ -0,0 +1,26 @@
+package models
+
+import "testing"
+
+func TestNormalizeEmail(t *testing.T) {
+	testCases := []struct {
+		name    string
+		address string
+		policy  EmailPolicy
+		email   string
+	}{
+		{name: "domain", address: "Bob@X.COM", email: "Bob@x.com"},
+		{name: "lowercase", address: "Bob@X.COM", policy: EmailPolicy{LowercaseLocalPart: true}, email: "bob@x.com"},
+		{name: "gmail_not_folded", address: "B.o.b+news@gmail.com", policy: EmailPolicy{LowercaseLocalPart: true}, email: "b.o.b+news@gmail.com"},
+		{name: "gmail_folded", address: "B.o.b+news@GoogleMail.com", policy: EmailPolicy{FoldGmail: true}, email: "bob@gmail.com"},
+		{name: "other_domain_not_folded", address: "b.o.b+news@x.com", policy: EmailPolicy{FoldGmail: true}, email: "b.o.b+news@x.com"},
+		{name: "quoted_at", address: `"bob@home"@X.com`, email: `"bob@home"@x.com`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if email := NormalizeEmail(tc.address, tc.policy); email != tc.email {
+				t.Fatalf("expected %q, got %q", tc.email, email)
+			}
+		})
+	}
+}
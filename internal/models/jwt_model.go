@@ -1,9 +1,50 @@
 package models
 
-import "github.com/golang-jwt/jwt"
+import (
+	"strings"
+	"time"
 
+	"github.com/golang-jwt/jwt"
+)
+
+// AmrPassword is the amr value of a password check, RFC 8176.
+const AmrPassword = "pwd"
+
+// MyJwtClaims are the claims of access tokens, README.md documents them for the services that verify the tokens.
+// sub is the user id of user tokens and the client id of client tokens, user_id is kept for verifiers that read it.
 type MyJwtClaims struct {
 	UserId 					string 		`json:"user_id"`
 	XCSRFToken				string 		`json:"x_csrf_token"`
+	Scope					string 		`json:"scope,omitempty"`
+	ClientId				string 		`json:"client_id,omitempty"`
+	Roles					[]string	`json:"roles,omitempty"`
+	UserName				string		`json:"username,omitempty"`
+	EmailVerified			*bool		`json:"email_verified,omitempty"`
+	AuthTime				int64		`json:"auth_time,omitempty"`
+	Amr						[]string	`json:"amr,omitempty"`
 	jwt.StandardClaims
-}
\ No newline at end of file
+}
+
+// AuthenticatedWithin tells if the user proved who they are at most maxAge before now, tokens without auth_time never did.
+func (claims *MyJwtClaims) AuthenticatedWithin(maxAge time.Duration, now time.Time) bool {
+	return claims.AuthTime != 0 && !now.After(time.Unix(claims.AuthTime, 0).Add(maxAge))
+}
+
+// HasPermission tells if the token grants permission, permissions are carried in the space separated scope claim.
+func (claims *MyJwtClaims) HasPermission(permission string) bool {
+	for _, scope := range strings.Fields(claims.Scope) {
+		if scope == permission {
+			return true
+		}
+	}
+	return false
+}
+
+func (claims *MyJwtClaims) HasRole(role string) bool {
+	for _, r := range claims.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// KnownDevice is a user agent and IP combination a user signed in from, Fingerprint is the hash of both.
+type KnownDevice struct {
+	UserId      uuid.UUID
+	Fingerprint string
+	UserAgent   string
+	Ip          string
+	SeenAt      time.Time
+}
+
+// SecureAccountCode is the single-use code of the "secure my account" link in new device emails, only its hash is stored.
+// Fingerprint is the device the email was sent for.
+type SecureAccountCode struct {
+	Code           string
+	CodeHash       string
+	UserId         uuid.UUID
+	Fingerprint    string
+	ExpirationTime time.Time
+}
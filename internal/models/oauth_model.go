@@ -0,0 +1,162 @@
+package models
+
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+)
+
+// OAuthClient is a relying party or, with the client_credentials grant type, a service account.
+// PublicKey is a PEM encoded RSA key of a client that authenticates with private_key_jwt.
+type OAuthClient struct {
+	ClientId     string
+	SecretHash   string
+	Name         string
+	RedirectUris []string
+	Scopes       []string
+	GrantTypes   []string
+	PublicKey    string
+	Audience     string
+	CreatedAt    time.Time
+}
+
+// Public clients have neither a secret nor a key and must rely on PKCE alone.
+func (client *OAuthClient) Public() bool {
+	return client.SecretHash == "" && client.PublicKey == ""
+}
+
+type AuthorizationRequest struct {
+	ResponseType        string
+	ClientId            string
+	RedirectUri         string
+	Scope               string
+	State               string
+	Nonce               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+}
+
+type AuthorizationCode struct {
+	Code           string
+	CodeHash       string
+	ClientId       string
+	UserId         uuid.UUID
+	RedirectUri    string
+	Scope          string
+	Nonce          string
+	CodeChallenge  string
+	AuthTime       time.Time
+	ExpirationTime time.Time
+}
+
+type Consent struct {
+	UserId    uuid.UUID
+	ClientId  string
+	Scopes    []string
+	GrantedAt time.Time
+}
+
+type OAuthRefreshToken struct {
+	Token     string
+	TokenHash string
+	ClientId  string
+	UserId    uuid.UUID
+	Scope     string
+	AuthTime  time.Time
+	// GrantedAt is when the user granted the client, rotation keeps it and no token outlives it plus SessionMaxLifetime
+	GrantedAt      time.Time
+	ExpirationTime time.Time
+}
+
+type TokenRequest struct {
+	GrantType           string
+	ClientId            string
+	ClientSecret        string
+	ClientAssertionType string
+	ClientAssertion     string
+	Code                string
+	RedirectUri         string
+	CodeVerifier        string
+	RefreshToken        string
+	DeviceCode          string
+	Scope               string
+}
+
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	IdToken      string `json:"id_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+}
+
+type IdTokenClaims struct {
+	Nonce             string `json:"nonce,omitempty"`
+	AuthTime          int64  `json:"auth_time,omitempty"`
+	Email             string `json:"email,omitempty"`
+	EmailVerified     *bool  `json:"email_verified,omitempty"`
+	PreferredUsername string `json:"preferred_username,omitempty"`
+	jwt.StandardClaims
+}
+
+type UserInfo struct {
+	Sub               string `json:"sub"`
+	PreferredUsername string `json:"preferred_username,omitempty"`
+	Email             string `json:"email,omitempty"`
+	EmailVerified     *bool  `json:"email_verified,omitempty"`
+}
+
+// IntrospectionRequest is a RFC 7662 request, the client is authenticated the same way as on the token endpoint.
+type IntrospectionRequest struct {
+	ClientId      string
+	ClientSecret  string
+	Token         string
+	TokenTypeHint string
+}
+
+type Introspection struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientId  string `json:"client_id,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	Exp       int64  `json:"exp,omitempty"`
+	Iat       int64  `json:"iat,omitempty"`
+	Nbf       int64  `json:"nbf,omitempty"`
+	Sub       string `json:"sub,omitempty"`
+	Aud       string `json:"aud,omitempty"`
+	Iss       string `json:"iss,omitempty"`
+	Username  string `json:"username,omitempty"`
+	Jti       string `json:"jti,omitempty"`
+}
+
+// RevocationRequest is a RFC 7009 request.
+type RevocationRequest struct {
+	ClientId      string
+	ClientSecret  string
+	Token         string
+	TokenTypeHint string
+}
+
+// RevokedToken is an access token on the denylist, it can be dropped once the token expires.
+// UserId and IssuedAt are only used for lookups, tokens of a user issued before their sessions were revoked are revoked too.
+type RevokedToken struct {
+	Jti            string
+	ExpirationTime time.Time
+	UserId         uuid.UUID
+	IssuedAt       time.Time
+}
+
+type Jwk struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+type Jwks struct {
+	Keys []Jwk `json:"keys"`
+}
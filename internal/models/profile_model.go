@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Profile is what the signed in user sees about themselves.
+type Profile struct {
+	UserId           uuid.UUID `json:"id"`
+	UserName         string    `json:"user_name"`
+	Email            string    `json:"email"`
+	Verified         bool      `json:"verified"`
+	Status           string    `json:"status"`
+	RegistrationTime time.Time `json:"registration_time"`
+	DisplayName      string    `json:"display_name"`
+	Locale           string    `json:"locale"`
+	Timezone         string    `json:"timezone"`
+}
+
+// ProfileUpdate changes the fields that aren't nil.
+type ProfileUpdate struct {
+	UserId      uuid.UUID
+	UserName    *string
+	DisplayName *string
+	Locale      *string
+	Timezone    *string
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PermissionRolesManage allows managing roles, permissions and role assignments.
+const PermissionRolesManage = "roles:manage"
+
+type Permission struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type Role struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Permissions []string  `json:"permissions"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type UserRole struct {
+	UserId    uuid.UUID
+	Role      string
+	GrantedAt time.Time
+}
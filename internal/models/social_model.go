@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+)
+
+// SocialLogin is the start of a sign in with an external provider, StateToken must come back with the callback.
+type SocialLogin struct {
+	AuthUrl    string
+	StateToken string
+}
+
+type SocialCallback struct {
+	Provider   string
+	Code       string
+	State      string
+	StateToken string
+}
+
+// SocialStateClaims keep the state, nonce and PKCE verifier of a social login between the redirect and the callback.
+type SocialStateClaims struct {
+	Provider string `json:"provider"`
+	State    string `json:"state"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+	jwt.StandardClaims
+}
+
+// SocialEndpoints of a provider, either configured or taken from its discovery document.
+type SocialEndpoints struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserInfoEndpoint      string `json:"userinfo_endpoint"`
+	JwksUri               string `json:"jwks_uri"`
+}
+
+// Identity links an account of an external provider to a user.
+type Identity struct {
+	Provider      string
+	Subject       string
+	UserId        uuid.UUID
+	Email         string
+	EmailVerified bool
+	CreatedAt     time.Time
+}
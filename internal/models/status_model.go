@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Account statuses, a user signs up pending and becomes active once the email is verified.
+// Suspended is set by admins, locked is a security hold that a password reset lifts. Deleted is final.
+const (
+	UserStatusPending   = "pending"
+	UserStatusActive    = "active"
+	UserStatusSuspended = "suspended"
+	UserStatusLocked    = "locked"
+	UserStatusDeleted   = "deleted"
+)
+
+// userStatusTransitions lists the statuses each status can change to.
+var userStatusTransitions = map[string][]string{
+	UserStatusPending:   {UserStatusActive, UserStatusDeleted},
+	UserStatusActive:    {UserStatusSuspended, UserStatusLocked, UserStatusDeleted},
+	UserStatusSuspended: {UserStatusActive, UserStatusDeleted},
+	UserStatusLocked:    {UserStatusActive, UserStatusSuspended, UserStatusDeleted},
+}
+
+// CanTransitionStatus tells if a user with the status from can change to the status to.
+func CanTransitionStatus(from string, to string) bool {
+	for _, status := range userStatusTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsUserStatus tells if status is one of the account statuses.
+func IsUserStatus(status string) bool {
+	_, ok := userStatusTransitions[status]
+	return ok || status == UserStatusDeleted
+}
+
+// StatusChange moves the user UserId from the status From to To, it fails if the status is no longer From.
+type StatusChange struct {
+	UserId    uuid.UUID
+	From      string
+	To        string
+	Reason    string
+	ChangedAt time.Time
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PersonalAccessToken is a long-lived credential for scripts, Token is set only when the token is created.
+type PersonalAccessToken struct {
+	TokenId        uuid.UUID  `json:"id"`
+	UserId         uuid.UUID  `json:"-"`
+	Name           string     `json:"name"`
+	Token          string     `json:"token,omitempty"`
+	TokenHash      string     `json:"-"`
+	Scopes         []string   `json:"scopes"`
+	CreatedAt      time.Time  `json:"created_at"`
+	ExpirationTime *time.Time `json:"expires_at"`
+	LastUsedAt     *time.Time `json:"last_used_at"`
+}
@@ -14,7 +14,23 @@ type User struct {
 	RefreshToken 		string		
 	ExpirationTime 		time.Time   
 	VerificationCode	string		
-	Verified		 	bool 		
+	Status				string
 	CsrfToken			string 		
 	Jwt					string		
+	Roles				[]string
+	Permissions			[]string
+	// RememberMe keeps the refresh cookie after the browser closes and lets the session last RefreshTokenTtl instead of SessionTtl
+	RememberMe			bool
+	// SessionStartedAt is when the refresh token was issued, sliding expiry never takes a session past it plus SessionMaxLifetime
+	SessionStartedAt	time.Time
+	// AuthTime and Amr become the auth_time and amr claims, AuthTime is when the user last proved who they are
+	AuthTime			time.Time
+	Amr					[]string
+	// PreviousJwt is the access token the device sent along with its refresh token, a refresh carries its auth_time forward
+	PreviousJwt			string
+}
+
+// EmailVerified is true once the user left pending, the status changes only after the email was verified.
+func (user *User) EmailVerified() bool {
+	return user.Status != UserStatusPending
 }
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// confusables maps case folded characters that look like a latin letter or digit to it. Folded forms of uppercase
+// lookalikes are listed too, Cyrillic "в" is here because "В" folds to it. The translate() of
+// migrations/upgrades/006_user_name_canonical.sql mirrors this table, TestCanonicalUserNameMigration keeps them in step.
+var confusables = map[rune]rune{
+	// Cyrillic
+	'а': 'a', 'в': 'b', 'е': 'e', 'һ': 'h', 'н': 'h', 'і': 'i', 'ј': 'j', 'к': 'k', 'ӏ': 'l', 'м': 'm',
+	'о': 'o', 'р': 'p', 'ԛ': 'q', 'ѕ': 's', 'т': 't', 'у': 'y', 'ԝ': 'w', 'х': 'x', 'ԁ': 'd', 'с': 'c',
+	// Greek
+	'α': 'a', 'β': 'b', 'ε': 'e', 'ζ': 'z', 'η': 'h', 'ι': 'i', 'κ': 'k', 'μ': 'm', 'ν': 'n', 'ο': 'o',
+	'ρ': 'p', 'τ': 't', 'υ': 'y', 'χ': 'x',
+	// digits
+	'0': 'o', '1': 'l',
+}
+
+// NormalizeUserName is the form user names are stored and shown in, NFKC without surrounding spaces.
+// Fullwidth "Ａｌｉｃｅ" becomes "Alice".
+func NormalizeUserName(name string) string {
+	return strings.TrimSpace(norm.NFKC.String(name))
+}
+
+// CanonicalUserName is the form user names are compared in, two names with the same canonical form are the same name.
+// It folds case and confusable characters, "Alice", "ALICE" and "аlice" with a Cyrillic "а" are all "alice".
+func CanonicalUserName(name string) string {
+	folded := norm.NFKC.String(cases.Fold().String(NormalizeUserName(name)))
+	return strings.Map(func(r rune) rune {
+		if prototype, ok := confusables[r]; ok {
+			return prototype
+		}
+		return r
+	}, folded)
+}
+
+// userNameScripts are the scripts a user name may mix, Japanese is written in three of them at once.
+var userNameScripts = map[string][]string{
+	"Han":      {"Hiragana", "Katakana"},
+	"Hiragana": {"Han", "Katakana"},
+	"Katakana": {"Han", "Hiragana"},
+}
+
+// MixesScripts tells if the letters of name come from scripts that aren't used together, like a Cyrillic letter
+// in a Latin name. Digits, punctuation and letters shared by scripts, like the Japanese "ー", belong to no script.
+func MixesScripts(name string) bool {
+	var scripts []string
+	for _, r := range name {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		script := letterScript(r)
+		if script != "Common" && !containsString(scripts, script) {
+			scripts = append(scripts, script)
+		}
+	}
+	for _, script := range scripts {
+		for _, other := range scripts {
+			if script != other && !containsString(userNameScripts[script], other) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func letterScript(r rune) string {
+	for name, table := range unicode.Scripts {
+		if name != "Common" && name != "Inherited" && unicode.Is(table, r) {
+			return name
+		}
+	}
+	return "Common"
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"os"
+	"regexp"
+	"testing"
+)
+
+func TestCanonicalUserName(t *testing.T) {
+	testCases := []struct {
+		name      string
+		userName  string
+		canonical string
+	}{
+		{name: "case", userName: "Alice_Smith", canonical: "alice_smith"},
+		{name: "fullwidth", userName: "Ａｌｉｃｅ", canonical: "alice"},
+		{name: "cyrillic_lookalike", userName: "аlice", canonical: "alice"},
+		{name: "greek_uppercase", userName: "ΑLICE", canonical: "alice"},
+		{name: "digits", userName: "b0b1", canonical: "bobl"},
+		{name: "sharp_s", userName: "straße", canonical: "strasse"},
+		{name: "not_latin", userName: "Юлия", canonical: "юлия"},
+		{name: "lookalike_in_cyrillic", userName: "Юрий", canonical: "юpий"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if canonical := CanonicalUserName(tc.userName); canonical != tc.canonical {
+				t.Fatalf("expected %q, got %q", tc.canonical, canonical)
+			}
+		})
+	}
+}
+
+// TestCanonicalUserNameMigration checks that the translate() of the upgrade script maps the same characters as confusables.
+func TestCanonicalUserNameMigration(t *testing.T) {
+	script, err := os.ReadFile("../../migrations/upgrades/006_user_name_canonical.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	match := regexp.MustCompile(`translate\([^']*'([^']*)',\s*'([^']*)'\s*\)`).FindSubmatch(script)
+	if match == nil {
+		t.Fatal("no translate() in the upgrade script")
+	}
+	from, to := []rune(string(match[1])), []rune(string(match[2]))
+	if len(from) != len(to) {
+		t.Fatalf("translate() maps %d characters to %d", len(from), len(to))
+	}
+	mapping := make(map[rune]rune, len(from))
+	for i, r := range from {
+		mapping[r] = to[i]
+	}
+	for r, prototype := range confusables {
+		if mapping[r] != prototype {
+			t.Errorf("%q maps to %q in confusables and to %q in the upgrade script", r, prototype, mapping[r])
+		}
+	}
+	for r, prototype := range mapping {
+		if _, ok := confusables[r]; !ok {
+			t.Errorf("%q maps to %q in the upgrade script but isn't in confusables", r, prototype)
+		}
+	}
+}
+
+func TestMixesScripts(t *testing.T) {
+	testCases := []struct {
+		userName string
+		mixes    bool
+	}{
+		{userName: "alice_smith_1", mixes: false},
+		{userName: "Юрий.Гагарин", mixes: false},
+		{userName: "東京すかいつりー", mixes: false},
+		{userName: "раypal", mixes: true},
+		{userName: "alice_Юрий", mixes: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.userName, func(t *testing.T) {
+			if mixes := MixesScripts(tc.userName); mixes != tc.mixes {
+				t.Fatalf("expected %v, got %v", tc.mixes, mixes)
+			}
+		})
+	}
+}
@@ -1 +1,20 @@
+DROP TABLE IF EXISTS used_challenges;
+DROP TABLE IF EXISTS email_domain_rules;
+DROP TABLE IF EXISTS secure_account_codes;
+DROP TABLE IF EXISTS known_devices;
+DROP TABLE IF EXISTS audit_events;
+DROP FUNCTION IF EXISTS audit_events_append_only;
+DROP TABLE IF EXISTS password_resets;
+DROP TABLE IF EXISTS user_roles;
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS roles;
+DROP TABLE IF EXISTS permissions;
+DROP TABLE IF EXISTS oauth_device_codes;
+DROP TABLE IF EXISTS personal_access_tokens;
+DROP TABLE IF EXISTS revoked_tokens;
+DROP TABLE IF EXISTS user_identities;
+DROP TABLE IF EXISTS oauth_refresh_tokens;
+DROP TABLE IF EXISTS oauth_consents;
+DROP TABLE IF EXISTS oauth_codes;
+DROP TABLE IF EXISTS oauth_clients;
 DROP TABLE IF EXISTS users;
\ No newline at end of file
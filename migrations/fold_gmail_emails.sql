@@ -0,0 +1,31 @@
+-- Folds existing Gmail addresses the way EMAIL_FOLD_GMAIL = true does: dots and "+tag" dropped, googlemail.com becomes gmail.com.
+-- Run it when EMAIL_FOLD_GMAIL is turned on, lookups use the folded form so "b.o.b@gmail.com" couldn't sign in without it.
+-- It stops and lists the emails that fold into the same address, merge or delete one of them and run it again.
+-- Running it again changes nothing.
+
+BEGIN;
+
+CREATE TEMPORARY TABLE folded_emails ON COMMIT DROP AS
+    SELECT id, email, registration_date, CASE
+        WHEN lower(substring(email from '@([^@]*)$')) IN ('gmail.com', 'googlemail.com')
+            THEN lower(replace(split_part(substring(email from '^(.*)@'), '+', 1), '.', '')) || '@gmail.com'
+        ELSE email
+    END AS folded FROM users;
+
+DO $$
+DECLARE
+    duplicates TEXT;
+BEGIN
+    SELECT string_agg(emails, '; ') INTO duplicates FROM (
+        SELECT string_agg(email, ', ' ORDER BY registration_date) || ' -> ' || lower(min(folded)) AS emails FROM folded_emails
+        GROUP BY lower(folded) HAVING count(*) > 1
+    ) AS groups;
+    IF duplicates IS NOT NULL THEN
+        RAISE EXCEPTION 'emails that fold into the same address: %', duplicates;
+    END IF;
+END $$;
+
+UPDATE users SET email = folded_emails.folded FROM folded_emails
+    WHERE users.id = folded_emails.id AND users.email <> folded_emails.folded;
+
+COMMIT;
@@ -15,8 +15,190 @@ CREATE TABLE users(
     refresh_token     TEXT,
     expiration_time   TIMESTAMP,  
     verification_code TEXT                                                                  NOT NULL,
-    verified          BOOL                                                                  NOT NULL,
+    status            TEXT                                                                  NOT NULL CHECK(status IN ('pending', 'active', 'suspended', 'locked', 'deleted')),
+    status_reason     TEXT                                                                  NOT NULL DEFAULT '',
+    status_changed_at TIMESTAMP                                                             NOT NULL,
+    sessions_revoked_at TIMESTAMP,
+    display_name      TEXT                                                                  NOT NULL DEFAULT '',
+    locale            TEXT                                                                  NOT NULL DEFAULT 'en',
+    timezone          TEXT                                                                  NOT NULL DEFAULT 'UTC',
+    user_name_canonical TEXT                                                                NOT NULL,
+    session_started_at TIMESTAMP,
+    remember_me       BOOLEAN                                                               NOT NULL DEFAULT FALSE,
     UNIQUE (id),
-    UNIQUE(user_name),
-    UNIQUE (email)
+    CONSTRAINT users_user_name_key UNIQUE (user_name),
+    CONSTRAINT users_user_name_canonical_key UNIQUE (user_name_canonical)
 );
+
+-- emails are unique whatever the case, lookups compare lower(email) too
+CREATE UNIQUE INDEX users_email_lower_key ON users (lower(email));
+
+CREATE TABLE oauth_clients(
+    id                TEXT                                                                  NOT NULL PRIMARY KEY,
+    secret_hash       TEXT,
+    name              TEXT                                                                  NOT NULL CHECK(name != ''),
+    redirect_uris     TEXT[]                                                                NOT NULL,
+    scopes            TEXT[]                                                                NOT NULL,
+    grant_types       TEXT[]                                                                NOT NULL DEFAULT '{authorization_code,refresh_token}',
+    public_key        TEXT,
+    audience          TEXT,
+    created_at        TIMESTAMP                                                             NOT NULL
+);
+
+CREATE TABLE oauth_codes(
+    code_hash         TEXT                                                                  NOT NULL PRIMARY KEY,
+    client_id         TEXT                                                                  NOT NULL REFERENCES oauth_clients(id) ON DELETE CASCADE,
+    user_id           UUID                                                                  NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    redirect_uri      TEXT                                                                  NOT NULL,
+    scope             TEXT                                                                  NOT NULL,
+    nonce             TEXT                                                                  NOT NULL,
+    code_challenge    TEXT                                                                  NOT NULL,
+    auth_time         TIMESTAMP                                                             NOT NULL,
+    expiration_time   TIMESTAMP                                                             NOT NULL
+);
+
+CREATE TABLE oauth_consents(
+    user_id           UUID                                                                  NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    client_id         TEXT                                                                  NOT NULL REFERENCES oauth_clients(id) ON DELETE CASCADE,
+    scopes            TEXT[]                                                                NOT NULL,
+    granted_at        TIMESTAMP                                                             NOT NULL,
+    PRIMARY KEY (user_id, client_id)
+);
+
+CREATE TABLE oauth_refresh_tokens(
+    token_hash        TEXT                                                                  NOT NULL PRIMARY KEY,
+    client_id         TEXT                                                                  NOT NULL REFERENCES oauth_clients(id) ON DELETE CASCADE,
+    user_id           UUID                                                                  NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    scope             TEXT                                                                  NOT NULL,
+    auth_time         TIMESTAMP                                                             NOT NULL,
+    granted_at        TIMESTAMP                                                             NOT NULL,
+    expiration_time   TIMESTAMP                                                             NOT NULL
+);
+
+CREATE TABLE user_identities(
+    provider          TEXT                                                                  NOT NULL,
+    subject           TEXT                                                                  NOT NULL,
+    user_id           UUID                                                                  NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    email             TEXT                                                                  NOT NULL,
+    created_at        TIMESTAMP                                                             NOT NULL,
+    PRIMARY KEY (provider, subject)
+);
+
+CREATE TABLE revoked_tokens(
+    jti               TEXT                                                                  NOT NULL PRIMARY KEY,
+    expiration_time   TIMESTAMP                                                             NOT NULL
+);
+
+CREATE TABLE personal_access_tokens(
+    id                UUID                                                                  NOT NULL PRIMARY KEY,
+    user_id           UUID                                                                  NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name              TEXT                                                                  NOT NULL CHECK(name != ''),
+    token_hash        TEXT                                                                  NOT NULL UNIQUE,
+    scopes            TEXT[]                                                                NOT NULL,
+    created_at        TIMESTAMP                                                             NOT NULL,
+    expiration_time   TIMESTAMP,
+    last_used_at      TIMESTAMP
+);
+
+CREATE TABLE oauth_device_codes(
+    device_code_hash  TEXT                                                                  NOT NULL PRIMARY KEY,
+    user_code         TEXT                                                                  NOT NULL UNIQUE,
+    client_id         TEXT                                                                  NOT NULL REFERENCES oauth_clients(id) ON DELETE CASCADE,
+    scope             TEXT                                                                  NOT NULL,
+    user_id           UUID                                                                  REFERENCES users(id) ON DELETE CASCADE,
+    status            TEXT                                                                  NOT NULL CHECK(status IN ('pending', 'approved', 'denied')),
+    interval          INT                                                                   NOT NULL,
+    last_polled_at    TIMESTAMP,
+    expiration_time   TIMESTAMP                                                             NOT NULL
+);
+
+CREATE TABLE permissions(
+    name              TEXT                                                                  NOT NULL PRIMARY KEY,
+    description       TEXT                                                                  NOT NULL DEFAULT ''
+);
+
+CREATE TABLE roles(
+    name              TEXT                                                                  NOT NULL PRIMARY KEY,
+    description       TEXT                                                                  NOT NULL DEFAULT '',
+    created_at        TIMESTAMP                                                             NOT NULL
+);
+
+CREATE TABLE role_permissions(
+    role              TEXT                                                                  NOT NULL REFERENCES roles(name) ON DELETE CASCADE ON UPDATE CASCADE,
+    permission        TEXT                                                                  NOT NULL REFERENCES permissions(name) ON DELETE CASCADE ON UPDATE CASCADE,
+    PRIMARY KEY(role, permission)
+);
+
+CREATE TABLE user_roles(
+    user_id           UUID                                                                  NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role              TEXT                                                                  NOT NULL REFERENCES roles(name) ON DELETE CASCADE ON UPDATE CASCADE,
+    granted_at        TIMESTAMP                                                             NOT NULL,
+    PRIMARY KEY(user_id, role)
+);
+
+INSERT INTO permissions(name, description) VALUES('roles:manage', 'Manage roles, permissions and role assignments');
+INSERT INTO roles(name, description, created_at) VALUES('admin', 'Administrators', now());
+INSERT INTO role_permissions(role, permission) VALUES('admin', 'roles:manage');
+
+CREATE TABLE password_resets(
+    code_hash         TEXT                                                                  NOT NULL PRIMARY KEY,
+    user_id           UUID                                                                  NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    expiration_time   TIMESTAMP                                                             NOT NULL
+);
+
+-- devices users signed in from, a device is a user agent and IP combination identified by the hash of both
+CREATE TABLE known_devices(
+    user_id           UUID                                                                  NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    fingerprint       TEXT                                                                  NOT NULL,
+    user_agent        TEXT                                                                  NOT NULL,
+    ip                TEXT                                                                  NOT NULL,
+    first_seen_at     TIMESTAMP                                                             NOT NULL,
+    last_seen_at      TIMESTAMP                                                             NOT NULL,
+    PRIMARY KEY (user_id, fingerprint)
+);
+
+CREATE TABLE secure_account_codes(
+    code_hash         TEXT                                                                  NOT NULL PRIMARY KEY,
+    user_id           UUID                                                                  NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    fingerprint       TEXT                                                                  NOT NULL,
+    expiration_time   TIMESTAMP                                                             NOT NULL
+);
+
+-- admin entries of the email domain policy, they take precedence over the bundled and file lists
+CREATE TABLE email_domain_rules(
+    domain            TEXT                                                                  NOT NULL PRIMARY KEY CHECK(domain != ''),
+    kind              TEXT                                                                  NOT NULL CHECK(kind IN ('allow', 'block')),
+    created_at        TIMESTAMP                                                             NOT NULL
+);
+
+-- solved proof-of-work challenges, kept until they expire so a solution can't be used twice
+CREATE TABLE used_challenges(
+    jti               TEXT                                                                  NOT NULL PRIMARY KEY,
+    expiration_time   TIMESTAMP                                                             NOT NULL
+);
+
+CREATE TABLE audit_events(
+    id                UUID                                                                  NOT NULL PRIMARY KEY,
+    type              TEXT                                                                  NOT NULL,
+    actor_id          UUID,
+    target_id         UUID,
+    ip                TEXT                                                                  NOT NULL DEFAULT '',
+    user_agent        TEXT                                                                  NOT NULL DEFAULT '',
+    request_id        TEXT                                                                  NOT NULL DEFAULT '',
+    outcome           TEXT                                                                  NOT NULL CHECK(outcome IN ('success', 'failure')),
+    metadata          JSONB                                                                 NOT NULL DEFAULT '{}',
+    created_at        TIMESTAMP                                                             NOT NULL
+);
+
+CREATE INDEX audit_events_target_id_idx ON audit_events(target_id, created_at);
+CREATE INDEX audit_events_actor_id_idx ON audit_events(actor_id, created_at);
+CREATE INDEX audit_events_created_at_idx ON audit_events(created_at, id);
+
+-- the audit log is append-only, rows are only deleted once they are older than the retention
+CREATE FUNCTION audit_events_append_only() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_events_append_only BEFORE UPDATE OR TRUNCATE ON audit_events EXECUTE FUNCTION audit_events_append_only();
@@ -0,0 +1,21 @@
+-- Replaces the verified flag of users with the account status and adds the time their sessions were last revoked.
+-- This is the first script of the upgrade path from the original up.sql, run them all in order.
+
+BEGIN;
+
+ALTER TABLE users
+    ADD COLUMN status TEXT CHECK(status IN ('pending', 'active', 'suspended', 'locked', 'deleted')),
+    ADD COLUMN status_reason TEXT NOT NULL DEFAULT '',
+    ADD COLUMN status_changed_at TIMESTAMP,
+    ADD COLUMN sessions_revoked_at TIMESTAMP;
+
+UPDATE users SET
+    status = CASE WHEN verified THEN 'active' ELSE 'pending' END,
+    status_changed_at = registration_date;
+
+ALTER TABLE users
+    ALTER COLUMN status SET NOT NULL,
+    ALTER COLUMN status_changed_at SET NOT NULL,
+    DROP COLUMN verified;
+
+COMMIT;
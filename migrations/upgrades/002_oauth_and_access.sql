@@ -0,0 +1,118 @@
+-- Adds the OAuth clients, codes, consents and refresh tokens, linked social identities, the access token denylist,
+-- personal access tokens, device codes, roles and permissions and password resets. Every table starts empty except
+-- for the admin role.
+
+BEGIN;
+
+CREATE TABLE oauth_clients(
+    id                TEXT                                                                  NOT NULL PRIMARY KEY,
+    secret_hash       TEXT,
+    name              TEXT                                                                  NOT NULL CHECK(name != ''),
+    redirect_uris     TEXT[]                                                                NOT NULL,
+    scopes            TEXT[]                                                                NOT NULL,
+    grant_types       TEXT[]                                                                NOT NULL DEFAULT '{authorization_code,refresh_token}',
+    public_key        TEXT,
+    created_at        TIMESTAMP                                                             NOT NULL
+);
+
+CREATE TABLE oauth_codes(
+    code_hash         TEXT                                                                  NOT NULL PRIMARY KEY,
+    client_id         TEXT                                                                  NOT NULL REFERENCES oauth_clients(id) ON DELETE CASCADE,
+    user_id           UUID                                                                  NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    redirect_uri      TEXT                                                                  NOT NULL,
+    scope             TEXT                                                                  NOT NULL,
+    nonce             TEXT                                                                  NOT NULL,
+    code_challenge    TEXT                                                                  NOT NULL,
+    auth_time         TIMESTAMP                                                             NOT NULL,
+    expiration_time   TIMESTAMP                                                             NOT NULL
+);
+
+CREATE TABLE oauth_consents(
+    user_id           UUID                                                                  NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    client_id         TEXT                                                                  NOT NULL REFERENCES oauth_clients(id) ON DELETE CASCADE,
+    scopes            TEXT[]                                                                NOT NULL,
+    granted_at        TIMESTAMP                                                             NOT NULL,
+    PRIMARY KEY (user_id, client_id)
+);
+
+CREATE TABLE oauth_refresh_tokens(
+    token_hash        TEXT                                                                  NOT NULL PRIMARY KEY,
+    client_id         TEXT                                                                  NOT NULL REFERENCES oauth_clients(id) ON DELETE CASCADE,
+    user_id           UUID                                                                  NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    scope             TEXT                                                                  NOT NULL,
+    auth_time         TIMESTAMP                                                             NOT NULL,
+    expiration_time   TIMESTAMP                                                             NOT NULL
+);
+
+CREATE TABLE user_identities(
+    provider          TEXT                                                                  NOT NULL,
+    subject           TEXT                                                                  NOT NULL,
+    user_id           UUID                                                                  NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    email             TEXT                                                                  NOT NULL,
+    created_at        TIMESTAMP                                                             NOT NULL,
+    PRIMARY KEY (provider, subject)
+);
+
+CREATE TABLE revoked_tokens(
+    jti               TEXT                                                                  NOT NULL PRIMARY KEY,
+    expiration_time   TIMESTAMP                                                             NOT NULL
+);
+
+CREATE TABLE personal_access_tokens(
+    id                UUID                                                                  NOT NULL PRIMARY KEY,
+    user_id           UUID                                                                  NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name              TEXT                                                                  NOT NULL CHECK(name != ''),
+    token_hash        TEXT                                                                  NOT NULL UNIQUE,
+    scopes            TEXT[]                                                                NOT NULL,
+    created_at        TIMESTAMP                                                             NOT NULL,
+    expiration_time   TIMESTAMP,
+    last_used_at      TIMESTAMP
+);
+
+CREATE TABLE oauth_device_codes(
+    device_code_hash  TEXT                                                                  NOT NULL PRIMARY KEY,
+    user_code         TEXT                                                                  NOT NULL UNIQUE,
+    client_id         TEXT                                                                  NOT NULL REFERENCES oauth_clients(id) ON DELETE CASCADE,
+    scope             TEXT                                                                  NOT NULL,
+    user_id           UUID                                                                  REFERENCES users(id) ON DELETE CASCADE,
+    status            TEXT                                                                  NOT NULL CHECK(status IN ('pending', 'approved', 'denied')),
+    interval          INT                                                                   NOT NULL,
+    last_polled_at    TIMESTAMP,
+    expiration_time   TIMESTAMP                                                             NOT NULL
+);
+
+CREATE TABLE permissions(
+    name              TEXT                                                                  NOT NULL PRIMARY KEY,
+    description       TEXT                                                                  NOT NULL DEFAULT ''
+);
+
+CREATE TABLE roles(
+    name              TEXT                                                                  NOT NULL PRIMARY KEY,
+    description       TEXT                                                                  NOT NULL DEFAULT '',
+    created_at        TIMESTAMP                                                             NOT NULL
+);
+
+CREATE TABLE role_permissions(
+    role              TEXT                                                                  NOT NULL REFERENCES roles(name) ON DELETE CASCADE ON UPDATE CASCADE,
+    permission        TEXT                                                                  NOT NULL REFERENCES permissions(name) ON DELETE CASCADE ON UPDATE CASCADE,
+    PRIMARY KEY(role, permission)
+);
+
+CREATE TABLE user_roles(
+    user_id           UUID                                                                  NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role              TEXT                                                                  NOT NULL REFERENCES roles(name) ON DELETE CASCADE ON UPDATE CASCADE,
+    granted_at        TIMESTAMP                                                             NOT NULL,
+    PRIMARY KEY(user_id, role)
+);
+
+INSERT INTO permissions(name, description) VALUES('roles:manage', 'Manage roles, permissions and role assignments');
+INSERT INTO roles(name, description, created_at) VALUES('admin', 'Administrators', now());
+INSERT INTO role_permissions(role, permission) VALUES('admin', 'roles:manage');
+
+CREATE TABLE password_resets(
+    code_hash         TEXT                                                                  NOT NULL PRIMARY KEY,
+    user_id           UUID                                                                  NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    expiration_time   TIMESTAMP                                                             NOT NULL
+);
+
+COMMIT;
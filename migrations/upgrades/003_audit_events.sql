@@ -0,0 +1,30 @@
+-- Adds the append-only audit log.
+
+BEGIN;
+
+CREATE TABLE audit_events(
+    id                UUID                                                                  NOT NULL PRIMARY KEY,
+    type              TEXT                                                                  NOT NULL,
+    actor_id          UUID,
+    target_id         UUID,
+    ip                TEXT                                                                  NOT NULL DEFAULT '',
+    user_agent        TEXT                                                                  NOT NULL DEFAULT '',
+    request_id        TEXT                                                                  NOT NULL DEFAULT '',
+    outcome           TEXT                                                                  NOT NULL CHECK(outcome IN ('success', 'failure')),
+    metadata          JSONB                                                                 NOT NULL DEFAULT '{}',
+    created_at        TIMESTAMP                                                             NOT NULL
+);
+
+CREATE INDEX audit_events_target_id_idx ON audit_events(target_id, created_at);
+CREATE INDEX audit_events_actor_id_idx ON audit_events(actor_id, created_at);
+CREATE INDEX audit_events_created_at_idx ON audit_events(created_at, id);
+
+CREATE FUNCTION audit_events_append_only() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_events_append_only BEFORE UPDATE OR TRUNCATE ON audit_events EXECUTE FUNCTION audit_events_append_only();
+
+COMMIT;
@@ -0,0 +1,23 @@
+-- Adds the devices users signed in from and the codes of the "secure my account" link of new device emails.
+-- Users get no email until they signed in once after the upgrade, their first device is just remembered.
+
+BEGIN;
+
+CREATE TABLE known_devices(
+    user_id           UUID                                                                  NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    fingerprint       TEXT                                                                  NOT NULL,
+    user_agent        TEXT                                                                  NOT NULL,
+    ip                TEXT                                                                  NOT NULL,
+    first_seen_at     TIMESTAMP                                                             NOT NULL,
+    last_seen_at      TIMESTAMP                                                             NOT NULL,
+    PRIMARY KEY (user_id, fingerprint)
+);
+
+CREATE TABLE secure_account_codes(
+    code_hash         TEXT                                                                  NOT NULL PRIMARY KEY,
+    user_id           UUID                                                                  NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    fingerprint       TEXT                                                                  NOT NULL,
+    expiration_time   TIMESTAMP                                                             NOT NULL
+);
+
+COMMIT;
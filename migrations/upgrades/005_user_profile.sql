@@ -0,0 +1,10 @@
+-- Adds the profile preferences of users, existing users get the defaults.
+
+BEGIN;
+
+ALTER TABLE users
+    ADD COLUMN display_name TEXT NOT NULL DEFAULT '',
+    ADD COLUMN locale TEXT NOT NULL DEFAULT 'en',
+    ADD COLUMN timezone TEXT NOT NULL DEFAULT 'UTC';
+
+COMMIT;
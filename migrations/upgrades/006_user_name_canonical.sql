@@ -0,0 +1,33 @@
+-- Adds the canonical user name and makes it unique, it needs PostgreSQL 13 or later for normalize().
+-- The canonical form is computed the way models.CanonicalUserName does it, lower() stands in for case folding.
+-- The translate() below mirrors the confusables table of internal/models/user_name.go, TestCanonicalUserNameMigration compares them.
+-- The upgrade stops and lists the names if two existing users share a canonical form, rename one of them and run it again.
+
+BEGIN;
+
+ALTER TABLE users ADD COLUMN user_name_canonical TEXT;
+
+UPDATE users SET user_name_canonical = CASE WHEN status = 'deleted' THEN 'deleted-' || id ELSE translate(
+    lower(normalize(btrim(user_name), NFKC)),
+    'авеһнірјкӏмоԛѕтуԝхԁсαβεζηικμνορτυχ01',
+    'abehhipjklmoqstywxdcabezhikmnoptyxol'
+) END;
+
+DO $$
+DECLARE
+    duplicates TEXT;
+BEGIN
+    SELECT string_agg(names, '; ') INTO duplicates FROM (
+        SELECT string_agg(user_name, ', ' ORDER BY registration_date) AS names FROM users
+        GROUP BY user_name_canonical HAVING count(*) > 1
+    ) AS groups;
+    IF duplicates IS NOT NULL THEN
+        RAISE EXCEPTION 'user names with the same canonical form: %', duplicates;
+    END IF;
+END $$;
+
+ALTER TABLE users
+    ALTER COLUMN user_name_canonical SET NOT NULL,
+    ADD CONSTRAINT users_user_name_canonical_key UNIQUE (user_name_canonical);
+
+COMMIT;
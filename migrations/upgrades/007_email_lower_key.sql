@@ -0,0 +1,28 @@
+-- Makes emails unique case-insensitively and lowercases their domains.
+-- The upgrade stops and lists the emails if two existing users only differ in case, merge or delete one of them and run it again.
+-- Local parts are left as they are, lookups compare lower(email) so "Bob@x.com" still signs in as "bob@x.com".
+-- Gmail addresses aren't folded here, with EMAIL_FOLD_GMAIL = true run migrations/fold_gmail_emails.sql after this script.
+
+BEGIN;
+
+DO $$
+DECLARE
+    duplicates TEXT;
+BEGIN
+    SELECT string_agg(emails, '; ') INTO duplicates FROM (
+        SELECT string_agg(email, ', ' ORDER BY registration_date) AS emails FROM users
+        GROUP BY lower(email) HAVING count(*) > 1
+    ) AS groups;
+    IF duplicates IS NOT NULL THEN
+        RAISE EXCEPTION 'emails that only differ in case: %', duplicates;
+    END IF;
+END $$;
+
+ALTER TABLE users DROP CONSTRAINT users_email_key;
+
+UPDATE users SET email = substring(email from '^(.*)@') || '@' || lower(substring(email from '@([^@]*)$'))
+    WHERE substring(email from '@([^@]*)$') <> lower(substring(email from '@([^@]*)$'));
+
+CREATE UNIQUE INDEX users_email_lower_key ON users (lower(email));
+
+COMMIT;
@@ -0,0 +1,11 @@
+-- Adds the admin entries of the email domain policy.
+
+BEGIN;
+
+CREATE TABLE email_domain_rules(
+    domain            TEXT                                                                  NOT NULL PRIMARY KEY CHECK(domain != ''),
+    kind              TEXT                                                                  NOT NULL CHECK(kind IN ('allow', 'block')),
+    created_at        TIMESTAMP                                                             NOT NULL
+);
+
+COMMIT;
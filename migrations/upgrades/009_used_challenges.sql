@@ -0,0 +1,10 @@
+-- Adds the solved proof-of-work challenges.
+
+BEGIN;
+
+CREATE TABLE used_challenges(
+    jti               TEXT                                                                  NOT NULL PRIMARY KEY,
+    expiration_time   TIMESTAMP                                                             NOT NULL
+);
+
+COMMIT;
@@ -0,0 +1,11 @@
+-- Adds the start and the remember me flag of sessions, sessions that exist already count as remembered and started now.
+
+BEGIN;
+
+ALTER TABLE users
+    ADD COLUMN session_started_at TIMESTAMP,
+    ADD COLUMN remember_me BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE users SET session_started_at = now() AT TIME ZONE 'utc', remember_me = TRUE WHERE refresh_token IS NOT NULL;
+
+COMMIT;
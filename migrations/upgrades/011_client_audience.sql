@@ -0,0 +1,8 @@
+-- Adds the audience of access tokens issued to a client, clients without one use their client id.
+
+BEGIN;
+
+ALTER TABLE oauth_clients
+    ADD COLUMN audience TEXT;
+
+COMMIT;
@@ -0,0 +1,13 @@
+-- Adds when the user granted the client, rotated refresh tokens keep it. Tokens that exist already count as granted now.
+
+BEGIN;
+
+ALTER TABLE oauth_refresh_tokens
+    ADD COLUMN granted_at TIMESTAMP;
+
+UPDATE oauth_refresh_tokens SET granted_at = now() AT TIME ZONE 'utc';
+
+ALTER TABLE oauth_refresh_tokens
+    ALTER COLUMN granted_at SET NOT NULL;
+
+COMMIT;
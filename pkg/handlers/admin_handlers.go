@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/dto"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	repository "github.com/Kin-dza-dzaa/userApi/pkg/repositories"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+func (handlers *Handlers) SearchUsersHandler() apierror.UserHandler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-type", "application/json")
+		actor, err := sessionUser(r)
+		if err != nil {
+			return err
+		}
+		query := r.URL.Query()
+		searchDto := dto.UserSearchDto{
+			Email:            query.Get("email"),
+			UserName:         query.Get("user_name"),
+			Verified:         query.Get("verified"),
+			Status:           query.Get("status"),
+			RegisteredAfter:  query.Get("registered_after"),
+			RegisteredBefore: query.Get("registered_before"),
+			Page:             query.Get("page"),
+			PerPage:          query.Get("per_page"),
+		}
+		filter, err := searchDto.IntoUserFilter()
+		if err != nil {
+			return err
+		}
+		page, err := handlers.Service.SearchUsers(r.Context(), actor.UserId, filter)
+		if err != nil {
+			return err
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": "ok", "code": 200, "users": page.Users, "total": page.Total, "page": page.Page, "per_page": page.PerPage})
+		return nil
+	}
+}
+
+func (handlers *Handlers) GetAdminUserHandler() apierror.UserHandler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-type", "application/json")
+		actor, err := sessionUser(r)
+		if err != nil {
+			return err
+		}
+		user := new(models.AdminUser)
+		if user.UserId, err = uuid.Parse(mux.Vars(r)["id"]); err != nil {
+			return apierror.NewCatalogError(apierror.CodeUserNotFound, repository.ErrUserNotFound.Error())
+		}
+		if err := handlers.Service.GetAdminUser(r.Context(), actor.UserId, user); err != nil {
+			return err
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": "ok", "code": 200, "user": user})
+		return nil
+	}
+}
+
+// AdminActionHandler runs action on the user from the path on behalf of the signed in admin, the body {"reason": "string"} is optional.
+func (handlers *Handlers) AdminActionHandler(action func(context.Context, *models.AdminAction) error) apierror.UserHandler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-type", "application/json")
+		actor, err := sessionUser(r)
+		if err != nil {
+			return err
+		}
+		var actionDto dto.AdminActionDto
+		if err := json.NewDecoder(r.Body).Decode(&actionDto); err != nil && err != io.EOF {
+			return apierror.NewCatalogError(apierror.CodeMalformedBody, `unmarshal failed, expected object{"reason":"string"} or no body`)
+		}
+		adminAction, err := actionDto.IntoAdminAction()
+		if err != nil {
+			return err
+		}
+		adminAction.ActorId = actor.UserId
+		if adminAction.TargetId, err = uuid.Parse(mux.Vars(r)["id"]); err != nil {
+			return apierror.NewCatalogError(apierror.CodeUserNotFound, repository.ErrUserNotFound.Error())
+		}
+		if err := action(r.Context(), adminAction); err != nil {
+			return err
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": "ok", "code": 200})
+		return nil
+	}
+}
+
+// ResetPasswordHandler sets a new password with the code from the password reset email.
+func (handlers *Handlers) ResetPasswordHandler() apierror.UserHandler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-type", "application/json")
+		var resetDto dto.PasswordResetDto
+		if err := json.NewDecoder(r.Body).Decode(&resetDto); err != nil {
+			return apierror.NewCatalogError(apierror.CodeMalformedBody, `unmarshal failed, expected object{"code":"string", "password":"string"}`)
+		}
+		reset, err := resetDto.IntoPasswordReset()
+		if err != nil {
+			return err
+		}
+		if err := handlers.Service.ResetPassword(r.Context(), reset); err != nil {
+			return err
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": "ok", "code": 200})
+		return nil
+	}
+}
+
+// SecureAccountHandler signs the user out everywhere with the code from a new device email.
+func (handlers *Handlers) SecureAccountHandler() apierror.UserHandler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-type", "application/json")
+		var secureDto dto.SecureAccountDto
+		if err := json.NewDecoder(r.Body).Decode(&secureDto); err != nil {
+			return apierror.NewCatalogError(apierror.CodeMalformedBody, `unmarshal failed, expected object{"code":"string"}`)
+		}
+		code, err := secureDto.IntoSecureAccountCode()
+		if err != nil {
+			return err
+		}
+		if err := handlers.Service.SecureAccount(r.Context(), code); err != nil {
+			return err
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": "ok", "code": 200})
+		return nil
+	}
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	config "github.com/Kin-dza-dzaa/userApi/configs"
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/Kin-dza-dzaa/userApi/pkg/mocks"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSearchUsersHandler(t *testing.T) {
+	adminId := uuid.New()
+	testCases := []struct {
+		name       string
+		claims     *models.MyJwtClaims
+		query      string
+		beforeTest func(service *mocks.Service)
+		errCode    string
+	}{
+		{
+			name:   "filtered",
+			claims: &models.MyJwtClaims{UserId: adminId.String(), Roles: []string{models.RoleAdmin}},
+			query:  "?email=gmail&verified=false&registered_after=2022-01-01&page=2",
+			beforeTest: func(service *mocks.Service) {
+				service.On("SearchUsers", mock.Anything, adminId, mock.MatchedBy(func(filter *models.UserFilter) bool {
+					return filter.Email == "gmail" && !*filter.Verified && filter.RegisteredAfter.Year() == 2022 && filter.Page == 2 && filter.PerPage == 20
+				})).Return(&models.UserPage{Users: []models.AdminUser{{UserName: "test"}}, Total: 21, Page: 2, PerPage: 20}, nil).Once()
+			},
+		},
+		{
+			name:    "invalid_filter",
+			claims:  &models.MyJwtClaims{UserId: adminId.String(), Roles: []string{models.RoleAdmin}},
+			query:   "?verified=maybe&per_page=1000",
+			errCode: apierror.CodeValidationFailed,
+		},
+		{
+			name:    "not_admin",
+			claims:  &models.MyJwtClaims{UserId: adminId.String(), Scope: models.PermissionRolesManage},
+			errCode: apierror.CodePermissionDenied,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			service := mocks.NewService(t)
+			handlers := &Handlers{Service: service, Config: &config.Config{}}
+			service.On("Authenticate", mock.Anything, "jwt").Return(tc.claims, nil).Once()
+			if tc.beforeTest != nil {
+				tc.beforeTest(service)
+			}
+			r := httptest.NewRequest("GET", "/admin/users"+tc.query, nil)
+			r.Header.Set("Authorization", "Bearer jwt")
+			w := httptest.NewRecorder()
+			err := handlers.AdminMiddleWare(handlers.SearchUsersHandler())(w, r)
+			if tc.errCode != "" {
+				if err.(*apierror.ErrorStruct).ErrorCode != tc.errCode {
+					t.FailNow()
+				}
+				return
+			}
+			var response struct {
+				Users []models.AdminUser `json:"users"`
+				Total int                `json:"total"`
+			}
+			if err != nil || w.Code != http.StatusOK || json.NewDecoder(w.Body).Decode(&response) != nil || response.Total != 21 || len(response.Users) != 1 {
+				t.FailNow()
+			}
+		})
+	}
+}
+
+func TestAdminActionHandler(t *testing.T) {
+	adminId, userId := uuid.New(), uuid.New()
+	for name, body := range map[string]string{"with_reason": `{"reason":"spam"}`, "without_body": ""} {
+		t.Run(name, func(t *testing.T) {
+			var got *models.AdminAction
+			action := func(ctx context.Context, action *models.AdminAction) error {
+				got = action
+				return nil
+			}
+			handlers := &Handlers{Service: mocks.NewService(t), Config: &config.Config{}}
+			r := httptest.NewRequest("POST", "/admin/users/"+userId.String()+"/suspend", strings.NewReader(body))
+			r = mux.SetURLVars(r.WithContext(context.WithValue(r.Context(), claimsContextKey, &models.MyJwtClaims{UserId: adminId.String()})), map[string]string{"id": userId.String()})
+			w := httptest.NewRecorder()
+			if err := handlers.AdminActionHandler(action)(w, r); err != nil || w.Code != http.StatusOK {
+				t.FailNow()
+			}
+			if got.ActorId != adminId || got.TargetId != userId || (name == "with_reason") != (got.Reason == "spam") {
+				t.FailNow()
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/dto"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+)
+
+// GetUserActivityHandler lists the audit events of the signed in user, newest first.
+func (handlers *Handlers) GetUserActivityHandler() apierror.UserHandler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-type", "application/json")
+		user, err := sessionUser(r)
+		if err != nil {
+			return err
+		}
+		filter, err := auditSearchDto(r).IntoAuditFilter()
+		if err != nil {
+			return err
+		}
+		page, err := handlers.Service.GetUserActivity(r.Context(), user, filter)
+		if err != nil {
+			return err
+		}
+		writeAuditPage(w, page)
+		return nil
+	}
+}
+
+func (handlers *Handlers) GetAuditEventsHandler() apierror.UserHandler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-type", "application/json")
+		filter, err := auditSearchDto(r).IntoAuditFilter()
+		if err != nil {
+			return err
+		}
+		page, err := handlers.Service.GetAuditEvents(r.Context(), filter)
+		if err != nil {
+			return err
+		}
+		writeAuditPage(w, page)
+		return nil
+	}
+}
+
+func auditSearchDto(r *http.Request) dto.AuditSearchDto {
+	query := r.URL.Query()
+	return dto.AuditSearchDto{
+		Type:     query.Get("type"),
+		ActorId:  query.Get("actor_id"),
+		TargetId: query.Get("target_id"),
+		Outcome:  query.Get("outcome"),
+		From:     query.Get("from"),
+		To:       query.Get("to"),
+		Cursor:   query.Get("cursor"),
+		Limit:    query.Get("limit"),
+	}
+}
+
+func writeAuditPage(w http.ResponseWriter, page *models.AuditPage) {
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{"result": "ok", "code": 200, "events": page.Events, "next_cursor": page.NextCursor})
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	config "github.com/Kin-dza-dzaa/userApi/configs"
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/Kin-dza-dzaa/userApi/pkg/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetUserActivityHandler(t *testing.T) {
+	userId := uuid.New()
+	testCases := []struct {
+		name       string
+		query      string
+		beforeTest func(service *mocks.Service)
+		errCode    string
+	}{
+		{
+			name:  "own_events",
+			query: "?type=user.signin&limit=10",
+			beforeTest: func(service *mocks.Service) {
+				service.On("GetUserActivity", mock.Anything, &models.User{UserId: userId}, mock.MatchedBy(func(filter *models.AuditFilter) bool {
+					return filter.Type == "user.signin" && filter.Limit == 10
+				})).Return(&models.AuditPage{Events: []models.AuditEvent{{Type: "user.signin"}}, NextCursor: "next"}, nil).Once()
+			},
+		},
+		{
+			name:    "invalid_filter",
+			query:   "?limit=0",
+			errCode: apierror.CodeValidationFailed,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			service := mocks.NewService(t)
+			handlers := &Handlers{Service: service, Config: &config.Config{}}
+			service.On("Authenticate", mock.Anything, "jwt").Return(&models.MyJwtClaims{UserId: userId.String()}, nil).Once()
+			if tc.beforeTest != nil {
+				tc.beforeTest(service)
+			}
+			r := httptest.NewRequest("GET", "/user/activity"+tc.query, nil)
+			r.Header.Set("Authorization", "Bearer jwt")
+			w := httptest.NewRecorder()
+			err := handlers.AuthMiddleWare(handlers.GetUserActivityHandler())(w, r)
+			if tc.errCode != "" {
+				if err.(*apierror.ErrorStruct).ErrorCode != tc.errCode {
+					t.FailNow()
+				}
+				return
+			}
+			var response struct {
+				Events     []models.AuditEvent `json:"events"`
+				NextCursor string              `json:"next_cursor"`
+			}
+			if err != nil || w.Code != http.StatusOK || json.NewDecoder(w.Body).Decode(&response) != nil || len(response.Events) != 1 || response.NextCursor != "next" {
+				t.FailNow()
+			}
+		})
+	}
+}
+
+func TestGetAuditEventsHandlerNotAdmin(t *testing.T) {
+	service := mocks.NewService(t)
+	handlers := &Handlers{Service: service, Config: &config.Config{}}
+	service.On("Authenticate", mock.Anything, "jwt").Return(&models.MyJwtClaims{UserId: uuid.NewString()}, nil).Once()
+	r := httptest.NewRequest("GET", "/admin/audit", nil)
+	r.Header.Set("Authorization", "Bearer jwt")
+	if err := handlers.AdminMiddleWare(handlers.GetAuditEventsHandler())(httptest.NewRecorder(), r); err.(*apierror.ErrorStruct).ErrorCode != apierror.CodePermissionDenied {
+		t.FailNow()
+	}
+}
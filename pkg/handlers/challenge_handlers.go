@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/dto"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+)
+
+// a solved proof-of-work is sent in ChallengeHeader and ChallengeSolutionHeader, a CAPTCHA token in CaptchaTokenHeader
+const (
+	ChallengeHeader         = "X-Challenge"
+	ChallengeSolutionHeader = "X-Challenge-Solution"
+	CaptchaTokenHeader      = "X-Captcha-Token"
+)
+
+// GetChallengeHandler tells the client what to solve before calling ?endpoint=sign_up or sign_in.
+func (handlers *Handlers) GetChallengeHandler() apierror.UserHandler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-type", "application/json")
+		endpoint, err := dto.ChallengeDto{Endpoint: r.URL.Query().Get("endpoint")}.IntoEndpoint()
+		if err != nil {
+			return err
+		}
+		challenge, err := handlers.Service.NewChallenge(r.Context(), endpoint)
+		if err != nil {
+			return err
+		}
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": "ok", "code": 200, "challenge": challenge})
+		return nil
+	}
+}
+
+// ChallengeMiddleWare lets the request through to next once the challenge of endpoint is solved,
+// the service decides per endpoint if there is one.
+func (handlers *Handlers) ChallengeMiddleWare(endpoint string, next apierror.UserHandler) apierror.UserHandler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		response := &models.ChallengeResponse{
+			Endpoint:     endpoint,
+			Token:        r.Header.Get(ChallengeHeader),
+			Solution:     r.Header.Get(ChallengeSolutionHeader),
+			CaptchaToken: r.Header.Get(CaptchaTokenHeader),
+			Ip:           models.RequestMetaFromContext(r.Context()).Ip,
+		}
+		if err := handlers.Service.VerifyChallenge(r.Context(), response); err != nil {
+			return err
+		}
+		return next(w, r)
+	}
+}
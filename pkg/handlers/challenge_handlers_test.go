@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	config "github.com/Kin-dza-dzaa/userApi/configs"
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/Kin-dza-dzaa/userApi/pkg/mocks"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestChallengeMiddleWare(t *testing.T) {
+	service := mocks.NewService(t)
+	handlers := &Handlers{Service: service, Config: &config.Config{}}
+	next := func(w http.ResponseWriter, r *http.Request) error {
+		w.WriteHeader(http.StatusOK)
+		return nil
+	}
+	service.On("VerifyChallenge", mock.Anything, &models.ChallengeResponse{Endpoint: models.ChallengeSignUp, Token: "puzzle", Solution: "42", Ip: ""}).Return(nil).Once()
+	r := httptest.NewRequest("POST", "/user", nil)
+	r.Header.Set(ChallengeHeader, "puzzle")
+	r.Header.Set(ChallengeSolutionHeader, "42")
+	w := httptest.NewRecorder()
+	if err := handlers.ChallengeMiddleWare(models.ChallengeSignUp, next)(w, r); err != nil || w.Code != http.StatusOK {
+		t.FailNow()
+	}
+
+	service.On("VerifyChallenge", mock.Anything, mock.Anything).Return(apierror.NewCatalogError(apierror.CodeChallengeRequired, "")).Once()
+	err := handlers.ChallengeMiddleWare(models.ChallengeSignUp, next)(httptest.NewRecorder(), httptest.NewRequest("POST", "/user", nil))
+	if err == nil || err.(*apierror.ErrorStruct).ErrorCode != apierror.CodeChallengeRequired {
+		t.FailNow()
+	}
+}
+
+func TestGetChallengeHandlerInvalidEndpoint(t *testing.T) {
+	handlers := &Handlers{Service: mocks.NewService(t), Config: &config.Config{}}
+	err := handlers.GetChallengeHandler()(httptest.NewRecorder(), httptest.NewRequest("GET", "/user/challenge?endpoint=logout", nil))
+	if err == nil || err.(*apierror.ErrorStruct).ErrorCode != apierror.CodeValidationFailed {
+		t.FailNow()
+	}
+}
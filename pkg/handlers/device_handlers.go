@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/dto"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+)
+
+// DeviceAuthorizationHandler serves the RFC 8628 device authorization endpoint.
+func (handlers *Handlers) DeviceAuthorizationHandler() apierror.UserHandler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		if err := r.ParseForm(); err != nil {
+			return apierror.NewOAuthError(apierror.OAuthInvalidRequest, ErrMalformedForm.Error())
+		}
+		request := &models.DeviceAuthorizationRequest{
+			ClientId:     r.PostForm.Get("client_id"),
+			ClientSecret: r.PostForm.Get("client_secret"),
+			Scope:        r.PostForm.Get("scope"),
+		}
+		request.ClientId, request.ClientSecret = clientCredentials(r, request.ClientId, request.ClientSecret)
+		response, err := handlers.Service.AuthorizeDevice(r.Context(), request)
+		if err != nil {
+			return err
+		}
+		w.Header().Set("Content-type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("Pragma", "no-cache")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(response)
+		return nil
+	}
+}
+
+// GetDevicePromptHandler shows the signed in user the client and the scopes of a user code before they decide.
+func (handlers *Handlers) GetDevicePromptHandler() apierror.UserHandler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-type", "application/json")
+		if _, err := sessionUser(r); err != nil {
+			return err
+		}
+		userCode := strings.TrimSpace(r.URL.Query().Get("user_code"))
+		if userCode == "" {
+			return apierror.NewValidationError(dto.ErrInvalidDeviceApproval.Error(), []apierror.FieldError{{Field: "user_code", Code: dto.CodeRequired}})
+		}
+		prompt, err := handlers.Service.GetDevicePrompt(r.Context(), userCode)
+		if err != nil {
+			return err
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": "ok", "code": 200, "device": prompt})
+		return nil
+	}
+}
+
+// ApproveDeviceHandler lets the signed in user approve or deny the device showing the user code, an approval has to
+// confirm the client_id returned by GetDevicePromptHandler.
+func (handlers *Handlers) ApproveDeviceHandler() apierror.UserHandler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-type", "application/json")
+		user, err := sessionUser(r)
+		if err != nil {
+			return err
+		}
+		var approvalDto dto.DeviceApprovalDto
+		if err := json.NewDecoder(r.Body).Decode(&approvalDto); err != nil {
+			return apierror.NewCatalogError(apierror.CodeMalformedBody, `unmarshal failed, expected object{"user_code":"string", "client_id":"string", "approve":bool}`)
+		}
+		approval, err := approvalDto.IntoDeviceApproval(user.UserId)
+		if err != nil {
+			return err
+		}
+		if err := handlers.Service.ApproveDevice(r.Context(), approval); err != nil {
+			return err
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": "ok", "code": 200})
+		return nil
+	}
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	config "github.com/Kin-dza-dzaa/userApi/configs"
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/Kin-dza-dzaa/userApi/pkg/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDeviceAuthorizationHandler(t *testing.T) {
+	service := mocks.NewService(t)
+	handlers := &Handlers{Service: service, Config: &config.Config{}}
+	service.On("AuthorizeDevice", mock.Anything, &models.DeviceAuthorizationRequest{ClientId: "cli", Scope: "openid"}).Return(&models.DeviceAuthorizationResponse{DeviceCode: "device", UserCode: "BCDF-GHJK", Interval: 5}, nil).Once()
+	r := httptest.NewRequest("POST", "/oauth/device", strings.NewReader(url.Values{"client_id": {"cli"}, "scope": {"openid"}}.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	var response models.DeviceAuthorizationResponse
+	if err := handlers.DeviceAuthorizationHandler()(w, r); err != nil || w.Header().Get("Cache-Control") != "no-store" || json.NewDecoder(w.Body).Decode(&response) != nil || response.UserCode != "BCDF-GHJK" {
+		t.FailNow()
+	}
+}
+
+func TestApproveDeviceHandler(t *testing.T) {
+	userId := uuid.New()
+	testCases := []struct {
+		name       string
+		body       string
+		beforeTest func(service *mocks.Service)
+		errCode    string
+	}{
+		{
+			name: "approved",
+			body: `{"user_code":"BCDF-GHJK","client_id":"cli","approve":true}`,
+			beforeTest: func(service *mocks.Service) {
+				service.On("ApproveDevice", mock.Anything, &models.DeviceApproval{UserCode: "BCDF-GHJK", UserId: userId, ClientId: "cli", Approve: true}).Return(nil).Once()
+			},
+		},
+		{
+			name: "denied",
+			body: `{"user_code":"BCDF-GHJK","approve":false}`,
+			beforeTest: func(service *mocks.Service) {
+				service.On("ApproveDevice", mock.Anything, &models.DeviceApproval{UserCode: "BCDF-GHJK", UserId: userId}).Return(nil).Once()
+			},
+		},
+		{
+			name:    "approved_without_client_id",
+			body:    `{"user_code":"BCDF-GHJK","approve":true}`,
+			errCode: apierror.CodeValidationFailed,
+		},
+		{
+			name: "unknown_user_code",
+			body: `{"user_code":"BCDF-GHJK","client_id":"cli","approve":true}`,
+			beforeTest: func(service *mocks.Service) {
+				service.On("ApproveDevice", mock.Anything, mock.Anything).Return(apierror.NewCatalogError(apierror.CodeUserCodeInvalid, "")).Once()
+			},
+			errCode: apierror.CodeUserCodeInvalid,
+		},
+		{
+			name:    "missing_user_code",
+			body:    `{"approve":true}`,
+			errCode: apierror.CodeValidationFailed,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			service := mocks.NewService(t)
+			handlers := &Handlers{Service: service, Config: &config.Config{}}
+			service.On("Authenticate", mock.Anything, "jwt").Return(&models.MyJwtClaims{UserId: userId.String()}, nil).Once()
+			if tc.beforeTest != nil {
+				tc.beforeTest(service)
+			}
+			r := httptest.NewRequest("POST", "/user/device", strings.NewReader(tc.body))
+			r.Header.Set("Authorization", "Bearer jwt")
+			w := httptest.NewRecorder()
+			err := handlers.AuthMiddleWare(handlers.ApproveDeviceHandler())(w, r)
+			if tc.errCode != "" {
+				if err.(*apierror.ErrorStruct).ErrorCode != tc.errCode {
+					t.FailNow()
+				}
+				return
+			}
+			if err != nil || w.Code != http.StatusOK {
+				t.FailNow()
+			}
+		})
+	}
+}
+
+func TestGetDevicePromptHandler(t *testing.T) {
+	userId := uuid.New()
+	testCases := []struct {
+		name       string
+		query      string
+		beforeTest func(service *mocks.Service)
+		errCode    string
+	}{
+		{
+			name:  "pending",
+			query: "?user_code=BCDF-GHJK",
+			beforeTest: func(service *mocks.Service) {
+				service.On("GetDevicePrompt", mock.Anything, "BCDF-GHJK").Return(&models.DevicePrompt{UserCode: "BCDF-GHJK", ClientId: "cli", ClientName: "Words CLI", Scopes: []string{"openid"}}, nil).Once()
+			},
+		},
+		{
+			name:  "unknown_user_code",
+			query: "?user_code=BCDF-GHJK",
+			beforeTest: func(service *mocks.Service) {
+				service.On("GetDevicePrompt", mock.Anything, "BCDF-GHJK").Return(nil, apierror.NewCatalogError(apierror.CodeUserCodeInvalid, "")).Once()
+			},
+			errCode: apierror.CodeUserCodeInvalid,
+		},
+		{
+			name:    "missing_user_code",
+			errCode: apierror.CodeValidationFailed,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			service := mocks.NewService(t)
+			handlers := &Handlers{Service: service, Config: &config.Config{}}
+			service.On("Authenticate", mock.Anything, "jwt").Return(&models.MyJwtClaims{UserId: userId.String()}, nil).Once()
+			if tc.beforeTest != nil {
+				tc.beforeTest(service)
+			}
+			r := httptest.NewRequest("GET", "/user/device"+tc.query, nil)
+			r.Header.Set("Authorization", "Bearer jwt")
+			w := httptest.NewRecorder()
+			err := handlers.AuthMiddleWare(handlers.GetDevicePromptHandler())(w, r)
+			if tc.errCode != "" {
+				if err.(*apierror.ErrorStruct).ErrorCode != tc.errCode {
+					t.FailNow()
+				}
+				return
+			}
+			var response struct {
+				Device models.DevicePrompt `json:"device"`
+			}
+			if err != nil || w.Code != http.StatusOK || json.NewDecoder(w.Body).Decode(&response) != nil || response.Device.ClientName != "Words CLI" {
+				t.FailNow()
+			}
+		})
+	}
+}
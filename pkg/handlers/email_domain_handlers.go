@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/dto"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/gorilla/mux"
+)
+
+// GetEmailDomainRulesHandler lists the admin entries of the email domain policy, the bundled and file lists aren't included.
+func (handlers *Handlers) GetEmailDomainRulesHandler() apierror.UserHandler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-type", "application/json")
+		rules, err := handlers.Service.GetEmailDomainRules(r.Context())
+		if err != nil {
+			return err
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": "ok", "code": 200, "domains": rules})
+		return nil
+	}
+}
+
+func (handlers *Handlers) PutEmailDomainRuleHandler() apierror.UserHandler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-type", "application/json")
+		var ruleDto dto.EmailDomainRuleDto
+		if err := json.NewDecoder(r.Body).Decode(&ruleDto); err != nil {
+			return apierror.NewCatalogError(apierror.CodeMalformedBody, `unmarshal failed, expected object{"kind":"allow|block"}`)
+		}
+		rule, err := ruleDto.IntoEmailDomainRule(mux.Vars(r)["domain"])
+		if err != nil {
+			return err
+		}
+		if err := handlers.Service.PutEmailDomainRule(r.Context(), rule); err != nil {
+			return err
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": "ok", "code": 200})
+		return nil
+	}
+}
+
+func (handlers *Handlers) DeleteEmailDomainRuleHandler() apierror.UserHandler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-type", "application/json")
+		rule := &models.EmailDomainRule{Domain: strings.ToLower(mux.Vars(r)["domain"])}
+		if err := handlers.Service.DeleteEmailDomainRule(r.Context(), rule); err != nil {
+			return err
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": "ok", "code": 200})
+		return nil
+	}
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	config "github.com/Kin-dza-dzaa/userApi/configs"
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/Kin-dza-dzaa/userApi/pkg/mocks"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPutEmailDomainRuleHandler(t *testing.T) {
+	testCases := []struct {
+		name       string
+		domain     string
+		body       string
+		beforeTest func(service *mocks.Service)
+		errCode    string
+	}{
+		{
+			name:   "blocked",
+			domain: "Throwaway.Example",
+			body:   `{"kind":"block"}`,
+			beforeTest: func(service *mocks.Service) {
+				service.On("PutEmailDomainRule", mock.Anything, &models.EmailDomainRule{Domain: "throwaway.example", Kind: models.EmailDomainBlock}).Return(nil).Once()
+			},
+		},
+		{
+			name:    "invalid_kind",
+			domain:  "throwaway.example",
+			body:    `{"kind":"deny"}`,
+			errCode: apierror.CodeValidationFailed,
+		},
+		{
+			name:    "invalid_domain",
+			domain:  "-throwaway.example",
+			body:    `{"kind":"block"}`,
+			errCode: apierror.CodeValidationFailed,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			service := mocks.NewService(t)
+			handlers := &Handlers{Service: service, Config: &config.Config{}}
+			if tc.beforeTest != nil {
+				tc.beforeTest(service)
+			}
+			r := mux.SetURLVars(httptest.NewRequest("PUT", "/admin/email-domains/"+tc.domain, strings.NewReader(tc.body)), map[string]string{"domain": tc.domain})
+			w := httptest.NewRecorder()
+			err := handlers.PutEmailDomainRuleHandler()(w, r)
+			if tc.errCode != "" {
+				if err.(*apierror.ErrorStruct).ErrorCode != tc.errCode {
+					t.FailNow()
+				}
+				return
+			}
+			if err != nil || w.Code != http.StatusOK {
+				t.FailNow()
+			}
+		})
+	}
+}
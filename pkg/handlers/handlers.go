@@ -18,6 +18,7 @@ var StopHTTPServerChan = make(chan bool)
 
 var (
 	ErrCookieNotPresent = errors.New("cookie not present")
+	ErrCsrfTokenMismatch = errors.New("csrf token is missing or doesn't match")
 )
 
 type Handlers struct {
@@ -33,7 +34,7 @@ func (handlers *Handlers) SignUpHandler() apierror.UserHandler {
 		w.Header().Set("Content-type", "application/json")
 		var userDto dto.UserSignUpDto
 		if err := json.NewDecoder(r.Body).Decode(&userDto); err != nil {
-			return apierror.NewErrorStruct(`unmarshal failed, expected object{"email":"string", "password":"string", "user_name": "string"}`, "error", http.StatusBadRequest)
+			return apierror.NewCatalogError(apierror.CodeMalformedBody, `unmarshal failed, expected object{"email":"string", "password":"string", "user_name": "string"}`)
 		}
 		User, err := userDto.IntoUser()
 		if err != nil {
@@ -42,8 +43,12 @@ func (handlers *Handlers) SignUpHandler() apierror.UserHandler {
 		if err := handlers.Service.SignUpUser(r.Context(), User); err != nil {
 			return err
 		}
+		message := "email was sent"
+		if handlers.Config.HardenedAuth {
+			message = "check your email"
+		}
 		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(map[string]interface{}{"result": "ok", "message": "email was sent", "code": 200})
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": "ok", "message": message, "code": 200})
 		return nil
 	}
 }
@@ -53,7 +58,7 @@ func (handlers *Handlers) SignInHandler() apierror.UserHandler {
 		w.Header().Set("Content-type", "application/json")
 		var userDto dto.UserSignInDto
 		if err := json.NewDecoder(r.Body).Decode(&userDto); err != nil {
-			return apierror.NewErrorStruct(`unmarshal failed, expected object{"email":"string", "password":"string"}`, "error", http.StatusBadRequest)
+			return apierror.NewCatalogError(apierror.CodeMalformedBody, `unmarshal failed, expected object{"email":"string", "password":"string"}`)
 		}
 		User, err := userDto.IntoUser()
 		if err != nil {
@@ -62,27 +67,7 @@ func (handlers *Handlers) SignInHandler() apierror.UserHandler {
 		if err := handlers.Service.SignInUser(r.Context(), User); err != nil {
 			return err
 		}
-		http.SetCookie(w, &http.Cookie{
-			Name:     "Refresh-token",
-			Value:    User.RefreshToken,
-			Expires:  User.ExpirationTime,
-			HttpOnly: true,
-			Secure:   handlers.Config.Secure,
-			SameSite: http.SameSiteStrictMode,
-			Path:     "/",
-		})
-		http.SetCookie(w, &http.Cookie{
-			Name:     "Access-token",
-			Value:    User.Jwt,
-			MaxAge:   300,
-			HttpOnly: true,
-			Secure:   handlers.Config.Secure,
-			SameSite: http.SameSiteStrictMode,
-			Path:     "/",
-		})
-		w.Header().Set("X-CSRF-Token", User.CsrfToken)
-		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(map[string]interface{}{"result": "ok", "code": 200})
+		handlers.writeTokens(w, r, User, true)
 		return nil
 	}
 }
@@ -96,27 +81,7 @@ func (handlers *Handlers) VerifyHandler() apierror.UserHandler {
 		if err := handlers.Service.VerifyUser(r.Context(), &user); err != nil {
 			return err
 		}
-		http.SetCookie(w, &http.Cookie{
-			Name:     "Refresh-token",
-			Value:    user.RefreshToken,
-			Expires:  user.ExpirationTime,
-			HttpOnly: true,
-			Secure:   handlers.Config.Secure,
-			SameSite: http.SameSiteStrictMode,
-			Path:     "/",
-		})
-		http.SetCookie(w, &http.Cookie{
-			Name:     "Access-token",
-			Value:    user.Jwt,
-			MaxAge:   300,
-			HttpOnly: true,
-			Secure:   handlers.Config.Secure,
-			SameSite: http.SameSiteStrictMode,
-			Path:     "/",
-		})
-		w.Header().Set("X-CSRF-Token", user.CsrfToken)
-		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(map[string]interface{}{"result": "ok", "code": 200})
+		handlers.writeTokens(w, r, &user, true)
 		return nil
 	}
 }
@@ -124,27 +89,55 @@ func (handlers *Handlers) VerifyHandler() apierror.UserHandler {
 func (handlers *Handlers) GetTokenHandler() apierror.UserHandler {
 	return func(w http.ResponseWriter, r *http.Request) error {
 		w.Header().Set("Content-type", "application/json")
-		cookie, err := r.Cookie("Refresh-token")
-		if err != nil {
-			return apierror.NewErrorStruct(ErrCookieNotPresent.Error(), "error", http.StatusBadRequest)
-		}
 		user := new(models.User)
-		user.RefreshToken = cookie.Value
+		if bodyTokenDelivery(r) {
+			var tokenDto dto.RefreshTokenDto
+			if err := json.NewDecoder(r.Body).Decode(&tokenDto); err != nil || tokenDto.RefreshToken == "" {
+				return apierror.NewCatalogError(apierror.CodeMalformedBody, `unmarshal failed, expected object{"refresh_token":"string"}`)
+			}
+			user.RefreshToken = tokenDto.RefreshToken
+		} else {
+			cookie, err := r.Cookie("Refresh-token")
+			if err != nil {
+				return apierror.NewCatalogError(apierror.CodeCookieNotPresent, ErrCookieNotPresent.Error())
+			}
+			user.RefreshToken = cookie.Value
+		}
+		// the access token the device had, even an expired one, keeps its re-authentication across the refresh
+		if token := bearerToken(r); token != "" {
+			user.PreviousJwt = token
+		} else if cookie, err := r.Cookie("Access-token"); err == nil {
+			user.PreviousJwt = cookie.Value
+		}
 		if err := handlers.Service.GetAccessToken(r.Context(), user); err != nil {
 			return err
 		}
-		http.SetCookie(w, &http.Cookie{
-			Name:     "Access-token",
-			Value:    user.Jwt,
-			MaxAge:   300,
-			HttpOnly: true,
-			Secure:   handlers.Config.Secure,
-			SameSite: http.SameSiteStrictMode,
-			Path:     "/",
-		})
-		w.Header().Set("X-CSRF-Token", user.CsrfToken)
-		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(map[string]interface{}{"result": "ok", "code": 200})
+		handlers.writeTokens(w, r, user, false)
+		return nil
+	}
+}
+
+// ReauthHandler sends a new access token with a fresh auth_time once the password is confirmed, the refresh token stays.
+func (handlers *Handlers) ReauthHandler() apierror.UserHandler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-type", "application/json")
+		session, err := sessionUser(r)
+		if err != nil {
+			return err
+		}
+		var reauthDto dto.ReauthDto
+		if err := json.NewDecoder(r.Body).Decode(&reauthDto); err != nil {
+			return apierror.NewCatalogError(apierror.CodeMalformedBody, `unmarshal failed, expected object{"password":"string"}`)
+		}
+		user, err := reauthDto.IntoUser()
+		if err != nil {
+			return err
+		}
+		user.UserId = session.UserId
+		if err := handlers.Service.Reauthenticate(r.Context(), user); err != nil {
+			return err
+		}
+		handlers.writeTokens(w, r, user, false)
 		return nil
 	}
 }
@@ -152,6 +145,12 @@ func (handlers *Handlers) GetTokenHandler() apierror.UserHandler {
 func (handlers *Handlers) LogOutHandler() apierror.UserHandler {
 	return func(w http.ResponseWriter, r *http.Request) error {
 		w.Header().Set("Content-type", "application/json")
+		// the access token stays valid until it expires, revoke it so introspection reports it as inactive
+		if cookie, err := r.Cookie("Access-token"); err == nil {
+			if err := handlers.Service.RevokeAccessToken(r.Context(), cookie.Value); err != nil {
+				return err
+			}
+		}
 		http.SetCookie(w, &http.Cookie{
 			Name:     "Refresh-token",
 			Value:    "",
@@ -167,7 +166,7 @@ func (handlers *Handlers) LogOutHandler() apierror.UserHandler {
 			MaxAge:   -1,
 			HttpOnly: true,
 			Secure:   handlers.Config.Secure,
-			SameSite: http.SameSiteStrictMode,
+			SameSite: http.SameSiteLaxMode,
 			Path:     "/",
 		})
 		w.WriteHeader(http.StatusOK)
@@ -184,17 +183,66 @@ func NewHandlers(service service.Service, config *config.Config, ApiError *apier
 	handlers.Router = mux.NewRouter()
 	handlers.Cors = cors.New(cors.Options{
 		AllowedOrigins: strings.Split(config.AllowedOrigins, ","),
-		AllowedHeaders: []string{"User-Agent", "Content-type"},
-		ExposedHeaders: []string{"X-Csrf-Token"},
+		AllowedHeaders: []string{"User-Agent", "Content-type", "Authorization", TokenDeliveryHeader, CsrfHeader, RequestIdHeader, ChallengeHeader, ChallengeSolutionHeader, CaptchaTokenHeader},
+		ExposedHeaders: []string{"X-Csrf-Token", RequestIdHeader},
 		AllowCredentials: config.AllowCredentials,
 		MaxAge:         5,
-		AllowedMethods: []string{"POST", "GET", "PUT", "DELETE", "OPTIONS"},
+		AllowedMethods: []string{"POST", "GET", "PUT", "PATCH", "DELETE", "OPTIONS"},
 	})
-	handlers.Router.Handle("/user", handlers.ApiError.ErrorMiddleWare(handlers.SignUpHandler())).Methods("POST").Schemes("http")
+	handlers.Router.Use(handlers.RequestMetaMiddleWare)
+	handlers.Router.Handle("/user", handlers.ApiError.ErrorMiddleWare(handlers.ChallengeMiddleWare(models.ChallengeSignUp, handlers.SignUpHandler()))).Methods("POST").Schemes("http")
+	handlers.Router.Handle("/.well-known/openid-configuration", handlers.ApiError.ErrorMiddleWare(handlers.DiscoveryHandler())).Methods("GET").Schemes("http")
+	handlers.Router.Handle("/.well-known/jwks.json", handlers.ApiError.ErrorMiddleWare(handlers.JwksHandler())).Methods("GET").Schemes("http")
+	oauth := handlers.Router.PathPrefix("/oauth").Subrouter()
+	oauth.Handle("/authorize", handlers.ApiError.ErrorMiddleWare(handlers.AuthorizeHandler())).Methods("GET", "POST").Schemes("http")
+	oauth.Handle("/token", handlers.ApiError.ErrorMiddleWare(handlers.TokenHandler())).Methods("POST").Schemes("http")
+	oauth.Handle("/device", handlers.ApiError.ErrorMiddleWare(handlers.DeviceAuthorizationHandler())).Methods("POST").Schemes("http")
+	oauth.Handle("/introspect", handlers.ApiError.ErrorMiddleWare(handlers.IntrospectHandler())).Methods("POST").Schemes("http")
+	oauth.Handle("/revoke", handlers.ApiError.ErrorMiddleWare(handlers.RevokeHandler())).Methods("POST").Schemes("http")
+	oauth.Handle("/userinfo", handlers.ApiError.ErrorMiddleWare(handlers.UserInfoHandler())).Methods("GET", "POST").Schemes("http")
+	admin := handlers.Router.PathPrefix("/admin").Subrouter()
+	admin.Handle("/roles", handlers.ApiError.ErrorMiddleWare(handlers.AdminMiddleWare(handlers.PermissionMiddleWare(models.PermissionRolesManage, handlers.GetRolesHandler())))).Methods("GET").Schemes("http")
+	admin.Handle("/roles/{role}", handlers.ApiError.ErrorMiddleWare(handlers.CsrfMiddleWare(handlers.AdminMiddleWare(handlers.PermissionMiddleWare(models.PermissionRolesManage, handlers.PutRoleHandler()))))).Methods("PUT").Schemes("http")
+	admin.Handle("/roles/{role}", handlers.ApiError.ErrorMiddleWare(handlers.CsrfMiddleWare(handlers.AdminMiddleWare(handlers.PermissionMiddleWare(models.PermissionRolesManage, handlers.DeleteRoleHandler()))))).Methods("DELETE").Schemes("http")
+	admin.Handle("/permissions", handlers.ApiError.ErrorMiddleWare(handlers.AdminMiddleWare(handlers.PermissionMiddleWare(models.PermissionRolesManage, handlers.GetPermissionsHandler())))).Methods("GET").Schemes("http")
+	admin.Handle("/permissions/{permission}", handlers.ApiError.ErrorMiddleWare(handlers.CsrfMiddleWare(handlers.AdminMiddleWare(handlers.PermissionMiddleWare(models.PermissionRolesManage, handlers.PutPermissionHandler()))))).Methods("PUT").Schemes("http")
+	admin.Handle("/permissions/{permission}", handlers.ApiError.ErrorMiddleWare(handlers.CsrfMiddleWare(handlers.AdminMiddleWare(handlers.PermissionMiddleWare(models.PermissionRolesManage, handlers.DeletePermissionHandler()))))).Methods("DELETE").Schemes("http")
+	admin.Handle("/users/{id}/roles", handlers.ApiError.ErrorMiddleWare(handlers.AdminMiddleWare(handlers.PermissionMiddleWare(models.PermissionRolesManage, handlers.GetUserRolesHandler())))).Methods("GET").Schemes("http")
+	admin.Handle("/users/{id}/roles/{role}", handlers.ApiError.ErrorMiddleWare(handlers.CsrfMiddleWare(handlers.AdminMiddleWare(handlers.PermissionMiddleWare(models.PermissionRolesManage, handlers.RecentAuthMiddleWare(handlers.GrantRoleHandler())))))).Methods("PUT").Schemes("http")
+	admin.Handle("/users/{id}/roles/{role}", handlers.ApiError.ErrorMiddleWare(handlers.CsrfMiddleWare(handlers.AdminMiddleWare(handlers.PermissionMiddleWare(models.PermissionRolesManage, handlers.RecentAuthMiddleWare(handlers.RevokeRoleHandler())))))).Methods("DELETE").Schemes("http")
+	admin.Handle("/users", handlers.ApiError.ErrorMiddleWare(handlers.AdminMiddleWare(handlers.SearchUsersHandler()))).Methods("GET").Schemes("http")
+	admin.Handle("/users/{id}", handlers.ApiError.ErrorMiddleWare(handlers.AdminMiddleWare(handlers.GetAdminUserHandler()))).Methods("GET").Schemes("http")
+	admin.Handle("/users/{id}", handlers.ApiError.ErrorMiddleWare(handlers.CsrfMiddleWare(handlers.AdminMiddleWare(handlers.RecentAuthMiddleWare(handlers.AdminActionHandler(handlers.Service.AdminDeleteUser)))))).Methods("DELETE").Schemes("http")
+	admin.Handle("/users/{id}/verify", handlers.ApiError.ErrorMiddleWare(handlers.CsrfMiddleWare(handlers.AdminMiddleWare(handlers.AdminActionHandler(handlers.Service.AdminVerifyUser))))).Methods("POST").Schemes("http")
+	admin.Handle("/users/{id}/suspend", handlers.ApiError.ErrorMiddleWare(handlers.CsrfMiddleWare(handlers.AdminMiddleWare(handlers.RecentAuthMiddleWare(handlers.AdminActionHandler(handlers.Service.SuspendUser)))))).Methods("POST").Schemes("http")
+	admin.Handle("/users/{id}/unsuspend", handlers.ApiError.ErrorMiddleWare(handlers.CsrfMiddleWare(handlers.AdminMiddleWare(handlers.AdminActionHandler(handlers.Service.UnsuspendUser))))).Methods("POST").Schemes("http")
+	admin.Handle("/users/{id}/lock", handlers.ApiError.ErrorMiddleWare(handlers.CsrfMiddleWare(handlers.AdminMiddleWare(handlers.RecentAuthMiddleWare(handlers.AdminActionHandler(handlers.Service.LockUser)))))).Methods("POST").Schemes("http")
+	admin.Handle("/users/{id}/unlock", handlers.ApiError.ErrorMiddleWare(handlers.CsrfMiddleWare(handlers.AdminMiddleWare(handlers.AdminActionHandler(handlers.Service.UnlockUser))))).Methods("POST").Schemes("http")
+	admin.Handle("/users/{id}/logout", handlers.ApiError.ErrorMiddleWare(handlers.CsrfMiddleWare(handlers.AdminMiddleWare(handlers.RecentAuthMiddleWare(handlers.AdminActionHandler(handlers.Service.ForceLogout)))))).Methods("POST").Schemes("http")
+	admin.Handle("/users/{id}/password-reset", handlers.ApiError.ErrorMiddleWare(handlers.CsrfMiddleWare(handlers.AdminMiddleWare(handlers.RecentAuthMiddleWare(handlers.AdminActionHandler(handlers.Service.TriggerPasswordReset)))))).Methods("POST").Schemes("http")
+	admin.Handle("/email-domains", handlers.ApiError.ErrorMiddleWare(handlers.AdminMiddleWare(handlers.GetEmailDomainRulesHandler()))).Methods("GET").Schemes("http")
+	admin.Handle("/email-domains/{domain}", handlers.ApiError.ErrorMiddleWare(handlers.CsrfMiddleWare(handlers.AdminMiddleWare(handlers.PutEmailDomainRuleHandler())))).Methods("PUT").Schemes("http")
+	admin.Handle("/email-domains/{domain}", handlers.ApiError.ErrorMiddleWare(handlers.CsrfMiddleWare(handlers.AdminMiddleWare(handlers.DeleteEmailDomainRuleHandler())))).Methods("DELETE").Schemes("http")
+	admin.Handle("/audit", handlers.ApiError.ErrorMiddleWare(handlers.AdminMiddleWare(handlers.GetAuditEventsHandler()))).Methods("GET").Schemes("http")
 	user := handlers.Router.PathPrefix("/user").Subrouter()
-	user.Handle("/auth", handlers.ApiError.ErrorMiddleWare(handlers.SignInHandler())).Methods("POST").Schemes("http")
-	user.Handle("/token", handlers.ApiError.ErrorMiddleWare(handlers.GetTokenHandler())).Methods("GET").Schemes("http")
+	user.Handle("/auth", handlers.ApiError.ErrorMiddleWare(handlers.ChallengeMiddleWare(models.ChallengeSignIn, handlers.SignInHandler()))).Methods("POST").Schemes("http")
+	user.Handle("/challenge", handlers.ApiError.ErrorMiddleWare(handlers.GetChallengeHandler())).Methods("GET").Schemes("http")
+	user.Handle("/token", handlers.ApiError.ErrorMiddleWare(handlers.GetTokenHandler())).Methods("GET", "POST").Schemes("http")
 	user.Handle("/verify/{code:.{16}}", handlers.ApiError.ErrorMiddleWare(handlers.VerifyHandler())).Methods("POST").Schemes("http")
-	user.Handle("/logout", handlers.ApiError.ErrorMiddleWare(handlers.LogOutHandler())).Methods("GET").Schemes("http")
+	user.Handle("/social/{provider}", handlers.ApiError.ErrorMiddleWare(handlers.SocialLoginHandler())).Methods("GET").Schemes("http")
+	user.Handle("/social/{provider}/callback", handlers.ApiError.ErrorMiddleWare(handlers.SocialCallbackHandler())).Methods("GET").Schemes("http")
+	user.Handle("/reauth", handlers.ApiError.ErrorMiddleWare(handlers.CsrfMiddleWare(handlers.AuthMiddleWare(handlers.ReauthHandler())))).Methods("POST").Schemes("http")
+	user.Handle("/tokens", handlers.ApiError.ErrorMiddleWare(handlers.CsrfMiddleWare(handlers.AuthMiddleWare(handlers.RecentAuthMiddleWare(handlers.CreatePersonalAccessTokenHandler()))))).Methods("POST").Schemes("http")
+	user.Handle("/tokens", handlers.ApiError.ErrorMiddleWare(handlers.AuthMiddleWare(handlers.GetPersonalAccessTokensHandler()))).Methods("GET").Schemes("http")
+	user.Handle("/tokens/{id}", handlers.ApiError.ErrorMiddleWare(handlers.CsrfMiddleWare(handlers.AuthMiddleWare(handlers.DeletePersonalAccessTokenHandler())))).Methods("DELETE").Schemes("http")
+	user.Handle("/device", handlers.ApiError.ErrorMiddleWare(handlers.AuthMiddleWare(handlers.GetDevicePromptHandler()))).Methods("GET").Schemes("http")
+	user.Handle("/device", handlers.ApiError.ErrorMiddleWare(handlers.CsrfMiddleWare(handlers.AuthMiddleWare(handlers.RecentAuthMiddleWare(handlers.ApproveDeviceHandler()))))).Methods("POST").Schemes("http")
+	user.Handle("/me", handlers.ApiError.ErrorMiddleWare(handlers.AuthMiddleWare(handlers.GetProfileHandler()))).Methods("GET").Schemes("http")
+	user.Handle("/me", handlers.ApiError.ErrorMiddleWare(handlers.CsrfMiddleWare(handlers.AuthMiddleWare(handlers.UpdateProfileHandler())))).Methods("PATCH").Schemes("http")
+	user.Handle("/name-available", handlers.ApiError.ErrorMiddleWare(handlers.UserNameAvailableHandler())).Methods("GET").Schemes("http")
+	user.Handle("/activity", handlers.ApiError.ErrorMiddleWare(handlers.AuthMiddleWare(handlers.GetUserActivityHandler()))).Methods("GET").Schemes("http")
+	user.Handle("/password-reset", handlers.ApiError.ErrorMiddleWare(handlers.ResetPasswordHandler())).Methods("POST").Schemes("http")
+	user.Handle("/secure-account", handlers.ApiError.ErrorMiddleWare(handlers.SecureAccountHandler())).Methods("POST").Schemes("http")
+	user.Handle("/logout", handlers.ApiError.ErrorMiddleWare(handlers.CsrfMiddleWare(handlers.LogOutHandler()))).Methods("POST").Schemes("http")
 	return handlers
 }
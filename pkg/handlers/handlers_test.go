@@ -2,17 +2,21 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	config "github.com/Kin-dza-dzaa/userApi/configs"
 	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
 	"github.com/Kin-dza-dzaa/userApi/internal/dto"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
 	"github.com/Kin-dza-dzaa/userApi/pkg/mocks"
+	"github.com/google/uuid"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 type HandlersSuite struct {
@@ -30,7 +34,7 @@ func (suite *HandlersSuite) SetupSuite() {
 	}
 	suite.config = config
 	suite.service = mocks.NewService(suite.T())
-	ApiError := apierror.NewApiError(&logger)
+	ApiError := apierror.NewApiError(&logger, config)
 	suite.handlers = NewHandlers(suite.service, config, ApiError)
 }
 
@@ -65,6 +69,10 @@ func (suite *HandlersSuite) TestSignUpHandler() {
 				Message: dto.ErrInvalidCredentials.Error(),
 				Result:  "error",
 				Code:    400,
+				ErrorCode: apierror.CodeValidationFailed,
+				Errors: []apierror.FieldError{
+					{Field: "user_name", Code: dto.CodeRequired, Min: dto.MinUserNameLength},
+				},
 			},
 			expectedErr: true,
 		},
@@ -79,6 +87,10 @@ func (suite *HandlersSuite) TestSignUpHandler() {
 				Message: dto.ErrInvalidCredentials.Error(),
 				Result:  "error",
 				Code:    400,
+				ErrorCode: apierror.CodeValidationFailed,
+				Errors: []apierror.FieldError{
+					{Field: "password", Code: dto.CodeRequired, Min: dto.MinPasswordLength},
+				},
 			},
 			expectedErr: true,
 		},
@@ -89,6 +101,12 @@ func (suite *HandlersSuite) TestSignUpHandler() {
 				Message: dto.ErrInvalidCredentials.Error(),
 				Result:  "error",
 				Code:    400,
+				ErrorCode: apierror.CodeValidationFailed,
+				Errors: []apierror.FieldError{
+					{Field: "user_name", Code: dto.CodeRequired, Min: dto.MinUserNameLength},
+					{Field: "email", Code: dto.CodeRequired},
+					{Field: "password", Code: dto.CodeRequired, Min: dto.MinPasswordLength},
+				},
 			},
 			expectedErr: true,
 		},
@@ -147,6 +165,10 @@ func (suite *HandlersSuite) TestSignInHandler() {
 				Result:  "error",
 				Message: dto.ErrInvalidCredentials.Error(),
 				Code:    400,
+				ErrorCode: apierror.CodeValidationFailed,
+				Errors: []apierror.FieldError{
+					{Field: "password", Code: dto.CodeRequired, Min: dto.MinPasswordLength},
+				},
 			},
 			expectedErr: true,
 		},
@@ -160,6 +182,10 @@ func (suite *HandlersSuite) TestSignInHandler() {
 				Result:  "error",
 				Message: dto.ErrInvalidCredentials.Error(),
 				Code:    400,
+				ErrorCode: apierror.CodeValidationFailed,
+				Errors: []apierror.FieldError{
+					{Field: "email", Code: dto.CodeRequired},
+				},
 			},
 			expectedErr: true,
 		},
@@ -170,6 +196,11 @@ func (suite *HandlersSuite) TestSignInHandler() {
 				Result:  "error",
 				Message: dto.ErrInvalidCredentials.Error(),
 				Code:    400,
+				ErrorCode: apierror.CodeValidationFailed,
+				Errors: []apierror.FieldError{
+					{Field: "email", Code: dto.CodeRequired},
+					{Field: "password", Code: dto.CodeRequired, Min: dto.MinPasswordLength},
+				},
 			},
 			expectedErr: true,
 		},
@@ -202,12 +233,18 @@ func (suite *HandlersSuite) TestSignInHandler() {
 func (suite *HandlersSuite) TestGetTokenHandler() {
 	testCases := []struct {
 		name        string
+		accessToken string
 		expectedErr bool
 	}{
 		{
 			name: "refresh_token_present",
 			expectedErr: false,
 		},
+		{
+			name: "access_token_present",
+			accessToken: "previous",
+			expectedErr: false,
+		},
 		{
 			name: "refresh_token_not_present",
 			expectedErr: true,
@@ -221,7 +258,13 @@ func (suite *HandlersSuite) TestGetTokenHandler() {
 				r.AddCookie(&http.Cookie{
 					Name: "Refresh-token",
 				})
-				suite.service.Mock.On("GetAccessToken", mock.Anything, mock.Anything).Return(nil).Once()
+				if tc.accessToken != "" {
+					r.AddCookie(&http.Cookie{Name: "Access-token", Value: tc.accessToken})
+				}
+				// the access token the device had goes along with the refresh token, it carries a re-authentication forward
+				suite.service.Mock.On("GetAccessToken", mock.Anything, mock.MatchedBy(func(user *models.User) bool {
+					return user.PreviousJwt == tc.accessToken
+				})).Return(nil).Once()
 			}
 			err := suite.handlers.GetTokenHandler()(w, r)
 			if tc.expectedErr {
@@ -233,6 +276,139 @@ func (suite *HandlersSuite) TestGetTokenHandler() {
 	}
 }
 
+func (suite *HandlersSuite) TestBodyTokenDelivery() {
+	testCases := []struct {
+		name     string
+		request  func() *http.Request
+		handler  func() apierror.UserHandler
+		method   string
+		withRefresh bool
+	}{
+		{
+			name: "sign_in_header",
+			request: func() *http.Request {
+				r := httptest.NewRequest("POST", "/user/auth", bytes.NewReader([]byte(`{"email":"testEmail@gmail.com","password":"12345Qwerty"}`)))
+				r.Header.Set(TokenDeliveryHeader, TokenDeliveryBody)
+				return r
+			},
+			handler: suite.handlers.SignInHandler,
+			method: "SignInUser",
+			withRefresh: true,
+		},
+		{
+			name: "token_query",
+			request: func() *http.Request {
+				return httptest.NewRequest("POST", "/user/token?token_delivery=body", bytes.NewReader([]byte(`{"refresh_token":"token"}`)))
+			},
+			handler: suite.handlers.GetTokenHandler,
+			method: "GetAccessToken",
+			withRefresh: false,
+		},
+	}
+	for _, tc := range testCases {
+		suite.T().Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			suite.service.On(tc.method, mock.Anything, mock.Anything).Return(nil).Once()
+			err := tc.handler()(w, tc.request())
+			suite.Nil(err)
+			var response map[string]interface{}
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.FailNow()
+			}
+			suite.Empty(w.Result().Cookies())
+			suite.Equal("Bearer", response["token_type"])
+			suite.Equal(suite.config.AccessTokenTtl().Seconds(), response["expires_in"])
+			suite.Contains(response, "access_token")
+			_, ok := response["refresh_token"]
+			suite.Equal(tc.withRefresh, ok)
+		})
+	}
+}
+
+func (suite *HandlersSuite) TestRememberMeCookie() {
+	testCases := []struct {
+		name       string
+		body       string
+		persistent bool
+	}{
+		{
+			name:       "session_cookie",
+			body:       `{"email":"testEmail@gmail.com","password":"12345Qwerty"}`,
+			persistent: false,
+		},
+		{
+			name:       "remember_me",
+			body:       `{"email":"testEmail@gmail.com","password":"12345Qwerty","remember_me":true}`,
+			persistent: true,
+		},
+	}
+	for _, tc := range testCases {
+		suite.T().Run(tc.name, func(t *testing.T) {
+			startedAt := time.Now().UTC().Truncate(time.Second)
+			suite.service.On("SignInUser", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				args.Get(1).(*models.User).SessionStartedAt = startedAt
+			}).Return(nil).Once()
+			w := httptest.NewRecorder()
+			err := suite.handlers.SignInHandler()(w, httptest.NewRequest("POST", "/user/auth", bytes.NewReader([]byte(tc.body))))
+			suite.Nil(err)
+			for _, cookie := range w.Result().Cookies() {
+				switch cookie.Name {
+				case "Refresh-token":
+					if tc.persistent {
+						suite.Equal(startedAt.Add(suite.config.SessionMaxLifetime()), cookie.Expires)
+					} else {
+						suite.True(cookie.Expires.IsZero())
+						suite.Zero(cookie.MaxAge)
+					}
+				case "Access-token":
+					suite.Equal(int(suite.config.AccessTokenTtl().Seconds()), cookie.MaxAge)
+					suite.Equal(http.SameSiteLaxMode, cookie.SameSite)
+				}
+			}
+		})
+	}
+}
+
+func (suite *HandlersSuite) TestReauthHandler() {
+	userId := uuid.New()
+	testCases := []struct {
+		name    string
+		body    string
+		errCode string
+	}{
+		{
+			name: "password",
+			body: `{"password":"12345Qwerty"}`,
+		},
+		{
+			name:    "no_password",
+			body:    `{}`,
+			errCode: apierror.CodeValidationFailed,
+		},
+	}
+	for _, tc := range testCases {
+		suite.T().Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/user/reauth", bytes.NewReader([]byte(tc.body)))
+			r = r.WithContext(context.WithValue(r.Context(), claimsContextKey, &models.MyJwtClaims{UserId: userId.String()}))
+			w := httptest.NewRecorder()
+			if tc.errCode == "" {
+				suite.service.On("Reauthenticate", mock.Anything, mock.MatchedBy(func(user *models.User) bool {
+					return user.UserId == userId && user.Password == "12345Qwerty"
+				})).Return(nil).Once()
+			}
+			err := suite.handlers.ReauthHandler()(w, r)
+			if tc.errCode != "" {
+				suite.Equal(tc.errCode, err.(*apierror.ErrorStruct).ErrorCode)
+				return
+			}
+			suite.Nil(err)
+			for _, cookie := range w.Result().Cookies() {
+				suite.NotEqual("Refresh-token", cookie.Name)
+			}
+		})
+	}
+}
+
 func (suite *HandlersSuite) TestLogOutHandler() {
 	testCases := []struct {
 		name    string
@@ -252,13 +428,13 @@ func (suite *HandlersSuite) TestLogOutHandler() {
 					Path:     "/",
 				},
 				{
-					Raw:      "Access-token=; Path=/; Max-Age=0; HttpOnly; SameSite=Strict",
+					Raw:      "Access-token=; Path=/; Max-Age=0; HttpOnly; SameSite=Lax",
 					Name:     "Access-token",
 					Value:    "",
 					MaxAge:   -1,
 					HttpOnly: true,
 					Secure:   suite.config.Secure,
-					SameSite: http.SameSiteStrictMode,
+					SameSite: http.SameSiteLaxMode,
 					Path:     "/",
 				},
 			},
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/Kin-dza-dzaa/userApi/pkg/service"
+	"github.com/google/uuid"
+)
+
+const (
+	CsrfHeader      = "X-CSRF-Token"
+	RequestIdHeader = "X-Request-Id"
+	maxUserAgent    = 512
+)
+
+// request ids from clients are kept if they can't mess up logs
+var requestIdPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+type contextKey int
+
+const claimsContextKey contextKey = iota
+
+var (
+	ErrCredentialsNotPresent        = errors.New("bearer token or access token cookie not present")
+	ErrPermissionDenied             = errors.New("the access token doesn't grant the required permission")
+	ErrRoleRequired                 = errors.New("the access token doesn't have the required role")
+	ErrReauthRequired               = errors.New("the user has to confirm their password again")
+	ErrClientToken                  = errors.New("tokens issued to an OAuth client can't be used on this endpoint")
+	ErrSessionTokenRequired         = errors.New("the endpoint requires a session token issued for this service")
+	ErrPersonalAccessTokenForbidden = errors.New("personal access tokens can't be used on this endpoint")
+)
+
+// CsrfMiddleWare implements double-submit validation: on state-changing requests authenticated by
+// the Access-token cookie the X-CSRF-Token header must match the x_csrf_token claim of that cookie.
+func (handlers *Handlers) CsrfMiddleWare(next apierror.UserHandler) apierror.UserHandler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			return next(w, r)
+		}
+		cookie, err := r.Cookie("Access-token")
+		if err != nil {
+			// no ambient credentials, nothing to forge
+			return next(w, r)
+		}
+		claims, err := handlers.Service.ParseAccessToken(r.Context(), cookie.Value)
+		if err != nil {
+			return err
+		}
+		header := r.Header.Get(CsrfHeader)
+		if header == "" || subtle.ConstantTimeCompare([]byte(header), []byte(claims.XCSRFToken)) != 1 {
+			return apierror.NewCatalogError(apierror.CodeCsrfTokenMismatch, ErrCsrfTokenMismatch.Error())
+		}
+		return next(w, r)
+	}
+}
+
+// AuthMiddleWare authenticates the request by a bearer access token or by the Access-token cookie, next gets the claims
+// through claimsFromContext. Every route behind it is first-party, so tokens issued to an OAuth client or a service account
+// (those carrying client_id) are rejected, and so are personal access tokens: they are meant for resource servers,
+// which check them with /oauth/introspect, and their scopes say nothing about the profile, tokens or security log of the user.
+func (handlers *Handlers) AuthMiddleWare(next apierror.UserHandler) apierror.UserHandler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var claims *models.MyJwtClaims
+		var err error
+		token := bearerToken(r)
+		// a personal access token is refused before it is looked up, so it isn't marked as used
+		if strings.HasPrefix(token, service.PersonalAccessTokenPrefix) {
+			return apierror.NewCatalogError(apierror.CodePersonalAccessTokenForbidden, ErrPersonalAccessTokenForbidden.Error())
+		}
+		if token != "" {
+			claims, err = handlers.Service.Authenticate(r.Context(), token)
+		} else if cookie, cookieErr := r.Cookie("Access-token"); cookieErr == nil {
+			claims, err = handlers.Service.ParseAccessToken(r.Context(), cookie.Value)
+		} else {
+			return apierror.NewCatalogError(apierror.CodeAccessTokenInvalid, ErrCredentialsNotPresent.Error())
+		}
+		if err != nil {
+			return err
+		}
+		if claims.ClientId != "" {
+			return apierror.NewCatalogError(apierror.CodePermissionDenied, ErrClientToken.Error())
+		}
+		ctx := context.WithValue(r.Context(), claimsContextKey, claims)
+		if userId, err := uuid.Parse(claims.UserId); err == nil {
+			meta := *models.RequestMetaFromContext(ctx)
+			meta.ActorId = &userId
+			ctx = models.WithRequestMeta(ctx, &meta)
+		}
+		return next(w, r.WithContext(ctx))
+	}
+}
+
+// RequestMetaMiddleWare keeps the client IP, user agent and request id for the audit log, the request id is echoed
+// in the response. The IP is the peer address unless TrustProxyHeaders is set.
+func (handlers *Handlers) RequestMetaMiddleWare(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		meta := &models.RequestMeta{Ip: handlers.clientIp(r), UserAgent: r.UserAgent(), RequestId: r.Header.Get(RequestIdHeader)}
+		if len(meta.UserAgent) > maxUserAgent {
+			meta.UserAgent = meta.UserAgent[:maxUserAgent]
+		}
+		if !requestIdPattern.MatchString(meta.RequestId) {
+			meta.RequestId = uuid.NewString()
+		}
+		w.Header().Set(RequestIdHeader, meta.RequestId)
+		next.ServeHTTP(w, r.WithContext(models.WithRequestMeta(r.Context(), meta)))
+	})
+}
+
+func (handlers *Handlers) clientIp(r *http.Request) string {
+	if handlers.Config.TrustProxyHeaders {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			first, _, _ := strings.Cut(forwarded, ",")
+			return strings.TrimSpace(first)
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func claimsFromContext(ctx context.Context) *models.MyJwtClaims {
+	claims, _ := ctx.Value(claimsContextKey).(*models.MyJwtClaims)
+	return claims
+}
+
+// RecentAuthMiddleWare must wrap a handler already wrapped by AuthMiddleWare, it guards sensitive operations by rejecting
+// tokens whose auth_time is older than ReauthMaxAge. The client asks for the password, calls POST /user/reauth and retries.
+func (handlers *Handlers) RecentAuthMiddleWare(next apierror.UserHandler) apierror.UserHandler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		if err := handlers.requireRecentAuth(r); err != nil {
+			return err
+		}
+		return next(w, r)
+	}
+}
+
+// requireRecentAuth is RecentAuthMiddleWare for handlers where only some changes are sensitive.
+func (handlers *Handlers) requireRecentAuth(r *http.Request) error {
+	claims := claimsFromContext(r.Context())
+	if claims == nil || !claims.AuthenticatedWithin(handlers.Config.ReauthMaxAge(), time.Now()) {
+		return apierror.NewCatalogError(apierror.CodeReauthenticationRequired, ErrReauthRequired.Error())
+	}
+	return nil
+}
+
+// PermissionMiddleWare must wrap a handler already wrapped by AuthMiddleWare, it rejects tokens without permission.
+func (handlers *Handlers) PermissionMiddleWare(permission string, next apierror.UserHandler) apierror.UserHandler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		claims := claimsFromContext(r.Context())
+		if claims == nil || !claims.HasPermission(permission) {
+			return apierror.NewCatalogError(apierror.CodePermissionDenied, ErrPermissionDenied.Error())
+		}
+		return next(w, r)
+	}
+}
+
+// RoleMiddleWare must wrap a handler already wrapped by AuthMiddleWare, it rejects tokens without role.
+func (handlers *Handlers) RoleMiddleWare(role string, next apierror.UserHandler) apierror.UserHandler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		claims := claimsFromContext(r.Context())
+		if claims == nil || !claims.HasRole(role) {
+			return apierror.NewCatalogError(apierror.CodePermissionDenied, ErrRoleRequired.Error())
+		}
+		return next(w, r)
+	}
+}
+
+// SessionTokenMiddleWare must wrap a handler already wrapped by AuthMiddleWare, it rejects every token but first-party
+// session tokens: no client_id and aud of this service. Personal access tokens carry no aud.
+func (handlers *Handlers) SessionTokenMiddleWare(next apierror.UserHandler) apierror.UserHandler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		claims := claimsFromContext(r.Context())
+		if claims == nil || claims.ClientId != "" || claims.Audience != handlers.Config.Audience() {
+			return apierror.NewCatalogError(apierror.CodePermissionDenied, ErrSessionTokenRequired.Error())
+		}
+		return next(w, r)
+	}
+}
+
+// AdminMiddleWare protects every /admin endpoint, the admin role is trusted only in a session token.
+func (handlers *Handlers) AdminMiddleWare(next apierror.UserHandler) apierror.UserHandler {
+	return handlers.AuthMiddleWare(handlers.SessionTokenMiddleWare(handlers.RoleMiddleWare(models.RoleAdmin, next)))
+}
@@ -0,0 +1,315 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	config "github.com/Kin-dza-dzaa/userApi/configs"
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/Kin-dza-dzaa/userApi/pkg/mocks"
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCsrfMiddleWare(t *testing.T) {
+	testCases := []struct {
+		name       string
+		method     string
+		cookie     bool
+		header     string
+		nextCalled bool
+	}{
+		{
+			name:       "safe_method",
+			method:     "GET",
+			cookie:     true,
+			nextCalled: true,
+		},
+		{
+			name:       "no_cookie",
+			method:     "POST",
+			nextCalled: true,
+		},
+		{
+			name:       "matching_header",
+			method:     "POST",
+			cookie:     true,
+			header:     "csrf",
+			nextCalled: true,
+		},
+		{
+			name:       "missing_header",
+			method:     "POST",
+			cookie:     true,
+			nextCalled: false,
+		},
+		{
+			name:       "wrong_header",
+			method:     "DELETE",
+			cookie:     true,
+			header:     "forged",
+			nextCalled: false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			service := mocks.NewService(t)
+			handlers := &Handlers{Service: service}
+			r := httptest.NewRequest(tc.method, "/user/logout", nil)
+			if tc.cookie {
+				r.AddCookie(&http.Cookie{Name: "Access-token", Value: "jwt"})
+				if tc.method != "GET" {
+					service.On("ParseAccessToken", mock.Anything, "jwt").Return(&models.MyJwtClaims{XCSRFToken: "csrf"}, nil).Once()
+				}
+			}
+			if tc.header != "" {
+				r.Header.Set(CsrfHeader, tc.header)
+			}
+			nextCalled := false
+			err := handlers.CsrfMiddleWare(func(w http.ResponseWriter, r *http.Request) error {
+				nextCalled = true
+				return nil
+			})(httptest.NewRecorder(), r)
+			if tc.nextCalled != nextCalled {
+				t.FailNow()
+			}
+			if !tc.nextCalled {
+				Err, ok := err.(*apierror.ErrorStruct)
+				if !ok || Err.Code != http.StatusForbidden {
+					t.FailNow()
+				}
+			}
+		})
+	}
+}
+
+func TestAuthMiddleWareRejectsClientTokens(t *testing.T) {
+	testCases := []struct {
+		name       string
+		claims     *models.MyJwtClaims
+		nextCalled bool
+	}{
+		{
+			name:       "session",
+			claims:     &models.MyJwtClaims{UserId: "8f14e45f-ceea-467f-a8f4-0d3f2c1b6a11"},
+			nextCalled: true,
+		},
+		{
+			name:       "delegated_token",
+			claims:     &models.MyJwtClaims{UserId: "8f14e45f-ceea-467f-a8f4-0d3f2c1b6a11", ClientId: "cli", Scope: "openid profile"},
+			nextCalled: false,
+		},
+		{
+			name:       "service_account",
+			claims:     &models.MyJwtClaims{ClientId: "cli", Scope: "words:read"},
+			nextCalled: false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			service := mocks.NewService(t)
+			handlers := &Handlers{Service: service, Config: &config.Config{}}
+			service.On("Authenticate", mock.Anything, "jwt").Return(tc.claims, nil).Once()
+			r := httptest.NewRequest("GET", "/user/me", nil)
+			r.Header.Set("Authorization", "Bearer jwt")
+			nextCalled := false
+			err := handlers.AuthMiddleWare(func(w http.ResponseWriter, r *http.Request) error {
+				nextCalled = true
+				return nil
+			})(httptest.NewRecorder(), r)
+			if tc.nextCalled != nextCalled {
+				t.FailNow()
+			}
+			if !tc.nextCalled {
+				Err, ok := err.(*apierror.ErrorStruct)
+				if !ok || Err.ErrorCode != apierror.CodePermissionDenied {
+					t.FailNow()
+				}
+			}
+		})
+	}
+}
+
+func TestAuthMiddleWareRejectsPersonalAccessTokens(t *testing.T) {
+	testCases := []struct {
+		name    string
+		method  string
+		target  string
+		handler func(handlers *Handlers) apierror.UserHandler
+	}{
+		{name: "get_profile", method: "GET", target: "/user/me", handler: (*Handlers).GetProfileHandler},
+		{name: "update_profile", method: "PATCH", target: "/user/me", handler: (*Handlers).UpdateProfileHandler},
+		{name: "user_activity", method: "GET", target: "/user/activity", handler: (*Handlers).GetUserActivityHandler},
+		{name: "delete_token", method: "DELETE", target: "/user/tokens/8f14e45f-ceea-467f-a8f4-0d3f2c1b6a12", handler: (*Handlers).DeletePersonalAccessTokenHandler},
+		{name: "device_prompt", method: "GET", target: "/user/device", handler: (*Handlers).GetDevicePromptHandler},
+		{name: "reauth", method: "POST", target: "/user/reauth", handler: (*Handlers).ReauthHandler},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			service := mocks.NewService(t)
+			handlers := &Handlers{Service: service, Config: &config.Config{}}
+			r := httptest.NewRequest(tc.method, tc.target, nil)
+			r.Header.Set("Authorization", "Bearer pat_secret")
+			err := handlers.AuthMiddleWare(tc.handler(handlers))(httptest.NewRecorder(), r)
+			Err, ok := err.(*apierror.ErrorStruct)
+			if !ok || Err.ErrorCode != apierror.CodePersonalAccessTokenForbidden {
+				t.FailNow()
+			}
+			// the token isn't looked up, a refused call doesn't update last_used_at
+			service.AssertNotCalled(t, "Authenticate", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestRecentAuthMiddleWare(t *testing.T) {
+	testCases := []struct {
+		name       string
+		claims     *models.MyJwtClaims
+		nextCalled bool
+	}{
+		{
+			name:       "recent",
+			claims:     &models.MyJwtClaims{AuthTime: time.Now().Add(-time.Minute).Unix()},
+			nextCalled: true,
+		},
+		{
+			name:       "stale",
+			claims:     &models.MyJwtClaims{AuthTime: time.Now().Add(-time.Hour).Unix()},
+			nextCalled: false,
+		},
+		{
+			name:       "no_auth_time",
+			claims:     &models.MyJwtClaims{},
+			nextCalled: false,
+		},
+		{
+			name:       "no_claims",
+			nextCalled: false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			handlers := &Handlers{Config: &config.Config{ReauthMaxAgeSeconds: 600}}
+			r := httptest.NewRequest("POST", "/user/tokens", nil)
+			if tc.claims != nil {
+				r = r.WithContext(context.WithValue(r.Context(), claimsContextKey, tc.claims))
+			}
+			nextCalled := false
+			err := handlers.RecentAuthMiddleWare(func(w http.ResponseWriter, r *http.Request) error {
+				nextCalled = true
+				return nil
+			})(httptest.NewRecorder(), r)
+			if tc.nextCalled != nextCalled {
+				t.FailNow()
+			}
+			if !tc.nextCalled {
+				Err, ok := err.(*apierror.ErrorStruct)
+				if !ok || Err.ErrorCode != apierror.CodeReauthenticationRequired {
+					t.FailNow()
+				}
+			}
+		})
+	}
+}
+
+func TestAdminMiddleWare(t *testing.T) {
+	testCases := []struct {
+		name       string
+		claims     *models.MyJwtClaims
+		nextCalled bool
+	}{
+		{
+			name:       "admin_session",
+			claims:     &models.MyJwtClaims{Roles: []string{models.RoleAdmin}, StandardClaims: jwt.StandardClaims{Audience: "https://id.example.com"}},
+			nextCalled: true,
+		},
+		{
+			name:       "delegated_token",
+			claims:     &models.MyJwtClaims{ClientId: "cli", Scope: "openid", StandardClaims: jwt.StandardClaims{Audience: "https://id.example.com"}},
+			nextCalled: false,
+		},
+		{
+			name:       "delegated_token_with_admin_role",
+			claims:     &models.MyJwtClaims{ClientId: "cli", Roles: []string{models.RoleAdmin}, StandardClaims: jwt.StandardClaims{Audience: "https://id.example.com"}},
+			nextCalled: false,
+		},
+		{
+			name:       "personal_access_token",
+			claims:     &models.MyJwtClaims{Roles: []string{models.RoleAdmin}},
+			nextCalled: false,
+		},
+		{
+			name:       "foreign_audience",
+			claims:     &models.MyJwtClaims{Roles: []string{models.RoleAdmin}, StandardClaims: jwt.StandardClaims{Audience: "https://words.example.com"}},
+			nextCalled: false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			service := mocks.NewService(t)
+			handlers := &Handlers{Service: service, Config: &config.Config{Issuer: "https://id.example.com"}}
+			service.On("Authenticate", mock.Anything, "jwt").Return(tc.claims, nil).Once()
+			r := httptest.NewRequest("DELETE", "/admin/users/1", nil)
+			r.Header.Set("Authorization", "Bearer jwt")
+			nextCalled := false
+			err := handlers.AdminMiddleWare(func(w http.ResponseWriter, r *http.Request) error {
+				nextCalled = true
+				return nil
+			})(httptest.NewRecorder(), r)
+			if tc.nextCalled != nextCalled {
+				t.FailNow()
+			}
+			if !tc.nextCalled {
+				Err, ok := err.(*apierror.ErrorStruct)
+				if !ok || Err.ErrorCode != apierror.CodePermissionDenied {
+					t.FailNow()
+				}
+			}
+		})
+	}
+}
+
+func TestRequestMetaMiddleWare(t *testing.T) {
+	testCases := []struct {
+		name         string
+		trustProxy   bool
+		requestId    string
+		ip           string
+		keepsRequest bool
+	}{
+		{
+			name:         "peer_address",
+			requestId:    "req-1",
+			ip:           "192.0.2.1",
+			keepsRequest: true,
+		},
+		{
+			name:       "trusted_proxy",
+			trustProxy: true,
+			requestId:  "bad id\n",
+			ip:         "203.0.113.7",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			handlers := &Handlers{Config: &config.Config{TrustProxyHeaders: tc.trustProxy}}
+			r := httptest.NewRequest("GET", "/user/activity", nil)
+			r.RemoteAddr = "192.0.2.1:4242"
+			r.Header.Set("X-Forwarded-For", "203.0.113.7, 192.0.2.1")
+			r.Header.Set(RequestIdHeader, tc.requestId)
+			r.Header.Set("User-Agent", "curl")
+			var meta *models.RequestMeta
+			w := httptest.NewRecorder()
+			handlers.RequestMetaMiddleWare(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				meta = models.RequestMetaFromContext(r.Context())
+			})).ServeHTTP(w, r)
+			if meta.Ip != tc.ip || meta.UserAgent != "curl" || w.Header().Get(RequestIdHeader) != meta.RequestId || (meta.RequestId == tc.requestId) != tc.keepsRequest {
+				t.Fatalf("unexpected request meta %+v", meta)
+			}
+		})
+	}
+}
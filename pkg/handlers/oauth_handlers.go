@@ -0,0 +1,358 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/dto"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/Kin-dza-dzaa/userApi/pkg/service"
+	"github.com/dchest/uniuri"
+	"github.com/google/uuid"
+)
+
+// LoginCsrfCookie holds the login CSRF token of the /oauth/authorize login form
+const LoginCsrfCookie = "Login-csrf"
+
+var (
+	ErrMalformedForm         = errors.New("request form is malformed")
+	ErrBearerTokenNotPresent = errors.New("bearer token not present")
+)
+
+// authorizationParams are carried through the login and consent forms.
+var authorizationParams = []string{"response_type", "client_id", "redirect_uri", "scope", "state", "nonce", "code_challenge", "code_challenge_method"}
+
+func (handlers *Handlers) DiscoveryHandler() apierror.UserHandler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-type", "application/json")
+		issuer := handlers.Config.Issuer
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"issuer":                                           issuer,
+			"authorization_endpoint":                           issuer + "/oauth/authorize",
+			"token_endpoint":                                   issuer + "/oauth/token",
+			"userinfo_endpoint":                                issuer + "/oauth/userinfo",
+			"introspection_endpoint":                           issuer + "/oauth/introspect",
+			"revocation_endpoint":                              issuer + "/oauth/revoke",
+			"device_authorization_endpoint":                    issuer + "/oauth/device",
+			"jwks_uri":                                         issuer + "/.well-known/jwks.json",
+			"response_types_supported":                         []string{"code"},
+			"grant_types_supported":                            []string{service.GrantTypeAuthorizationCode, service.GrantTypeRefreshToken, service.GrantTypeClientCredentials, service.GrantTypeDeviceCode},
+			"subject_types_supported":                          []string{"public"},
+			"id_token_signing_alg_values_supported":            []string{"RS256"},
+			"scopes_supported":                                 []string{service.ScopeOpenId, service.ScopeProfile, service.ScopeEmail},
+			"token_endpoint_auth_methods_supported":            []string{"client_secret_basic", "client_secret_post", "private_key_jwt", "none"},
+			"token_endpoint_auth_signing_alg_values_supported": []string{"RS256"},
+			"code_challenge_methods_supported":                 []string{"S256"},
+			"claims_supported":                                 []string{"sub", "iss", "aud", "exp", "iat", "auth_time", "nonce", "email", "email_verified", "preferred_username"},
+		})
+		return nil
+	}
+}
+
+func (handlers *Handlers) JwksHandler() apierror.UserHandler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-type", "application/json")
+		jwks, err := handlers.Service.Jwks(r.Context())
+		if err != nil {
+			return err
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(jwks)
+		return nil
+	}
+}
+
+// AuthorizeHandler serves GET and POST /oauth/authorize: it signs the user in with the same SignInUser logic as /user/auth,
+// asks for consent once per client and scope set and redirects back with an authorization code.
+func (handlers *Handlers) AuthorizeHandler() apierror.UserHandler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		if err := r.ParseForm(); err != nil {
+			return apierror.NewOAuthError(apierror.OAuthInvalidRequest, ErrMalformedForm.Error())
+		}
+		request := &models.AuthorizationRequest{
+			ResponseType:        r.Form.Get("response_type"),
+			ClientId:            r.Form.Get("client_id"),
+			RedirectUri:         r.Form.Get("redirect_uri"),
+			Scope:               r.Form.Get("scope"),
+			State:               r.Form.Get("state"),
+			Nonce:               r.Form.Get("nonce"),
+			CodeChallenge:       r.Form.Get("code_challenge"),
+			CodeChallengeMethod: r.Form.Get("code_challenge_method"),
+		}
+		client, err := handlers.Service.ValidateAuthorizationRequest(r.Context(), request)
+		if err != nil {
+			var oauthError *apierror.OAuthError
+			if client != nil && errors.As(err, &oauthError) {
+				redirectWithParams(w, r, request.RedirectUri, map[string]string{"error": oauthError.ErrorCode, "error_description": oauthError.Description, "state": request.State})
+				return nil
+			}
+			return err
+		}
+		params := make(map[string]string)
+		for _, param := range authorizationParams {
+			params[param] = r.Form.Get(param)
+		}
+
+		var userId uuid.UUID
+		var csrfToken string
+		var authTime time.Time
+		if cookie, err := r.Cookie("Access-token"); err == nil {
+			if claims, err := handlers.Service.ParseAccessToken(r.Context(), cookie.Value); err == nil {
+				if userId, err = uuid.Parse(claims.UserId); err == nil {
+					csrfToken = claims.XCSRFToken
+					if claims.AuthTime != 0 {
+						authTime = time.Unix(claims.AuthTime, 0).UTC()
+					} else if claims.IssuedAt != 0 {
+						authTime = time.Unix(claims.IssuedAt, 0).UTC()
+					}
+				}
+			}
+		}
+		if userId == uuid.Nil && r.Method == http.MethodPost && r.PostForm.Get("email") != "" {
+			user, err := handlers.loginFormUser(r)
+			if err == nil {
+				err = handlers.Service.SignInUser(r.Context(), user)
+			}
+			if err != nil {
+				var errorStruct *apierror.ErrorStruct
+				if errors.As(err, &errorStruct) {
+					return handlers.renderLogin(w, r, client, params, errorStruct.Message)
+				}
+				return err
+			}
+			handlers.writeSessionCookies(w, user, true)
+			userId, csrfToken, authTime = user.UserId, user.CsrfToken, time.Now().UTC()
+		}
+		if userId == uuid.Nil {
+			return handlers.renderLogin(w, r, client, params, "")
+		}
+
+		consent := &models.Consent{UserId: userId, ClientId: client.ClientId, Scopes: strings.Fields(request.Scope)}
+		consented, err := handlers.Service.HasConsent(r.Context(), consent)
+		if err != nil {
+			return err
+		}
+		if !consented {
+			decision := r.PostForm.Get("consent")
+			if decision == "" {
+				return handlers.renderPage(w, handlers.Config.OauthConsentTemplateLocation, map[string]interface{}{"Client": client.Name, "Scopes": consent.Scopes, "Params": params, "Csrf": csrfToken})
+			}
+			if subtle.ConstantTimeCompare([]byte(r.PostForm.Get("csrf")), []byte(csrfToken)) != 1 {
+				return apierror.NewCatalogError(apierror.CodeCsrfTokenMismatch, ErrCsrfTokenMismatch.Error())
+			}
+			if decision != "approve" {
+				redirectWithParams(w, r, request.RedirectUri, map[string]string{"error": apierror.OAuthAccessDenied, "state": request.State})
+				return nil
+			}
+			if err := handlers.Service.GrantConsent(r.Context(), consent); err != nil {
+				return err
+			}
+		}
+
+		code := &models.AuthorizationCode{
+			ClientId:      client.ClientId,
+			UserId:        userId,
+			RedirectUri:   request.RedirectUri,
+			Scope:         request.Scope,
+			Nonce:         request.Nonce,
+			CodeChallenge: request.CodeChallenge,
+			AuthTime:      authTime,
+		}
+		if err := handlers.Service.IssueAuthorizationCode(r.Context(), code); err != nil {
+			return err
+		}
+		redirectWithParams(w, r, request.RedirectUri, map[string]string{"code": code.Code, "state": request.State})
+		return nil
+	}
+}
+
+func (handlers *Handlers) TokenHandler() apierror.UserHandler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		if err := r.ParseForm(); err != nil {
+			return apierror.NewOAuthError(apierror.OAuthInvalidRequest, ErrMalformedForm.Error())
+		}
+		request := &models.TokenRequest{
+			GrantType:           r.PostForm.Get("grant_type"),
+			ClientId:            r.PostForm.Get("client_id"),
+			ClientSecret:        r.PostForm.Get("client_secret"),
+			ClientAssertionType: r.PostForm.Get("client_assertion_type"),
+			ClientAssertion:     r.PostForm.Get("client_assertion"),
+			Code:                r.PostForm.Get("code"),
+			RedirectUri:         r.PostForm.Get("redirect_uri"),
+			CodeVerifier:        r.PostForm.Get("code_verifier"),
+			RefreshToken:        r.PostForm.Get("refresh_token"),
+			DeviceCode:          r.PostForm.Get("device_code"),
+			Scope:               r.PostForm.Get("scope"),
+		}
+		request.ClientId, request.ClientSecret = clientCredentials(r, request.ClientId, request.ClientSecret)
+		response, err := handlers.Service.Token(r.Context(), request)
+		if err != nil {
+			return err
+		}
+		w.Header().Set("Content-type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("Pragma", "no-cache")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(response)
+		return nil
+	}
+}
+
+// IntrospectHandler serves RFC 7662 introspection for resource servers registered as confidential clients.
+func (handlers *Handlers) IntrospectHandler() apierror.UserHandler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		if err := r.ParseForm(); err != nil || r.PostForm.Get("token") == "" {
+			return apierror.NewOAuthError(apierror.OAuthInvalidRequest, ErrMalformedForm.Error())
+		}
+		request := &models.IntrospectionRequest{
+			ClientId:      r.PostForm.Get("client_id"),
+			ClientSecret:  r.PostForm.Get("client_secret"),
+			Token:         r.PostForm.Get("token"),
+			TokenTypeHint: r.PostForm.Get("token_type_hint"),
+		}
+		request.ClientId, request.ClientSecret = clientCredentials(r, request.ClientId, request.ClientSecret)
+		introspection, err := handlers.Service.IntrospectToken(r.Context(), request)
+		if err != nil {
+			return err
+		}
+		w.Header().Set("Content-type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(introspection)
+		return nil
+	}
+}
+
+// RevokeHandler serves RFC 7009 revocation, it responds with 200 for unknown tokens too.
+func (handlers *Handlers) RevokeHandler() apierror.UserHandler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		if err := r.ParseForm(); err != nil || r.PostForm.Get("token") == "" {
+			return apierror.NewOAuthError(apierror.OAuthInvalidRequest, ErrMalformedForm.Error())
+		}
+		request := &models.RevocationRequest{
+			ClientId:      r.PostForm.Get("client_id"),
+			ClientSecret:  r.PostForm.Get("client_secret"),
+			Token:         r.PostForm.Get("token"),
+			TokenTypeHint: r.PostForm.Get("token_type_hint"),
+		}
+		request.ClientId, request.ClientSecret = clientCredentials(r, request.ClientId, request.ClientSecret)
+		if err := handlers.Service.RevokeToken(r.Context(), request); err != nil {
+			return err
+		}
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(http.StatusOK)
+		return nil
+	}
+}
+
+func (handlers *Handlers) UserInfoHandler() apierror.UserHandler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		token := bearerToken(r)
+		if token == "" {
+			return apierror.NewOAuthError(apierror.OAuthInvalidToken, ErrBearerTokenNotPresent.Error())
+		}
+		userInfo, err := handlers.Service.GetUserInfo(r.Context(), token)
+		if err != nil {
+			return err
+		}
+		w.Header().Set("Content-type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(userInfo)
+		return nil
+	}
+}
+
+// loginFormUser checks the login CSRF token and the sign in challenge of the login form, the same challenge /user/auth
+// takes in headers. The form can't set headers, the answer comes in form fields and the CAPTCHA widgets name theirs.
+func (handlers *Handlers) loginFormUser(r *http.Request) (*models.User, error) {
+	cookie, err := r.Cookie(LoginCsrfCookie)
+	if err != nil || cookie.Value == "" || subtle.ConstantTimeCompare([]byte(r.PostForm.Get("login_csrf")), []byte(cookie.Value)) != 1 {
+		return nil, apierror.NewCatalogError(apierror.CodeCsrfTokenMismatch, ErrCsrfTokenMismatch.Error())
+	}
+	captchaToken := r.PostForm.Get("captcha_token")
+	for _, field := range []string{"h-captcha-response", "cf-turnstile-response"} {
+		if captchaToken == "" {
+			captchaToken = r.PostForm.Get(field)
+		}
+	}
+	response := &models.ChallengeResponse{
+		Endpoint:     models.ChallengeSignIn,
+		Token:        r.PostForm.Get("challenge"),
+		Solution:     r.PostForm.Get("challenge_solution"),
+		CaptchaToken: captchaToken,
+		Ip:           models.RequestMetaFromContext(r.Context()).Ip,
+	}
+	if err := handlers.Service.VerifyChallenge(r.Context(), response); err != nil {
+		return nil, err
+	}
+	userDto := dto.UserSignInDto{Email: r.PostForm.Get("email"), Password: r.PostForm.Get("password")}
+	return userDto.IntoUser()
+}
+
+// renderLogin renders the login form with a new sign in challenge and login CSRF token, the token is double-submitted
+// in LoginCsrfCookie so another site can't sign the browser in to an account of its choosing.
+func (handlers *Handlers) renderLogin(w http.ResponseWriter, r *http.Request, client *models.OAuthClient, params map[string]string, message string) error {
+	challenge, err := handlers.Service.NewChallenge(r.Context(), models.ChallengeSignIn)
+	if err != nil {
+		return err
+	}
+	loginCsrf := uniuri.NewLen(32)
+	http.SetCookie(w, &http.Cookie{
+		Name:     LoginCsrfCookie,
+		Value:    loginCsrf,
+		HttpOnly: true,
+		Secure:   handlers.Config.Secure,
+		SameSite: http.SameSiteStrictMode,
+		Path:     "/oauth/authorize",
+	})
+	return handlers.renderPage(w, handlers.Config.OauthLoginTemplateLocation, map[string]interface{}{"Client": client.Name, "Params": params, "Error": message, "LoginCsrf": loginCsrf, "Challenge": challenge})
+}
+
+func (handlers *Handlers) renderPage(w http.ResponseWriter, templateLocation string, data map[string]interface{}) error {
+	t, err := template.ParseFiles(templateLocation)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-type", "text/html; charset=utf-8")
+	w.Header().Set("X-Frame-Options", "DENY")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	return t.Execute(w, data)
+}
+
+// clientCredentials prefers client_secret_basic over the credentials sent in the form.
+func clientCredentials(r *http.Request, clientId string, clientSecret string) (string, string) {
+	if basicId, basicSecret, ok := r.BasicAuth(); ok {
+		clientId, _ = url.QueryUnescape(basicId)
+		clientSecret, _ = url.QueryUnescape(basicSecret)
+	}
+	return clientId, clientSecret
+}
+
+func bearerToken(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	if len(header) < 7 || !strings.EqualFold(header[:7], "Bearer ") {
+		return ""
+	}
+	return strings.TrimSpace(header[7:])
+}
+
+func redirectWithParams(w http.ResponseWriter, r *http.Request, redirectUri string, params map[string]string) {
+	location, _ := url.Parse(redirectUri)
+	query := location.Query()
+	for key, value := range params {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	location.RawQuery = query.Encode()
+	http.Redirect(w, r, location.String(), http.StatusFound)
+}
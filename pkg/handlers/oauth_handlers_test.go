@@ -0,0 +1,258 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	config "github.com/Kin-dza-dzaa/userApi/configs"
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/Kin-dza-dzaa/userApi/pkg/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+func newOAuthHandlers(t *testing.T) (*Handlers, *mocks.Service) {
+	service := mocks.NewService(t)
+	return &Handlers{
+		Service: service,
+		Config: &config.Config{
+			Issuer:                       "http://localhost:8001",
+			OauthLoginTemplateLocation:   "./../../internal/templates/oauth_login_template.html",
+			OauthConsentTemplateLocation: "./../../internal/templates/oauth_consent_template.html",
+		},
+	}, service
+}
+
+func TestDiscoveryHandler(t *testing.T) {
+	handlers, _ := newOAuthHandlers(t)
+	w := httptest.NewRecorder()
+	if err := handlers.DiscoveryHandler()(w, httptest.NewRequest("GET", "/.well-known/openid-configuration", nil)); err != nil {
+		t.FailNow()
+	}
+	var discovery map[string]interface{}
+	if err := json.NewDecoder(w.Body).Decode(&discovery); err != nil {
+		t.FailNow()
+	}
+	if discovery["issuer"] != "http://localhost:8001" || discovery["token_endpoint"] != "http://localhost:8001/oauth/token" {
+		t.FailNow()
+	}
+}
+
+func TestAuthorizeHandler(t *testing.T) {
+	query := "/oauth/authorize?response_type=code&client_id=wordapi&redirect_uri=http%3A%2F%2Flocalhost%3A3000%2Fcallback&scope=openid&state=xyz&code_challenge=abc&code_challenge_method=S256"
+	client := &models.OAuthClient{ClientId: "wordapi", Name: "wordApi"}
+	userId := uuid.New()
+	testCases := []struct {
+		name       string
+		beforeTest func(service *mocks.Service, r *http.Request)
+		status     int
+		location   map[string]string
+		body       string
+		err        bool
+	}{
+		{
+			name: "unregistered_redirect_uri",
+			beforeTest: func(service *mocks.Service, r *http.Request) {
+				service.On("ValidateAuthorizationRequest", mock.Anything, mock.Anything).Return(nil, apierror.NewOAuthError(apierror.OAuthInvalidRequest, "")).Once()
+			},
+			err: true,
+		},
+		{
+			name: "redirectable_error",
+			beforeTest: func(service *mocks.Service, r *http.Request) {
+				service.On("ValidateAuthorizationRequest", mock.Anything, mock.Anything).Return(client, apierror.NewOAuthError(apierror.OAuthInvalidScope, "")).Once()
+			},
+			status:   http.StatusFound,
+			location: map[string]string{"error": apierror.OAuthInvalidScope, "state": "xyz"},
+		},
+		{
+			name: "login_page",
+			beforeTest: func(service *mocks.Service, r *http.Request) {
+				service.On("ValidateAuthorizationRequest", mock.Anything, mock.Anything).Return(client, nil).Once()
+				service.On("NewChallenge", mock.Anything, models.ChallengeSignIn).Return(&models.Challenge{Endpoint: models.ChallengeSignIn, Type: "pow", Token: "puzzle", Difficulty: 20}, nil).Once()
+			},
+			status: http.StatusOK,
+			body:   `name="challenge" value="puzzle"`,
+		},
+		{
+			name: "consent_page",
+			beforeTest: func(service *mocks.Service, r *http.Request) {
+				r.AddCookie(&http.Cookie{Name: "Access-token", Value: "jwt"})
+				service.On("ValidateAuthorizationRequest", mock.Anything, mock.Anything).Return(client, nil).Once()
+				service.On("ParseAccessToken", mock.Anything, "jwt").Return(&models.MyJwtClaims{UserId: userId.String(), XCSRFToken: "csrf"}, nil).Once()
+				service.On("HasConsent", mock.Anything, mock.Anything).Return(false, nil).Once()
+			},
+			status: http.StatusOK,
+			body:   `name="csrf" value="csrf"`,
+		},
+		{
+			name: "code_issued",
+			beforeTest: func(service *mocks.Service, r *http.Request) {
+				r.AddCookie(&http.Cookie{Name: "Access-token", Value: "jwt"})
+				service.On("ValidateAuthorizationRequest", mock.Anything, mock.Anything).Return(client, nil).Once()
+				service.On("ParseAccessToken", mock.Anything, "jwt").Return(&models.MyJwtClaims{UserId: userId.String(), XCSRFToken: "csrf"}, nil).Once()
+				service.On("HasConsent", mock.Anything, mock.Anything).Return(true, nil).Once()
+				service.On("IssueAuthorizationCode", mock.Anything, mock.MatchedBy(func(code *models.AuthorizationCode) bool {
+					return code.UserId == userId && code.CodeChallenge == "abc"
+				})).Run(func(args mock.Arguments) {
+					args.Get(1).(*models.AuthorizationCode).Code = "issued"
+				}).Return(nil).Once()
+			},
+			status:   http.StatusFound,
+			location: map[string]string{"code": "issued", "state": "xyz"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			handlers, service := newOAuthHandlers(t)
+			r := httptest.NewRequest("GET", query, nil)
+			w := httptest.NewRecorder()
+			tc.beforeTest(service, r)
+			err := handlers.AuthorizeHandler()(w, r)
+			if tc.err {
+				if err == nil {
+					t.FailNow()
+				}
+				return
+			}
+			if err != nil || w.Code != tc.status {
+				t.FailNow()
+			}
+			if tc.location != nil {
+				location, _ := url.Parse(w.Header().Get("Location"))
+				for key, value := range tc.location {
+					if location.Query().Get(key) != value {
+						t.Fatalf("%s: expected %s, got %s", key, value, location.Query().Get(key))
+					}
+				}
+			}
+			if tc.body != "" && !strings.Contains(w.Body.String(), tc.body) {
+				t.FailNow()
+			}
+		})
+	}
+}
+
+func TestAuthorizeLoginForm(t *testing.T) {
+	query := "/oauth/authorize?response_type=code&client_id=wordapi&redirect_uri=http%3A%2F%2Flocalhost%3A3000%2Fcallback&scope=openid&state=xyz&code_challenge=abc&code_challenge_method=S256"
+	client := &models.OAuthClient{ClientId: "wordapi", Name: "wordApi"}
+	form := url.Values{"email": {"test@gmail.com"}, "password": {"123456789"}, "login_csrf": {"login-csrf"}, "challenge": {"puzzle"}, "challenge_solution": {"42"}}
+	testCases := []struct {
+		name       string
+		cookie     string
+		beforeTest func(service *mocks.Service)
+		body       string
+	}{
+		{
+			name:   "login_csrf_mismatch",
+			cookie: "other",
+			beforeTest: func(service *mocks.Service) {
+				service.On("NewChallenge", mock.Anything, models.ChallengeSignIn).Return(&models.Challenge{Type: "none"}, nil).Once()
+			},
+			body: "csrf token is missing or doesn&#39;t match",
+		},
+		{
+			name:   "challenge_failed",
+			cookie: "login-csrf",
+			beforeTest: func(service *mocks.Service) {
+				service.On("VerifyChallenge", mock.Anything, &models.ChallengeResponse{Endpoint: models.ChallengeSignIn, Token: "puzzle", Solution: "42"}).Return(apierror.NewCatalogError(apierror.CodeChallengeFailed, "challenge expired or wasn't solved")).Once()
+				service.On("NewChallenge", mock.Anything, models.ChallengeSignIn).Return(&models.Challenge{Type: "none"}, nil).Once()
+			},
+			body: "challenge expired or wasn&#39;t solved",
+		},
+		{
+			name:   "signed_in",
+			cookie: "login-csrf",
+			beforeTest: func(service *mocks.Service) {
+				service.On("VerifyChallenge", mock.Anything, mock.Anything).Return(nil).Once()
+				service.On("SignInUser", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+					args.Get(1).(*models.User).UserId = uuid.New()
+				}).Return(nil).Once()
+				service.On("HasConsent", mock.Anything, mock.Anything).Return(false, nil).Once()
+			},
+			body: `name="consent"`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			handlers, service := newOAuthHandlers(t)
+			r := httptest.NewRequest("POST", query, strings.NewReader(form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			r.AddCookie(&http.Cookie{Name: LoginCsrfCookie, Value: tc.cookie})
+			w := httptest.NewRecorder()
+			service.On("ValidateAuthorizationRequest", mock.Anything, mock.Anything).Return(client, nil).Once()
+			tc.beforeTest(service)
+			if err := handlers.AuthorizeHandler()(w, r); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(w.Body.String(), tc.body) {
+				t.Fatalf("expected %q in %s", tc.body, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestTokenHandler(t *testing.T) {
+	handlers, service := newOAuthHandlers(t)
+	r := httptest.NewRequest("POST", "/oauth/token", strings.NewReader("grant_type=authorization_code&code=code&code_verifier=verifier"))
+	r.Header.Set("Content-type", "application/x-www-form-urlencoded")
+	r.SetBasicAuth("wordapi", "secret")
+	service.On("Token", mock.Anything, mock.MatchedBy(func(request *models.TokenRequest) bool {
+		return request.ClientId == "wordapi" && request.ClientSecret == "secret" && request.Code == "code"
+	})).Return(&models.TokenResponse{AccessToken: "access", TokenType: "Bearer"}, nil).Once()
+	w := httptest.NewRecorder()
+	if err := handlers.TokenHandler()(w, r); err != nil {
+		t.FailNow()
+	}
+	if w.Header().Get("Cache-Control") != "no-store" {
+		t.FailNow()
+	}
+}
+
+func TestIntrospectHandler(t *testing.T) {
+	handlers, service := newOAuthHandlers(t)
+	service.On("IntrospectToken", mock.Anything, &models.IntrospectionRequest{ClientId: "wordapi", ClientSecret: "secret", Token: "jwt"}).Return(&models.Introspection{Active: true, Sub: "sub"}, nil).Once()
+	r := httptest.NewRequest("POST", "/oauth/introspect", strings.NewReader("token=jwt"))
+	r.Header.Set("Content-type", "application/x-www-form-urlencoded")
+	r.SetBasicAuth("wordapi", "secret")
+	w := httptest.NewRecorder()
+	if err := handlers.IntrospectHandler()(w, r); err != nil {
+		t.FailNow()
+	}
+	var introspection map[string]interface{}
+	if err := json.NewDecoder(w.Body).Decode(&introspection); err != nil || introspection["active"] != true || introspection["sub"] != "sub" {
+		t.FailNow()
+	}
+}
+
+func TestRevokeHandler(t *testing.T) {
+	handlers, service := newOAuthHandlers(t)
+	service.On("RevokeToken", mock.Anything, &models.RevocationRequest{ClientId: "wordapi", Token: "refresh", TokenTypeHint: "refresh_token"}).Return(nil).Once()
+	r := httptest.NewRequest("POST", "/oauth/revoke", strings.NewReader("client_id=wordapi&token=refresh&token_type_hint=refresh_token"))
+	r.Header.Set("Content-type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	if err := handlers.RevokeHandler()(w, r); err != nil || w.Code != http.StatusOK {
+		t.FailNow()
+	}
+
+	r = httptest.NewRequest("POST", "/oauth/revoke", strings.NewReader("client_id=wordapi"))
+	r.Header.Set("Content-type", "application/x-www-form-urlencoded")
+	if err := handlers.RevokeHandler()(httptest.NewRecorder(), r); err.(*apierror.OAuthError).ErrorCode != apierror.OAuthInvalidRequest {
+		t.FailNow()
+	}
+}
+
+func TestLogOutRevokesAccessToken(t *testing.T) {
+	handlers, service := newOAuthHandlers(t)
+	service.On("RevokeAccessToken", mock.Anything, "jwt").Return(nil).Once()
+	r := httptest.NewRequest("POST", "/user/logout", nil)
+	r.AddCookie(&http.Cookie{Name: "Access-token", Value: "jwt"})
+	if err := handlers.LogOutHandler()(httptest.NewRecorder(), r); err != nil {
+		t.FailNow()
+	}
+}
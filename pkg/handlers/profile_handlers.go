@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/dto"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+)
+
+// GetProfileHandler tells the SPA who is signed in.
+func (handlers *Handlers) GetProfileHandler() apierror.UserHandler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-type", "application/json")
+		user, err := sessionUser(r)
+		if err != nil {
+			return err
+		}
+		profile, err := handlers.Service.GetProfile(r.Context(), user)
+		if err != nil {
+			return err
+		}
+		writeProfile(w, profile)
+		return nil
+	}
+}
+
+func (handlers *Handlers) UpdateProfileHandler() apierror.UserHandler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-type", "application/json")
+		user, err := sessionUser(r)
+		if err != nil {
+			return err
+		}
+		var updateDto dto.ProfileUpdateDto
+		if err := json.NewDecoder(r.Body).Decode(&updateDto); err != nil {
+			return apierror.NewCatalogError(apierror.CodeMalformedBody, `unmarshal failed, expected object{"user_name":"string", "display_name":"string", "locale":"string", "timezone":"string"}`)
+		}
+		update, err := updateDto.IntoProfileUpdate()
+		if err != nil {
+			return err
+		}
+		// the user name identifies the user to others and in tokens, changing it needs a recent sign-in
+		if update.UserName != nil {
+			if err := handlers.requireRecentAuth(r); err != nil {
+				return err
+			}
+		}
+		update.UserId = user.UserId
+		profile, err := handlers.Service.UpdateProfile(r.Context(), update)
+		if err != nil {
+			return err
+		}
+		writeProfile(w, profile)
+		return nil
+	}
+}
+
+// UserNameAvailableHandler lets the sign up form check a user name before it's submitted, the response carries
+// the name in the form it would be stored in.
+func (handlers *Handlers) UserNameAvailableHandler() apierror.UserHandler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-type", "application/json")
+		user, err := dto.UserNameDto{UserName: r.URL.Query().Get("user_name")}.IntoUser()
+		if err != nil {
+			return err
+		}
+		available, err := handlers.Service.UserNameAvailable(r.Context(), user)
+		if err != nil {
+			return err
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": "ok", "code": 200, "user_name": user.UserName, "available": available})
+		return nil
+	}
+}
+
+func writeProfile(w http.ResponseWriter, profile *models.Profile) {
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{"result": "ok", "code": 200, "user": profile})
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	config "github.com/Kin-dza-dzaa/userApi/configs"
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/Kin-dza-dzaa/userApi/pkg/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestUpdateProfileHandler(t *testing.T) {
+	userId := uuid.New()
+	testCases := []struct {
+		name       string
+		body       string
+		authTime   time.Time
+		beforeTest func(service *mocks.Service)
+		errCode    string
+	}{
+		{
+			name: "updated",
+			body: `{"display_name": "Test", "timezone": "Europe/Berlin"}`,
+			beforeTest: func(service *mocks.Service) {
+				service.On("UpdateProfile", mock.Anything, mock.MatchedBy(func(update *models.ProfileUpdate) bool {
+					return update.UserId == userId && *update.DisplayName == "Test" && *update.Timezone == "Europe/Berlin" && update.UserName == nil && update.Locale == nil
+				})).Return(&models.Profile{UserId: userId, DisplayName: "Test", Timezone: "Europe/Berlin"}, nil).Once()
+			},
+		},
+		{
+			name:    "invalid_timezone",
+			body:    `{"timezone": "Mars/Olympus"}`,
+			errCode: apierror.CodeValidationFailed,
+		},
+		{
+			name:     "user_name_taken",
+			body:     `{"user_name": "someone_else"}`,
+			authTime: time.Now(),
+			beforeTest: func(service *mocks.Service) {
+				service.On("UpdateProfile", mock.Anything, mock.Anything).Return(nil, apierror.NewCatalogError(apierror.CodeUserNameTaken, "")).Once()
+			},
+			errCode: apierror.CodeUserNameTaken,
+		},
+		{
+			name:     "user_name_stale_session",
+			body:     `{"user_name": "new_name"}`,
+			authTime: time.Now().Add(-time.Hour),
+			errCode:  apierror.CodeReauthenticationRequired,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			service := mocks.NewService(t)
+			handlers := &Handlers{Service: service, Config: &config.Config{}}
+			claims := &models.MyJwtClaims{UserId: userId.String()}
+			if !tc.authTime.IsZero() {
+				claims.AuthTime = tc.authTime.Unix()
+			}
+			service.On("Authenticate", mock.Anything, "jwt").Return(claims, nil).Once()
+			if tc.beforeTest != nil {
+				tc.beforeTest(service)
+			}
+			r := httptest.NewRequest("PATCH", "/user/me", strings.NewReader(tc.body))
+			r.Header.Set("Authorization", "Bearer jwt")
+			w := httptest.NewRecorder()
+			err := handlers.AuthMiddleWare(handlers.UpdateProfileHandler())(w, r)
+			if tc.errCode != "" {
+				if err.(*apierror.ErrorStruct).ErrorCode != tc.errCode {
+					t.FailNow()
+				}
+				return
+			}
+			var response struct {
+				User models.Profile `json:"user"`
+			}
+			if err != nil || w.Code != http.StatusOK || json.NewDecoder(w.Body).Decode(&response) != nil || response.User.DisplayName != "Test" {
+				t.FailNow()
+			}
+		})
+	}
+}
+
+func TestUserNameAvailableHandler(t *testing.T) {
+	testCases := []struct {
+		name       string
+		query      string
+		beforeTest func(service *mocks.Service)
+		available  bool
+		errCode    string
+	}{
+		{
+			name:  "available",
+			query: "user_name=%EF%BC%A1lice_smith",
+			beforeTest: func(service *mocks.Service) {
+				service.On("UserNameAvailable", mock.Anything, &models.User{UserName: "Alice_smith"}).Return(true, nil).Once()
+			},
+			available: true,
+		},
+		{
+			name:  "taken",
+			query: "user_name=alice_smith",
+			beforeTest: func(service *mocks.Service) {
+				service.On("UserNameAvailable", mock.Anything, &models.User{UserName: "alice_smith"}).Return(false, nil).Once()
+			},
+		},
+		{
+			name:    "reserved",
+			query:   "user_name=administrator",
+			errCode: apierror.CodeValidationFailed,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			service := mocks.NewService(t)
+			handlers := &Handlers{Service: service, Config: &config.Config{}}
+			if tc.beforeTest != nil {
+				tc.beforeTest(service)
+			}
+			w := httptest.NewRecorder()
+			err := handlers.UserNameAvailableHandler()(w, httptest.NewRequest("GET", "/user/name-available?"+tc.query, nil))
+			if tc.errCode != "" {
+				if err.(*apierror.ErrorStruct).ErrorCode != tc.errCode {
+					t.FailNow()
+				}
+				return
+			}
+			var response struct {
+				Available bool `json:"available"`
+			}
+			if err != nil || w.Code != http.StatusOK || json.NewDecoder(w.Body).Decode(&response) != nil || response.Available != tc.available {
+				t.FailNow()
+			}
+		})
+	}
+}
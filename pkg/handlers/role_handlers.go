@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/dto"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	repository "github.com/Kin-dza-dzaa/userApi/pkg/repositories"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+func (handlers *Handlers) GetRolesHandler() apierror.UserHandler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-type", "application/json")
+		roles, err := handlers.Service.GetRoles(r.Context())
+		if err != nil {
+			return err
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": "ok", "code": 200, "roles": roles})
+		return nil
+	}
+}
+
+func (handlers *Handlers) PutRoleHandler() apierror.UserHandler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-type", "application/json")
+		var roleDto dto.RoleDto
+		if err := json.NewDecoder(r.Body).Decode(&roleDto); err != nil {
+			return apierror.NewCatalogError(apierror.CodeMalformedBody, `unmarshal failed, expected object{"description":"string", "permissions":["string"]}`)
+		}
+		role, err := roleDto.IntoRole(mux.Vars(r)["role"])
+		if err != nil {
+			return err
+		}
+		if err := handlers.Service.PutRole(r.Context(), role); err != nil {
+			return err
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": "ok", "code": 200})
+		return nil
+	}
+}
+
+func (handlers *Handlers) DeleteRoleHandler() apierror.UserHandler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-type", "application/json")
+		if err := handlers.Service.DeleteRole(r.Context(), &models.Role{Name: mux.Vars(r)["role"]}); err != nil {
+			return err
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": "ok", "code": 200})
+		return nil
+	}
+}
+
+func (handlers *Handlers) GetPermissionsHandler() apierror.UserHandler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-type", "application/json")
+		permissions, err := handlers.Service.GetPermissions(r.Context())
+		if err != nil {
+			return err
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": "ok", "code": 200, "permissions": permissions})
+		return nil
+	}
+}
+
+func (handlers *Handlers) PutPermissionHandler() apierror.UserHandler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-type", "application/json")
+		var permissionDto dto.PermissionDto
+		if err := json.NewDecoder(r.Body).Decode(&permissionDto); err != nil {
+			return apierror.NewCatalogError(apierror.CodeMalformedBody, `unmarshal failed, expected object{"description":"string"}`)
+		}
+		permission, err := permissionDto.IntoPermission(mux.Vars(r)["permission"])
+		if err != nil {
+			return err
+		}
+		if err := handlers.Service.PutPermission(r.Context(), permission); err != nil {
+			return err
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": "ok", "code": 200})
+		return nil
+	}
+}
+
+func (handlers *Handlers) DeletePermissionHandler() apierror.UserHandler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-type", "application/json")
+		if err := handlers.Service.DeletePermission(r.Context(), &models.Permission{Name: mux.Vars(r)["permission"]}); err != nil {
+			return err
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": "ok", "code": 200})
+		return nil
+	}
+}
+
+func (handlers *Handlers) GetUserRolesHandler() apierror.UserHandler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-type", "application/json")
+		user := new(models.User)
+		var err error
+		if user.UserId, err = uuid.Parse(mux.Vars(r)["id"]); err != nil {
+			return apierror.NewCatalogError(apierror.CodeUserNotFound, repository.ErrUserNotFound.Error())
+		}
+		if err := handlers.Service.GetUserRoles(r.Context(), user); err != nil {
+			return err
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": "ok", "code": 200, "roles": user.Roles, "permissions": user.Permissions})
+		return nil
+	}
+}
+
+func (handlers *Handlers) GrantRoleHandler() apierror.UserHandler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-type", "application/json")
+		userRole, err := userRoleFromPath(r)
+		if err != nil {
+			return err
+		}
+		if err := handlers.Service.GrantRole(r.Context(), userRole); err != nil {
+			return err
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": "ok", "code": 200})
+		return nil
+	}
+}
+
+func (handlers *Handlers) RevokeRoleHandler() apierror.UserHandler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-type", "application/json")
+		userRole, err := userRoleFromPath(r)
+		if err != nil {
+			return err
+		}
+		if err := handlers.Service.RevokeRole(r.Context(), userRole); err != nil {
+			return err
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": "ok", "code": 200})
+		return nil
+	}
+}
+
+func userRoleFromPath(r *http.Request) (*models.UserRole, error) {
+	vars := mux.Vars(r)
+	userId, err := uuid.Parse(vars["id"])
+	if err != nil {
+		return nil, apierror.NewCatalogError(apierror.CodeUserNotFound, repository.ErrUserNotFound.Error())
+	}
+	return &models.UserRole{UserId: userId, Role: vars["role"]}, nil
+}
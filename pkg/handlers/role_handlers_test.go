@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	config "github.com/Kin-dza-dzaa/userApi/configs"
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/Kin-dza-dzaa/userApi/pkg/mocks"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPutRoleHandler(t *testing.T) {
+	testCases := []struct {
+		name       string
+		claims     *models.MyJwtClaims
+		body       string
+		beforeTest func(service *mocks.Service)
+		errCode    string
+	}{
+		{
+			name:   "replaced",
+			claims: &models.MyJwtClaims{Scope: models.PermissionRolesManage, Roles: []string{"admin"}},
+			body:   `{"description":"Teachers","permissions":["words:write"]}`,
+			beforeTest: func(service *mocks.Service) {
+				service.On("PutRole", mock.Anything, &models.Role{Name: "teacher", Description: "Teachers", Permissions: []string{"words:write"}}).Return(nil).Once()
+			},
+		},
+		{
+			name:   "unknown_permission",
+			claims: &models.MyJwtClaims{Scope: models.PermissionRolesManage},
+			body:   `{"permissions":["words:delete"]}`,
+			beforeTest: func(service *mocks.Service) {
+				service.On("PutRole", mock.Anything, mock.Anything).Return(apierror.NewCatalogError(apierror.CodePermissionNotFound, "")).Once()
+			},
+			errCode: apierror.CodePermissionNotFound,
+		},
+		{
+			name:    "invalid_permission_name",
+			claims:  &models.MyJwtClaims{Scope: models.PermissionRolesManage},
+			body:    `{"permissions":["words write"]}`,
+			errCode: apierror.CodeValidationFailed,
+		},
+		{
+			name:    "without_permission",
+			claims:  &models.MyJwtClaims{Scope: "words:write"},
+			body:    `{}`,
+			errCode: apierror.CodePermissionDenied,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			service := mocks.NewService(t)
+			handlers := &Handlers{Service: service, Config: &config.Config{}}
+			service.On("Authenticate", mock.Anything, "jwt").Return(tc.claims, nil).Once()
+			if tc.beforeTest != nil {
+				tc.beforeTest(service)
+			}
+			r := mux.SetURLVars(httptest.NewRequest("PUT", "/admin/roles/teacher", strings.NewReader(tc.body)), map[string]string{"role": "teacher"})
+			r.Header.Set("Authorization", "Bearer jwt")
+			w := httptest.NewRecorder()
+			err := handlers.AuthMiddleWare(handlers.PermissionMiddleWare(models.PermissionRolesManage, handlers.PutRoleHandler()))(w, r)
+			if tc.errCode != "" {
+				if err.(*apierror.ErrorStruct).ErrorCode != tc.errCode {
+					t.FailNow()
+				}
+				return
+			}
+			if err != nil || w.Code != http.StatusOK {
+				t.FailNow()
+			}
+		})
+	}
+}
+
+func TestGrantRoleHandlerUnknownUser(t *testing.T) {
+	handlers := &Handlers{Service: mocks.NewService(t), Config: &config.Config{}}
+	r := mux.SetURLVars(httptest.NewRequest("PUT", "/admin/users/x/roles/admin", nil), map[string]string{"id": "x", "role": "admin"})
+	if err := handlers.GrantRoleHandler()(httptest.NewRecorder(), r); err.(*apierror.ErrorStruct).ErrorCode != apierror.CodeUserNotFound {
+		t.FailNow()
+	}
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/gorilla/mux"
+)
+
+const socialStateCookie = "Social-state"
+
+// SocialLoginHandler redirects to the provider, the state token is kept in a cookie scoped to /user/social.
+func (handlers *Handlers) SocialLoginHandler() apierror.UserHandler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		login, err := handlers.Service.BeginSocialLogin(r.Context(), mux.Vars(r)["provider"])
+		if err != nil {
+			return err
+		}
+		http.SetCookie(w, &http.Cookie{
+			Name:     socialStateCookie,
+			Value:    login.StateToken,
+			MaxAge:   600,
+			HttpOnly: true,
+			Secure:   handlers.Config.Secure,
+			SameSite: http.SameSiteLaxMode,
+			Path:     "/user/social",
+		})
+		http.Redirect(w, r, login.AuthUrl, http.StatusFound)
+		return nil
+	}
+}
+
+// SocialCallbackHandler sets the same cookies as SignInHandler and redirects to the SPA,
+// errors are sent to the SPA sign in page as ?error=<code>.
+func (handlers *Handlers) SocialCallbackHandler() apierror.UserHandler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		http.SetCookie(w, &http.Cookie{
+			Name:     socialStateCookie,
+			Value:    "",
+			MaxAge:   -1,
+			HttpOnly: true,
+			Secure:   handlers.Config.Secure,
+			SameSite: http.SameSiteLaxMode,
+			Path:     "/user/social",
+		})
+		callback := &models.SocialCallback{
+			Provider: mux.Vars(r)["provider"],
+			Code:     r.URL.Query().Get("code"),
+			State:    r.URL.Query().Get("state"),
+		}
+		cookie, err := r.Cookie(socialStateCookie)
+		if err != nil {
+			redirectWithParams(w, r, handlers.Config.SpaUrl+"/signin", map[string]string{"error": apierror.CodeCookieNotPresent})
+			return nil
+		}
+		callback.StateToken = cookie.Value
+		if r.URL.Query().Get("error") != "" {
+			redirectWithParams(w, r, handlers.Config.SpaUrl+"/signin", map[string]string{"error": apierror.CodeSocialLoginFailed})
+			return nil
+		}
+		user, err := handlers.Service.CompleteSocialLogin(r.Context(), callback)
+		if err != nil {
+			var errorStruct *apierror.ErrorStruct
+			if errors.As(err, &errorStruct) {
+				redirectWithParams(w, r, handlers.Config.SpaUrl+"/signin", map[string]string{"error": errorStruct.ErrorCode})
+				return nil
+			}
+			return err
+		}
+		handlers.writeSessionCookies(w, user, true)
+		http.Redirect(w, r, handlers.Config.SpaUrl, http.StatusFound)
+		return nil
+	}
+}
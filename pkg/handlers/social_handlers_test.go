@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	config "github.com/Kin-dza-dzaa/userApi/configs"
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/Kin-dza-dzaa/userApi/pkg/mocks"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSocialLoginHandler(t *testing.T) {
+	service := mocks.NewService(t)
+	handlers := &Handlers{Service: service, Config: &config.Config{}}
+	service.On("BeginSocialLogin", mock.Anything, "google").Return(&models.SocialLogin{AuthUrl: "https://accounts.google.com/o/oauth2/v2/auth?state=xyz", StateToken: "state-token"}, nil).Once()
+	w := httptest.NewRecorder()
+	r := mux.SetURLVars(httptest.NewRequest("GET", "/user/social/google", nil), map[string]string{"provider": "google"})
+	if err := handlers.SocialLoginHandler()(w, r); err != nil {
+		t.FailNow()
+	}
+	if w.Code != http.StatusFound || w.Header().Get("Location") != "https://accounts.google.com/o/oauth2/v2/auth?state=xyz" {
+		t.FailNow()
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != socialStateCookie || cookies[0].Value != "state-token" || cookies[0].SameSite != http.SameSiteLaxMode {
+		t.FailNow()
+	}
+}
+
+func TestSocialCallbackHandler(t *testing.T) {
+	testCases := []struct {
+		name       string
+		cookie     bool
+		beforeTest func(service *mocks.Service)
+		location   string
+		session    bool
+	}{
+		{
+			name:     "no_state_cookie",
+			location: "http://localhost:3000/signin?error=" + apierror.CodeCookieNotPresent,
+		},
+		{
+			name:   "email_unverified",
+			cookie: true,
+			beforeTest: func(service *mocks.Service) {
+				service.On("CompleteSocialLogin", mock.Anything, mock.Anything).Return(nil, apierror.NewCatalogError(apierror.CodeSocialEmailUnverified, "")).Once()
+			},
+			location: "http://localhost:3000/signin?error=" + apierror.CodeSocialEmailUnverified,
+		},
+		{
+			name:   "signed_in",
+			cookie: true,
+			beforeTest: func(service *mocks.Service) {
+				service.On("CompleteSocialLogin", mock.Anything, mock.MatchedBy(func(callback *models.SocialCallback) bool {
+					return callback.Provider == "google" && callback.Code == "code" && callback.State == "xyz" && callback.StateToken == "state-token"
+				})).Return(&models.User{Jwt: "jwt", RefreshToken: "refresh", CsrfToken: "csrf"}, nil).Once()
+			},
+			location: "http://localhost:3000",
+			session:  true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			service := mocks.NewService(t)
+			handlers := &Handlers{Service: service, Config: &config.Config{SpaUrl: "http://localhost:3000"}}
+			if tc.beforeTest != nil {
+				tc.beforeTest(service)
+			}
+			r := httptest.NewRequest("GET", "/user/social/google/callback?"+url.Values{"code": {"code"}, "state": {"xyz"}}.Encode(), nil)
+			r = mux.SetURLVars(r, map[string]string{"provider": "google"})
+			if tc.cookie {
+				r.AddCookie(&http.Cookie{Name: socialStateCookie, Value: "state-token"})
+			}
+			w := httptest.NewRecorder()
+			if err := handlers.SocialCallbackHandler()(w, r); err != nil {
+				t.FailNow()
+			}
+			if w.Code != http.StatusFound || w.Header().Get("Location") != tc.location {
+				t.Fatalf("got %d %s", w.Code, w.Header().Get("Location"))
+			}
+			if tc.session != (w.Header().Get("X-CSRF-Token") == "csrf") {
+				t.FailNow()
+			}
+		})
+	}
+}
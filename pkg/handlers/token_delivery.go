@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+)
+
+const (
+	// TokenDeliveryHeader set to "body" makes /user/auth, /user/verify and /user/token return tokens in the response body.
+	TokenDeliveryHeader = "X-Token-Delivery"
+	// TokenDeliveryQuery does the same as TokenDeliveryHeader for clients that can't set headers.
+	TokenDeliveryQuery = "token_delivery"
+	TokenDeliveryBody  = "body"
+)
+
+// bodyTokenDelivery reports whether the client asked for tokens in the body instead of cookies, it is meant for mobile and CLI clients.
+func bodyTokenDelivery(r *http.Request) bool {
+	return r.Header.Get(TokenDeliveryHeader) == TokenDeliveryBody || r.URL.Query().Get(TokenDeliveryQuery) == TokenDeliveryBody
+}
+
+// writeTokens sends user's tokens either as cookies or as a json body, refresh token is sent only if withRefresh is set.
+func (handlers *Handlers) writeTokens(w http.ResponseWriter, r *http.Request, user *models.User, withRefresh bool) {
+	if bodyTokenDelivery(r) {
+		response := map[string]interface{}{
+			"result":       "ok",
+			"code":         200,
+			"access_token": user.Jwt,
+			"expires_in":   int(handlers.Config.AccessTokenTtl().Seconds()),
+			"token_type":   "Bearer",
+		}
+		if withRefresh {
+			response["refresh_token"] = user.RefreshToken
+		}
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+	handlers.writeSessionCookies(w, user, withRefresh)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{"result": "ok", "code": 200})
+}
+
+// writeSessionCookies sets the cookies used by the SPA and the matching X-CSRF-Token header.
+// The refresh cookie is a session cookie unless the user asked to be remembered, a remembered one is kept until the session
+// reaches its maximum lifetime since refreshes slide the session without setting the cookie again.
+// The access cookie is Lax so a client redirecting to /oauth/authorize from another site finds the session, CSRF is left
+// to CsrfMiddleWare and the consent form token. The refresh cookie stays Strict.
+func (handlers *Handlers) writeSessionCookies(w http.ResponseWriter, user *models.User, withRefresh bool) {
+	if withRefresh {
+		var expires time.Time
+		if user.RememberMe {
+			expires = user.SessionStartedAt.Add(handlers.Config.SessionMaxLifetime())
+		}
+		http.SetCookie(w, &http.Cookie{
+			Name:     "Refresh-token",
+			Value:    user.RefreshToken,
+			Expires:  expires,
+			HttpOnly: true,
+			Secure:   handlers.Config.Secure,
+			SameSite: http.SameSiteStrictMode,
+			Path:     "/",
+		})
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     "Access-token",
+		Value:    user.Jwt,
+		MaxAge:   int(handlers.Config.AccessTokenTtl().Seconds()),
+		HttpOnly: true,
+		Secure:   handlers.Config.Secure,
+		SameSite: http.SameSiteLaxMode,
+		Path:     "/",
+	})
+	w.Header().Set("X-CSRF-Token", user.CsrfToken)
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/dto"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	repository "github.com/Kin-dza-dzaa/userApi/pkg/repositories"
+	"github.com/Kin-dza-dzaa/userApi/pkg/service"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+func (handlers *Handlers) CreatePersonalAccessTokenHandler() apierror.UserHandler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-type", "application/json")
+		user, err := sessionUser(r)
+		if err != nil {
+			return err
+		}
+		var tokenDto dto.PersonalAccessTokenDto
+		if err := json.NewDecoder(r.Body).Decode(&tokenDto); err != nil {
+			return apierror.NewCatalogError(apierror.CodeMalformedBody, `unmarshal failed, expected object{"name":"string", "scopes":["string"], "expires_in_days":int}`)
+		}
+		token, err := tokenDto.IntoPersonalAccessToken()
+		if err != nil {
+			return err
+		}
+		token.UserId = user.UserId
+		if err := handlers.Service.CreatePersonalAccessToken(r.Context(), token); err != nil {
+			return err
+		}
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": "ok", "code": 201, "token": token})
+		return nil
+	}
+}
+
+func (handlers *Handlers) GetPersonalAccessTokensHandler() apierror.UserHandler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-type", "application/json")
+		user, err := sessionUser(r)
+		if err != nil {
+			return err
+		}
+		tokens, err := handlers.Service.GetPersonalAccessTokens(r.Context(), user)
+		if err != nil {
+			return err
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": "ok", "code": 200, "tokens": tokens})
+		return nil
+	}
+}
+
+func (handlers *Handlers) DeletePersonalAccessTokenHandler() apierror.UserHandler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-type", "application/json")
+		user, err := sessionUser(r)
+		if err != nil {
+			return err
+		}
+		token := &models.PersonalAccessToken{UserId: user.UserId}
+		if token.TokenId, err = uuid.Parse(mux.Vars(r)["id"]); err != nil {
+			return apierror.NewCatalogError(apierror.CodePersonalAccessTokenNotFound, repository.ErrPersonalAccessTokenNotFound.Error())
+		}
+		if err := handlers.Service.DeletePersonalAccessToken(r.Context(), token); err != nil {
+			return err
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": "ok", "code": 200})
+		return nil
+	}
+}
+
+// sessionUser returns the user authenticated by AuthMiddleWare, which lets only session tokens through.
+func sessionUser(r *http.Request) (*models.User, error) {
+	claims := claimsFromContext(r.Context())
+	if claims == nil {
+		return nil, apierror.NewCatalogError(apierror.CodeAccessTokenInvalid, ErrCredentialsNotPresent.Error())
+	}
+	userId, err := uuid.Parse(claims.UserId)
+	if err != nil {
+		return nil, apierror.NewCatalogError(apierror.CodeAccessTokenInvalid, service.ErrAccessTokenInvalid.Error())
+	}
+	return &models.User{UserId: userId}, nil
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	config "github.com/Kin-dza-dzaa/userApi/configs"
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/Kin-dza-dzaa/userApi/pkg/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreatePersonalAccessTokenHandler(t *testing.T) {
+	userId := uuid.New()
+	testCases := []struct {
+		name       string
+		bearer     string
+		beforeTest func(service *mocks.Service)
+		status     int
+		errCode    string
+	}{
+		{
+			name:   "created",
+			bearer: "jwt",
+			beforeTest: func(service *mocks.Service) {
+				service.On("Authenticate", mock.Anything, "jwt").Return(&models.MyJwtClaims{UserId: userId.String()}, nil).Once()
+				service.On("CreatePersonalAccessToken", mock.Anything, mock.MatchedBy(func(token *models.PersonalAccessToken) bool {
+					return token.UserId == userId && token.Name == "import"
+				})).Run(func(args mock.Arguments) {
+					args.Get(1).(*models.PersonalAccessToken).Token = "pat_secret"
+				}).Return(nil).Once()
+			},
+			status: http.StatusCreated,
+		},
+		{
+			name:    "with_personal_access_token",
+			bearer:  "pat_secret",
+			errCode: apierror.CodePersonalAccessTokenForbidden,
+		},
+		{
+			name:    "unauthenticated",
+			errCode: apierror.CodeAccessTokenInvalid,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			service := mocks.NewService(t)
+			handlers := &Handlers{Service: service, Config: &config.Config{}}
+			if tc.beforeTest != nil {
+				tc.beforeTest(service)
+			}
+			r := httptest.NewRequest("POST", "/user/tokens", strings.NewReader(`{"name":"import","scopes":["words:write"]}`))
+			if tc.bearer != "" {
+				r.Header.Set("Authorization", "Bearer "+tc.bearer)
+			}
+			w := httptest.NewRecorder()
+			err := handlers.AuthMiddleWare(handlers.CreatePersonalAccessTokenHandler())(w, r)
+			if tc.errCode != "" {
+				if err.(*apierror.ErrorStruct).ErrorCode != tc.errCode {
+					t.FailNow()
+				}
+				return
+			}
+			var response struct {
+				Token models.PersonalAccessToken `json:"token"`
+			}
+			if err != nil || w.Code != tc.status || json.NewDecoder(w.Body).Decode(&response) != nil || response.Token.Token != "pat_secret" {
+				t.FailNow()
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ package mocks
 
 import (
 	context "context"
+	time "time"
 
 	models "github.com/Kin-dza-dzaa/userApi/internal/models"
 	mock "github.com/stretchr/testify/mock"
@@ -14,6 +15,146 @@ type Repository struct {
 	mock.Mock
 }
 
+// AddAuditEvent provides a mock function with given fields: ctx, auditEvent
+func (_m *Repository) AddAuditEvent(ctx context.Context, auditEvent *models.AuditEvent) error {
+	ret := _m.Called(ctx, auditEvent)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.AuditEvent) error); ok {
+		r0 = rf(ctx, auditEvent)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// AddAuthorizationCode provides a mock function with given fields: ctx, authorizationCode
+func (_m *Repository) AddAuthorizationCode(ctx context.Context, authorizationCode *models.AuthorizationCode) error {
+	ret := _m.Called(ctx, authorizationCode)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.AuthorizationCode) error); ok {
+		r0 = rf(ctx, authorizationCode)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// AddConsent provides a mock function with given fields: ctx, consent
+func (_m *Repository) AddConsent(ctx context.Context, consent *models.Consent) error {
+	ret := _m.Called(ctx, consent)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Consent) error); ok {
+		r0 = rf(ctx, consent)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// AddDeviceAuthorization provides a mock function with given fields: ctx, deviceAuthorization
+func (_m *Repository) AddDeviceAuthorization(ctx context.Context, deviceAuthorization *models.DeviceAuthorization) error {
+	ret := _m.Called(ctx, deviceAuthorization)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.DeviceAuthorization) error); ok {
+		r0 = rf(ctx, deviceAuthorization)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// AddIdentity provides a mock function with given fields: ctx, identity
+func (_m *Repository) AddIdentity(ctx context.Context, identity *models.Identity) error {
+	ret := _m.Called(ctx, identity)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Identity) error); ok {
+		r0 = rf(ctx, identity)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// AddOAuthRefreshToken provides a mock function with given fields: ctx, oAuthRefreshToken
+func (_m *Repository) AddOAuthRefreshToken(ctx context.Context, oAuthRefreshToken *models.OAuthRefreshToken) error {
+	ret := _m.Called(ctx, oAuthRefreshToken)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.OAuthRefreshToken) error); ok {
+		r0 = rf(ctx, oAuthRefreshToken)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// AddPasswordReset provides a mock function with given fields: ctx, passwordReset
+func (_m *Repository) AddPasswordReset(ctx context.Context, passwordReset *models.PasswordReset) error {
+	ret := _m.Called(ctx, passwordReset)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.PasswordReset) error); ok {
+		r0 = rf(ctx, passwordReset)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// AddPersonalAccessToken provides a mock function with given fields: ctx, personalAccessToken
+func (_m *Repository) AddPersonalAccessToken(ctx context.Context, personalAccessToken *models.PersonalAccessToken) error {
+	ret := _m.Called(ctx, personalAccessToken)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.PersonalAccessToken) error); ok {
+		r0 = rf(ctx, personalAccessToken)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// AddRevokedToken provides a mock function with given fields: ctx, revokedToken, result
+func (_m *Repository) AddRevokedToken(ctx context.Context, revokedToken *models.RevokedToken, result *bool) error {
+	ret := _m.Called(ctx, revokedToken, result)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.RevokedToken, *bool) error); ok {
+		r0 = rf(ctx, revokedToken, result)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// AddSecureAccountCode provides a mock function with given fields: ctx, secureAccountCode
+func (_m *Repository) AddSecureAccountCode(ctx context.Context, secureAccountCode *models.SecureAccountCode) error {
+	ret := _m.Called(ctx, secureAccountCode)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.SecureAccountCode) error); ok {
+		r0 = rf(ctx, secureAccountCode)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // AddUser provides a mock function with given fields: ctx, user
 func (_m *Repository) AddUser(ctx context.Context, user *models.User) error {
 	ret := _m.Called(ctx, user)
@@ -28,8 +169,510 @@ func (_m *Repository) AddUser(ctx context.Context, user *models.User) error {
 	return r0
 }
 
-// GetUUid provides a mock function with given fields: ctx, user
-func (_m *Repository) GetUUid(ctx context.Context, user *models.User) error {
+// AddUserRole provides a mock function with given fields: ctx, userRole
+func (_m *Repository) AddUserRole(ctx context.Context, userRole *models.UserRole) error {
+	ret := _m.Called(ctx, userRole)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.UserRole) error); ok {
+		r0 = rf(ctx, userRole)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ClaimUnverifiedUser provides a mock function with given fields: ctx, user
+func (_m *Repository) ClaimUnverifiedUser(ctx context.Context, user *models.User) error {
+	ret := _m.Called(ctx, user)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.User) error); ok {
+		r0 = rf(ctx, user)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ConsumeAuthorizationCode provides a mock function with given fields: ctx, authorizationCode
+func (_m *Repository) ConsumeAuthorizationCode(ctx context.Context, authorizationCode *models.AuthorizationCode) error {
+	ret := _m.Called(ctx, authorizationCode)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.AuthorizationCode) error); ok {
+		r0 = rf(ctx, authorizationCode)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ConsumeOAuthRefreshToken provides a mock function with given fields: ctx, oAuthRefreshToken
+func (_m *Repository) ConsumeOAuthRefreshToken(ctx context.Context, oAuthRefreshToken *models.OAuthRefreshToken) error {
+	ret := _m.Called(ctx, oAuthRefreshToken)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.OAuthRefreshToken) error); ok {
+		r0 = rf(ctx, oAuthRefreshToken)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ConsumePasswordReset provides a mock function with given fields: ctx, passwordReset
+func (_m *Repository) ConsumePasswordReset(ctx context.Context, passwordReset *models.PasswordReset) error {
+	ret := _m.Called(ctx, passwordReset)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.PasswordReset) error); ok {
+		r0 = rf(ctx, passwordReset)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ConsumeSecureAccountCode provides a mock function with given fields: ctx, secureAccountCode
+func (_m *Repository) ConsumeSecureAccountCode(ctx context.Context, secureAccountCode *models.SecureAccountCode) error {
+	ret := _m.Called(ctx, secureAccountCode)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.SecureAccountCode) error); ok {
+		r0 = rf(ctx, secureAccountCode)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DecideDeviceAuthorization provides a mock function with given fields: ctx, deviceAuthorization
+func (_m *Repository) DecideDeviceAuthorization(ctx context.Context, deviceAuthorization *models.DeviceAuthorization) error {
+	ret := _m.Called(ctx, deviceAuthorization)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.DeviceAuthorization) error); ok {
+		r0 = rf(ctx, deviceAuthorization)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteAuditEventsBefore provides a mock function with given fields: ctx, _a1
+func (_m *Repository) DeleteAuditEventsBefore(ctx context.Context, _a1 time.Time) error {
+	ret := _m.Called(ctx, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) error); ok {
+		r0 = rf(ctx, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteDeviceAuthorization provides a mock function with given fields: ctx, deviceAuthorization
+func (_m *Repository) DeleteDeviceAuthorization(ctx context.Context, deviceAuthorization *models.DeviceAuthorization) error {
+	ret := _m.Called(ctx, deviceAuthorization)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.DeviceAuthorization) error); ok {
+		r0 = rf(ctx, deviceAuthorization)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteEmailDomainRule provides a mock function with given fields: ctx, emailDomainRule
+func (_m *Repository) DeleteEmailDomainRule(ctx context.Context, emailDomainRule *models.EmailDomainRule) error {
+	ret := _m.Called(ctx, emailDomainRule)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.EmailDomainRule) error); ok {
+		r0 = rf(ctx, emailDomainRule)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteOAuthRefreshToken provides a mock function with given fields: ctx, oAuthRefreshToken, result
+func (_m *Repository) DeleteOAuthRefreshToken(ctx context.Context, oAuthRefreshToken *models.OAuthRefreshToken, result *bool) error {
+	ret := _m.Called(ctx, oAuthRefreshToken, result)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.OAuthRefreshToken, *bool) error); ok {
+		r0 = rf(ctx, oAuthRefreshToken, result)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeletePermission provides a mock function with given fields: ctx, permission
+func (_m *Repository) DeletePermission(ctx context.Context, permission *models.Permission) error {
+	ret := _m.Called(ctx, permission)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Permission) error); ok {
+		r0 = rf(ctx, permission)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeletePersonalAccessToken provides a mock function with given fields: ctx, personalAccessToken
+func (_m *Repository) DeletePersonalAccessToken(ctx context.Context, personalAccessToken *models.PersonalAccessToken) error {
+	ret := _m.Called(ctx, personalAccessToken)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.PersonalAccessToken) error); ok {
+		r0 = rf(ctx, personalAccessToken)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteRole provides a mock function with given fields: ctx, role
+func (_m *Repository) DeleteRole(ctx context.Context, role *models.Role) error {
+	ret := _m.Called(ctx, role)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Role) error); ok {
+		r0 = rf(ctx, role)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteUser provides a mock function with given fields: ctx, statusChange
+func (_m *Repository) DeleteUser(ctx context.Context, statusChange *models.StatusChange) error {
+	ret := _m.Called(ctx, statusChange)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.StatusChange) error); ok {
+		r0 = rf(ctx, statusChange)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteUserRole provides a mock function with given fields: ctx, userRole
+func (_m *Repository) DeleteUserRole(ctx context.Context, userRole *models.UserRole) error {
+	ret := _m.Called(ctx, userRole)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.UserRole) error); ok {
+		r0 = rf(ctx, userRole)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ExtendSession provides a mock function with given fields: ctx, user
+func (_m *Repository) ExtendSession(ctx context.Context, user *models.User) error {
+	ret := _m.Called(ctx, user)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.User) error); ok {
+		r0 = rf(ctx, user)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ForgetKnownDevice provides a mock function with given fields: ctx, knownDevice
+func (_m *Repository) ForgetKnownDevice(ctx context.Context, knownDevice *models.KnownDevice) error {
+	ret := _m.Called(ctx, knownDevice)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.KnownDevice) error); ok {
+		r0 = rf(ctx, knownDevice)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAdminUser provides a mock function with given fields: ctx, adminUser
+func (_m *Repository) GetAdminUser(ctx context.Context, adminUser *models.AdminUser) error {
+	ret := _m.Called(ctx, adminUser)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.AdminUser) error); ok {
+		r0 = rf(ctx, adminUser)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAuditEvents provides a mock function with given fields: ctx, auditFilter
+func (_m *Repository) GetAuditEvents(ctx context.Context, auditFilter *models.AuditFilter) (*models.AuditPage, error) {
+	ret := _m.Called(ctx, auditFilter)
+
+	var r0 *models.AuditPage
+	if rf, ok := ret.Get(0).(func(context.Context, *models.AuditFilter) *models.AuditPage); ok {
+		r0 = rf(ctx, auditFilter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.AuditPage)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *models.AuditFilter) error); ok {
+		r1 = rf(ctx, auditFilter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetClient provides a mock function with given fields: ctx, oAuthClient
+func (_m *Repository) GetClient(ctx context.Context, oAuthClient *models.OAuthClient) error {
+	ret := _m.Called(ctx, oAuthClient)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.OAuthClient) error); ok {
+		r0 = rf(ctx, oAuthClient)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetDeviceAuthorization provides a mock function with given fields: ctx, deviceAuthorization
+func (_m *Repository) GetDeviceAuthorization(ctx context.Context, deviceAuthorization *models.DeviceAuthorization) error {
+	ret := _m.Called(ctx, deviceAuthorization)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.DeviceAuthorization) error); ok {
+		r0 = rf(ctx, deviceAuthorization)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetEmailDomainRules provides a mock function with given fields: ctx
+func (_m *Repository) GetEmailDomainRules(ctx context.Context) ([]models.EmailDomainRule, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []models.EmailDomainRule
+	if rf, ok := ret.Get(0).(func(context.Context) []models.EmailDomainRule); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.EmailDomainRule)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetIdentity provides a mock function with given fields: ctx, identity, result
+func (_m *Repository) GetIdentity(ctx context.Context, identity *models.Identity, result *bool) error {
+	ret := _m.Called(ctx, identity, result)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Identity, *bool) error); ok {
+		r0 = rf(ctx, identity, result)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetOAuthRefreshToken provides a mock function with given fields: ctx, oAuthRefreshToken, result
+func (_m *Repository) GetOAuthRefreshToken(ctx context.Context, oAuthRefreshToken *models.OAuthRefreshToken, result *bool) error {
+	ret := _m.Called(ctx, oAuthRefreshToken, result)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.OAuthRefreshToken, *bool) error); ok {
+		r0 = rf(ctx, oAuthRefreshToken, result)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetPassword provides a mock function with given fields: ctx, user
+func (_m *Repository) GetPassword(ctx context.Context, user *models.User) error {
+	ret := _m.Called(ctx, user)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.User) error); ok {
+		r0 = rf(ctx, user)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetPermissions provides a mock function with given fields: ctx
+func (_m *Repository) GetPermissions(ctx context.Context) ([]models.Permission, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []models.Permission
+	if rf, ok := ret.Get(0).(func(context.Context) []models.Permission); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Permission)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPersonalAccessTokens provides a mock function with given fields: ctx, user
+func (_m *Repository) GetPersonalAccessTokens(ctx context.Context, user *models.User) ([]models.PersonalAccessToken, error) {
+	ret := _m.Called(ctx, user)
+
+	var r0 []models.PersonalAccessToken
+	if rf, ok := ret.Get(0).(func(context.Context, *models.User) []models.PersonalAccessToken); ok {
+		r0 = rf(ctx, user)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.PersonalAccessToken)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *models.User) error); ok {
+		r1 = rf(ctx, user)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetProfile provides a mock function with given fields: ctx, profile
+func (_m *Repository) GetProfile(ctx context.Context, profile *models.Profile) error {
+	ret := _m.Called(ctx, profile)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Profile) error); ok {
+		r0 = rf(ctx, profile)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetRoles provides a mock function with given fields: ctx
+func (_m *Repository) GetRoles(ctx context.Context) ([]models.Role, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []models.Role
+	if rf, ok := ret.Get(0).(func(context.Context) []models.Role); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Role)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSignInUser provides a mock function with given fields: ctx, user
+func (_m *Repository) GetSignInUser(ctx context.Context, user *models.User) (*models.User, error) {
+	ret := _m.Called(ctx, user)
+
+	var r0 *models.User
+	if rf, ok := ret.Get(0).(func(context.Context, *models.User) *models.User); ok {
+		r0 = rf(ctx, user)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.User)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *models.User) error); ok {
+		r1 = rf(ctx, user)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUUid provides a mock function with given fields: ctx, user
+func (_m *Repository) GetUUid(ctx context.Context, user *models.User) error {
+	ret := _m.Called(ctx, user)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.User) error); ok {
+		r0 = rf(ctx, user)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetUserAccess provides a mock function with given fields: ctx, user
+func (_m *Repository) GetUserAccess(ctx context.Context, user *models.User) error {
 	ret := _m.Called(ctx, user)
 
 	var r0 error
@@ -42,22 +685,50 @@ func (_m *Repository) GetUUid(ctx context.Context, user *models.User) error {
 	return r0
 }
 
-// GetVerifiedUser provides a mock function with given fields: ctx, user
-func (_m *Repository) GetVerifiedUser(ctx context.Context, user *models.User) (*models.User, error) {
+// GetUserByEmail provides a mock function with given fields: ctx, user, result
+func (_m *Repository) GetUserByEmail(ctx context.Context, user *models.User, result *bool) error {
+	ret := _m.Called(ctx, user, result)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.User, *bool) error); ok {
+		r0 = rf(ctx, user, result)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetUserById provides a mock function with given fields: ctx, user
+func (_m *Repository) GetUserById(ctx context.Context, user *models.User) error {
 	ret := _m.Called(ctx, user)
 
-	var r0 *models.User
-	if rf, ok := ret.Get(0).(func(context.Context, *models.User) *models.User); ok {
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.User) error); ok {
 		r0 = rf(ctx, user)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GrantableScopes provides a mock function with given fields: ctx, user, _a2
+func (_m *Repository) GrantableScopes(ctx context.Context, user *models.User, _a2 []string) ([]string, error) {
+	ret := _m.Called(ctx, user, _a2)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(context.Context, *models.User, []string) []string); ok {
+		r0 = rf(ctx, user, _a2)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*models.User)
+			r0 = ret.Get(0).([]string)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, *models.User) error); ok {
-		r1 = rf(ctx, user)
+	if rf, ok := ret.Get(1).(func(context.Context, *models.User, []string) error); ok {
+		r1 = rf(ctx, user, _a2)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -65,6 +736,34 @@ func (_m *Repository) GetVerifiedUser(ctx context.Context, user *models.User) (*
 	return r0, r1
 }
 
+// IfConsentExists provides a mock function with given fields: ctx, consent, result
+func (_m *Repository) IfConsentExists(ctx context.Context, consent *models.Consent, result *bool) error {
+	ret := _m.Called(ctx, consent, result)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Consent, *bool) error); ok {
+		r0 = rf(ctx, consent, result)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// IfTokenRevoked provides a mock function with given fields: ctx, revokedToken, result
+func (_m *Repository) IfTokenRevoked(ctx context.Context, revokedToken *models.RevokedToken, result *bool) error {
+	ret := _m.Called(ctx, revokedToken, result)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.RevokedToken, *bool) error); ok {
+		r0 = rf(ctx, revokedToken, result)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // IfUnverifiedUserExists provides a mock function with given fields: ctx, user, result
 func (_m *Repository) IfUnverifiedUserExists(ctx context.Context, user *models.User, result *bool) error {
 	ret := _m.Called(ctx, user, result)
@@ -79,6 +778,169 @@ func (_m *Repository) IfUnverifiedUserExists(ctx context.Context, user *models.U
 	return r0
 }
 
+// IfUserNameExists provides a mock function with given fields: ctx, user, result
+func (_m *Repository) IfUserNameExists(ctx context.Context, user *models.User, result *bool) error {
+	ret := _m.Called(ctx, user, result)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.User, *bool) error); ok {
+		r0 = rf(ctx, user, result)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// IfVerifiedUserExists provides a mock function with given fields: ctx, user, result
+func (_m *Repository) IfVerifiedUserExists(ctx context.Context, user *models.User, result *bool) error {
+	ret := _m.Called(ctx, user, result)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.User, *bool) error); ok {
+		r0 = rf(ctx, user, result)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MatchEmailDomainRule provides a mock function with given fields: ctx, _a1, emailDomainRule, result
+func (_m *Repository) MatchEmailDomainRule(ctx context.Context, _a1 string, emailDomainRule *models.EmailDomainRule, result *bool) error {
+	ret := _m.Called(ctx, _a1, emailDomainRule, result)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *models.EmailDomainRule, *bool) error); ok {
+		r0 = rf(ctx, _a1, emailDomainRule, result)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// PollDeviceAuthorization provides a mock function with given fields: ctx, deviceAuthorization
+func (_m *Repository) PollDeviceAuthorization(ctx context.Context, deviceAuthorization *models.DeviceAuthorization) error {
+	ret := _m.Called(ctx, deviceAuthorization)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.DeviceAuthorization) error); ok {
+		r0 = rf(ctx, deviceAuthorization)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// PutEmailDomainRule provides a mock function with given fields: ctx, emailDomainRule
+func (_m *Repository) PutEmailDomainRule(ctx context.Context, emailDomainRule *models.EmailDomainRule) error {
+	ret := _m.Called(ctx, emailDomainRule)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.EmailDomainRule) error); ok {
+		r0 = rf(ctx, emailDomainRule)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// PutPermission provides a mock function with given fields: ctx, permission
+func (_m *Repository) PutPermission(ctx context.Context, permission *models.Permission) error {
+	ret := _m.Called(ctx, permission)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Permission) error); ok {
+		r0 = rf(ctx, permission)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// PutRole provides a mock function with given fields: ctx, role
+func (_m *Repository) PutRole(ctx context.Context, role *models.Role) error {
+	ret := _m.Called(ctx, role)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Role) error); ok {
+		r0 = rf(ctx, role)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RevokeSessions provides a mock function with given fields: ctx, user
+func (_m *Repository) RevokeSessions(ctx context.Context, user *models.User) error {
+	ret := _m.Called(ctx, user)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.User) error); ok {
+		r0 = rf(ctx, user)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SearchUsers provides a mock function with given fields: ctx, userFilter
+func (_m *Repository) SearchUsers(ctx context.Context, userFilter *models.UserFilter) (*models.UserPage, error) {
+	ret := _m.Called(ctx, userFilter)
+
+	var r0 *models.UserPage
+	if rf, ok := ret.Get(0).(func(context.Context, *models.UserFilter) *models.UserPage); ok {
+		r0 = rf(ctx, userFilter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.UserPage)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *models.UserFilter) error); ok {
+		r1 = rf(ctx, userFilter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetUserStatus provides a mock function with given fields: ctx, statusChange
+func (_m *Repository) SetUserStatus(ctx context.Context, statusChange *models.StatusChange) error {
+	ret := _m.Called(ctx, statusChange)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.StatusChange) error); ok {
+		r0 = rf(ctx, statusChange)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// TouchKnownDevice provides a mock function with given fields: ctx, knownDevice, result
+func (_m *Repository) TouchKnownDevice(ctx context.Context, knownDevice *models.KnownDevice, result *bool) error {
+	ret := _m.Called(ctx, knownDevice, result)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.KnownDevice, *bool) error); ok {
+		r0 = rf(ctx, knownDevice, result)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateCredentials provides a mock function with given fields: ctx, user
 func (_m *Repository) UpdateCredentials(ctx context.Context, user *models.User) error {
 	ret := _m.Called(ctx, user)
@@ -93,6 +955,34 @@ func (_m *Repository) UpdateCredentials(ctx context.Context, user *models.User)
 	return r0
 }
 
+// UpdatePassword provides a mock function with given fields: ctx, user
+func (_m *Repository) UpdatePassword(ctx context.Context, user *models.User) error {
+	ret := _m.Called(ctx, user)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.User) error); ok {
+		r0 = rf(ctx, user)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateProfile provides a mock function with given fields: ctx, profileUpdate
+func (_m *Repository) UpdateProfile(ctx context.Context, profileUpdate *models.ProfileUpdate) error {
+	ret := _m.Called(ctx, profileUpdate)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.ProfileUpdate) error); ok {
+		r0 = rf(ctx, profileUpdate)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateRefreshToken provides a mock function with given fields: ctx, user
 func (_m *Repository) UpdateRefreshToken(ctx context.Context, user *models.User) error {
 	ret := _m.Called(ctx, user)
@@ -107,6 +997,34 @@ func (_m *Repository) UpdateRefreshToken(ctx context.Context, user *models.User)
 	return r0
 }
 
+// UseChallenge provides a mock function with given fields: ctx, usedChallenge
+func (_m *Repository) UseChallenge(ctx context.Context, usedChallenge *models.UsedChallenge) error {
+	ret := _m.Called(ctx, usedChallenge)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.UsedChallenge) error); ok {
+		r0 = rf(ctx, usedChallenge)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UsePersonalAccessToken provides a mock function with given fields: ctx, personalAccessToken, result
+func (_m *Repository) UsePersonalAccessToken(ctx context.Context, personalAccessToken *models.PersonalAccessToken, result *bool) error {
+	ret := _m.Called(ctx, personalAccessToken, result)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.PersonalAccessToken, *bool) error); ok {
+		r0 = rf(ctx, personalAccessToken, result)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // VerifyUser provides a mock function with given fields: ctx, user
 func (_m *Repository) VerifyUser(ctx context.Context, user *models.User) error {
 	ret := _m.Called(ctx, user)
@@ -6,6 +6,7 @@ import (
 	context "context"
 
 	models "github.com/Kin-dza-dzaa/userApi/internal/models"
+	uuid "github.com/google/uuid"
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -14,6 +15,224 @@ type Service struct {
 	mock.Mock
 }
 
+// AdminDeleteUser provides a mock function with given fields: ctx, adminAction
+func (_m *Service) AdminDeleteUser(ctx context.Context, adminAction *models.AdminAction) error {
+	ret := _m.Called(ctx, adminAction)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.AdminAction) error); ok {
+		r0 = rf(ctx, adminAction)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// AdminVerifyUser provides a mock function with given fields: ctx, adminAction
+func (_m *Service) AdminVerifyUser(ctx context.Context, adminAction *models.AdminAction) error {
+	ret := _m.Called(ctx, adminAction)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.AdminAction) error); ok {
+		r0 = rf(ctx, adminAction)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ApproveDevice provides a mock function with given fields: ctx, deviceApproval
+func (_m *Service) ApproveDevice(ctx context.Context, deviceApproval *models.DeviceApproval) error {
+	ret := _m.Called(ctx, deviceApproval)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.DeviceApproval) error); ok {
+		r0 = rf(ctx, deviceApproval)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Authenticate provides a mock function with given fields: ctx, _a1
+func (_m *Service) Authenticate(ctx context.Context, _a1 string) (*models.MyJwtClaims, error) {
+	ret := _m.Called(ctx, _a1)
+
+	var r0 *models.MyJwtClaims
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.MyJwtClaims); ok {
+		r0 = rf(ctx, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.MyJwtClaims)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AuthorizeDevice provides a mock function with given fields: ctx, deviceAuthorizationRequest
+func (_m *Service) AuthorizeDevice(ctx context.Context, deviceAuthorizationRequest *models.DeviceAuthorizationRequest) (*models.DeviceAuthorizationResponse, error) {
+	ret := _m.Called(ctx, deviceAuthorizationRequest)
+
+	var r0 *models.DeviceAuthorizationResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *models.DeviceAuthorizationRequest) *models.DeviceAuthorizationResponse); ok {
+		r0 = rf(ctx, deviceAuthorizationRequest)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.DeviceAuthorizationResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *models.DeviceAuthorizationRequest) error); ok {
+		r1 = rf(ctx, deviceAuthorizationRequest)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// BeginSocialLogin provides a mock function with given fields: ctx, _a1
+func (_m *Service) BeginSocialLogin(ctx context.Context, _a1 string) (*models.SocialLogin, error) {
+	ret := _m.Called(ctx, _a1)
+
+	var r0 *models.SocialLogin
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.SocialLogin); ok {
+		r0 = rf(ctx, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.SocialLogin)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CompleteSocialLogin provides a mock function with given fields: ctx, socialCallback
+func (_m *Service) CompleteSocialLogin(ctx context.Context, socialCallback *models.SocialCallback) (*models.User, error) {
+	ret := _m.Called(ctx, socialCallback)
+
+	var r0 *models.User
+	if rf, ok := ret.Get(0).(func(context.Context, *models.SocialCallback) *models.User); ok {
+		r0 = rf(ctx, socialCallback)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.User)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *models.SocialCallback) error); ok {
+		r1 = rf(ctx, socialCallback)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreatePersonalAccessToken provides a mock function with given fields: ctx, personalAccessToken
+func (_m *Service) CreatePersonalAccessToken(ctx context.Context, personalAccessToken *models.PersonalAccessToken) error {
+	ret := _m.Called(ctx, personalAccessToken)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.PersonalAccessToken) error); ok {
+		r0 = rf(ctx, personalAccessToken)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteEmailDomainRule provides a mock function with given fields: ctx, emailDomainRule
+func (_m *Service) DeleteEmailDomainRule(ctx context.Context, emailDomainRule *models.EmailDomainRule) error {
+	ret := _m.Called(ctx, emailDomainRule)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.EmailDomainRule) error); ok {
+		r0 = rf(ctx, emailDomainRule)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeletePermission provides a mock function with given fields: ctx, permission
+func (_m *Service) DeletePermission(ctx context.Context, permission *models.Permission) error {
+	ret := _m.Called(ctx, permission)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Permission) error); ok {
+		r0 = rf(ctx, permission)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeletePersonalAccessToken provides a mock function with given fields: ctx, personalAccessToken
+func (_m *Service) DeletePersonalAccessToken(ctx context.Context, personalAccessToken *models.PersonalAccessToken) error {
+	ret := _m.Called(ctx, personalAccessToken)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.PersonalAccessToken) error); ok {
+		r0 = rf(ctx, personalAccessToken)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteRole provides a mock function with given fields: ctx, role
+func (_m *Service) DeleteRole(ctx context.Context, role *models.Role) error {
+	ret := _m.Called(ctx, role)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Role) error); ok {
+		r0 = rf(ctx, role)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ForceLogout provides a mock function with given fields: ctx, adminAction
+func (_m *Service) ForceLogout(ctx context.Context, adminAction *models.AdminAction) error {
+	ret := _m.Called(ctx, adminAction)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.AdminAction) error); ok {
+		r0 = rf(ctx, adminAction)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // GetAccessToken provides a mock function with given fields: ctx, user
 func (_m *Service) GetAccessToken(ctx context.Context, user *models.User) error {
 	ret := _m.Called(ctx, user)
@@ -28,6 +247,559 @@ func (_m *Service) GetAccessToken(ctx context.Context, user *models.User) error
 	return r0
 }
 
+// GetAdminUser provides a mock function with given fields: ctx, uUID, adminUser
+func (_m *Service) GetAdminUser(ctx context.Context, uUID uuid.UUID, adminUser *models.AdminUser) error {
+	ret := _m.Called(ctx, uUID, adminUser)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, *models.AdminUser) error); ok {
+		r0 = rf(ctx, uUID, adminUser)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAuditEvents provides a mock function with given fields: ctx, auditFilter
+func (_m *Service) GetAuditEvents(ctx context.Context, auditFilter *models.AuditFilter) (*models.AuditPage, error) {
+	ret := _m.Called(ctx, auditFilter)
+
+	var r0 *models.AuditPage
+	if rf, ok := ret.Get(0).(func(context.Context, *models.AuditFilter) *models.AuditPage); ok {
+		r0 = rf(ctx, auditFilter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.AuditPage)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *models.AuditFilter) error); ok {
+		r1 = rf(ctx, auditFilter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetDevicePrompt provides a mock function with given fields: ctx, _a1
+func (_m *Service) GetDevicePrompt(ctx context.Context, _a1 string) (*models.DevicePrompt, error) {
+	ret := _m.Called(ctx, _a1)
+
+	var r0 *models.DevicePrompt
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.DevicePrompt); ok {
+		r0 = rf(ctx, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.DevicePrompt)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetEmailDomainRules provides a mock function with given fields: ctx
+func (_m *Service) GetEmailDomainRules(ctx context.Context) ([]models.EmailDomainRule, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []models.EmailDomainRule
+	if rf, ok := ret.Get(0).(func(context.Context) []models.EmailDomainRule); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.EmailDomainRule)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPermissions provides a mock function with given fields: ctx
+func (_m *Service) GetPermissions(ctx context.Context) ([]models.Permission, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []models.Permission
+	if rf, ok := ret.Get(0).(func(context.Context) []models.Permission); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Permission)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPersonalAccessTokens provides a mock function with given fields: ctx, user
+func (_m *Service) GetPersonalAccessTokens(ctx context.Context, user *models.User) ([]models.PersonalAccessToken, error) {
+	ret := _m.Called(ctx, user)
+
+	var r0 []models.PersonalAccessToken
+	if rf, ok := ret.Get(0).(func(context.Context, *models.User) []models.PersonalAccessToken); ok {
+		r0 = rf(ctx, user)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.PersonalAccessToken)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *models.User) error); ok {
+		r1 = rf(ctx, user)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetProfile provides a mock function with given fields: ctx, user
+func (_m *Service) GetProfile(ctx context.Context, user *models.User) (*models.Profile, error) {
+	ret := _m.Called(ctx, user)
+
+	var r0 *models.Profile
+	if rf, ok := ret.Get(0).(func(context.Context, *models.User) *models.Profile); ok {
+		r0 = rf(ctx, user)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Profile)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *models.User) error); ok {
+		r1 = rf(ctx, user)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRoles provides a mock function with given fields: ctx
+func (_m *Service) GetRoles(ctx context.Context) ([]models.Role, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []models.Role
+	if rf, ok := ret.Get(0).(func(context.Context) []models.Role); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Role)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserActivity provides a mock function with given fields: ctx, user, auditFilter
+func (_m *Service) GetUserActivity(ctx context.Context, user *models.User, auditFilter *models.AuditFilter) (*models.AuditPage, error) {
+	ret := _m.Called(ctx, user, auditFilter)
+
+	var r0 *models.AuditPage
+	if rf, ok := ret.Get(0).(func(context.Context, *models.User, *models.AuditFilter) *models.AuditPage); ok {
+		r0 = rf(ctx, user, auditFilter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.AuditPage)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *models.User, *models.AuditFilter) error); ok {
+		r1 = rf(ctx, user, auditFilter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserInfo provides a mock function with given fields: ctx, _a1
+func (_m *Service) GetUserInfo(ctx context.Context, _a1 string) (*models.UserInfo, error) {
+	ret := _m.Called(ctx, _a1)
+
+	var r0 *models.UserInfo
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.UserInfo); ok {
+		r0 = rf(ctx, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.UserInfo)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserRoles provides a mock function with given fields: ctx, user
+func (_m *Service) GetUserRoles(ctx context.Context, user *models.User) error {
+	ret := _m.Called(ctx, user)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.User) error); ok {
+		r0 = rf(ctx, user)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GrantConsent provides a mock function with given fields: ctx, consent
+func (_m *Service) GrantConsent(ctx context.Context, consent *models.Consent) error {
+	ret := _m.Called(ctx, consent)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Consent) error); ok {
+		r0 = rf(ctx, consent)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GrantRole provides a mock function with given fields: ctx, userRole
+func (_m *Service) GrantRole(ctx context.Context, userRole *models.UserRole) error {
+	ret := _m.Called(ctx, userRole)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.UserRole) error); ok {
+		r0 = rf(ctx, userRole)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// HasConsent provides a mock function with given fields: ctx, consent
+func (_m *Service) HasConsent(ctx context.Context, consent *models.Consent) (bool, error) {
+	ret := _m.Called(ctx, consent)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Consent) bool); ok {
+		r0 = rf(ctx, consent)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *models.Consent) error); ok {
+		r1 = rf(ctx, consent)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IntrospectToken provides a mock function with given fields: ctx, introspectionRequest
+func (_m *Service) IntrospectToken(ctx context.Context, introspectionRequest *models.IntrospectionRequest) (*models.Introspection, error) {
+	ret := _m.Called(ctx, introspectionRequest)
+
+	var r0 *models.Introspection
+	if rf, ok := ret.Get(0).(func(context.Context, *models.IntrospectionRequest) *models.Introspection); ok {
+		r0 = rf(ctx, introspectionRequest)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Introspection)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *models.IntrospectionRequest) error); ok {
+		r1 = rf(ctx, introspectionRequest)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IssueAuthorizationCode provides a mock function with given fields: ctx, authorizationCode
+func (_m *Service) IssueAuthorizationCode(ctx context.Context, authorizationCode *models.AuthorizationCode) error {
+	ret := _m.Called(ctx, authorizationCode)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.AuthorizationCode) error); ok {
+		r0 = rf(ctx, authorizationCode)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Jwks provides a mock function with given fields: ctx
+func (_m *Service) Jwks(ctx context.Context) (*models.Jwks, error) {
+	ret := _m.Called(ctx)
+
+	var r0 *models.Jwks
+	if rf, ok := ret.Get(0).(func(context.Context) *models.Jwks); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Jwks)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// LockUser provides a mock function with given fields: ctx, adminAction
+func (_m *Service) LockUser(ctx context.Context, adminAction *models.AdminAction) error {
+	ret := _m.Called(ctx, adminAction)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.AdminAction) error); ok {
+		r0 = rf(ctx, adminAction)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewChallenge provides a mock function with given fields: ctx, _a1
+func (_m *Service) NewChallenge(ctx context.Context, _a1 string) (*models.Challenge, error) {
+	ret := _m.Called(ctx, _a1)
+
+	var r0 *models.Challenge
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.Challenge); ok {
+		r0 = rf(ctx, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Challenge)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ParseAccessToken provides a mock function with given fields: ctx, _a1
+func (_m *Service) ParseAccessToken(ctx context.Context, _a1 string) (*models.MyJwtClaims, error) {
+	ret := _m.Called(ctx, _a1)
+
+	var r0 *models.MyJwtClaims
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.MyJwtClaims); ok {
+		r0 = rf(ctx, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.MyJwtClaims)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PutEmailDomainRule provides a mock function with given fields: ctx, emailDomainRule
+func (_m *Service) PutEmailDomainRule(ctx context.Context, emailDomainRule *models.EmailDomainRule) error {
+	ret := _m.Called(ctx, emailDomainRule)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.EmailDomainRule) error); ok {
+		r0 = rf(ctx, emailDomainRule)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// PutPermission provides a mock function with given fields: ctx, permission
+func (_m *Service) PutPermission(ctx context.Context, permission *models.Permission) error {
+	ret := _m.Called(ctx, permission)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Permission) error); ok {
+		r0 = rf(ctx, permission)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// PutRole provides a mock function with given fields: ctx, role
+func (_m *Service) PutRole(ctx context.Context, role *models.Role) error {
+	ret := _m.Called(ctx, role)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Role) error); ok {
+		r0 = rf(ctx, role)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Reauthenticate provides a mock function with given fields: ctx, user
+func (_m *Service) Reauthenticate(ctx context.Context, user *models.User) error {
+	ret := _m.Called(ctx, user)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.User) error); ok {
+		r0 = rf(ctx, user)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ResetPassword provides a mock function with given fields: ctx, passwordReset
+func (_m *Service) ResetPassword(ctx context.Context, passwordReset *models.PasswordReset) error {
+	ret := _m.Called(ctx, passwordReset)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.PasswordReset) error); ok {
+		r0 = rf(ctx, passwordReset)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RevokeAccessToken provides a mock function with given fields: ctx, _a1
+func (_m *Service) RevokeAccessToken(ctx context.Context, _a1 string) error {
+	ret := _m.Called(ctx, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RevokeRole provides a mock function with given fields: ctx, userRole
+func (_m *Service) RevokeRole(ctx context.Context, userRole *models.UserRole) error {
+	ret := _m.Called(ctx, userRole)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.UserRole) error); ok {
+		r0 = rf(ctx, userRole)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RevokeToken provides a mock function with given fields: ctx, revocationRequest
+func (_m *Service) RevokeToken(ctx context.Context, revocationRequest *models.RevocationRequest) error {
+	ret := _m.Called(ctx, revocationRequest)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.RevocationRequest) error); ok {
+		r0 = rf(ctx, revocationRequest)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SearchUsers provides a mock function with given fields: ctx, uUID, userFilter
+func (_m *Service) SearchUsers(ctx context.Context, uUID uuid.UUID, userFilter *models.UserFilter) (*models.UserPage, error) {
+	ret := _m.Called(ctx, uUID, userFilter)
+
+	var r0 *models.UserPage
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, *models.UserFilter) *models.UserPage); ok {
+		r0 = rf(ctx, uUID, userFilter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.UserPage)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, *models.UserFilter) error); ok {
+		r1 = rf(ctx, uUID, userFilter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SecureAccount provides a mock function with given fields: ctx, secureAccountCode
+func (_m *Service) SecureAccount(ctx context.Context, secureAccountCode *models.SecureAccountCode) error {
+	ret := _m.Called(ctx, secureAccountCode)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.SecureAccountCode) error); ok {
+		r0 = rf(ctx, secureAccountCode)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SignInUser provides a mock function with given fields: ctx, user
 func (_m *Service) SignInUser(ctx context.Context, user *models.User) error {
 	ret := _m.Called(ctx, user)
@@ -56,6 +828,166 @@ func (_m *Service) SignUpUser(ctx context.Context, user *models.User) error {
 	return r0
 }
 
+// SuspendUser provides a mock function with given fields: ctx, adminAction
+func (_m *Service) SuspendUser(ctx context.Context, adminAction *models.AdminAction) error {
+	ret := _m.Called(ctx, adminAction)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.AdminAction) error); ok {
+		r0 = rf(ctx, adminAction)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Token provides a mock function with given fields: ctx, tokenRequest
+func (_m *Service) Token(ctx context.Context, tokenRequest *models.TokenRequest) (*models.TokenResponse, error) {
+	ret := _m.Called(ctx, tokenRequest)
+
+	var r0 *models.TokenResponse
+	if rf, ok := ret.Get(0).(func(context.Context, *models.TokenRequest) *models.TokenResponse); ok {
+		r0 = rf(ctx, tokenRequest)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.TokenResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *models.TokenRequest) error); ok {
+		r1 = rf(ctx, tokenRequest)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// TriggerPasswordReset provides a mock function with given fields: ctx, adminAction
+func (_m *Service) TriggerPasswordReset(ctx context.Context, adminAction *models.AdminAction) error {
+	ret := _m.Called(ctx, adminAction)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.AdminAction) error); ok {
+		r0 = rf(ctx, adminAction)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UnlockUser provides a mock function with given fields: ctx, adminAction
+func (_m *Service) UnlockUser(ctx context.Context, adminAction *models.AdminAction) error {
+	ret := _m.Called(ctx, adminAction)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.AdminAction) error); ok {
+		r0 = rf(ctx, adminAction)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UnsuspendUser provides a mock function with given fields: ctx, adminAction
+func (_m *Service) UnsuspendUser(ctx context.Context, adminAction *models.AdminAction) error {
+	ret := _m.Called(ctx, adminAction)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.AdminAction) error); ok {
+		r0 = rf(ctx, adminAction)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateProfile provides a mock function with given fields: ctx, profileUpdate
+func (_m *Service) UpdateProfile(ctx context.Context, profileUpdate *models.ProfileUpdate) (*models.Profile, error) {
+	ret := _m.Called(ctx, profileUpdate)
+
+	var r0 *models.Profile
+	if rf, ok := ret.Get(0).(func(context.Context, *models.ProfileUpdate) *models.Profile); ok {
+		r0 = rf(ctx, profileUpdate)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Profile)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *models.ProfileUpdate) error); ok {
+		r1 = rf(ctx, profileUpdate)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UserNameAvailable provides a mock function with given fields: ctx, user
+func (_m *Service) UserNameAvailable(ctx context.Context, user *models.User) (bool, error) {
+	ret := _m.Called(ctx, user)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, *models.User) bool); ok {
+		r0 = rf(ctx, user)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *models.User) error); ok {
+		r1 = rf(ctx, user)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ValidateAuthorizationRequest provides a mock function with given fields: ctx, authorizationRequest
+func (_m *Service) ValidateAuthorizationRequest(ctx context.Context, authorizationRequest *models.AuthorizationRequest) (*models.OAuthClient, error) {
+	ret := _m.Called(ctx, authorizationRequest)
+
+	var r0 *models.OAuthClient
+	if rf, ok := ret.Get(0).(func(context.Context, *models.AuthorizationRequest) *models.OAuthClient); ok {
+		r0 = rf(ctx, authorizationRequest)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.OAuthClient)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *models.AuthorizationRequest) error); ok {
+		r1 = rf(ctx, authorizationRequest)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// VerifyChallenge provides a mock function with given fields: ctx, challengeResponse
+func (_m *Service) VerifyChallenge(ctx context.Context, challengeResponse *models.ChallengeResponse) error {
+	ret := _m.Called(ctx, challengeResponse)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.ChallengeResponse) error); ok {
+		r0 = rf(ctx, challengeResponse)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // VerifyUser provides a mock function with given fields: ctx, user
 func (_m *Service) VerifyUser(ctx context.Context, user *models.User) error {
 	ret := _m.Called(ctx, user)
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	// empty filters are passed as NULL and match every user
+	userFilterWhere = ` FROM users WHERE ($1::text IS NULL OR email ILIKE $1) AND ($2::text IS NULL OR user_name ILIKE $2) AND ($3::bool IS NULL OR (status <> 'pending') = $3)
+		AND ($4::timestamp IS NULL OR registration_date >= $4) AND ($5::timestamp IS NULL OR registration_date < $5) AND ($6::text IS NULL OR status = $6)`
+	queryCountUsers   = "SELECT count(*)" + userFilterWhere + ";"
+	querySearchUsers  = "SELECT id, user_name, email, status, status_reason, status_changed_at, registration_date, sessions_revoked_at" + userFilterWhere + " ORDER BY registration_date DESC, id LIMIT $7 OFFSET $8;"
+	queryGetAdminUser = "SELECT user_name, email, status, status_reason, status_changed_at, registration_date, sessions_revoked_at, ARRAY(SELECT role FROM user_roles WHERE user_id = users.id ORDER BY role) FROM users WHERE id = $1;"
+	// the status only changes if it is still the one the transition was checked against
+	querySetUserStatus = "UPDATE users SET status = $3, status_reason = $4, status_changed_at = $5, verification_code = '' WHERE id = $1 AND status = $2;"
+	// sessions_revoked_at revokes access tokens issued before it, the refresh token of the session expires and OAuth refresh tokens are deleted
+	queryRevokeSessions = "WITH oauth AS (DELETE FROM oauth_refresh_tokens WHERE user_id = $1) UPDATE users SET sessions_revoked_at = $2, expiration_time = $2 WHERE id = $1;"
+	// deleted users keep their row for the audit trail, everything that signs them in is dropped and the email and user name are freed
+	queryDeleteUser = `WITH identities AS (DELETE FROM user_identities WHERE user_id = $1), tokens AS (DELETE FROM personal_access_tokens WHERE user_id = $1),
+		oauth AS (DELETE FROM oauth_refresh_tokens WHERE user_id = $1), roles AS (DELETE FROM user_roles WHERE user_id = $1),
+		devices AS (DELETE FROM known_devices WHERE user_id = $1), codes AS (DELETE FROM secure_account_codes WHERE user_id = $1)
+		UPDATE users SET status = $3, status_reason = $4, status_changed_at = $5, email = id || '@deleted.invalid', user_name = 'deleted-' || id,
+		user_name_canonical = 'deleted-' || id, password = '!', refresh_token = NULL, expiration_time = NULL, verification_code = '', sessions_revoked_at = $5 WHERE id = $1 AND status = $2;`
+	queryUpdatePassword       = "UPDATE users SET password = $2 WHERE id = $1;"
+	queryAddPasswordReset     = "WITH expired AS (DELETE FROM password_resets WHERE expiration_time < $4) INSERT INTO password_resets(code_hash, user_id, expiration_time) VALUES($1, $2, $3);"
+	queryConsumePasswordReset = "DELETE FROM password_resets WHERE code_hash = $1 AND expiration_time > $2 RETURNING user_id;"
+)
+
+var (
+	ErrPasswordResetInvalid   = errors.New("password reset code expired, used or doesn't exist")
+	ErrStatusChangedMeanwhile = errors.New("account status changed meanwhile")
+)
+
+func (repository *UserRepositry) SearchUsers(ctx context.Context, filter *models.UserFilter) (*models.UserPage, error) {
+	args := []interface{}{likePattern(filter.Email), likePattern(filter.UserName), filter.Verified, filter.RegisteredAfter, filter.RegisteredBefore, nullString(filter.Status)}
+	page := &models.UserPage{Users: []models.AdminUser{}, Page: filter.Page, PerPage: filter.PerPage}
+	if err := repository.pool.QueryRow(ctx, queryCountUsers, args...).Scan(&page.Total); err != nil {
+		return nil, err
+	}
+	rows, err := repository.pool.Query(ctx, querySearchUsers, append(args, filter.PerPage, (filter.Page-1)*filter.PerPage)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var user models.AdminUser
+		if err := rows.Scan(&user.UserId, &user.UserName, &user.Email, &user.Status, &user.StatusReason, &user.StatusChangedAt, &user.RegistrationTime, &user.SessionsRevokedAt); err != nil {
+			return nil, err
+		}
+		page.Users = append(page.Users, user)
+	}
+	return page, rows.Err()
+}
+
+func (repository *UserRepositry) GetAdminUser(ctx context.Context, user *models.AdminUser) error {
+	if err := repository.pool.QueryRow(ctx, queryGetAdminUser, user.UserId).Scan(&user.UserName, &user.Email, &user.Status, &user.StatusReason, &user.StatusChangedAt, &user.RegistrationTime, &user.SessionsRevokedAt, &user.Roles); err != nil {
+		if err == pgx.ErrNoRows {
+			return apierror.NewCatalogError(apierror.CodeUserNotFound, ErrUserNotFound.Error())
+		}
+		return err
+	}
+	return nil
+}
+
+// SetUserStatus changes the status of the user, the transition must be checked beforehand.
+func (repository *UserRepositry) SetUserStatus(ctx context.Context, change *models.StatusChange) error {
+	return repository.execStatusChange(ctx, querySetUserStatus, change)
+}
+
+// RevokeSessions signs the user out everywhere, personal access tokens aren't sessions and stay valid.
+func (repository *UserRepositry) RevokeSessions(ctx context.Context, user *models.User) error {
+	return repository.execOnUser(ctx, queryRevokeSessions, user.UserId, time.Now().UTC())
+}
+
+// DeleteUser moves the user to deleted, their credentials, tokens, identities and roles are dropped.
+func (repository *UserRepositry) DeleteUser(ctx context.Context, change *models.StatusChange) error {
+	return repository.execStatusChange(ctx, queryDeleteUser, change)
+}
+
+// UpdatePassword sets user.Password, it must already be hashed.
+func (repository *UserRepositry) UpdatePassword(ctx context.Context, user *models.User) error {
+	return repository.execOnUser(ctx, queryUpdatePassword, user.UserId, user.Password)
+}
+
+func (repository *UserRepositry) AddPasswordReset(ctx context.Context, reset *models.PasswordReset) error {
+	_, err := repository.pool.Exec(ctx, queryAddPasswordReset, reset.CodeHash, reset.UserId, reset.ExpirationTime, time.Now().UTC())
+	return err
+}
+
+// ConsumePasswordReset deletes the code and fills the user it was issued for.
+func (repository *UserRepositry) ConsumePasswordReset(ctx context.Context, reset *models.PasswordReset) error {
+	if err := repository.pool.QueryRow(ctx, queryConsumePasswordReset, reset.CodeHash, time.Now().UTC()).Scan(&reset.UserId); err != nil {
+		if err == pgx.ErrNoRows {
+			return apierror.NewCatalogError(apierror.CodePasswordResetInvalid, ErrPasswordResetInvalid.Error())
+		}
+		return err
+	}
+	return nil
+}
+
+// execOnUser runs an update or a delete of a single user, no affected rows means the user doesn't exist.
+func (repository *UserRepositry) execOnUser(ctx context.Context, query string, args ...interface{}) error {
+	commandTag, err := repository.pool.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if commandTag.RowsAffected() == 0 {
+		return apierror.NewCatalogError(apierror.CodeUserNotFound, ErrUserNotFound.Error())
+	}
+	return nil
+}
+
+// execStatusChange fails if the status of the user is no longer change.From, someone else changed it in between.
+func (repository *UserRepositry) execStatusChange(ctx context.Context, query string, change *models.StatusChange) error {
+	commandTag, err := repository.pool.Exec(ctx, query, change.UserId, change.From, change.To, change.Reason, change.ChangedAt)
+	if err != nil {
+		return err
+	}
+	if commandTag.RowsAffected() == 0 {
+		return apierror.NewCatalogError(apierror.CodeStatusTransitionInvalid, ErrStatusChangedMeanwhile.Error())
+	}
+	return nil
+}
+
+// nullString is NULL for an empty value so it doesn't filter.
+func nullString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// likePattern matches value as a substring, an empty value is NULL so it doesn't filter.
+func likePattern(value string) *string {
+	if value == "" {
+		return nil
+	}
+	pattern := "%" + strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value) + "%"
+	return &pattern
+}
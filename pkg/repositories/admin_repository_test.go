@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/chrisyxlee/pgxpoolmock"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/jackc/pgconn"
+)
+
+func TestSearchUsers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	MockPool := pgxpoolmock.NewMockPgxIface(ctrl)
+	repository := NewUserRepository(MockPool)
+	verified := true
+	filter := &models.UserFilter{Email: "50%_off", Verified: &verified, Status: models.UserStatusSuspended, Page: 3, PerPage: 10}
+	pattern := `%50\%\_off%`
+	status := models.UserStatusSuspended
+	var noTime *time.Time
+	MockPool.EXPECT().QueryRow(gomock.Any(), queryCountUsers, &pattern, nil, &verified, noTime, noTime, &status).Return(pgxpoolmock.NewRow(21)).Times(1)
+	rows := pgxpoolmock.NewRows([]string{"id", "user_name", "email", "status", "status_reason", "status_changed_at", "registration_date", "sessions_revoked_at"}).
+		AddRow(uuid.New(), "username", "50%_off@gmail.com", models.UserStatusSuspended, "spam", time.Now().UTC(), time.Now().UTC(), noTime).ToPgxRows()
+	MockPool.EXPECT().Query(gomock.Any(), querySearchUsers, &pattern, nil, &verified, noTime, noTime, &status, 10, 20).Return(rows, nil).Times(1)
+	page, err := repository.SearchUsers(context.TODO(), filter)
+	if err != nil || page.Total != 21 || len(page.Users) != 1 || page.Page != 3 {
+		t.FailNow()
+	}
+}
+
+func TestRevokeSessionsUnknownUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	MockPool := pgxpoolmock.NewMockPgxIface(ctrl)
+	repository := NewUserRepository(MockPool)
+	user := &models.User{UserId: uuid.New()}
+	MockPool.EXPECT().Exec(gomock.Any(), queryRevokeSessions, user.UserId, gomock.Any()).Return(pgconn.CommandTag("UPDATE 0"), nil).Times(1)
+	if err := repository.RevokeSessions(context.TODO(), user); err.(*apierror.ErrorStruct).ErrorCode != apierror.CodeUserNotFound {
+		t.FailNow()
+	}
+}
+
+func TestSetUserStatusChangedMeanwhile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	MockPool := pgxpoolmock.NewMockPgxIface(ctrl)
+	repository := NewUserRepository(MockPool)
+	change := &models.StatusChange{UserId: uuid.New(), From: models.UserStatusActive, To: models.UserStatusSuspended, Reason: "spam", ChangedAt: time.Now().UTC()}
+	MockPool.EXPECT().Exec(gomock.Any(), querySetUserStatus, change.UserId, change.From, change.To, change.Reason, change.ChangedAt).Return(pgconn.CommandTag("UPDATE 0"), nil).Times(1)
+	if err := repository.SetUserStatus(context.TODO(), change); err.(*apierror.ErrorStruct).ErrorCode != apierror.CodeStatusTransitionInvalid {
+		t.FailNow()
+	}
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+)
+
+const (
+	queryAddAuditEvent = `INSERT INTO audit_events(id, type, actor_id, target_id, ip, user_agent, request_id, outcome, metadata, created_at) VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);`
+	queryDeleteAuditEventsBefore = `DELETE FROM audit_events WHERE created_at < $1;`
+	// empty filters are passed as NULL and match every event
+	queryGetAuditEvents = `SELECT id, type, actor_id, target_id, ip, user_agent, request_id, outcome, metadata, created_at FROM audit_events
+		WHERE ($1::text IS NULL OR type = $1) AND ($2::uuid IS NULL OR actor_id = $2) AND ($3::uuid IS NULL OR target_id = $3)
+		AND ($4::text IS NULL OR outcome = $4) AND ($5::timestamp IS NULL OR created_at >= $5) AND ($6::timestamp IS NULL OR created_at < $6)
+		AND ($7::timestamp IS NULL OR (created_at, id) < ($7, $8)) ORDER BY created_at DESC, id DESC LIMIT $9;`
+)
+
+func (repository *UserRepositry) AddAuditEvent(ctx context.Context, event *models.AuditEvent) error {
+	metadata := event.Metadata
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	_, err := repository.pool.Exec(ctx, queryAddAuditEvent, event.EventId, event.Type, event.ActorId, event.TargetId, event.Ip, event.UserAgent, event.RequestId, event.Outcome, metadata, event.CreatedAt)
+	return err
+}
+
+// DeleteAuditEventsBefore purges the events created before before, it is run by the retention job and not on every insert.
+func (repository *UserRepositry) DeleteAuditEventsBefore(ctx context.Context, before time.Time) error {
+	_, err := repository.pool.Exec(ctx, queryDeleteAuditEventsBefore, before)
+	return err
+}
+
+// GetAuditEvents returns a page of events newest first, NextCursor is empty on the last page.
+func (repository *UserRepositry) GetAuditEvents(ctx context.Context, filter *models.AuditFilter) (*models.AuditPage, error) {
+	var afterTime *time.Time
+	var afterId interface{}
+	if filter.After != nil {
+		afterTime, afterId = &filter.After.CreatedAt, filter.After.EventId
+	}
+	// one more event than asked for tells if there is a next page
+	rows, err := repository.pool.Query(ctx, queryGetAuditEvents, nullString(filter.Type), filter.ActorId, filter.TargetId, nullString(filter.Outcome),
+		filter.From, filter.To, afterTime, afterId, filter.Limit+1)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	page := &models.AuditPage{Events: []models.AuditEvent{}}
+	for rows.Next() {
+		var event models.AuditEvent
+		if err := rows.Scan(&event.EventId, &event.Type, &event.ActorId, &event.TargetId, &event.Ip, &event.UserAgent, &event.RequestId, &event.Outcome, &event.Metadata, &event.CreatedAt); err != nil {
+			return nil, err
+		}
+		page.Events = append(page.Events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(page.Events) > filter.Limit {
+		page.Events = page.Events[:filter.Limit]
+		last := page.Events[len(page.Events)-1]
+		page.NextCursor = (&models.AuditCursor{CreatedAt: last.CreatedAt, EventId: last.EventId}).Encode()
+	}
+	return page, nil
+}
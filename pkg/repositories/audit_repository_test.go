@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/chrisyxlee/pgxpoolmock"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+)
+
+func TestAddAuditEvent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	MockPool := pgxpoolmock.NewMockPgxIface(ctrl)
+	repository := NewUserRepository(MockPool)
+	event := &models.AuditEvent{EventId: uuid.New(), Type: "user.signin", Outcome: models.AuditOutcomeSuccess, CreatedAt: time.Now().UTC()}
+	MockPool.EXPECT().Exec(gomock.Any(), queryAddAuditEvent, event.EventId, event.Type, event.ActorId, event.TargetId, "", "", "", event.Outcome, map[string]interface{}{}, event.CreatedAt).Return(nil, nil).Times(1)
+	if err := repository.AddAuditEvent(context.TODO(), event); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDeleteAuditEventsBefore(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	MockPool := pgxpoolmock.NewMockPgxIface(ctrl)
+	repository := NewUserRepository(MockPool)
+	before := time.Now().UTC().Add(-24 * time.Hour)
+	MockPool.EXPECT().Exec(gomock.Any(), queryDeleteAuditEventsBefore, before).Return(nil, nil).Times(1)
+	if err := repository.DeleteAuditEventsBefore(context.TODO(), before); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGetAuditEventsCursor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	MockPool := pgxpoolmock.NewMockPgxIface(ctrl)
+	repository := NewUserRepository(MockPool)
+	userId := uuid.New()
+	after := &models.AuditCursor{CreatedAt: time.Now().UTC(), EventId: uuid.New()}
+	filter := &models.AuditFilter{ActorId: &userId, After: after, Limit: 2}
+	var noId *uuid.UUID
+	var noTime *time.Time
+	columns := []string{"id", "type", "actor_id", "target_id", "ip", "user_agent", "request_id", "outcome", "metadata", "created_at"}
+	rows := pgxpoolmock.NewRows(columns)
+	events := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	for i, eventId := range events {
+		rows.AddRow(eventId, "user.signin", &userId, &userId, "127.0.0.1", "curl", "req", models.AuditOutcomeSuccess, map[string]interface{}{}, after.CreatedAt.Add(-time.Duration(i+1)*time.Minute))
+	}
+	MockPool.EXPECT().Query(gomock.Any(), queryGetAuditEvents, nil, &userId, noId, nil, noTime, noTime, &after.CreatedAt, after.EventId, 3).Return(rows.ToPgxRows(), nil).Times(1)
+	page, err := repository.GetAuditEvents(context.TODO(), filter)
+	if err != nil || len(page.Events) != 2 {
+		t.Fatalf("unexpected page %+v, %v", page, err)
+	}
+	cursor, err := models.DecodeAuditCursor(page.NextCursor)
+	if err != nil || cursor.EventId != events[1] || !cursor.CreatedAt.Equal(page.Events[1].CreatedAt) {
+		t.Fatalf("unexpected cursor %+v, %v", cursor, err)
+	}
+}
@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+)
+
+const queryUseChallenge = "WITH expired AS (DELETE FROM used_challenges WHERE expiration_time < $3) INSERT INTO used_challenges(jti, expiration_time) VALUES($1, $2) ON CONFLICT (jti) DO NOTHING;"
+
+var ErrChallengeUsed = errors.New("challenge was already used")
+
+// UseChallenge fails with CodeChallengeFailed if the challenge was used before.
+func (repository *UserRepositry) UseChallenge(ctx context.Context, challenge *models.UsedChallenge) error {
+	commandTag, err := repository.pool.Exec(ctx, queryUseChallenge, challenge.Jti, challenge.ExpirationTime, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	if commandTag.RowsAffected() == 0 {
+		return apierror.NewCatalogError(apierror.CodeChallengeFailed, ErrChallengeUsed.Error())
+	}
+	return nil
+}
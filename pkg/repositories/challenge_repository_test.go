@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/chrisyxlee/pgxpoolmock"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgconn"
+)
+
+func TestUseChallengeTwice(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	MockPool := pgxpoolmock.NewMockPgxIface(ctrl)
+	repository := NewUserRepository(MockPool)
+	challenge := &models.UsedChallenge{Jti: "jti", ExpirationTime: time.Now().UTC().Add(time.Minute)}
+	MockPool.EXPECT().Exec(gomock.Any(), queryUseChallenge, challenge.Jti, challenge.ExpirationTime, gomock.Any()).Return(pgconn.CommandTag("INSERT 0 1"), nil).Times(1)
+	if err := repository.UseChallenge(context.TODO(), challenge); err != nil {
+		t.FailNow()
+	}
+	MockPool.EXPECT().Exec(gomock.Any(), queryUseChallenge, challenge.Jti, challenge.ExpirationTime, gomock.Any()).Return(pgconn.CommandTag("INSERT 0 0"), nil).Times(1)
+	if err := repository.UseChallenge(context.TODO(), challenge); err.(*apierror.ErrorStruct).ErrorCode != apierror.CodeChallengeFailed {
+		t.FailNow()
+	}
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	queryAddDeviceAuthorization    = "WITH expired AS (DELETE FROM oauth_device_codes WHERE expiration_time < $8) INSERT INTO oauth_device_codes(device_code_hash, user_code, client_id, scope, status, interval, expiration_time) VALUES($1, $2, $3, $4, $5, $6, $7);"
+	queryGetDeviceAuthorization    = "SELECT d.client_id, c.name, d.scope FROM oauth_device_codes d JOIN oauth_clients c ON c.id = d.client_id WHERE d.user_code = $1 AND d.status = 'pending' AND d.expiration_time > $2;"
+	queryDecideDeviceAuthorization = "UPDATE oauth_device_codes SET status = $1, user_id = $2 WHERE user_code = $3 AND status = 'pending' AND expiration_time > $4 AND ($1 = 'denied' OR client_id = $5) RETURNING client_id, scope;"
+	// every poll is recorded, polling faster than the interval adds 5 seconds to it
+	queryPollDeviceAuthorization = `UPDATE oauth_device_codes d SET last_polled_at = $2,
+		interval = CASE WHEN old.last_polled_at > $2 - make_interval(secs => old.interval) THEN old.interval + 5 ELSE old.interval END
+		FROM (SELECT device_code_hash, last_polled_at, interval FROM oauth_device_codes WHERE device_code_hash = $1 FOR UPDATE) old
+		WHERE d.device_code_hash = old.device_code_hash
+		RETURNING d.client_id, d.scope, COALESCE(d.user_id, '00000000-0000-0000-0000-000000000000'), d.status, d.interval, d.interval > old.interval, d.expiration_time;`
+	queryDeleteDeviceAuthorization = "DELETE FROM oauth_device_codes WHERE device_code_hash = $1 AND status = 'approved';"
+)
+
+var (
+	ErrUserCodeInvalid   = errors.New("user code expired, used or doesn't exist")
+	ErrDeviceCodeInvalid = errors.New("device code expired, used or doesn't exist")
+)
+
+// AddDeviceAuthorization purges expired device codes so their user codes can be reused.
+func (repository *UserRepositry) AddDeviceAuthorization(ctx context.Context, device *models.DeviceAuthorization) error {
+	_, err := repository.pool.Exec(ctx, queryAddDeviceAuthorization, device.DeviceCodeHash, device.UserCode, device.ClientId, device.Scope, device.Status, device.Interval, device.ExpirationTime, time.Now().UTC())
+	return err
+}
+
+// GetDeviceAuthorization fills the client, its name and the scope of a pending user code.
+func (repository *UserRepositry) GetDeviceAuthorization(ctx context.Context, device *models.DeviceAuthorization) error {
+	if err := repository.pool.QueryRow(ctx, queryGetDeviceAuthorization, device.UserCode, time.Now().UTC()).Scan(&device.ClientId, &device.ClientName, &device.Scope); err != nil {
+		if err == pgx.ErrNoRows {
+			return apierror.NewCatalogError(apierror.CodeUserCodeInvalid, ErrUserCodeInvalid.Error())
+		}
+		return err
+	}
+	return nil
+}
+
+// DecideDeviceAuthorization sets the status of a pending user code and fills the client and the scope it was issued for.
+// An approval only matches when device.ClientId is the client of the user code.
+func (repository *UserRepositry) DecideDeviceAuthorization(ctx context.Context, device *models.DeviceAuthorization) error {
+	if err := repository.pool.QueryRow(ctx, queryDecideDeviceAuthorization, device.Status, device.UserId, device.UserCode, time.Now().UTC(), device.ClientId).Scan(&device.ClientId, &device.Scope); err != nil {
+		if err == pgx.ErrNoRows {
+			return apierror.NewCatalogError(apierror.CodeUserCodeInvalid, ErrUserCodeInvalid.Error())
+		}
+		return err
+	}
+	return nil
+}
+
+// PollDeviceAuthorization records the poll and fills the rest of the device authorization.
+func (repository *UserRepositry) PollDeviceAuthorization(ctx context.Context, device *models.DeviceAuthorization) error {
+	if err := repository.pool.QueryRow(ctx, queryPollDeviceAuthorization, device.DeviceCodeHash, time.Now().UTC()).Scan(&device.ClientId, &device.Scope, &device.UserId, &device.Status, &device.Interval, &device.SlowDown, &device.ExpirationTime); err != nil {
+		if err == pgx.ErrNoRows {
+			return apierror.NewOAuthError(apierror.OAuthInvalidGrant, ErrDeviceCodeInvalid.Error())
+		}
+		return err
+	}
+	return nil
+}
+
+// DeleteDeviceAuthorization consumes an approved device code so it can be exchanged only once.
+func (repository *UserRepositry) DeleteDeviceAuthorization(ctx context.Context, device *models.DeviceAuthorization) error {
+	commandTag, err := repository.pool.Exec(ctx, queryDeleteDeviceAuthorization, device.DeviceCodeHash)
+	if err != nil {
+		return err
+	}
+	if commandTag.RowsAffected() == 0 {
+		return apierror.NewOAuthError(apierror.OAuthInvalidGrant, ErrDeviceCodeInvalid.Error())
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/chrisyxlee/pgxpoolmock"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+func TestPollDeviceAuthorization(t *testing.T) {
+	testCases := []struct {
+		name    string
+		row     *pgxpoolmock.Row
+		errCode string
+	}{
+		{
+			name: "slow_down",
+			row:  pgxpoolmock.NewRow("cli", "openid", uuid.UUID{}, models.DeviceStatusPending, 10, true, time.Now().UTC()),
+		},
+		{
+			name:    "unknown_device_code",
+			row:     pgxpoolmock.NewRow("", "", uuid.UUID{}, "", 0, false, time.Time{}).WithError(pgx.ErrNoRows),
+			errCode: apierror.OAuthInvalidGrant,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			MockPool := pgxpoolmock.NewMockPgxIface(ctrl)
+			repository := NewUserRepository(MockPool)
+			device := &models.DeviceAuthorization{DeviceCodeHash: "hash"}
+			MockPool.EXPECT().QueryRow(gomock.Any(), queryPollDeviceAuthorization, device.DeviceCodeHash, gomock.Any()).Return(tc.row).Times(1)
+			err := repository.PollDeviceAuthorization(context.TODO(), device)
+			if tc.errCode != "" {
+				if err.(*apierror.OAuthError).ErrorCode != tc.errCode {
+					t.FailNow()
+				}
+				return
+			}
+			if err != nil || !device.SlowDown || device.Interval != 10 || device.ClientId != "cli" {
+				t.FailNow()
+			}
+		})
+	}
+}
+
+func TestGetDeviceAuthorizationUnknown(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	MockPool := pgxpoolmock.NewMockPgxIface(ctrl)
+	repository := NewUserRepository(MockPool)
+	device := &models.DeviceAuthorization{UserCode: "BCDF-GHJK"}
+	MockPool.EXPECT().QueryRow(gomock.Any(), queryGetDeviceAuthorization, device.UserCode, gomock.Any()).Return(pgxpoolmock.NewRow("", "", "").WithError(pgx.ErrNoRows)).Times(1)
+	if err := repository.GetDeviceAuthorization(context.TODO(), device); err.(*apierror.ErrorStruct).ErrorCode != apierror.CodeUserCodeInvalid {
+		t.FailNow()
+	}
+}
+
+func TestDecideDeviceAuthorizationUnknown(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	MockPool := pgxpoolmock.NewMockPgxIface(ctrl)
+	repository := NewUserRepository(MockPool)
+	device := &models.DeviceAuthorization{UserCode: "BCDF-GHJK", UserId: uuid.New(), Status: models.DeviceStatusApproved}
+	MockPool.EXPECT().QueryRow(gomock.Any(), queryDecideDeviceAuthorization, device.Status, device.UserId, device.UserCode, gomock.Any(), device.ClientId).Return(pgxpoolmock.NewRow("", "").WithError(pgx.ErrNoRows)).Times(1)
+	if err := repository.DecideDeviceAuthorization(context.TODO(), device); err.(*apierror.ErrorStruct).ErrorCode != apierror.CodeUserCodeInvalid {
+		t.FailNow()
+	}
+}
+
+func TestDeleteDeviceAuthorizationTwice(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	MockPool := pgxpoolmock.NewMockPgxIface(ctrl)
+	repository := NewUserRepository(MockPool)
+	device := &models.DeviceAuthorization{DeviceCodeHash: "hash"}
+	MockPool.EXPECT().Exec(gomock.Any(), queryDeleteDeviceAuthorization, device.DeviceCodeHash).Return(pgconn.CommandTag("DELETE 0"), nil).Times(1)
+	if err := repository.DeleteDeviceAuthorization(context.TODO(), device); err.(*apierror.OAuthError).ErrorCode != apierror.OAuthInvalidGrant {
+		t.FailNow()
+	}
+}
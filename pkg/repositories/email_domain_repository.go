@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	queryGetEmailDomainRules = "SELECT domain, kind, created_at FROM email_domain_rules ORDER BY domain;"
+	// the most specific rule wins, a rule for "mail.example.com" overrides one for "example.com"
+	queryMatchEmailDomainRule  = "SELECT domain, kind, created_at FROM email_domain_rules WHERE domain = ANY($1) ORDER BY length(domain) DESC LIMIT 1;"
+	queryPutEmailDomainRule    = "INSERT INTO email_domain_rules(domain, kind, created_at) VALUES($1, $2, $3) ON CONFLICT (domain) DO UPDATE SET kind = EXCLUDED.kind;"
+	queryDeleteEmailDomainRule = "DELETE FROM email_domain_rules WHERE domain = $1;"
+)
+
+var ErrEmailDomainRuleNotFound = errors.New("email domain rule doesn't exist")
+
+func (repository *UserRepositry) GetEmailDomainRules(ctx context.Context) ([]models.EmailDomainRule, error) {
+	rows, err := repository.pool.Query(ctx, queryGetEmailDomainRules)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	rules := []models.EmailDomainRule{}
+	for rows.Next() {
+		var rule models.EmailDomainRule
+		if err := rows.Scan(&rule.Domain, &rule.Kind, &rule.CreatedAt); err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+// MatchEmailDomainRule fills rule with the most specific rule covering domain, result is false if there is none.
+func (repository *UserRepositry) MatchEmailDomainRule(ctx context.Context, domain string, rule *models.EmailDomainRule, result *bool) error {
+	if err := repository.pool.QueryRow(ctx, queryMatchEmailDomainRule, models.ParentDomains(domain)).Scan(&rule.Domain, &rule.Kind, &rule.CreatedAt); err != nil {
+		if err == pgx.ErrNoRows {
+			*result = false
+			return nil
+		}
+		return err
+	}
+	*result = true
+	return nil
+}
+
+func (repository *UserRepositry) PutEmailDomainRule(ctx context.Context, rule *models.EmailDomainRule) error {
+	_, err := repository.pool.Exec(ctx, queryPutEmailDomainRule, rule.Domain, rule.Kind, rule.CreatedAt)
+	return err
+}
+
+func (repository *UserRepositry) DeleteEmailDomainRule(ctx context.Context, rule *models.EmailDomainRule) error {
+	commandTag, err := repository.pool.Exec(ctx, queryDeleteEmailDomainRule, rule.Domain)
+	if err != nil {
+		return err
+	}
+	if commandTag.RowsAffected() == 0 {
+		return apierror.NewCatalogError(apierror.CodeEmailDomainRuleNotFound, ErrEmailDomainRuleNotFound.Error())
+	}
+	return nil
+}
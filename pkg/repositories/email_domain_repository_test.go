@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/chrisyxlee/pgxpoolmock"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+func TestMatchEmailDomainRule(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	MockPool := pgxpoolmock.NewMockPgxIface(ctrl)
+	repository := NewUserRepository(MockPool)
+	MockPool.EXPECT().QueryRow(gomock.Any(), queryMatchEmailDomainRule, []string{"eu.mailinator.com", "mailinator.com", "com"}).Return(pgxpoolmock.NewRow("mailinator.com", models.EmailDomainAllow, time.Now().UTC())).Times(1)
+	rule, matched := new(models.EmailDomainRule), new(bool)
+	if err := repository.MatchEmailDomainRule(context.TODO(), "eu.mailinator.com", rule, matched); err != nil || !*matched || rule.Kind != models.EmailDomainAllow {
+		t.FailNow()
+	}
+
+	MockPool.EXPECT().QueryRow(gomock.Any(), queryMatchEmailDomainRule, []string{"example.org", "org"}).Return(pgxpoolmock.NewRow("", "", time.Time{}).WithError(pgx.ErrNoRows)).Times(1)
+	if err := repository.MatchEmailDomainRule(context.TODO(), "example.org", rule, matched); err != nil || *matched {
+		t.FailNow()
+	}
+}
+
+func TestDeleteEmailDomainRuleNotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	MockPool := pgxpoolmock.NewMockPgxIface(ctrl)
+	repository := NewUserRepository(MockPool)
+	MockPool.EXPECT().Exec(gomock.Any(), queryDeleteEmailDomainRule, "example.org").Return(pgconn.CommandTag("DELETE 0"), nil).Times(1)
+	if err := repository.DeleteEmailDomainRule(context.TODO(), &models.EmailDomainRule{Domain: "example.org"}); err.(*apierror.ErrorStruct).ErrorCode != apierror.CodeEmailDomainRuleNotFound {
+		t.FailNow()
+	}
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	queryGetIdentity         = "SELECT user_id FROM user_identities WHERE provider = $1 AND subject = $2;"
+	queryAddIdentity         = "INSERT INTO user_identities(provider, subject, user_id, email, created_at) VALUES($1, $2, $3, $4, $5);"
+	queryGetUserByEmail      = "SELECT id, status FROM users WHERE lower(email) = lower($1) AND status <> 'deleted';"
+	queryClaimUnverifiedUser = "UPDATE users SET status = 'active', status_changed_at = $3, password = $1, verification_code = '' WHERE id = $2 AND status = 'pending';"
+)
+
+// GetIdentity fills identity.UserId if the identity is already linked to a user.
+func (repository *UserRepositry) GetIdentity(ctx context.Context, identity *models.Identity, result *bool) error {
+	if err := repository.pool.QueryRow(ctx, queryGetIdentity, identity.Provider, identity.Subject).Scan(&identity.UserId); err != nil {
+		if err == pgx.ErrNoRows {
+			*result = false
+			return nil
+		}
+		return err
+	}
+	*result = true
+	return nil
+}
+
+func (repository *UserRepositry) AddIdentity(ctx context.Context, identity *models.Identity) error {
+	_, err := repository.pool.Exec(ctx, queryAddIdentity, identity.Provider, identity.Subject, identity.UserId, identity.Email, identity.CreatedAt)
+	return err
+}
+
+// GetUserByEmail fills user.UserId and user.Status of the user with the email, deleted users aren't found.
+func (repository *UserRepositry) GetUserByEmail(ctx context.Context, user *models.User, result *bool) error {
+	if err := repository.pool.QueryRow(ctx, queryGetUserByEmail, user.Email).Scan(&user.UserId, &user.Status); err != nil {
+		if err == pgx.ErrNoRows {
+			*result = false
+			return nil
+		}
+		return err
+	}
+	*result = true
+	return nil
+}
+
+// ClaimUnverifiedUser verifies a pending sign up for the owner of a verified external email, the password and the
+// verification code of the pending sign up are dropped so whoever registered it can't use them.
+func (repository *UserRepositry) ClaimUnverifiedUser(ctx context.Context, user *models.User) error {
+	commandTag, err := repository.pool.Exec(ctx, queryClaimUnverifiedUser, user.Password, user.UserId, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	if commandTag.RowsAffected() == 0 {
+		return apierror.NewCatalogError(apierror.CodeUserNotFound, ErrUserNotFound.Error())
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/chrisyxlee/pgxpoolmock"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+func TestGetIdentity(t *testing.T) {
+	userId := uuid.New()
+	testCases := []struct {
+		name   string
+		row    *pgxpoolmock.Row
+		linked bool
+	}{
+		{
+			name:   "linked",
+			row:    pgxpoolmock.NewRow(userId),
+			linked: true,
+		},
+		{
+			name: "not_linked",
+			row:  pgxpoolmock.NewRow(uuid.UUID{}).WithError(pgx.ErrNoRows),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			MockPool := pgxpoolmock.NewMockPgxIface(ctrl)
+			repository := NewUserRepository(MockPool)
+			identity := &models.Identity{Provider: "google", Subject: "42"}
+			MockPool.EXPECT().QueryRow(gomock.Any(), queryGetIdentity, identity.Provider, identity.Subject).Return(tc.row).Times(1)
+			result := new(bool)
+			if err := repository.GetIdentity(context.TODO(), identity, result); err != nil || *result != tc.linked {
+				t.FailNow()
+			}
+			if tc.linked && identity.UserId != userId {
+				t.FailNow()
+			}
+		})
+	}
+}
+
+func TestClaimUnverifiedUser(t *testing.T) {
+	testCases := []struct {
+		name string
+		tag  pgconn.CommandTag
+		err  error
+	}{
+		{
+			name: "claimed",
+			tag:  pgconn.CommandTag("UPDATE 1"),
+		},
+		{
+			name: "already_verified",
+			tag:  pgconn.CommandTag("UPDATE 0"),
+			err:  apierror.NewCatalogError(apierror.CodeUserNotFound, ErrUserNotFound.Error()),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			MockPool := pgxpoolmock.NewMockPgxIface(ctrl)
+			repository := NewUserRepository(MockPool)
+			user := &models.User{UserId: uuid.New(), Password: "!"}
+			MockPool.EXPECT().Exec(gomock.Any(), queryClaimUnverifiedUser, user.Password, user.UserId, gomock.Any()).Return(tc.tag, nil).Times(1)
+			if err := repository.ClaimUnverifiedUser(context.TODO(), user); !reflect.DeepEqual(tc.err, err) {
+				t.FailNow()
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	// the device is new if the upsert inserted it (xmax is 0) and the user had devices before, the first device isn't news
+	queryTouchKnownDevice = `WITH known AS (SELECT EXISTS(SELECT 1 FROM known_devices WHERE user_id = $1) AS had_devices),
+		touched AS (INSERT INTO known_devices(user_id, fingerprint, user_agent, ip, first_seen_at, last_seen_at) VALUES($1, $2, $3, $4, $5, $5)
+		ON CONFLICT (user_id, fingerprint) DO UPDATE SET last_seen_at = EXCLUDED.last_seen_at RETURNING xmax = 0 AS inserted)
+		SELECT touched.inserted AND known.had_devices FROM touched, known;`
+	queryForgetKnownDevice        = "DELETE FROM known_devices WHERE user_id = $1 AND fingerprint = $2;"
+	queryAddSecureAccountCode     = "WITH expired AS (DELETE FROM secure_account_codes WHERE expiration_time < $5) INSERT INTO secure_account_codes(code_hash, user_id, fingerprint, expiration_time) VALUES($1, $2, $3, $4);"
+	queryConsumeSecureAccountCode = "DELETE FROM secure_account_codes WHERE code_hash = $1 AND expiration_time > $2 RETURNING user_id, fingerprint;"
+)
+
+var ErrSecureAccountCodeInvalid = errors.New("secure account code expired, used or doesn't exist")
+
+// TouchKnownDevice remembers the device or updates when it was last seen, isNew tells if the user should hear about it.
+func (repository *UserRepositry) TouchKnownDevice(ctx context.Context, device *models.KnownDevice, isNew *bool) error {
+	return repository.pool.QueryRow(ctx, queryTouchKnownDevice, device.UserId, device.Fingerprint, device.UserAgent, device.Ip, device.SeenAt).Scan(isNew)
+}
+
+// ForgetKnownDevice makes the next sign in from the device notify the user again.
+func (repository *UserRepositry) ForgetKnownDevice(ctx context.Context, device *models.KnownDevice) error {
+	_, err := repository.pool.Exec(ctx, queryForgetKnownDevice, device.UserId, device.Fingerprint)
+	return err
+}
+
+func (repository *UserRepositry) AddSecureAccountCode(ctx context.Context, code *models.SecureAccountCode) error {
+	_, err := repository.pool.Exec(ctx, queryAddSecureAccountCode, code.CodeHash, code.UserId, code.Fingerprint, code.ExpirationTime, time.Now().UTC())
+	return err
+}
+
+// ConsumeSecureAccountCode deletes the code and fills the user and the device it was issued for.
+func (repository *UserRepositry) ConsumeSecureAccountCode(ctx context.Context, code *models.SecureAccountCode) error {
+	if err := repository.pool.QueryRow(ctx, queryConsumeSecureAccountCode, code.CodeHash, time.Now().UTC()).Scan(&code.UserId, &code.Fingerprint); err != nil {
+		if err == pgx.ErrNoRows {
+			return apierror.NewCatalogError(apierror.CodeSecureAccountInvalid, ErrSecureAccountCodeInvalid.Error())
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/chrisyxlee/pgxpoolmock"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+func TestTouchKnownDevice(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	MockPool := pgxpoolmock.NewMockPgxIface(ctrl)
+	repository := NewUserRepository(MockPool)
+	device := &models.KnownDevice{UserId: uuid.New(), Fingerprint: "fingerprint", UserAgent: "curl", Ip: "127.0.0.1", SeenAt: time.Now().UTC()}
+	MockPool.EXPECT().QueryRow(gomock.Any(), queryTouchKnownDevice, device.UserId, device.Fingerprint, device.UserAgent, device.Ip, device.SeenAt).Return(pgxpoolmock.NewRow(true)).Times(1)
+	isNew := new(bool)
+	if err := repository.TouchKnownDevice(context.TODO(), device, isNew); err != nil || !*isNew {
+		t.FailNow()
+	}
+}
+
+func TestConsumeSecureAccountCodeInvalid(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	MockPool := pgxpoolmock.NewMockPgxIface(ctrl)
+	repository := NewUserRepository(MockPool)
+	code := &models.SecureAccountCode{CodeHash: "hash"}
+	MockPool.EXPECT().QueryRow(gomock.Any(), queryConsumeSecureAccountCode, code.CodeHash, gomock.Any()).Return(pgxpoolmock.NewRow(uuid.Nil, "").WithError(pgx.ErrNoRows)).Times(1)
+	if err := repository.ConsumeSecureAccountCode(context.TODO(), code); err.(*apierror.ErrorStruct).ErrorCode != apierror.CodeSecureAccountInvalid {
+		t.FailNow()
+	}
+}
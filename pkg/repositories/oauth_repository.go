@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	queryGetClient                = "SELECT COALESCE(secret_hash, ''), name, redirect_uris, scopes, grant_types, COALESCE(public_key, ''), COALESCE(audience, ''), created_at FROM oauth_clients WHERE id = $1;"
+	queryAddAuthorizationCode     = "INSERT INTO oauth_codes(code_hash, client_id, user_id, redirect_uri, scope, nonce, code_challenge, auth_time, expiration_time) VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9);"
+	queryConsumeAuthorizationCode = "DELETE FROM oauth_codes WHERE code_hash = $1 AND expiration_time > $2 RETURNING client_id, user_id, redirect_uri, scope, nonce, code_challenge, auth_time;"
+	queryIfConsentExists          = "SELECT EXISTS(SELECT * FROM oauth_consents WHERE user_id = $1 AND client_id = $2 AND scopes @> $3);"
+	queryAddConsent               = "INSERT INTO oauth_consents(user_id, client_id, scopes, granted_at) VALUES($1, $2, $3, $4) ON CONFLICT (user_id, client_id) DO UPDATE SET scopes = EXCLUDED.scopes, granted_at = EXCLUDED.granted_at;"
+	queryAddOAuthRefreshToken     = "INSERT INTO oauth_refresh_tokens(token_hash, client_id, user_id, scope, auth_time, granted_at, expiration_time) VALUES($1, $2, $3, $4, $5, $6, $7);"
+	queryConsumeOAuthRefreshToken = "DELETE FROM oauth_refresh_tokens WHERE token_hash = $1 AND client_id = $2 AND expiration_time > $3 RETURNING user_id, scope, auth_time, granted_at, expiration_time;"
+	queryGetUserById              = "SELECT user_name, email, status, registration_date FROM users WHERE id = $1 AND status <> 'deleted';"
+	queryGetOAuthRefreshToken     = "SELECT client_id, user_id, scope, auth_time, expiration_time FROM oauth_refresh_tokens WHERE token_hash = $1 AND expiration_time > $2;"
+	queryDeleteOAuthRefreshToken  = "DELETE FROM oauth_refresh_tokens WHERE token_hash = $1 AND client_id = $2;"
+	queryAddRevokedToken          = "WITH expired AS (DELETE FROM revoked_tokens WHERE expiration_time < $3) INSERT INTO revoked_tokens(jti, expiration_time) VALUES($1, $2) ON CONFLICT (jti) DO NOTHING;"
+	queryIfTokenRevoked           = "SELECT EXISTS(SELECT * FROM revoked_tokens WHERE jti = $1) OR EXISTS(SELECT * FROM users WHERE id = $2 AND sessions_revoked_at >= $3);"
+)
+
+var (
+	ErrClientDoesntExists       = errors.New("client doesn't exist")
+	ErrAuthorizationCodeInvalid = errors.New("authorization code expired, used or doesn't exist")
+	ErrOAuthRefreshTokenInvalid = errors.New("refresh token expired, used or doesn't exist")
+	ErrUserNotFound             = errors.New("user doesn't exist")
+)
+
+func (repository *UserRepositry) GetClient(ctx context.Context, client *models.OAuthClient) error {
+	if err := repository.pool.QueryRow(ctx, queryGetClient, client.ClientId).Scan(&client.SecretHash, &client.Name, &client.RedirectUris, &client.Scopes, &client.GrantTypes, &client.PublicKey, &client.Audience, &client.CreatedAt); err != nil {
+		if err == pgx.ErrNoRows {
+			return apierror.NewOAuthError(apierror.OAuthInvalidClient, ErrClientDoesntExists.Error())
+		}
+		return err
+	}
+	return nil
+}
+
+func (repository *UserRepositry) AddAuthorizationCode(ctx context.Context, code *models.AuthorizationCode) error {
+	_, err := repository.pool.Exec(ctx, queryAddAuthorizationCode, code.CodeHash, code.ClientId, code.UserId, code.RedirectUri, code.Scope, code.Nonce, code.CodeChallenge, code.AuthTime, code.ExpirationTime)
+	return err
+}
+
+// ConsumeAuthorizationCode deletes the code so it can be exchanged only once and fills the rest of its fields.
+func (repository *UserRepositry) ConsumeAuthorizationCode(ctx context.Context, code *models.AuthorizationCode) error {
+	if err := repository.pool.QueryRow(ctx, queryConsumeAuthorizationCode, code.CodeHash, time.Now().UTC()).Scan(&code.ClientId, &code.UserId, &code.RedirectUri, &code.Scope, &code.Nonce, &code.CodeChallenge, &code.AuthTime); err != nil {
+		if err == pgx.ErrNoRows {
+			return apierror.NewOAuthError(apierror.OAuthInvalidGrant, ErrAuthorizationCodeInvalid.Error())
+		}
+		return err
+	}
+	return nil
+}
+
+func (repository *UserRepositry) IfConsentExists(ctx context.Context, consent *models.Consent, result *bool) error {
+	if err := repository.pool.QueryRow(ctx, queryIfConsentExists, consent.UserId, consent.ClientId, consent.Scopes).Scan(result); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (repository *UserRepositry) AddConsent(ctx context.Context, consent *models.Consent) error {
+	_, err := repository.pool.Exec(ctx, queryAddConsent, consent.UserId, consent.ClientId, consent.Scopes, consent.GrantedAt)
+	return err
+}
+
+func (repository *UserRepositry) AddOAuthRefreshToken(ctx context.Context, token *models.OAuthRefreshToken) error {
+	_, err := repository.pool.Exec(ctx, queryAddOAuthRefreshToken, token.TokenHash, token.ClientId, token.UserId, token.Scope, token.AuthTime, token.GrantedAt, token.ExpirationTime)
+	return err
+}
+
+// ConsumeOAuthRefreshToken deletes the token, refresh tokens are rotated on every use.
+func (repository *UserRepositry) ConsumeOAuthRefreshToken(ctx context.Context, token *models.OAuthRefreshToken) error {
+	if err := repository.pool.QueryRow(ctx, queryConsumeOAuthRefreshToken, token.TokenHash, token.ClientId, time.Now().UTC()).Scan(&token.UserId, &token.Scope, &token.AuthTime, &token.GrantedAt, &token.ExpirationTime); err != nil {
+		if err == pgx.ErrNoRows {
+			return apierror.NewOAuthError(apierror.OAuthInvalidGrant, ErrOAuthRefreshTokenInvalid.Error())
+		}
+		return err
+	}
+	return nil
+}
+
+// GetUserById fills the user with the id, deleted users aren't found.
+func (repository *UserRepositry) GetUserById(ctx context.Context, user *models.User) error {
+	if err := repository.pool.QueryRow(ctx, queryGetUserById, user.UserId).Scan(&user.UserName, &user.Email, &user.Status, &user.RegistrationTime); err != nil {
+		if err == pgx.ErrNoRows {
+			return apierror.NewCatalogError(apierror.CodeUserNotFound, ErrUserNotFound.Error())
+		}
+		return err
+	}
+	return nil
+}
+
+// GetOAuthRefreshToken fills the token without consuming it, result is false if the token expired or doesn't exist.
+func (repository *UserRepositry) GetOAuthRefreshToken(ctx context.Context, token *models.OAuthRefreshToken, result *bool) error {
+	if err := repository.pool.QueryRow(ctx, queryGetOAuthRefreshToken, token.TokenHash, time.Now().UTC()).Scan(&token.ClientId, &token.UserId, &token.Scope, &token.AuthTime, &token.ExpirationTime); err != nil {
+		if err == pgx.ErrNoRows {
+			*result = false
+			return nil
+		}
+		return err
+	}
+	*result = true
+	return nil
+}
+
+// DeleteOAuthRefreshToken revokes the token if it was issued to token.ClientId, result tells whether it was deleted.
+func (repository *UserRepositry) DeleteOAuthRefreshToken(ctx context.Context, token *models.OAuthRefreshToken, result *bool) error {
+	commandTag, err := repository.pool.Exec(ctx, queryDeleteOAuthRefreshToken, token.TokenHash, token.ClientId)
+	if err != nil {
+		return err
+	}
+	*result = commandTag.RowsAffected() != 0
+	return nil
+}
+
+// AddRevokedToken puts the jti on the denylist, result is false if it was already there.
+// Entries of expired tokens are purged on the way.
+func (repository *UserRepositry) AddRevokedToken(ctx context.Context, token *models.RevokedToken, result *bool) error {
+	commandTag, err := repository.pool.Exec(ctx, queryAddRevokedToken, token.Jti, token.ExpirationTime, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	*result = commandTag.RowsAffected() != 0
+	return nil
+}
+
+func (repository *UserRepositry) IfTokenRevoked(ctx context.Context, token *models.RevokedToken, result *bool) error {
+	if err := repository.pool.QueryRow(ctx, queryIfTokenRevoked, token.Jti, token.UserId, token.IssuedAt).Scan(result); err != nil {
+		return err
+	}
+	return nil
+}
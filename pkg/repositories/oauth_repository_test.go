@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/chrisyxlee/pgxpoolmock"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+func TestGetClient(t *testing.T) {
+	testCases := []struct {
+		name       string
+		client     *models.OAuthClient
+		beforeTest func(mockPool *pgxpoolmock.MockPgxIface, client *models.OAuthClient)
+		err        error
+	}{
+		{
+			name:   "GetClient",
+			client: &models.OAuthClient{ClientId: "wordapi"},
+			beforeTest: func(mockPool *pgxpoolmock.MockPgxIface, client *models.OAuthClient) {
+				mockPool.EXPECT().QueryRow(gomock.Any(), queryGetClient, client.ClientId).Return(pgxpoolmock.NewRow("hash", "wordApi", []string{"http://localhost:3000/callback"}, []string{"openid"}, []string{"authorization_code"}, "", "", time.Now().UTC())).Times(1)
+			},
+		},
+		{
+			name:   "unknown_client",
+			client: &models.OAuthClient{ClientId: "unknown"},
+			beforeTest: func(mockPool *pgxpoolmock.MockPgxIface, client *models.OAuthClient) {
+				mockPool.EXPECT().QueryRow(gomock.Any(), queryGetClient, client.ClientId).Return(pgxpoolmock.NewRow("", "", []string{}, []string{}, []string{}, "", "", time.Time{}).WithError(pgx.ErrNoRows)).Times(1)
+			},
+			err: apierror.NewOAuthError(apierror.OAuthInvalidClient, ErrClientDoesntExists.Error()),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			MockPool := pgxpoolmock.NewMockPgxIface(ctrl)
+			repository := NewUserRepository(MockPool)
+			tc.beforeTest(MockPool, tc.client)
+			if err := repository.GetClient(context.TODO(), tc.client); !reflect.DeepEqual(tc.err, err) {
+				t.FailNow()
+			}
+		})
+	}
+}
+
+func TestAddAuthorizationCode(t *testing.T) {
+	// just to make sure that no one will mess with an order of the func paramaters
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	MockPool := pgxpoolmock.NewMockPgxIface(ctrl)
+	repository := NewUserRepository(MockPool)
+	code := &models.AuthorizationCode{CodeHash: "hash", ClientId: "wordapi", UserId: uuid.New(), RedirectUri: "uri", Scope: "openid", Nonce: "nonce", CodeChallenge: "challenge"}
+	MockPool.EXPECT().Exec(gomock.Any(), queryAddAuthorizationCode, code.CodeHash, code.ClientId, code.UserId, code.RedirectUri, code.Scope, code.Nonce, code.CodeChallenge, code.AuthTime, code.ExpirationTime).Return(nil, nil).Times(1)
+	if err := repository.AddAuthorizationCode(context.TODO(), code); err != nil {
+		t.FailNow()
+	}
+}
+
+func TestConsumeAuthorizationCode(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	MockPool := pgxpoolmock.NewMockPgxIface(ctrl)
+	repository := NewUserRepository(MockPool)
+	code := &models.AuthorizationCode{CodeHash: "hash"}
+	MockPool.EXPECT().QueryRow(gomock.Any(), queryConsumeAuthorizationCode, code.CodeHash, gomock.Any()).Return(pgxpoolmock.NewRow("", uuid.UUID{}, "", "", "", "", time.Time{}).WithError(pgx.ErrNoRows)).Times(1)
+	err := repository.ConsumeAuthorizationCode(context.TODO(), code)
+	if !reflect.DeepEqual(apierror.NewOAuthError(apierror.OAuthInvalidGrant, ErrAuthorizationCodeInvalid.Error()), err) {
+		t.FailNow()
+	}
+}
+
+func TestAddOAuthRefreshToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	MockPool := pgxpoolmock.NewMockPgxIface(ctrl)
+	repository := NewUserRepository(MockPool)
+	token := &models.OAuthRefreshToken{TokenHash: "hash", ClientId: "wordapi", UserId: uuid.New(), Scope: "openid", AuthTime: time.Now().UTC(), GrantedAt: time.Now().UTC().Add(-time.Hour), ExpirationTime: time.Now().UTC().Add(time.Hour)}
+	MockPool.EXPECT().Exec(gomock.Any(), queryAddOAuthRefreshToken, token.TokenHash, token.ClientId, token.UserId, token.Scope, token.AuthTime, token.GrantedAt, token.ExpirationTime).Return(nil, nil).Times(1)
+	if err := repository.AddOAuthRefreshToken(context.TODO(), token); err != nil {
+		t.FailNow()
+	}
+}
+
+func TestDeleteOAuthRefreshToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	MockPool := pgxpoolmock.NewMockPgxIface(ctrl)
+	repository := NewUserRepository(MockPool)
+	token := &models.OAuthRefreshToken{TokenHash: "hash", ClientId: "wordapi"}
+	MockPool.EXPECT().Exec(gomock.Any(), queryDeleteOAuthRefreshToken, token.TokenHash, token.ClientId).Return(pgconn.CommandTag("DELETE 1"), nil).Times(1)
+	deleted := new(bool)
+	if err := repository.DeleteOAuthRefreshToken(context.TODO(), token, deleted); err != nil || !*deleted {
+		t.FailNow()
+	}
+}
+
+func TestAddRevokedToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	MockPool := pgxpoolmock.NewMockPgxIface(ctrl)
+	repository := NewUserRepository(MockPool)
+	token := &models.RevokedToken{Jti: "jti", ExpirationTime: time.Now().UTC()}
+	MockPool.EXPECT().Exec(gomock.Any(), queryAddRevokedToken, token.Jti, token.ExpirationTime, gomock.Any()).Return(pgconn.CommandTag("INSERT 0 0"), nil).Times(1)
+	added := new(bool)
+	if err := repository.AddRevokedToken(context.TODO(), token, added); err != nil || *added {
+		t.FailNow()
+	}
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	queryGetProfile = "SELECT user_name, email, status, registration_date, display_name, locale, timezone FROM users WHERE id = $1 AND status <> 'deleted';"
+	// NULL keeps the current value
+	queryUpdateProfile = `UPDATE users SET user_name = COALESCE($2, user_name), user_name_canonical = COALESCE($6, user_name_canonical), display_name = COALESCE($3, display_name), locale = COALESCE($4, locale),
+		timezone = COALESCE($5, timezone) WHERE id = $1 AND status <> 'deleted';`
+)
+
+func (repository *UserRepositry) GetProfile(ctx context.Context, profile *models.Profile) error {
+	if err := repository.pool.QueryRow(ctx, queryGetProfile, profile.UserId).Scan(&profile.UserName, &profile.Email, &profile.Status, &profile.RegistrationTime, &profile.DisplayName, &profile.Locale, &profile.Timezone); err != nil {
+		if err == pgx.ErrNoRows {
+			return apierror.NewCatalogError(apierror.CodeUserNotFound, ErrUserNotFound.Error())
+		}
+		return err
+	}
+	profile.Verified = profile.Status != models.UserStatusPending
+	return nil
+}
+
+// UpdateProfile fails with CodeUserNameTaken if someone else has the new user name.
+func (repository *UserRepositry) UpdateProfile(ctx context.Context, update *models.ProfileUpdate) error {
+	var canonical *string
+	if update.UserName != nil {
+		value := models.CanonicalUserName(*update.UserName)
+		canonical = &value
+	}
+	commandTag, err := repository.pool.Exec(ctx, queryUpdateProfile, update.UserId, update.UserName, update.DisplayName, update.Locale, update.Timezone, canonical)
+	if err != nil {
+		return uniqueViolation(err)
+	}
+	if commandTag.RowsAffected() == 0 {
+		return apierror.NewCatalogError(apierror.CodeUserNotFound, ErrUserNotFound.Error())
+	}
+	return nil
+}
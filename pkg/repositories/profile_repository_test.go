@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/chrisyxlee/pgxpoolmock"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/jackc/pgconn"
+)
+
+func TestGetProfile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	MockPool := pgxpoolmock.NewMockPgxIface(ctrl)
+	repository := NewUserRepository(MockPool)
+	profile := &models.Profile{UserId: uuid.New()}
+	MockPool.EXPECT().QueryRow(gomock.Any(), queryGetProfile, profile.UserId).Return(pgxpoolmock.NewRow("username", "test@gmail.com", models.UserStatusActive, time.Now().UTC(), "Test", "en", "UTC")).Times(1)
+	if err := repository.GetProfile(context.TODO(), profile); err != nil || !profile.Verified || profile.DisplayName != "Test" {
+		t.FailNow()
+	}
+}
+
+func TestUpdateProfileUserNameTaken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	MockPool := pgxpoolmock.NewMockPgxIface(ctrl)
+	repository := NewUserRepository(MockPool)
+	userName := "taken_name"
+	var unchanged *string
+	update := &models.ProfileUpdate{UserId: uuid.New(), UserName: &userName}
+	canonical := "taken_name"
+	MockPool.EXPECT().Exec(gomock.Any(), queryUpdateProfile, update.UserId, &userName, unchanged, unchanged, unchanged, &canonical).Return(nil, &pgconn.PgError{Code: pgUniqueViolation, ConstraintName: constraintUserName}).Times(1)
+	if err := repository.UpdateProfile(context.TODO(), update); err.(*apierror.ErrorStruct).ErrorCode != apierror.CodeUserNameTaken {
+		t.FailNow()
+	}
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/Kin-dza-dzaa/userApi/internal/models"
 	"github.com/jackc/pgx/v4/pgxpool"
@@ -12,9 +13,70 @@ type Repository interface {
 	UpdateCredentials(context.Context, *models.User) error
 	VerifyUser(context.Context, *models.User) error
 	GetUUid(context.Context, *models.User) error
-	GetVerifiedUser(context.Context, *models.User) (*models.User, error)
+	GetSignInUser(context.Context, *models.User) (*models.User, error)
 	UpdateRefreshToken(context.Context, *models.User) error
+	ExtendSession(context.Context, *models.User) error
+	GetPassword(context.Context, *models.User) error
 	IfUnverifiedUserExists(context.Context, *models.User, *bool) error
+	IfVerifiedUserExists(context.Context, *models.User, *bool) error
+	IfUserNameExists(context.Context, *models.User, *bool) error
+	GetUserById(context.Context, *models.User) error
+	GetClient(context.Context, *models.OAuthClient) error
+	AddAuthorizationCode(context.Context, *models.AuthorizationCode) error
+	ConsumeAuthorizationCode(context.Context, *models.AuthorizationCode) error
+	IfConsentExists(context.Context, *models.Consent, *bool) error
+	AddConsent(context.Context, *models.Consent) error
+	AddOAuthRefreshToken(context.Context, *models.OAuthRefreshToken) error
+	ConsumeOAuthRefreshToken(context.Context, *models.OAuthRefreshToken) error
+	GetOAuthRefreshToken(context.Context, *models.OAuthRefreshToken, *bool) error
+	DeleteOAuthRefreshToken(context.Context, *models.OAuthRefreshToken, *bool) error
+	AddRevokedToken(context.Context, *models.RevokedToken, *bool) error
+	IfTokenRevoked(context.Context, *models.RevokedToken, *bool) error
+	AddPersonalAccessToken(context.Context, *models.PersonalAccessToken) error
+	GetPersonalAccessTokens(context.Context, *models.User) ([]models.PersonalAccessToken, error)
+	DeletePersonalAccessToken(context.Context, *models.PersonalAccessToken) error
+	UsePersonalAccessToken(context.Context, *models.PersonalAccessToken, *bool) error
+	AddDeviceAuthorization(context.Context, *models.DeviceAuthorization) error
+	GetDeviceAuthorization(context.Context, *models.DeviceAuthorization) error
+	DecideDeviceAuthorization(context.Context, *models.DeviceAuthorization) error
+	PollDeviceAuthorization(context.Context, *models.DeviceAuthorization) error
+	DeleteDeviceAuthorization(context.Context, *models.DeviceAuthorization) error
+	GetUserAccess(context.Context, *models.User) error
+	GrantableScopes(context.Context, *models.User, []string) ([]string, error)
+	GetRoles(context.Context) ([]models.Role, error)
+	PutRole(context.Context, *models.Role) error
+	DeleteRole(context.Context, *models.Role) error
+	GetPermissions(context.Context) ([]models.Permission, error)
+	PutPermission(context.Context, *models.Permission) error
+	DeletePermission(context.Context, *models.Permission) error
+	AddUserRole(context.Context, *models.UserRole) error
+	DeleteUserRole(context.Context, *models.UserRole) error
+	SearchUsers(context.Context, *models.UserFilter) (*models.UserPage, error)
+	GetAdminUser(context.Context, *models.AdminUser) error
+	SetUserStatus(context.Context, *models.StatusChange) error
+	RevokeSessions(context.Context, *models.User) error
+	DeleteUser(context.Context, *models.StatusChange) error
+	UpdatePassword(context.Context, *models.User) error
+	AddPasswordReset(context.Context, *models.PasswordReset) error
+	ConsumePasswordReset(context.Context, *models.PasswordReset) error
+	AddAuditEvent(context.Context, *models.AuditEvent) error
+	DeleteAuditEventsBefore(context.Context, time.Time) error
+	GetAuditEvents(context.Context, *models.AuditFilter) (*models.AuditPage, error)
+	TouchKnownDevice(context.Context, *models.KnownDevice, *bool) error
+	ForgetKnownDevice(context.Context, *models.KnownDevice) error
+	AddSecureAccountCode(context.Context, *models.SecureAccountCode) error
+	ConsumeSecureAccountCode(context.Context, *models.SecureAccountCode) error
+	GetProfile(context.Context, *models.Profile) error
+	UpdateProfile(context.Context, *models.ProfileUpdate) error
+	GetEmailDomainRules(context.Context) ([]models.EmailDomainRule, error)
+	MatchEmailDomainRule(context.Context, string, *models.EmailDomainRule, *bool) error
+	PutEmailDomainRule(context.Context, *models.EmailDomainRule) error
+	DeleteEmailDomainRule(context.Context, *models.EmailDomainRule) error
+	UseChallenge(context.Context, *models.UsedChallenge) error
+	GetIdentity(context.Context, *models.Identity, *bool) error
+	AddIdentity(context.Context, *models.Identity) error
+	GetUserByEmail(context.Context, *models.User, *bool) error
+	ClaimUnverifiedUser(context.Context, *models.User) error
 } 
 
 func NewRepository(pool *pgxpool.Pool) Repository {
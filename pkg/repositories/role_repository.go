@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	queryGetUserAccess = `SELECT u.user_name, u.status, COALESCE(array_agg(DISTINCT ur.role) FILTER (WHERE ur.role IS NOT NULL), '{}'), COALESCE(array_agg(DISTINCT rp.permission) FILTER (WHERE rp.permission IS NOT NULL), '{}')
+		FROM users u LEFT JOIN user_roles ur ON ur.user_id = u.id LEFT JOIN role_permissions rp ON rp.role = ur.role WHERE u.id = $1 GROUP BY u.id;`
+	// scopes that aren't permissions are kept as is, permissions only if one of the roles of the user grants them
+	queryGrantableScopes = `SELECT COALESCE(array_agg(s), '{}') FROM unnest($1::text[]) s
+		WHERE NOT EXISTS(SELECT * FROM permissions p WHERE p.name = s)
+		OR EXISTS(SELECT * FROM user_roles ur JOIN role_permissions rp ON rp.role = ur.role WHERE ur.user_id = $2 AND rp.permission = s);`
+	queryGetRoles = `SELECT r.name, r.description, COALESCE(array_agg(rp.permission ORDER BY rp.permission) FILTER (WHERE rp.permission IS NOT NULL), '{}'), r.created_at
+		FROM roles r LEFT JOIN role_permissions rp ON rp.role = r.name GROUP BY r.name ORDER BY r.name;`
+	// the role is upserted and its permissions are replaced in one statement
+	queryPutRole = `WITH role AS (INSERT INTO roles(name, description, created_at) VALUES($1, $2, $3) ON CONFLICT (name) DO UPDATE SET description = EXCLUDED.description RETURNING name),
+		removed AS (DELETE FROM role_permissions WHERE role = $1 AND permission <> ALL($4::text[]))
+		INSERT INTO role_permissions(role, permission) SELECT (SELECT name FROM role), unnest($4::text[]) ON CONFLICT DO NOTHING;`
+	queryDeleteRole       = "DELETE FROM roles WHERE name = $1;"
+	queryGetPermissions   = "SELECT name, description FROM permissions ORDER BY name;"
+	queryPutPermission    = "INSERT INTO permissions(name, description) VALUES($1, $2) ON CONFLICT (name) DO UPDATE SET description = EXCLUDED.description;"
+	queryDeletePermission = "DELETE FROM permissions WHERE name = $1;"
+	queryAddUserRole      = "INSERT INTO user_roles(user_id, role, granted_at) VALUES($1, $2, $3) ON CONFLICT DO NOTHING;"
+	queryDeleteUserRole   = "DELETE FROM user_roles WHERE user_id = $1 AND role = $2;"
+)
+
+const (
+	pgForeignKeyViolation        = "23503"
+	constraintUserRolesUser      = "user_roles_user_id_fkey"
+	constraintUserRolesRole      = "user_roles_role_fkey"
+	constraintRolePermissionsRef = "role_permissions_permission_fkey"
+)
+
+var (
+	ErrRoleNotFound       = errors.New("role doesn't exist")
+	ErrPermissionNotFound = errors.New("permission doesn't exist")
+)
+
+// GetUserAccess fills the user name and the status of the user, their roles and the permissions they grant.
+func (repository *UserRepositry) GetUserAccess(ctx context.Context, user *models.User) error {
+	if err := repository.pool.QueryRow(ctx, queryGetUserAccess, user.UserId).Scan(&user.UserName, &user.Status, &user.Roles, &user.Permissions); err != nil {
+		if err == pgx.ErrNoRows {
+			return apierror.NewCatalogError(apierror.CodeUserNotFound, ErrUserNotFound.Error())
+		}
+		return err
+	}
+	return nil
+}
+
+// GrantableScopes drops the scopes that name a permission the user doesn't have.
+func (repository *UserRepositry) GrantableScopes(ctx context.Context, user *models.User, scopes []string) ([]string, error) {
+	var grantable []string
+	if err := repository.pool.QueryRow(ctx, queryGrantableScopes, scopes, user.UserId).Scan(&grantable); err != nil {
+		return nil, err
+	}
+	return grantable, nil
+}
+
+func (repository *UserRepositry) GetRoles(ctx context.Context) ([]models.Role, error) {
+	rows, err := repository.pool.Query(ctx, queryGetRoles)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	roles := []models.Role{}
+	for rows.Next() {
+		var role models.Role
+		if err := rows.Scan(&role.Name, &role.Description, &role.Permissions, &role.CreatedAt); err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+	return roles, rows.Err()
+}
+
+// PutRole creates the role or updates its description, the permissions of the role are replaced with role.Permissions.
+func (repository *UserRepositry) PutRole(ctx context.Context, role *models.Role) error {
+	if _, err := repository.pool.Exec(ctx, queryPutRole, role.Name, role.Description, role.CreatedAt, role.Permissions); err != nil {
+		return foreignKeyViolation(err)
+	}
+	return nil
+}
+
+func (repository *UserRepositry) DeleteRole(ctx context.Context, role *models.Role) error {
+	commandTag, err := repository.pool.Exec(ctx, queryDeleteRole, role.Name)
+	if err != nil {
+		return err
+	}
+	if commandTag.RowsAffected() == 0 {
+		return apierror.NewCatalogError(apierror.CodeRoleNotFound, ErrRoleNotFound.Error())
+	}
+	return nil
+}
+
+func (repository *UserRepositry) GetPermissions(ctx context.Context) ([]models.Permission, error) {
+	rows, err := repository.pool.Query(ctx, queryGetPermissions)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	permissions := []models.Permission{}
+	for rows.Next() {
+		var permission models.Permission
+		if err := rows.Scan(&permission.Name, &permission.Description); err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, permission)
+	}
+	return permissions, rows.Err()
+}
+
+func (repository *UserRepositry) PutPermission(ctx context.Context, permission *models.Permission) error {
+	_, err := repository.pool.Exec(ctx, queryPutPermission, permission.Name, permission.Description)
+	return err
+}
+
+// DeletePermission removes the permission from every role as well.
+func (repository *UserRepositry) DeletePermission(ctx context.Context, permission *models.Permission) error {
+	commandTag, err := repository.pool.Exec(ctx, queryDeletePermission, permission.Name)
+	if err != nil {
+		return err
+	}
+	if commandTag.RowsAffected() == 0 {
+		return apierror.NewCatalogError(apierror.CodePermissionNotFound, ErrPermissionNotFound.Error())
+	}
+	return nil
+}
+
+// AddUserRole assigns the role, assigning it twice isn't an error.
+func (repository *UserRepositry) AddUserRole(ctx context.Context, userRole *models.UserRole) error {
+	if _, err := repository.pool.Exec(ctx, queryAddUserRole, userRole.UserId, userRole.Role, userRole.GrantedAt); err != nil {
+		return foreignKeyViolation(err)
+	}
+	return nil
+}
+
+func (repository *UserRepositry) DeleteUserRole(ctx context.Context, userRole *models.UserRole) error {
+	commandTag, err := repository.pool.Exec(ctx, queryDeleteUserRole, userRole.UserId, userRole.Role)
+	if err != nil {
+		return err
+	}
+	if commandTag.RowsAffected() == 0 {
+		return apierror.NewCatalogError(apierror.CodeRoleNotFound, ErrRoleNotFound.Error())
+	}
+	return nil
+}
+
+// foreignKeyViolation tells which referenced row doesn't exist, any other error is returned as is.
+func foreignKeyViolation(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != pgForeignKeyViolation {
+		return err
+	}
+	switch pgErr.ConstraintName {
+	case constraintUserRolesUser:
+		return apierror.NewCatalogError(apierror.CodeUserNotFound, ErrUserNotFound.Error())
+	case constraintRolePermissionsRef:
+		return apierror.NewCatalogError(apierror.CodePermissionNotFound, ErrPermissionNotFound.Error())
+	default:
+		return apierror.NewCatalogError(apierror.CodeRoleNotFound, ErrRoleNotFound.Error())
+	}
+}
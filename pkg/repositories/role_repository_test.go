@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/chrisyxlee/pgxpoolmock"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/jackc/pgconn"
+)
+
+func TestGetUserAccess(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	MockPool := pgxpoolmock.NewMockPgxIface(ctrl)
+	repository := NewUserRepository(MockPool)
+	user := &models.User{UserId: uuid.New()}
+	MockPool.EXPECT().QueryRow(gomock.Any(), queryGetUserAccess, user.UserId).Return(pgxpoolmock.NewRow("username", models.UserStatusActive, []string{"admin"}, []string{models.PermissionRolesManage})).Times(1)
+	if err := repository.GetUserAccess(context.TODO(), user); err != nil || user.UserName != "username" || len(user.Roles) != 1 || user.Permissions[0] != models.PermissionRolesManage {
+		t.FailNow()
+	}
+}
+
+func TestGetRoles(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	MockPool := pgxpoolmock.NewMockPgxIface(ctrl)
+	repository := NewUserRepository(MockPool)
+	rows := pgxpoolmock.NewRows([]string{"name", "description", "permissions", "created_at"}).
+		AddRow("admin", "Administrators", []string{models.PermissionRolesManage}, time.Now().UTC()).
+		AddRow("teacher", "", []string{}, time.Now().UTC()).ToPgxRows()
+	MockPool.EXPECT().Query(gomock.Any(), queryGetRoles).Return(rows, nil).Times(1)
+	roles, err := repository.GetRoles(context.TODO())
+	if err != nil || len(roles) != 2 || roles[0].Permissions[0] != models.PermissionRolesManage {
+		t.FailNow()
+	}
+}
+
+func TestAddUserRole(t *testing.T) {
+	testCases := []struct {
+		name    string
+		err     error
+		errCode string
+	}{
+		{
+			name: "granted",
+		},
+		{
+			name:    "unknown_role",
+			err:     &pgconn.PgError{Code: pgForeignKeyViolation, ConstraintName: constraintUserRolesRole},
+			errCode: apierror.CodeRoleNotFound,
+		},
+		{
+			name:    "unknown_user",
+			err:     &pgconn.PgError{Code: pgForeignKeyViolation, ConstraintName: constraintUserRolesUser},
+			errCode: apierror.CodeUserNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			MockPool := pgxpoolmock.NewMockPgxIface(ctrl)
+			repository := NewUserRepository(MockPool)
+			userRole := &models.UserRole{UserId: uuid.New(), Role: "admin", GrantedAt: time.Now().UTC()}
+			MockPool.EXPECT().Exec(gomock.Any(), queryAddUserRole, userRole.UserId, userRole.Role, userRole.GrantedAt).Return(pgconn.CommandTag("INSERT 0 1"), tc.err).Times(1)
+			err := repository.AddUserRole(context.TODO(), userRole)
+			if tc.errCode != "" {
+				if err.(*apierror.ErrorStruct).ErrorCode != tc.errCode {
+					t.FailNow()
+				}
+				return
+			}
+			if err != nil {
+				t.FailNow()
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	queryAddPersonalAccessToken    = "INSERT INTO personal_access_tokens(id, user_id, name, token_hash, scopes, created_at, expiration_time) VALUES($1, $2, $3, $4, $5, $6, $7);"
+	queryGetPersonalAccessTokens   = "SELECT id, name, scopes, created_at, expiration_time, last_used_at FROM personal_access_tokens WHERE user_id = $1 ORDER BY created_at;"
+	queryDeletePersonalAccessToken = "DELETE FROM personal_access_tokens WHERE id = $1 AND user_id = $2;"
+	queryUsePersonalAccessToken    = "UPDATE personal_access_tokens SET last_used_at = $2 WHERE token_hash = $1 AND (expiration_time IS NULL OR expiration_time > $2) AND user_id IN (SELECT id FROM users WHERE status = 'active') RETURNING id, user_id, name, scopes, created_at, expiration_time;"
+)
+
+var ErrPersonalAccessTokenNotFound = errors.New("personal access token doesn't exist")
+
+func (repository *UserRepositry) AddPersonalAccessToken(ctx context.Context, token *models.PersonalAccessToken) error {
+	_, err := repository.pool.Exec(ctx, queryAddPersonalAccessToken, token.TokenId, token.UserId, token.Name, token.TokenHash, token.Scopes, token.CreatedAt, token.ExpirationTime)
+	return err
+}
+
+func (repository *UserRepositry) GetPersonalAccessTokens(ctx context.Context, user *models.User) ([]models.PersonalAccessToken, error) {
+	rows, err := repository.pool.Query(ctx, queryGetPersonalAccessTokens, user.UserId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	tokens := make([]models.PersonalAccessToken, 0)
+	for rows.Next() {
+		token := models.PersonalAccessToken{UserId: user.UserId}
+		if err := rows.Scan(&token.TokenId, &token.Name, &token.Scopes, &token.CreatedAt, &token.ExpirationTime, &token.LastUsedAt); err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, rows.Err()
+}
+
+func (repository *UserRepositry) DeletePersonalAccessToken(ctx context.Context, token *models.PersonalAccessToken) error {
+	commandTag, err := repository.pool.Exec(ctx, queryDeletePersonalAccessToken, token.TokenId, token.UserId)
+	if err != nil {
+		return err
+	}
+	if commandTag.RowsAffected() == 0 {
+		return apierror.NewCatalogError(apierror.CodePersonalAccessTokenNotFound, ErrPersonalAccessTokenNotFound.Error())
+	}
+	return nil
+}
+
+// UsePersonalAccessToken looks the token up by its hash and records last_used_at, result is false if it expired or doesn't exist.
+func (repository *UserRepositry) UsePersonalAccessToken(ctx context.Context, token *models.PersonalAccessToken, result *bool) error {
+	now := time.Now().UTC()
+	if err := repository.pool.QueryRow(ctx, queryUsePersonalAccessToken, token.TokenHash, now).Scan(&token.TokenId, &token.UserId, &token.Name, &token.Scopes, &token.CreatedAt, &token.ExpirationTime); err != nil {
+		if err == pgx.ErrNoRows {
+			*result = false
+			return nil
+		}
+		return err
+	}
+	token.LastUsedAt = &now
+	*result = true
+	return nil
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/chrisyxlee/pgxpoolmock"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+func TestUsePersonalAccessToken(t *testing.T) {
+	var expirationTime *time.Time
+	testCases := []struct {
+		name  string
+		row   *pgxpoolmock.Row
+		found bool
+	}{
+		{
+			name:  "found",
+			row:   pgxpoolmock.NewRow(uuid.New(), uuid.New(), "import", []string{"words:write"}, time.Now().UTC(), expirationTime),
+			found: true,
+		},
+		{
+			name: "expired_or_unknown",
+			row:  pgxpoolmock.NewRow(uuid.UUID{}, uuid.UUID{}, "", []string{}, time.Time{}, expirationTime).WithError(pgx.ErrNoRows),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			MockPool := pgxpoolmock.NewMockPgxIface(ctrl)
+			repository := NewUserRepository(MockPool)
+			token := &models.PersonalAccessToken{TokenHash: "hash"}
+			MockPool.EXPECT().QueryRow(gomock.Any(), queryUsePersonalAccessToken, token.TokenHash, gomock.Any()).Return(tc.row).Times(1)
+			found := new(bool)
+			if err := repository.UsePersonalAccessToken(context.TODO(), token, found); err != nil || *found != tc.found {
+				t.FailNow()
+			}
+			if tc.found && token.LastUsedAt == nil {
+				t.FailNow()
+			}
+		})
+	}
+}
+
+func TestGetPersonalAccessTokens(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	MockPool := pgxpoolmock.NewMockPgxIface(ctrl)
+	repository := NewUserRepository(MockPool)
+	user := &models.User{UserId: uuid.New()}
+	var noTime *time.Time
+	rows := pgxpoolmock.NewRows([]string{"id", "name", "scopes", "created_at", "expiration_time", "last_used_at"}).
+		AddRow(uuid.New(), "import", []string{}, time.Now().UTC(), noTime, noTime).
+		AddRow(uuid.New(), "backup", []string{"words:read"}, time.Now().UTC(), noTime, noTime).ToPgxRows()
+	MockPool.EXPECT().Query(gomock.Any(), queryGetPersonalAccessTokens, user.UserId).Return(rows, nil).Times(1)
+	tokens, err := repository.GetPersonalAccessTokens(context.TODO(), user)
+	if err != nil || len(tokens) != 2 || tokens[1].Name != "backup" {
+		t.FailNow()
+	}
+}
@@ -3,29 +3,40 @@ package repository
 import (
 	"context"
 	"errors"
-	"net/http"
 	"time"
 
 	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
 	"github.com/Kin-dza-dzaa/userApi/internal/models"
-	"github.com/google/uuid"
 	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v4"
 )
 
 const (
-	queryCreateUser             = "INSERT INTO users(id, user_name, email, password, registration_date, verification_code, verified) VALUES($1, $2, $3, $4, $5, $6, $7);"
-	queryUpdateCreditnails      = "UPDATE users SET user_name = $1, password = $2, verification_code = $3 WHERE email = $4;"
-	queryVerifyUser 	        = "UPDATE users SET verified = true, refresh_token = $1, expiration_time = $2, verification_code = '' WHERE verification_code = $3 AND verified = false;" 
-	queryGetUUid		        = "SELECT id FROM users WHERE refresh_token = $1 AND verified = true AND expiration_time > $2;"
-	queryGetVerifiedUser        = "SELECT id, password, refresh_token, expiration_time FROM users WHERE email = $1 AND verified = true;"
-	queryUpdateRefreshToken     = "UPDATE users SET refresh_token = $1, expiration_time = $2 WHERE email = $3;"
-	queryIfUnverifiedUserExists = "SELECT EXISTS(SELECT * FROM USERS WHERE email = $1 AND verified = false);"
+	queryCreateUser             = "INSERT INTO users(id, user_name, email, password, registration_date, verification_code, status, status_changed_at, user_name_canonical) VALUES($1, $2, $3, $4, $5, $6, $7, $5, $8);"
+	queryUpdateCreditnails      = "UPDATE users SET user_name = $1, password = $2, verification_code = $3, user_name_canonical = $5 WHERE lower(email) = lower($4);"
+	queryVerifyUser 	        = "UPDATE users SET status = 'active', status_changed_at = $4, refresh_token = $1, expiration_time = $2, session_started_at = $5, remember_me = $6, verification_code = '' WHERE verification_code = $3 AND status = 'pending';" 
+	queryGetUUid		        = "SELECT id, status, expiration_time, COALESCE(session_started_at, registration_date), remember_me FROM users WHERE refresh_token = $1;"
+	queryGetSignInUser          = "SELECT id, password, refresh_token, expiration_time, status, COALESCE(session_started_at, registration_date), remember_me FROM users WHERE lower(email) = lower($1) AND status NOT IN ('pending', 'deleted');"
+	queryUpdateRefreshToken     = "UPDATE users SET remember_me = $4 OR (remember_me AND refresh_token = $1), refresh_token = $1, expiration_time = $2, session_started_at = $5 WHERE lower(email) = lower($3);"
+	queryIfUnverifiedUserExists = "SELECT EXISTS(SELECT * FROM USERS WHERE lower(email) = lower($1) AND status = 'pending');"
+	queryIfVerifiedUserExists   = "SELECT EXISTS(SELECT * FROM USERS WHERE lower(email) = lower($1) AND status <> 'pending');"
+	queryIfUserNameExists       = "SELECT EXISTS(SELECT * FROM users WHERE user_name_canonical = $1);"
+	queryGetPassword            = "SELECT password, status FROM users WHERE id = $1 AND status <> 'deleted';"
+	queryExtendSession          = "UPDATE users SET expiration_time = $2 WHERE refresh_token = $1 AND expiration_time < $2;"
+)
+
+const (
+	pgUniqueViolation = "23505"
+	constraintUserName = "users_user_name_key"
+	constraintUserNameCanonical = "users_user_name_canonical_key"
+	constraintEmail = "users_email_lower_key"
 )
 
 var (
 	ErrWrongVerificationCode = errors.New("wrong verification code")
 	ErrUserAlredyExists = errors.New("user already exists")
+	ErrUserNameTaken = errors.New("user name already taken")
+	ErrEmailTaken = errors.New("email already taken")
 	ErrUserDoesntExists = errors.New("refresh token expired or user doesn't exists")
 	ErrWrongEmail = errors.New("wrong email")
 )
@@ -35,59 +46,77 @@ type UserRepositry struct {
 }
 
 func (repository *UserRepositry) AddUser(ctx context.Context, user *models.User) error {
-	if _, err := repository.pool.Exec(ctx, queryCreateUser, user.UserId, user.UserName, user.Email, user.Password, user.RegistrationTime, user.VerificationCode, user.Verified); err != nil {
-		return apierror.NewErrorStruct(ErrUserAlredyExists.Error(), "error", http.StatusBadRequest)
+	if _, err := repository.pool.Exec(ctx, queryCreateUser, user.UserId, user.UserName, user.Email, user.Password, user.RegistrationTime, user.VerificationCode, user.Status, models.CanonicalUserName(user.UserName)); err != nil {
+		return uniqueViolation(err)
 	}
 	return nil
 }
 
 func (repository *UserRepositry) UpdateCredentials(ctx context.Context, user *models.User) error {
-	if _, err := repository.pool.Exec(ctx, queryUpdateCreditnails, user.UserName, user.Password, user.VerificationCode, user.Email); err != nil {
-		return apierror.NewErrorStruct(ErrUserAlredyExists.Error(), "error", http.StatusBadRequest)
+	if _, err := repository.pool.Exec(ctx, queryUpdateCreditnails, user.UserName, user.Password, user.VerificationCode, user.Email, models.CanonicalUserName(user.UserName)); err != nil {
+		return uniqueViolation(err)
 	}
 	return nil
 }
 
 func (repository *UserRepositry) VerifyUser(ctx context.Context, user *models.User) error {
-	commandTag, err := repository.pool.Exec(ctx, queryVerifyUser, user.RefreshToken, user.ExpirationTime, user.VerificationCode)
+	commandTag, err := repository.pool.Exec(ctx, queryVerifyUser, user.RefreshToken, user.ExpirationTime, user.VerificationCode, time.Now().UTC(), user.SessionStartedAt, user.RememberMe)
 	if err != nil {
 		return err
 	}
 	if commandTag.RowsAffected() == 0 {
-		return apierror.NewErrorStruct(ErrWrongVerificationCode.Error(), "error", http.StatusBadRequest)
+		return apierror.NewCatalogError(apierror.CodeWrongVerificationCode, ErrWrongVerificationCode.Error())
 	}
 	return nil
 }
 
+// GetUUid fills user.UserId, user.Status, user.ExpirationTime, user.SessionStartedAt and user.RememberMe
+// of the refresh token, it is up to the caller to check them.
 func (repository *UserRepositry) GetUUid(ctx context.Context, user *models.User) error {
-	var userId string
-	if err := repository.pool.QueryRow(ctx, queryGetUUid, user.RefreshToken, time.Now().UTC()).Scan(&userId); err != nil {
+	if err := repository.pool.QueryRow(ctx, queryGetUUid, user.RefreshToken).Scan(&user.UserId, &user.Status, &user.ExpirationTime, &user.SessionStartedAt, &user.RememberMe); err != nil {
 		if err == pgx.ErrNoRows {
-			return apierror.NewErrorStruct(ErrUserDoesntExists.Error(), "error", http.StatusBadRequest)
+			return apierror.NewCatalogError(apierror.CodeRefreshTokenInvalid, ErrUserDoesntExists.Error())
 		}
 		return err
 	}
-	UUid, err := uuid.Parse(userId)
-	if err != nil {
-		return apierror.NewErrorStruct(ErrUserDoesntExists.Error(), "error", http.StatusBadRequest)
-	}
-	user.UserId = UUid
 	return nil
 }
 
-func (repository *UserRepositry) GetVerifiedUser(ctx context.Context, user *models.User) (*models.User, error) {
+// GetSignInUser returns the user with the email unless they are pending or deleted, the status of the user has to be checked.
+func (repository *UserRepositry) GetSignInUser(ctx context.Context, user *models.User) (*models.User, error) {
 	var dbUser models.User
-	if err := repository.pool.QueryRow(ctx, queryGetVerifiedUser, user.Email).Scan(&dbUser.UserId, &dbUser.Password, &dbUser.RefreshToken, &dbUser.ExpirationTime); err != nil {
+	if err := repository.pool.QueryRow(ctx, queryGetSignInUser, user.Email).Scan(&dbUser.UserId, &dbUser.Password, &dbUser.RefreshToken, &dbUser.ExpirationTime, &dbUser.Status, &dbUser.SessionStartedAt, &dbUser.RememberMe); err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, apierror.NewErrorStruct(ErrWrongEmail.Error(), "error", http.StatusBadRequest)
+			return nil, apierror.NewCatalogError(apierror.CodeWrongEmail, ErrWrongEmail.Error())
 		}
 		return nil, err
 	}
 	return &dbUser, nil
 }
 
+// UpdateRefreshToken stores the session of user, a session that keeps its refresh token stays remembered if it was.
 func (repository *UserRepositry) UpdateRefreshToken(ctx context.Context, user *models.User) error {
-	_, err := repository.pool.Exec(ctx, queryUpdateRefreshToken, user.RefreshToken, user.ExpirationTime, user.Email)
+	_, err := repository.pool.Exec(ctx, queryUpdateRefreshToken, user.RefreshToken, user.ExpirationTime, user.Email, user.RememberMe, user.SessionStartedAt)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetPassword fills the password hash and the status of the user with the id, deleted users aren't found.
+func (repository *UserRepositry) GetPassword(ctx context.Context, user *models.User) error {
+	if err := repository.pool.QueryRow(ctx, queryGetPassword, user.UserId).Scan(&user.Password, &user.Status); err != nil {
+		if err == pgx.ErrNoRows {
+			return apierror.NewCatalogError(apierror.CodeUserNotFound, ErrUserNotFound.Error())
+		}
+		return err
+	}
+	return nil
+}
+
+// ExtendSession moves the expiration of the refresh token forward to user.ExpirationTime, it never shortens a session.
+func (repository *UserRepositry) ExtendSession(ctx context.Context, user *models.User) error {
+	_, err := repository.pool.Exec(ctx, queryExtendSession, user.RefreshToken, user.ExpirationTime)
 	if err != nil {
 		return err
 	}
@@ -101,6 +130,37 @@ func (repository *UserRepositry) IfUnverifiedUserExists(ctx context.Context, use
 	return nil
 }
 
+func (repository *UserRepositry) IfVerifiedUserExists(ctx context.Context, user *models.User, result *bool) (error) {
+	if err := repository.pool.QueryRow(ctx, queryIfVerifiedUserExists, user.Email).Scan(result); err != nil {
+		return err
+	}
+	return nil
+}
+
+// IfUserNameExists compares canonical user names, a name is taken if any user, pending ones included, has the same canonical form.
+func (repository *UserRepositry) IfUserNameExists(ctx context.Context, user *models.User, result *bool) error {
+	if err := repository.pool.QueryRow(ctx, queryIfUserNameExists, models.CanonicalUserName(user.UserName)).Scan(result); err != nil {
+		return err
+	}
+	return nil
+}
+
+// uniqueViolation tells which unique constraint was hit, any other error is returned as is.
+func uniqueViolation(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != pgUniqueViolation {
+		return err
+	}
+	switch pgErr.ConstraintName {
+	case constraintUserName, constraintUserNameCanonical:
+		return apierror.NewCatalogError(apierror.CodeUserNameTaken, ErrUserNameTaken.Error())
+	case constraintEmail:
+		return apierror.NewCatalogError(apierror.CodeEmailTaken, ErrEmailTaken.Error())
+	default:
+		return apierror.NewCatalogError(apierror.CodeUserAlreadyExists, ErrUserAlredyExists.Error())
+	}
+}
+
 func NewUserRepository(pool dbconn) *UserRepositry {
 	return &UserRepositry{
 		pool: pool,
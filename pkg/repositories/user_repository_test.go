@@ -2,7 +2,6 @@ package repository
 
 import (
 	"context"
-	"net/http"
 	"reflect"
 	"testing"
 	"time"
@@ -12,6 +11,8 @@ import (
 	"github.com/chrisyxlee/pgxpoolmock"
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
 )
 
 func TestAddUser(t *testing.T) {
@@ -31,7 +32,7 @@ func TestAddUser(t *testing.T) {
 				user: new(models.User),
 			},
 			beforeTest: func(mockPool *pgxpoolmock.MockPgxIface, user *models.User) {
-				mockPool.EXPECT().Exec(gomock.Any(), queryCreateUser, user.UserId, user.UserName, user.Email, user.Password, user.RegistrationTime, user.VerificationCode, user.Verified).Return(nil, nil).Times(1)
+				mockPool.EXPECT().Exec(gomock.Any(), queryCreateUser, user.UserId, user.UserName, user.Email, user.Password, user.RegistrationTime, user.VerificationCode, user.Status, models.CanonicalUserName(user.UserName)).Return(nil, nil).Times(1)
 			},
 		},
 	}
@@ -66,7 +67,7 @@ func TestUpdateCredentials(t *testing.T) {
 				user: new(models.User),
 			},
 			beforeTest: func(mockPool *pgxpoolmock.MockPgxIface, user *models.User) {
-				mockPool.EXPECT().Exec(gomock.Any(), queryUpdateCreditnails, user.UserName, user.Password, user.VerificationCode, user.Email).Return(nil, nil).Times(1)
+				mockPool.EXPECT().Exec(gomock.Any(), queryUpdateCreditnails, user.UserName, user.Password, user.VerificationCode, user.Email, models.CanonicalUserName(user.UserName)).Return(nil, nil).Times(1)
 			},
 		},
 	}
@@ -102,9 +103,9 @@ func TestVerifyUser(t *testing.T) {
 				user: new(models.User),
 			},
 			beforeTest: func(mockPool *pgxpoolmock.MockPgxIface, user *models.User) {
-				mockPool.EXPECT().Exec(gomock.Any(), queryVerifyUser, user.RefreshToken, user.ExpirationTime, user.VerificationCode).Return(nil, nil).Times(1)
+				mockPool.EXPECT().Exec(gomock.Any(), queryVerifyUser, user.RefreshToken, user.ExpirationTime, user.VerificationCode, gomock.Any(), user.SessionStartedAt, user.RememberMe).Return(nil, nil).Times(1)
 			},
-			err: apierror.NewErrorStruct(ErrWrongVerificationCode.Error(), "error", http.StatusBadRequest),
+			err: apierror.NewCatalogError(apierror.CodeWrongVerificationCode, ErrWrongVerificationCode.Error()),
 		},
 	}
 	for _, tc := range testCases {
@@ -144,7 +145,7 @@ func TestGetUUid(t *testing.T) {
 				user: new(models.User),
 			},
 			beforeTest: func(mockPool *pgxpoolmock.MockPgxIface, user *models.User) {
-				mockPool.EXPECT().QueryRow(gomock.Any(), queryGetUUid, user.RefreshToken, gomock.Any()).Return(pgxpoolmock.NewRow(uuid.New().String())).Times(1)
+				mockPool.EXPECT().QueryRow(gomock.Any(), queryGetUUid, user.RefreshToken).Return(pgxpoolmock.NewRow(uuid.New(), models.UserStatusActive, time.Now().UTC(), time.Now().UTC(), true)).Times(1)
 			},
 		},
 	}
@@ -162,7 +163,7 @@ func TestGetUUid(t *testing.T) {
 	}
 }
 
-func TestGetVerifiedUser(t *testing.T) {
+func TestGetSignInUser(t *testing.T) {
 	// just to make sure that no one will mess with an order of the func paramaters
 	testCases := []struct{
 		name string
@@ -173,7 +174,7 @@ func TestGetVerifiedUser(t *testing.T) {
 		beforeTest func(mockPool *pgxpoolmock.MockPgxIface, user *models.User)
 	}{
 		{
-			name: "GetVerifiedUser",
+			name: "GetSignInUser",
 			args: struct{ctx context.Context; user *models.User}{
 				ctx: context.TODO(),
 				user: &models.User{
@@ -181,10 +182,11 @@ func TestGetVerifiedUser(t *testing.T) {
 					Password: "testPassword",
 					RefreshToken: "testToken",
 					ExpirationTime: time.Now().UTC(),
+					Status: models.UserStatusSuspended,
 				},
 			},
 			beforeTest: func(mockPool *pgxpoolmock.MockPgxIface, user *models.User) {
-				mockPool.EXPECT().QueryRow(gomock.Any(), queryGetVerifiedUser, user.Email).Return(pgxpoolmock.NewRow(user.UserId, user.Password, user.RefreshToken, user.ExpirationTime)).Times(1)
+				mockPool.EXPECT().QueryRow(gomock.Any(), queryGetSignInUser, user.Email).Return(pgxpoolmock.NewRow(user.UserId, user.Password, user.RefreshToken, user.ExpirationTime, user.Status, user.SessionStartedAt, user.RememberMe)).Times(1)
 			},
 		},
 	}
@@ -195,7 +197,7 @@ func TestGetVerifiedUser(t *testing.T) {
 			MockPool := pgxpoolmock.NewMockPgxIface(ctrl)
 			repository := NewUserRepository(MockPool)
 			tc.beforeTest(MockPool, tc.args.user)
-			user, err := repository.GetVerifiedUser(tc.args.ctx, tc.args.user)
+			user, err := repository.GetSignInUser(tc.args.ctx, tc.args.user)
 			if !reflect.DeepEqual(user, tc.args.user) || err != nil {
 				t.FailNow()
 			}
@@ -220,7 +222,7 @@ func TestUpdateRefreshToken(t *testing.T) {
 				user: new(models.User),
 			},
 			beforeTest: func(mockPool *pgxpoolmock.MockPgxIface, user *models.User) {
-				mockPool.EXPECT().Exec(gomock.Any(), queryUpdateRefreshToken, user.RefreshToken, user.ExpirationTime, user.Email).Return(nil, nil).Times(1)
+				mockPool.EXPECT().Exec(gomock.Any(), queryUpdateRefreshToken, user.RefreshToken, user.ExpirationTime, user.Email, user.RememberMe, user.SessionStartedAt).Return(nil, nil).Times(1)
 			},
 		},
 	}
@@ -238,6 +240,30 @@ func TestUpdateRefreshToken(t *testing.T) {
 	}
 }
 
+func TestGetPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	MockPool := pgxpoolmock.NewMockPgxIface(ctrl)
+	repository := NewUserRepository(MockPool)
+	user := &models.User{UserId: uuid.New()}
+	MockPool.EXPECT().QueryRow(gomock.Any(), queryGetPassword, user.UserId).Return(pgxpoolmock.NewRow("", "").WithError(pgx.ErrNoRows)).Times(1)
+	if err := repository.GetPassword(context.TODO(), user); err.(*apierror.ErrorStruct).ErrorCode != apierror.CodeUserNotFound {
+		t.FailNow()
+	}
+}
+
+func TestExtendSession(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	MockPool := pgxpoolmock.NewMockPgxIface(ctrl)
+	repository := NewUserRepository(MockPool)
+	user := &models.User{RefreshToken: "token", ExpirationTime: time.Now().UTC()}
+	MockPool.EXPECT().Exec(gomock.Any(), queryExtendSession, user.RefreshToken, user.ExpirationTime).Return(nil, nil).Times(1)
+	if err := repository.ExtendSession(context.TODO(), user); err != nil {
+		t.FailNow()
+	}
+}
+
 func TestIfUnverifiedUserExists(t *testing.T) {
 	// just to make sure that no one will mess with an order of the func paramaters
 	testCases := []struct{
@@ -272,4 +298,57 @@ func TestIfUnverifiedUserExists(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+func TestAddUserUniqueViolation(t *testing.T) {
+	testCases := []struct{
+		name string
+		execErr error
+		err error
+	}{
+		{
+			name: "user_name_taken",
+			execErr: &pgconn.PgError{Code: pgUniqueViolation, ConstraintName: constraintUserName},
+			err: apierror.NewCatalogError(apierror.CodeUserNameTaken, ErrUserNameTaken.Error()),
+		},
+		{
+			name: "canonical_user_name_taken",
+			execErr: &pgconn.PgError{Code: pgUniqueViolation, ConstraintName: constraintUserNameCanonical},
+			err: apierror.NewCatalogError(apierror.CodeUserNameTaken, ErrUserNameTaken.Error()),
+		},
+		{
+			name: "email_taken",
+			execErr: &pgconn.PgError{Code: pgUniqueViolation, ConstraintName: constraintEmail},
+			err: apierror.NewCatalogError(apierror.CodeEmailTaken, ErrEmailTaken.Error()),
+		},
+		{
+			name: "infrastructure_error",
+			execErr: context.DeadlineExceeded,
+			err: context.DeadlineExceeded,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			MockPool := pgxpoolmock.NewMockPgxIface(ctrl)
+			repository := NewUserRepository(MockPool)
+			user := new(models.User)
+			MockPool.EXPECT().Exec(gomock.Any(), queryCreateUser, user.UserId, user.UserName, user.Email, user.Password, user.RegistrationTime, user.VerificationCode, user.Status, models.CanonicalUserName(user.UserName)).Return(nil, tc.execErr).Times(1)
+			if err := repository.AddUser(context.TODO(), user); !reflect.DeepEqual(tc.err, err) {
+				t.FailNow()
+			}
+		})
+	}
+}
+
+func TestIfUserNameExists(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	MockPool := pgxpoolmock.NewMockPgxIface(ctrl)
+	repository := NewUserRepository(MockPool)
+	MockPool.EXPECT().QueryRow(gomock.Any(), queryIfUserNameExists, "alice_smith").Return(pgxpoolmock.NewRow(true)).Times(1)
+	exists := new(bool)
+	if err := repository.IfUserNameExists(context.TODO(), &models.User{UserName: "ALICE_Smith"}, exists); err != nil || !*exists {
+		t.FailNow()
+	}
+}
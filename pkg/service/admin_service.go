@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/dchest/uniuri"
+	"github.com/google/uuid"
+)
+
+const passwordResetTtl = time.Hour
+
+var (
+	ErrAdminSelfAction         = errors.New("admins can't suspend, lock or delete themselves")
+	ErrStatusTransitionInvalid = errors.New("account status can't change that way")
+)
+
+// SearchUsers and GetAdminUser take the admin doing them, the audit log records it.
+func (service *UserService) SearchUsers(ctx context.Context, actorId uuid.UUID, filter *models.UserFilter) (*models.UserPage, error) {
+	return service.repository.SearchUsers(ctx, filter)
+}
+
+func (service *UserService) GetAdminUser(ctx context.Context, actorId uuid.UUID, user *models.AdminUser) error {
+	return service.repository.GetAdminUser(ctx, user)
+}
+
+// AdminVerifyUser activates a pending user without the verification code.
+func (service *UserService) AdminVerifyUser(ctx context.Context, action *models.AdminAction) error {
+	return service.changeUserStatus(ctx, action, models.UserStatusActive, models.UserStatusPending)
+}
+
+// SuspendUser blocks sign in, refresh and personal access tokens of the user and ends their sessions.
+func (service *UserService) SuspendUser(ctx context.Context, action *models.AdminAction) error {
+	if action.ActorId == action.TargetId {
+		return apierror.NewCatalogError(apierror.CodeAdminSelfAction, ErrAdminSelfAction.Error())
+	}
+	if err := service.changeUserStatus(ctx, action, models.UserStatusSuspended); err != nil {
+		return err
+	}
+	return service.repository.RevokeSessions(ctx, &models.User{UserId: action.TargetId})
+}
+
+func (service *UserService) UnsuspendUser(ctx context.Context, action *models.AdminAction) error {
+	return service.changeUserStatus(ctx, action, models.UserStatusActive, models.UserStatusSuspended)
+}
+
+// LockUser puts a security hold on the user and ends their sessions, a password reset or UnlockUser lifts it.
+func (service *UserService) LockUser(ctx context.Context, action *models.AdminAction) error {
+	if action.ActorId == action.TargetId {
+		return apierror.NewCatalogError(apierror.CodeAdminSelfAction, ErrAdminSelfAction.Error())
+	}
+	if err := service.changeUserStatus(ctx, action, models.UserStatusLocked); err != nil {
+		return err
+	}
+	return service.repository.RevokeSessions(ctx, &models.User{UserId: action.TargetId})
+}
+
+func (service *UserService) UnlockUser(ctx context.Context, action *models.AdminAction) error {
+	return service.changeUserStatus(ctx, action, models.UserStatusActive, models.UserStatusLocked)
+}
+
+func (service *UserService) ForceLogout(ctx context.Context, action *models.AdminAction) error {
+	return service.repository.RevokeSessions(ctx, &models.User{UserId: action.TargetId})
+}
+
+// TriggerPasswordReset emails the user a single-use link to set a new password, the current password keeps working until then.
+func (service *UserService) TriggerPasswordReset(ctx context.Context, action *models.AdminAction) error {
+	user := &models.User{UserId: action.TargetId}
+	if err := service.repository.GetUserById(ctx, user); err != nil {
+		return err
+	}
+	reset := &models.PasswordReset{
+		Code:           uniuri.NewLen(32),
+		UserId:         user.UserId,
+		ExpirationTime: time.Now().UTC().Add(passwordResetTtl),
+	}
+	reset.CodeHash = hashToken(reset.Code)
+	if err := service.repository.AddPasswordReset(ctx, reset); err != nil {
+		return err
+	}
+	return service.sendPasswordReset(user, reset)
+}
+
+func (service *UserService) AdminDeleteUser(ctx context.Context, action *models.AdminAction) error {
+	if action.ActorId == action.TargetId {
+		return apierror.NewCatalogError(apierror.CodeAdminSelfAction, ErrAdminSelfAction.Error())
+	}
+	return service.changeUserStatus(ctx, action, models.UserStatusDeleted)
+}
+
+// ResetPassword sets the new password with a code from the reset email and signs the user out everywhere, it lifts a lock as well.
+func (service *UserService) ResetPassword(ctx context.Context, reset *models.PasswordReset) error {
+	reset.CodeHash = hashToken(reset.Code)
+	if err := service.repository.ConsumePasswordReset(ctx, reset); err != nil {
+		return err
+	}
+	user := &models.User{UserId: reset.UserId}
+	if err := service.repository.GetUserById(ctx, user); err != nil {
+		return err
+	}
+	user.Password = reset.Password
+	if err := service.hashPassword(user); err != nil {
+		return err
+	}
+	if err := service.repository.UpdatePassword(ctx, user); err != nil {
+		return err
+	}
+	if user.Status == models.UserStatusLocked {
+		if err := service.setUserStatus(ctx, user, models.UserStatusActive, "password reset"); err != nil {
+			return err
+		}
+	}
+	return service.repository.RevokeSessions(ctx, user)
+}
+
+// changeUserStatus moves the target of the action to the status to, from limits the statuses it may come from
+// further than the allowed transitions do.
+func (service *UserService) changeUserStatus(ctx context.Context, action *models.AdminAction, to string, from ...string) error {
+	user := &models.User{UserId: action.TargetId}
+	if err := service.repository.GetUserById(ctx, user); err != nil {
+		return err
+	}
+	if len(from) != 0 && !contains(from, user.Status) {
+		return apierror.NewCatalogError(apierror.CodeStatusTransitionInvalid, ErrStatusTransitionInvalid.Error())
+	}
+	return service.setUserStatus(ctx, user, to, action.Reason)
+}
+
+// setUserStatus records the reason and the time of the change, deleting the user drops their credentials as well.
+func (service *UserService) setUserStatus(ctx context.Context, user *models.User, to string, reason string) error {
+	if !models.CanTransitionStatus(user.Status, to) {
+		return apierror.NewCatalogError(apierror.CodeStatusTransitionInvalid, ErrStatusTransitionInvalid.Error())
+	}
+	change := &models.StatusChange{UserId: user.UserId, From: user.Status, To: to, Reason: reason, ChangedAt: time.Now().UTC()}
+	if to == models.UserStatusDeleted {
+		if err := service.repository.DeleteUser(ctx, change); err != nil {
+			return err
+		}
+	} else if err := service.repository.SetUserStatus(ctx, change); err != nil {
+		return err
+	}
+	user.Status = to
+	return nil
+}
+
+func (service *UserService) sendPasswordReset(user *models.User, reset *models.PasswordReset) error {
+	return service.sendTemplate(user.Email, "Password reset on WordDict", service.config.PasswordResetTemplateLocation, map[string]string{"ResetUrl": service.config.SpaUrl + "/password-reset/" + reset.Code, "UserName": user.UserName})
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	config "github.com/Kin-dza-dzaa/userApi/configs"
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/Kin-dza-dzaa/userApi/pkg/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSuspendUser(t *testing.T) {
+	actorId, targetId := uuid.New(), uuid.New()
+	testCases := []struct {
+		name       string
+		action     models.AdminAction
+		beforeTest func(repository *mocks.Repository)
+		errCode    string
+	}{
+		{
+			name:   "suspended",
+			action: models.AdminAction{ActorId: actorId, TargetId: targetId, Reason: "spam"},
+			beforeTest: func(repository *mocks.Repository) {
+				expectStatus(repository, targetId, models.UserStatusActive)
+				repository.On("SetUserStatus", mock.Anything, mock.MatchedBy(func(change *models.StatusChange) bool {
+					return change.UserId == targetId && change.From == models.UserStatusActive && change.To == models.UserStatusSuspended && change.Reason == "spam"
+				})).Return(nil).Once()
+				repository.On("RevokeSessions", mock.Anything, &models.User{UserId: targetId}).Return(nil).Once()
+			},
+		},
+		{
+			name:    "self",
+			action:  models.AdminAction{ActorId: actorId, TargetId: actorId},
+			errCode: apierror.CodeAdminSelfAction,
+		},
+		{
+			name:   "unknown_user",
+			action: models.AdminAction{ActorId: actorId, TargetId: targetId},
+			beforeTest: func(repository *mocks.Repository) {
+				repository.On("GetUserById", mock.Anything, mock.Anything).Return(apierror.NewCatalogError(apierror.CodeUserNotFound, "")).Once()
+			},
+			errCode: apierror.CodeUserNotFound,
+		},
+		{
+			name:   "pending_user",
+			action: models.AdminAction{ActorId: actorId, TargetId: targetId},
+			beforeTest: func(repository *mocks.Repository) {
+				expectStatus(repository, targetId, models.UserStatusPending)
+			},
+			errCode: apierror.CodeStatusTransitionInvalid,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repository := mocks.NewRepository(t)
+			service := NewUserService(repository, &config.Config{})
+			if tc.beforeTest != nil {
+				tc.beforeTest(repository)
+			}
+			err := service.SuspendUser(context.TODO(), &tc.action)
+			if tc.errCode != "" {
+				if err.(*apierror.ErrorStruct).ErrorCode != tc.errCode {
+					t.FailNow()
+				}
+				return
+			}
+			if err != nil {
+				t.FailNow()
+			}
+		})
+	}
+}
+
+func TestAdminStatusActions(t *testing.T) {
+	actorId, targetId := uuid.New(), uuid.New()
+	testCases := []struct {
+		name    string
+		action  func(service *UserService) func(context.Context, *models.AdminAction) error
+		status  string
+		change  string
+		errCode string
+	}{
+		{
+			name:   "verify_pending",
+			action: func(service *UserService) func(context.Context, *models.AdminAction) error { return service.AdminVerifyUser },
+			status: models.UserStatusPending,
+			change: "SetUserStatus",
+		},
+		{
+			name:    "verify_suspended",
+			action:  func(service *UserService) func(context.Context, *models.AdminAction) error { return service.AdminVerifyUser },
+			status:  models.UserStatusSuspended,
+			errCode: apierror.CodeStatusTransitionInvalid,
+		},
+		{
+			name:   "unlock_locked",
+			action: func(service *UserService) func(context.Context, *models.AdminAction) error { return service.UnlockUser },
+			status: models.UserStatusLocked,
+			change: "SetUserStatus",
+		},
+		{
+			name:    "unsuspend_locked",
+			action:  func(service *UserService) func(context.Context, *models.AdminAction) error { return service.UnsuspendUser },
+			status:  models.UserStatusLocked,
+			errCode: apierror.CodeStatusTransitionInvalid,
+		},
+		{
+			name:   "delete_suspended",
+			action: func(service *UserService) func(context.Context, *models.AdminAction) error { return service.AdminDeleteUser },
+			status: models.UserStatusSuspended,
+			change: "DeleteUser",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repository := mocks.NewRepository(t)
+			service := NewUserService(repository, &config.Config{})
+			expectStatus(repository, targetId, tc.status)
+			if tc.change != "" {
+				repository.On(tc.change, mock.Anything, mock.MatchedBy(func(change *models.StatusChange) bool {
+					return change.UserId == targetId && change.From == tc.status
+				})).Return(nil).Once()
+			}
+			err := tc.action(service)(context.TODO(), &models.AdminAction{ActorId: actorId, TargetId: targetId})
+			if tc.errCode != "" {
+				if err.(*apierror.ErrorStruct).ErrorCode != tc.errCode {
+					t.FailNow()
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+// expectStatus makes GetUserById find the user with the status.
+func expectStatus(repository *mocks.Repository, userId uuid.UUID, status string) {
+	repository.On("GetUserById", mock.Anything, &models.User{UserId: userId}).Run(func(args mock.Arguments) {
+		args.Get(1).(*models.User).Status = status
+	}).Return(nil).Once()
+}
+
+func TestPasswordReset(t *testing.T) {
+	repository := mocks.NewRepository(t)
+	mailer := mocks.NewMailer(t)
+	service := NewUserService(repository, &config.Config{SpaUrl: "http://spa", PasswordResetTemplateLocation: "./../../internal/templates/password_reset_template.html"})
+	service.mailer = mailer
+	action := &models.AdminAction{ActorId: uuid.New(), TargetId: uuid.New()}
+	var code string
+	repository.On("GetUserById", mock.Anything, &models.User{UserId: action.TargetId}).Run(func(args mock.Arguments) {
+		user := args.Get(1).(*models.User)
+		user.Email, user.Status = "test@gmail.com", models.UserStatusLocked
+	}).Return(nil).Twice()
+	repository.On("AddPasswordReset", mock.Anything, mock.MatchedBy(func(reset *models.PasswordReset) bool {
+		code = reset.Code
+		return reset.UserId == action.TargetId && reset.CodeHash == hashToken(reset.Code)
+	})).Return(nil).Once()
+	mailer.On("Send", "test@gmail.com", mock.Anything, mock.MatchedBy(func(body string) bool {
+		return strings.Contains(body, "http://spa/password-reset/"+code)
+	})).Return(nil).Once()
+	if err := service.TriggerPasswordReset(context.TODO(), action); err != nil {
+		t.Fatal(err)
+	}
+
+	repository.On("ConsumePasswordReset", mock.Anything, mock.MatchedBy(func(reset *models.PasswordReset) bool {
+		return reset.CodeHash == hashToken(code)
+	})).Run(func(args mock.Arguments) {
+		args.Get(1).(*models.PasswordReset).UserId = action.TargetId
+	}).Return(nil).Once()
+	repository.On("UpdatePassword", mock.Anything, mock.MatchedBy(func(user *models.User) bool {
+		return user.UserId == action.TargetId && strings.HasPrefix(user.Password, "$2a$")
+	})).Return(nil).Once()
+	repository.On("SetUserStatus", mock.Anything, mock.MatchedBy(func(change *models.StatusChange) bool {
+		return change.UserId == action.TargetId && change.From == models.UserStatusLocked && change.To == models.UserStatusActive
+	})).Return(nil).Once()
+	repository.On("RevokeSessions", mock.Anything, mock.Anything).Return(nil).Once()
+	if err := service.ResetPassword(context.TODO(), &models.PasswordReset{Code: code, Password: "new-password"}); err != nil {
+		t.Fatal(err)
+	}
+}
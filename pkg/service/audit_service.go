@@ -0,0 +1,604 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	config "github.com/Kin-dza-dzaa/userApi/configs"
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	repository "github.com/Kin-dza-dzaa/userApi/pkg/repositories"
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// audit event types
+const (
+	AuditUserSignUp        = "user.signup"
+	AuditUserSignIn        = "user.signin"
+	AuditUserVerify        = "user.verify"
+	AuditUserTokenRefresh  = "user.token.refresh"
+	AuditUserLogout        = "user.logout"
+	AuditTokenRejected     = "token.rejected"
+	AuditPatCreate         = "user.pat.create"
+	AuditPatList           = "user.pat.list"
+	AuditPatDelete         = "user.pat.delete"
+	AuditUserPasswordReset = "user.password.reset"
+	AuditUserSecureAccount = "user.secure_account"
+	AuditUserProfileUpdate = "user.profile.update"
+	AuditSocialBegin       = "user.social.begin"
+	AuditSocialSignIn      = "user.social.signin"
+	AuditUserReauth        = "user.reauth"
+
+	AuditOAuthAuthorize       = "oauth.authorize"
+	AuditOAuthConsentCheck    = "oauth.consent.check"
+	AuditOAuthConsentGrant    = "oauth.consent.grant"
+	AuditOAuthCodeIssue       = "oauth.code.issue"
+	AuditOAuthToken           = "oauth.token"
+	AuditOAuthUserInfo        = "oauth.userinfo"
+	AuditOAuthIntrospect      = "oauth.introspect"
+	AuditOAuthRevoke          = "oauth.revoke"
+	AuditOAuthDeviceAuthorize = "oauth.device.authorize"
+	AuditOAuthDeviceApprove   = "oauth.device.approve"
+
+	AuditAdminRoleList         = "admin.role.list"
+	AuditAdminRolePut          = "admin.role.put"
+	AuditAdminRoleDelete       = "admin.role.delete"
+	AuditAdminPermissionList   = "admin.permission.list"
+	AuditAdminPermissionPut    = "admin.permission.put"
+	AuditAdminPermissionDelete = "admin.permission.delete"
+	AuditAdminUserRolesView    = "admin.user.roles.view"
+	AuditAdminUserRoleGrant    = "admin.user.role.grant"
+	AuditAdminUserRoleRevoke   = "admin.user.role.revoke"
+	AuditAdminUserSearch       = "admin.user.search"
+	AuditAdminUserView         = "admin.user.view"
+	AuditAdminUserVerify       = "admin.user.verify"
+	AuditAdminUserSuspend      = "admin.user.suspend"
+	AuditAdminUserUnsuspend    = "admin.user.unsuspend"
+	AuditAdminUserLock         = "admin.user.lock"
+	AuditAdminUserUnlock       = "admin.user.unlock"
+	AuditAdminUserLogout       = "admin.user.logout"
+	AuditAdminPasswordReset    = "admin.user.password_reset"
+	AuditAdminUserDelete       = "admin.user.delete"
+	AuditAdminAuditView        = "admin.audit.view"
+
+	AuditAdminEmailDomainList   = "admin.email_domain.list"
+	AuditAdminEmailDomainPut    = "admin.email_domain.put"
+	AuditAdminEmailDomainDelete = "admin.email_domain.delete"
+)
+
+// GetUserActivity returns the events the user did themselves, the rest of filter is kept. Events where an admin acted on
+// the user carry the admin's id, IP, user agent and reason, they are only shown to admins.
+func (service *UserService) GetUserActivity(ctx context.Context, user *models.User, filter *models.AuditFilter) (*models.AuditPage, error) {
+	filter.ActorId, filter.TargetId = &user.UserId, nil
+	return service.repository.GetAuditEvents(ctx, filter)
+}
+
+func (service *UserService) GetAuditEvents(ctx context.Context, filter *models.AuditFilter) (*models.AuditPage, error) {
+	return service.repository.GetAuditEvents(ctx, filter)
+}
+
+// recordEvent appends event with the outcome of err and the request it was made for, the actor defaults to the signed in user.
+// err is always returned as is, the action already took effect and an event that couldn't be written is only logged.
+func (service *UserService) recordEvent(ctx context.Context, event *models.AuditEvent, err error) error {
+	meta := models.RequestMetaFromContext(ctx)
+	event.EventId = uuid.New()
+	event.CreatedAt = time.Now().UTC()
+	event.Ip, event.UserAgent, event.RequestId = meta.Ip, meta.UserAgent, meta.RequestId
+	if event.ActorId == nil {
+		event.ActorId = meta.ActorId
+	}
+	event.Outcome = models.AuditOutcomeSuccess
+	if err != nil {
+		event.Outcome = models.AuditOutcomeFailure
+		if event.Metadata == nil {
+			event.Metadata = map[string]interface{}{}
+		}
+		event.Metadata["error"] = auditErrorCode(err)
+	}
+	if auditErr := service.repository.AddAuditEvent(ctx, event); auditErr != nil {
+		zerolog.Ctx(ctx).Err(auditErr).Str("type", event.Type).Str("request_id", event.RequestId).Msg("audit event not written")
+	}
+	return err
+}
+
+// PurgeAuditEvents deletes the events older than AUDIT_RETENTION_DAYS, a retention of 0 keeps them forever.
+func (service *UserService) PurgeAuditEvents(ctx context.Context) error {
+	if service.config.AuditRetentionDays <= 0 {
+		return nil
+	}
+	return service.repository.DeleteAuditEventsBefore(ctx, time.Now().UTC().AddDate(0, 0, -service.config.AuditRetentionDays))
+}
+
+// RunAuditRetention purges expired audit events every interval until ctx is done, failures are logged and retried
+// on the next tick.
+func RunAuditRetention(ctx context.Context, repository repository.Repository, config *config.Config, interval time.Duration) {
+	service := NewUserService(repository, config)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := service.PurgeAuditEvents(ctx); err != nil {
+			zerolog.Ctx(ctx).Err(err).Msg("audit retention failed")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// auditErrorCode keeps the catalog or OAuth code of err, infrastructure errors aren't recorded in detail.
+func auditErrorCode(err error) string {
+	switch err := err.(type) {
+	case *apierror.ErrorStruct:
+		return err.ErrorCode
+	case *apierror.OAuthError:
+		return err.ErrorCode
+	}
+	return apierror.CodeInternalError
+}
+
+// rejectedTokenWindow is how often a rejected token is recorded per client IP, anyone can send garbage tokens
+// and each of them would be a row otherwise
+const (
+	rejectedTokenWindow = time.Minute
+	rejectedTokenMaxIps = 10000
+)
+
+// auditService records an audit event for every call to the wrapped service. Every method is spelled out,
+// a method added to Service doesn't compile here until it decides what to record.
+type auditService struct {
+	service  *UserService
+	rejected rejectedTokenSampler
+}
+
+func newAuditService(service *UserService) *auditService {
+	return &auditService{service: service}
+}
+
+// rejectedTokenSampler lets the first rejected token of an IP through in every window, once rejectedTokenMaxIps
+// IPs were seen the rest of the window isn't recorded.
+type rejectedTokenSampler struct {
+	mutex  sync.Mutex
+	window time.Time
+	seen   map[string]bool
+}
+
+func (sampler *rejectedTokenSampler) allow(ip string, now time.Time) bool {
+	sampler.mutex.Lock()
+	defer sampler.mutex.Unlock()
+	if sampler.seen == nil || now.Sub(sampler.window) >= rejectedTokenWindow {
+		sampler.window, sampler.seen = now, map[string]bool{}
+	}
+	if sampler.seen[ip] || len(sampler.seen) >= rejectedTokenMaxIps {
+		return false
+	}
+	sampler.seen[ip] = true
+	return true
+}
+
+// recordRejectedToken records a rejected token unless the IP already had one recorded in this window.
+func (audit *auditService) recordRejectedToken(ctx context.Context, tokenType string, err error) error {
+	if !audit.rejected.allow(models.RequestMetaFromContext(ctx).Ip, time.Now()) {
+		return err
+	}
+	return audit.record(ctx, AuditTokenRejected, nil, nil, map[string]interface{}{"token_type": tokenType}, err)
+}
+
+func (audit *auditService) record(ctx context.Context, eventType string, actorId *uuid.UUID, targetId *uuid.UUID, metadata map[string]interface{}, err error) error {
+	return audit.service.recordEvent(ctx, &models.AuditEvent{Type: eventType, ActorId: actorId, TargetId: targetId, Metadata: metadata}, err)
+}
+
+// recordUser records an action the user did on their own account, the user is only known once it succeeded.
+func (audit *auditService) recordUser(ctx context.Context, eventType string, userId uuid.UUID, metadata map[string]interface{}, err error) error {
+	if err != nil || userId == uuid.Nil {
+		return audit.record(ctx, eventType, nil, nil, metadata, err)
+	}
+	return audit.record(ctx, eventType, &userId, &userId, metadata, err)
+}
+
+func (audit *auditService) recordAdmin(ctx context.Context, eventType string, action *models.AdminAction, err error) error {
+	var metadata map[string]interface{}
+	if action.Reason != "" {
+		metadata = map[string]interface{}{"reason": action.Reason}
+	}
+	return audit.record(ctx, eventType, &action.ActorId, &action.TargetId, metadata, err)
+}
+
+// sign ups are recorded without the user, with hardened auth a sign up of a taken email doesn't create one
+func (audit *auditService) SignUpUser(ctx context.Context, user *models.User) error {
+	err := audit.service.SignUpUser(ctx, user)
+	return audit.record(ctx, AuditUserSignUp, nil, nil, map[string]interface{}{"email": user.Email}, err)
+}
+
+func (audit *auditService) SignInUser(ctx context.Context, user *models.User) error {
+	err := audit.service.SignInUser(ctx, user)
+	var metadata map[string]interface{}
+	if err != nil {
+		metadata = map[string]interface{}{"email": user.Email}
+	}
+	return audit.recordUser(ctx, AuditUserSignIn, user.UserId, metadata, err)
+}
+
+func (audit *auditService) VerifyUser(ctx context.Context, user *models.User) error {
+	err := audit.service.VerifyUser(ctx, user)
+	return audit.recordUser(ctx, AuditUserVerify, user.UserId, nil, err)
+}
+
+func (audit *auditService) GetAccessToken(ctx context.Context, user *models.User) error {
+	err := audit.service.GetAccessToken(ctx, user)
+	return audit.recordUser(ctx, AuditUserTokenRefresh, user.UserId, nil, err)
+}
+
+func (audit *auditService) Reauthenticate(ctx context.Context, user *models.User) error {
+	err := audit.service.Reauthenticate(ctx, user)
+	return audit.recordUser(ctx, AuditUserReauth, user.UserId, nil, err)
+}
+
+// ParseAccessToken runs on every authenticated request, only rejected tokens are recorded and those are sampled.
+func (audit *auditService) ParseAccessToken(ctx context.Context, token string) (*models.MyJwtClaims, error) {
+	claims, err := audit.service.ParseAccessToken(ctx, token)
+	if err != nil {
+		return nil, audit.recordRejectedToken(ctx, "access_token", err)
+	}
+	return claims, nil
+}
+
+func (audit *auditService) Authenticate(ctx context.Context, token string) (*models.MyJwtClaims, error) {
+	claims, err := audit.service.Authenticate(ctx, token)
+	if err != nil {
+		return nil, audit.recordRejectedToken(ctx, "bearer", err)
+	}
+	return claims, nil
+}
+
+func (audit *auditService) ValidateAuthorizationRequest(ctx context.Context, request *models.AuthorizationRequest) (*models.OAuthClient, error) {
+	client, err := audit.service.ValidateAuthorizationRequest(ctx, request)
+	if err := audit.record(ctx, AuditOAuthAuthorize, nil, nil, map[string]interface{}{"client_id": request.ClientId, "scope": request.Scope}, err); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+func (audit *auditService) HasConsent(ctx context.Context, consent *models.Consent) (bool, error) {
+	granted, err := audit.service.HasConsent(ctx, consent)
+	if err := audit.record(ctx, AuditOAuthConsentCheck, &consent.UserId, nil, map[string]interface{}{"client_id": consent.ClientId, "granted": granted}, err); err != nil {
+		return false, err
+	}
+	return granted, nil
+}
+
+func (audit *auditService) GrantConsent(ctx context.Context, consent *models.Consent) error {
+	err := audit.service.GrantConsent(ctx, consent)
+	return audit.record(ctx, AuditOAuthConsentGrant, &consent.UserId, nil, map[string]interface{}{"client_id": consent.ClientId, "scopes": consent.Scopes}, err)
+}
+
+func (audit *auditService) IssueAuthorizationCode(ctx context.Context, code *models.AuthorizationCode) error {
+	err := audit.service.IssueAuthorizationCode(ctx, code)
+	return audit.record(ctx, AuditOAuthCodeIssue, &code.UserId, nil, map[string]interface{}{"client_id": code.ClientId, "scope": code.Scope}, err)
+}
+
+func (audit *auditService) Token(ctx context.Context, request *models.TokenRequest) (*models.TokenResponse, error) {
+	response, err := audit.service.Token(ctx, request)
+	if err := audit.record(ctx, AuditOAuthToken, nil, nil, map[string]interface{}{"grant_type": request.GrantType, "client_id": request.ClientId}, err); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (audit *auditService) GetUserInfo(ctx context.Context, token string) (*models.UserInfo, error) {
+	userInfo, err := audit.service.GetUserInfo(ctx, token)
+	var actorId *uuid.UUID
+	if err == nil {
+		if userId, parseErr := uuid.Parse(userInfo.Sub); parseErr == nil {
+			actorId = &userId
+		}
+	}
+	if err := audit.record(ctx, AuditOAuthUserInfo, actorId, nil, nil, err); err != nil {
+		return nil, err
+	}
+	return userInfo, nil
+}
+
+// Jwks is public and isn't recorded.
+func (audit *auditService) Jwks(ctx context.Context) (*models.Jwks, error) {
+	return audit.service.Jwks(ctx)
+}
+
+func (audit *auditService) IntrospectToken(ctx context.Context, request *models.IntrospectionRequest) (*models.Introspection, error) {
+	introspection, err := audit.service.IntrospectToken(ctx, request)
+	metadata := map[string]interface{}{"client_id": request.ClientId}
+	if err == nil {
+		metadata["active"] = introspection.Active
+	}
+	if err := audit.record(ctx, AuditOAuthIntrospect, nil, nil, metadata, err); err != nil {
+		return nil, err
+	}
+	return introspection, nil
+}
+
+func (audit *auditService) RevokeToken(ctx context.Context, request *models.RevocationRequest) error {
+	err := audit.service.RevokeToken(ctx, request)
+	return audit.record(ctx, AuditOAuthRevoke, nil, nil, map[string]interface{}{"client_id": request.ClientId, "token_type_hint": request.TokenTypeHint}, err)
+}
+
+// RevokeAccessToken is the logout, the user is taken from the token without looking it up again.
+func (audit *auditService) RevokeAccessToken(ctx context.Context, accessToken string) error {
+	err := audit.service.RevokeAccessToken(ctx, accessToken)
+	return audit.recordUser(ctx, AuditUserLogout, audit.tokenSubject(accessToken), nil, err)
+}
+
+// tokenSubject is the user of a validly signed session access token, uuid.Nil otherwise.
+func (audit *auditService) tokenSubject(accessToken string) uuid.UUID {
+	claims := new(models.MyJwtClaims)
+	if _, err := jwt.ParseWithClaims(accessToken, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, ErrAccessTokenInvalid
+		}
+		return []byte(audit.service.config.JWTString), nil
+	}); err != nil {
+		return uuid.Nil
+	}
+	userId, _ := uuid.Parse(claims.UserId)
+	return userId
+}
+
+func (audit *auditService) CreatePersonalAccessToken(ctx context.Context, token *models.PersonalAccessToken) error {
+	err := audit.service.CreatePersonalAccessToken(ctx, token)
+	metadata := map[string]interface{}{"name": token.Name, "scopes": token.Scopes}
+	if err == nil {
+		metadata["token_id"] = token.TokenId
+	}
+	return audit.record(ctx, AuditPatCreate, &token.UserId, &token.UserId, metadata, err)
+}
+
+func (audit *auditService) GetPersonalAccessTokens(ctx context.Context, user *models.User) ([]models.PersonalAccessToken, error) {
+	tokens, err := audit.service.GetPersonalAccessTokens(ctx, user)
+	if err := audit.record(ctx, AuditPatList, &user.UserId, &user.UserId, nil, err); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+func (audit *auditService) DeletePersonalAccessToken(ctx context.Context, token *models.PersonalAccessToken) error {
+	err := audit.service.DeletePersonalAccessToken(ctx, token)
+	return audit.record(ctx, AuditPatDelete, &token.UserId, &token.UserId, map[string]interface{}{"token_id": token.TokenId}, err)
+}
+
+func (audit *auditService) AuthorizeDevice(ctx context.Context, request *models.DeviceAuthorizationRequest) (*models.DeviceAuthorizationResponse, error) {
+	response, err := audit.service.AuthorizeDevice(ctx, request)
+	if err := audit.record(ctx, AuditOAuthDeviceAuthorize, nil, nil, map[string]interface{}{"client_id": request.ClientId, "scope": request.Scope}, err); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// GetDevicePrompt only reads the user code, the decision is recorded by ApproveDevice.
+func (audit *auditService) GetDevicePrompt(ctx context.Context, userCode string) (*models.DevicePrompt, error) {
+	return audit.service.GetDevicePrompt(ctx, userCode)
+}
+
+func (audit *auditService) ApproveDevice(ctx context.Context, approval *models.DeviceApproval) error {
+	err := audit.service.ApproveDevice(ctx, approval)
+	return audit.record(ctx, AuditOAuthDeviceApprove, &approval.UserId, nil, map[string]interface{}{"approve": approval.Approve, "client_id": approval.ClientId}, err)
+}
+
+func (audit *auditService) GetRoles(ctx context.Context) ([]models.Role, error) {
+	roles, err := audit.service.GetRoles(ctx)
+	if err := audit.record(ctx, AuditAdminRoleList, nil, nil, nil, err); err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+func (audit *auditService) PutRole(ctx context.Context, role *models.Role) error {
+	err := audit.service.PutRole(ctx, role)
+	return audit.record(ctx, AuditAdminRolePut, nil, nil, map[string]interface{}{"role": role.Name, "permissions": role.Permissions}, err)
+}
+
+func (audit *auditService) DeleteRole(ctx context.Context, role *models.Role) error {
+	err := audit.service.DeleteRole(ctx, role)
+	return audit.record(ctx, AuditAdminRoleDelete, nil, nil, map[string]interface{}{"role": role.Name}, err)
+}
+
+func (audit *auditService) GetPermissions(ctx context.Context) ([]models.Permission, error) {
+	permissions, err := audit.service.GetPermissions(ctx)
+	if err := audit.record(ctx, AuditAdminPermissionList, nil, nil, nil, err); err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
+func (audit *auditService) PutPermission(ctx context.Context, permission *models.Permission) error {
+	err := audit.service.PutPermission(ctx, permission)
+	return audit.record(ctx, AuditAdminPermissionPut, nil, nil, map[string]interface{}{"permission": permission.Name}, err)
+}
+
+func (audit *auditService) DeletePermission(ctx context.Context, permission *models.Permission) error {
+	err := audit.service.DeletePermission(ctx, permission)
+	return audit.record(ctx, AuditAdminPermissionDelete, nil, nil, map[string]interface{}{"permission": permission.Name}, err)
+}
+
+func (audit *auditService) GetUserRoles(ctx context.Context, user *models.User) error {
+	err := audit.service.GetUserRoles(ctx, user)
+	return audit.record(ctx, AuditAdminUserRolesView, nil, &user.UserId, nil, err)
+}
+
+func (audit *auditService) GrantRole(ctx context.Context, userRole *models.UserRole) error {
+	err := audit.service.GrantRole(ctx, userRole)
+	return audit.record(ctx, AuditAdminUserRoleGrant, nil, &userRole.UserId, map[string]interface{}{"role": userRole.Role}, err)
+}
+
+func (audit *auditService) RevokeRole(ctx context.Context, userRole *models.UserRole) error {
+	err := audit.service.RevokeRole(ctx, userRole)
+	return audit.record(ctx, AuditAdminUserRoleRevoke, nil, &userRole.UserId, map[string]interface{}{"role": userRole.Role}, err)
+}
+
+// SearchUsers is recorded with the filter, support staff looking up users is an action as well.
+func (audit *auditService) SearchUsers(ctx context.Context, actorId uuid.UUID, filter *models.UserFilter) (*models.UserPage, error) {
+	page, err := audit.service.SearchUsers(ctx, actorId, filter)
+	metadata := map[string]interface{}{"email": filter.Email, "user_name": filter.UserName, "status": filter.Status, "page": filter.Page}
+	if err == nil {
+		metadata["results"] = len(page.Users)
+	}
+	if err := audit.record(ctx, AuditAdminUserSearch, &actorId, nil, metadata, err); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+func (audit *auditService) GetAdminUser(ctx context.Context, actorId uuid.UUID, user *models.AdminUser) error {
+	err := audit.service.GetAdminUser(ctx, actorId, user)
+	return audit.record(ctx, AuditAdminUserView, &actorId, &user.UserId, nil, err)
+}
+
+func (audit *auditService) AdminVerifyUser(ctx context.Context, action *models.AdminAction) error {
+	return audit.recordAdmin(ctx, AuditAdminUserVerify, action, audit.service.AdminVerifyUser(ctx, action))
+}
+
+func (audit *auditService) SuspendUser(ctx context.Context, action *models.AdminAction) error {
+	return audit.recordAdmin(ctx, AuditAdminUserSuspend, action, audit.service.SuspendUser(ctx, action))
+}
+
+func (audit *auditService) UnsuspendUser(ctx context.Context, action *models.AdminAction) error {
+	return audit.recordAdmin(ctx, AuditAdminUserUnsuspend, action, audit.service.UnsuspendUser(ctx, action))
+}
+
+func (audit *auditService) LockUser(ctx context.Context, action *models.AdminAction) error {
+	return audit.recordAdmin(ctx, AuditAdminUserLock, action, audit.service.LockUser(ctx, action))
+}
+
+func (audit *auditService) UnlockUser(ctx context.Context, action *models.AdminAction) error {
+	return audit.recordAdmin(ctx, AuditAdminUserUnlock, action, audit.service.UnlockUser(ctx, action))
+}
+
+func (audit *auditService) ForceLogout(ctx context.Context, action *models.AdminAction) error {
+	return audit.recordAdmin(ctx, AuditAdminUserLogout, action, audit.service.ForceLogout(ctx, action))
+}
+
+func (audit *auditService) TriggerPasswordReset(ctx context.Context, action *models.AdminAction) error {
+	return audit.recordAdmin(ctx, AuditAdminPasswordReset, action, audit.service.TriggerPasswordReset(ctx, action))
+}
+
+func (audit *auditService) AdminDeleteUser(ctx context.Context, action *models.AdminAction) error {
+	return audit.recordAdmin(ctx, AuditAdminUserDelete, action, audit.service.AdminDeleteUser(ctx, action))
+}
+
+// ResetPassword is done by the user with the code from the email, they are known once the code is consumed.
+func (audit *auditService) ResetPassword(ctx context.Context, reset *models.PasswordReset) error {
+	err := audit.service.ResetPassword(ctx, reset)
+	if reset.UserId == uuid.Nil {
+		return audit.record(ctx, AuditUserPasswordReset, nil, nil, nil, err)
+	}
+	return audit.record(ctx, AuditUserPasswordReset, &reset.UserId, &reset.UserId, nil, err)
+}
+
+// SecureAccount is done with the code from a new device email, the user is known once the code is consumed.
+func (audit *auditService) SecureAccount(ctx context.Context, code *models.SecureAccountCode) error {
+	err := audit.service.SecureAccount(ctx, code)
+	if code.UserId == uuid.Nil {
+		return audit.record(ctx, AuditUserSecureAccount, nil, nil, nil, err)
+	}
+	return audit.record(ctx, AuditUserSecureAccount, &code.UserId, &code.UserId, nil, err)
+}
+
+// GetProfile is the user reading their own profile, it isn't recorded.
+func (audit *auditService) GetProfile(ctx context.Context, user *models.User) (*models.Profile, error) {
+	return audit.service.GetProfile(ctx, user)
+}
+
+// UpdateProfile records the changed fields, the old user name is kept so renames can be traced.
+func (audit *auditService) UpdateProfile(ctx context.Context, update *models.ProfileUpdate) (*models.Profile, error) {
+	var previous string
+	if update.UserName != nil {
+		if profile, err := audit.service.GetProfile(ctx, &models.User{UserId: update.UserId}); err == nil {
+			previous = profile.UserName
+		}
+	}
+	profile, err := audit.service.UpdateProfile(ctx, update)
+	metadata := map[string]interface{}{}
+	if update.UserName != nil {
+		metadata["user_name"], metadata["previous_user_name"] = *update.UserName, previous
+	}
+	if update.DisplayName != nil {
+		metadata["display_name"] = *update.DisplayName
+	}
+	if update.Locale != nil {
+		metadata["locale"] = *update.Locale
+	}
+	if update.Timezone != nil {
+		metadata["timezone"] = *update.Timezone
+	}
+	if err := audit.record(ctx, AuditUserProfileUpdate, &update.UserId, &update.UserId, metadata, err); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// UserNameAvailable is a public lookup and isn't recorded.
+func (audit *auditService) UserNameAvailable(ctx context.Context, user *models.User) (bool, error) {
+	return audit.service.UserNameAvailable(ctx, user)
+}
+
+func (audit *auditService) GetEmailDomainRules(ctx context.Context) ([]models.EmailDomainRule, error) {
+	rules, err := audit.service.GetEmailDomainRules(ctx)
+	if err := audit.record(ctx, AuditAdminEmailDomainList, nil, nil, nil, err); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (audit *auditService) PutEmailDomainRule(ctx context.Context, rule *models.EmailDomainRule) error {
+	err := audit.service.PutEmailDomainRule(ctx, rule)
+	return audit.record(ctx, AuditAdminEmailDomainPut, nil, nil, map[string]interface{}{"domain": rule.Domain, "kind": rule.Kind}, err)
+}
+
+func (audit *auditService) DeleteEmailDomainRule(ctx context.Context, rule *models.EmailDomainRule) error {
+	err := audit.service.DeleteEmailDomainRule(ctx, rule)
+	return audit.record(ctx, AuditAdminEmailDomainDelete, nil, nil, map[string]interface{}{"domain": rule.Domain}, err)
+}
+
+// NewChallenge and VerifyChallenge aren't recorded, failed challenges are bot traffic and the guarded action records the rest.
+func (audit *auditService) NewChallenge(ctx context.Context, endpoint string) (*models.Challenge, error) {
+	return audit.service.NewChallenge(ctx, endpoint)
+}
+
+func (audit *auditService) VerifyChallenge(ctx context.Context, response *models.ChallengeResponse) error {
+	return audit.service.VerifyChallenge(ctx, response)
+}
+
+func (audit *auditService) BeginSocialLogin(ctx context.Context, provider string) (*models.SocialLogin, error) {
+	login, err := audit.service.BeginSocialLogin(ctx, provider)
+	if err := audit.record(ctx, AuditSocialBegin, nil, nil, map[string]interface{}{"provider": provider}, err); err != nil {
+		return nil, err
+	}
+	return login, nil
+}
+
+func (audit *auditService) CompleteSocialLogin(ctx context.Context, callback *models.SocialCallback) (*models.User, error) {
+	user, err := audit.service.CompleteSocialLogin(ctx, callback)
+	var userId uuid.UUID
+	if err == nil {
+		userId = user.UserId
+	}
+	if err := audit.recordUser(ctx, AuditSocialSignIn, userId, map[string]interface{}{"provider": callback.Provider}, err); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// GetUserActivity is the user reading their own history, it isn't recorded.
+func (audit *auditService) GetUserActivity(ctx context.Context, user *models.User, filter *models.AuditFilter) (*models.AuditPage, error) {
+	return audit.service.GetUserActivity(ctx, user, filter)
+}
+
+func (audit *auditService) GetAuditEvents(ctx context.Context, filter *models.AuditFilter) (*models.AuditPage, error) {
+	page, err := audit.service.GetAuditEvents(ctx, filter)
+	if err := audit.record(ctx, AuditAdminAuditView, nil, nil, map[string]interface{}{"type": filter.Type, "outcome": filter.Outcome}, err); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	config "github.com/Kin-dza-dzaa/userApi/configs"
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/Kin-dza-dzaa/userApi/pkg/mocks"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAuditServiceRecords(t *testing.T) {
+	actorId, targetId := uuid.New(), uuid.New()
+	ctx := models.WithRequestMeta(context.TODO(), &models.RequestMeta{Ip: "10.0.0.1", UserAgent: "curl", RequestId: "req-1", ActorId: &actorId})
+	testCases := []struct {
+		name       string
+		beforeTest func(repository *mocks.Repository)
+		outcome    string
+		errCode    string
+	}{
+		{
+			name: "success",
+			beforeTest: func(repository *mocks.Repository) {
+				repository.On("RevokeSessions", mock.Anything, &models.User{UserId: targetId}).Return(nil).Once()
+			},
+			outcome: models.AuditOutcomeSuccess,
+		},
+		{
+			name: "failure",
+			beforeTest: func(repository *mocks.Repository) {
+				repository.On("RevokeSessions", mock.Anything, mock.Anything).Return(apierror.NewCatalogError(apierror.CodeUserNotFound, "")).Once()
+			},
+			outcome: models.AuditOutcomeFailure,
+			errCode: apierror.CodeUserNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repository := mocks.NewRepository(t)
+			service := NewService(repository, &config.Config{AuditRetentionDays: 30})
+			tc.beforeTest(repository)
+			repository.On("AddAuditEvent", mock.Anything, mock.MatchedBy(func(event *models.AuditEvent) bool {
+				return event.Type == AuditAdminUserLogout && *event.ActorId == actorId && *event.TargetId == targetId && event.Ip == "10.0.0.1" &&
+					event.UserAgent == "curl" && event.RequestId == "req-1" && event.Outcome == tc.outcome && event.Metadata["reason"] == "stolen laptop" &&
+					(tc.errCode == "" || event.Metadata["error"] == tc.errCode)
+			})).Return(nil).Once()
+			err := service.ForceLogout(ctx, &models.AdminAction{ActorId: actorId, TargetId: targetId, Reason: "stolen laptop"})
+			if tc.errCode != "" {
+				if err.(*apierror.ErrorStruct).ErrorCode != tc.errCode {
+					t.FailNow()
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestAuditServiceSignIn(t *testing.T) {
+	repository := mocks.NewRepository(t)
+	service := NewService(repository, &config.Config{})
+	repository.On("GetSignInUser", mock.Anything, mock.Anything).Return(nil, errors.New("connection refused")).Once()
+	repository.On("AddAuditEvent", mock.Anything, mock.MatchedBy(func(event *models.AuditEvent) bool {
+		return event.Type == AuditUserSignIn && event.ActorId == nil && event.Outcome == models.AuditOutcomeFailure &&
+			event.Metadata["email"] == "test@gmail.com" && event.Metadata["error"] == apierror.CodeInternalError
+	})).Return(nil).Once()
+	if err := service.SignInUser(context.TODO(), &models.User{Email: "test@gmail.com", Password: "123456789"}); err == nil || err.Error() != "connection refused" {
+		t.Fatalf("expected the sign in error, got %v", err)
+	}
+}
+
+func TestAuditServiceWriteFails(t *testing.T) {
+	repository := mocks.NewRepository(t)
+	service := NewService(repository, &config.Config{})
+	var logs bytes.Buffer
+	ctx := zerolog.New(&logs).WithContext(context.TODO())
+	userId := uuid.New()
+	repository.On("RevokeSessions", mock.Anything, &models.User{UserId: userId}).Return(nil).Once()
+	repository.On("AddAuditEvent", mock.Anything, mock.Anything).Return(errors.New("connection refused")).Once()
+	// the sessions are revoked already, failing now would only make the admin retry
+	if err := service.ForceLogout(ctx, &models.AdminAction{ActorId: uuid.New(), TargetId: userId}); err != nil {
+		t.Fatalf("an action that took effect must not fail on the audit write, got %v", err)
+	}
+	if !strings.Contains(logs.String(), "connection refused") || !strings.Contains(logs.String(), AuditAdminUserLogout) {
+		t.Fatalf("expected the audit failure to be logged, got %q", logs.String())
+	}
+}
+
+func TestAuditServiceSamplesRejectedTokens(t *testing.T) {
+	repository := mocks.NewRepository(t)
+	service := NewService(repository, &config.Config{JWTString: "secret"})
+	ctx := models.WithRequestMeta(context.TODO(), &models.RequestMeta{Ip: "10.0.0.1"})
+	otherCtx := models.WithRequestMeta(context.TODO(), &models.RequestMeta{Ip: "10.0.0.2"})
+	repository.On("AddAuditEvent", mock.Anything, mock.MatchedBy(func(event *models.AuditEvent) bool {
+		return event.Type == AuditTokenRejected && event.Ip == "10.0.0.1"
+	})).Return(nil).Once()
+	repository.On("AddAuditEvent", mock.Anything, mock.MatchedBy(func(event *models.AuditEvent) bool {
+		return event.Type == AuditTokenRejected && event.Ip == "10.0.0.2"
+	})).Return(nil).Once()
+	for i := 0; i < 5; i++ {
+		if _, err := service.ParseAccessToken(ctx, "garbage"); err == nil {
+			t.Fatal("expected the token to be rejected")
+		}
+	}
+	if _, err := service.ParseAccessToken(otherCtx, "garbage"); err == nil {
+		t.Fatal("expected the token to be rejected")
+	}
+}
+
+func TestRejectedTokenSamplerWindow(t *testing.T) {
+	var sampler rejectedTokenSampler
+	now := time.Now()
+	if !sampler.allow("10.0.0.1", now) || sampler.allow("10.0.0.1", now.Add(time.Second)) {
+		t.Fatal("expected one rejected token per IP and window")
+	}
+	if !sampler.allow("10.0.0.1", now.Add(rejectedTokenWindow)) {
+		t.Fatal("expected the IP to be recorded again in the next window")
+	}
+}
+
+func TestPurgeAuditEvents(t *testing.T) {
+	repository := mocks.NewRepository(t)
+	repository.On("DeleteAuditEventsBefore", mock.Anything, mock.MatchedBy(func(before time.Time) bool {
+		return time.Since(before) > 29*24*time.Hour && time.Since(before) < 31*24*time.Hour
+	})).Return(nil).Once()
+	if err := NewUserService(repository, &config.Config{AuditRetentionDays: 30}).PurgeAuditEvents(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	// a retention of 0 keeps every event and doesn't touch the table
+	if err := NewUserService(repository, &config.Config{}).PurgeAuditEvents(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGetUserActivity(t *testing.T) {
+	repository := mocks.NewRepository(t)
+	service := NewUserService(repository, &config.Config{})
+	user := &models.User{UserId: uuid.New()}
+	other := uuid.New()
+	// only events the user did, an admin suspending them is actor admin and target user and isn't matched
+	repository.On("GetAuditEvents", mock.Anything, mock.MatchedBy(func(filter *models.AuditFilter) bool {
+		return *filter.ActorId == user.UserId && filter.TargetId == nil && filter.Type == AuditUserSignIn
+	})).Return(&models.AuditPage{}, nil).Once()
+	if _, err := service.GetUserActivity(context.TODO(), user, &models.AuditFilter{ActorId: &other, Type: AuditUserSignIn, Limit: 10}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGetUserActivityHidesAdminEvents(t *testing.T) {
+	repository := mocks.NewRepository(t)
+	service := NewUserService(repository, &config.Config{})
+	user := &models.User{UserId: uuid.New()}
+	adminId := uuid.New()
+	events := []models.AuditEvent{
+		{EventId: uuid.New(), Type: AuditUserSignIn, ActorId: &user.UserId, TargetId: &user.UserId},
+		{EventId: uuid.New(), Type: AuditAdminUserSuspend, ActorId: &adminId, TargetId: &user.UserId, Ip: "10.1.1.1", Metadata: map[string]interface{}{"reason": "abuse"}},
+	}
+	// the mock applies the filter the way queryGetAuditEvents does
+	repository.On("GetAuditEvents", mock.Anything, mock.Anything).Return(func(ctx context.Context, filter *models.AuditFilter) *models.AuditPage {
+		page := &models.AuditPage{}
+		for _, event := range events {
+			if (filter.ActorId == nil || *event.ActorId == *filter.ActorId) && (filter.TargetId == nil || *event.TargetId == *filter.TargetId) {
+				page.Events = append(page.Events, event)
+			}
+		}
+		return page
+	}, nil).Once()
+	page, err := service.GetUserActivity(context.TODO(), user, &models.AuditFilter{Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Events) != 1 || page.Events[0].Type != AuditUserSignIn {
+		t.Fatalf("expected only the user's own events, got %+v", page.Events)
+	}
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"math/bits"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	config "github.com/Kin-dza-dzaa/userApi/configs"
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/dchest/uniuri"
+	"github.com/golang-jwt/jwt"
+)
+
+const (
+	challengeTtl = 5 * time.Minute
+	// maxChallengeSolution keeps clients from making the server hash large inputs
+	maxChallengeSolution = 64
+
+	challengeNone = "none"
+)
+
+var (
+	ErrChallengeRequired = errors.New("this endpoint needs a solved challenge")
+	ErrChallengeFailed   = errors.New("challenge expired or wasn't solved")
+)
+
+// captchaVerifyUrls are the siteverify endpoints of the providers, hCaptcha and Turnstile share the API.
+var captchaVerifyUrls = map[string]string{
+	config.CaptchaHcaptcha:  "https://api.hcaptcha.com/siteverify",
+	config.CaptchaTurnstile: "https://challenges.cloudflare.com/turnstile/v0/siteverify",
+}
+
+// CaptchaVerifier checks the token a CAPTCHA widget gave the client, ok is false if the provider rejected it.
+type CaptchaVerifier interface {
+	Verify(ctx context.Context, token string, remoteIp string) (ok bool, err error)
+}
+
+// siteVerifyCaptcha posts the token to the siteverify url of hCaptcha or Turnstile.
+type siteVerifyCaptcha struct {
+	url        string
+	secret     string
+	httpClient *http.Client
+}
+
+func newCaptchaVerifier(config *config.Config, httpClient *http.Client) CaptchaVerifier {
+	verifyUrl := config.CaptchaVerifyUrl
+	if verifyUrl == "" {
+		verifyUrl = captchaVerifyUrls[config.CaptchaProvider]
+	}
+	return &siteVerifyCaptcha{url: verifyUrl, secret: config.CaptchaSecret, httpClient: httpClient}
+}
+
+func (captcha *siteVerifyCaptcha) Verify(ctx context.Context, token string, remoteIp string) (bool, error) {
+	form := url.Values{"secret": {captcha.secret}, "response": {token}}
+	if remoteIp != "" {
+		form.Set("remoteip", remoteIp)
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, captcha.url, strings.NewReader(form.Encode()))
+	if err != nil {
+		return false, err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	response, err := captcha.httpClient.Do(request)
+	if err != nil {
+		return false, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return false, errors.New("captcha siteverify answered " + response.Status)
+	}
+	var result struct {
+		Success bool `json:"success"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+		return false, err
+	}
+	return result.Success, nil
+}
+
+// challengeMode is the CHALLENGE_* setting of the endpoint, endpoints without one aren't guarded.
+func (service *UserService) challengeMode(endpoint string) string {
+	switch endpoint {
+	case models.ChallengeSignUp:
+		return service.config.ChallengeSignUp
+	case models.ChallengeSignIn:
+		return service.config.ChallengeSignIn
+	default:
+		return config.ChallengeOff
+	}
+}
+
+// NewChallenge tells the client what the endpoint needs, a proof-of-work comes with a signed puzzle.
+func (service *UserService) NewChallenge(ctx context.Context, endpoint string) (*models.Challenge, error) {
+	challenge := &models.Challenge{Endpoint: endpoint, Type: challengeNone}
+	switch service.challengeMode(endpoint) {
+	case config.ChallengeCaptcha:
+		challenge.Type, challenge.Provider, challenge.SiteKey = config.ChallengeCaptcha, service.config.CaptchaProvider, service.config.CaptchaSiteKey
+	case config.ChallengePow:
+		claims := &models.ChallengeClaims{
+			Endpoint:   endpoint,
+			Difficulty: service.config.ChallengePowDifficulty,
+			StandardClaims: jwt.StandardClaims{
+				Id:        uniuri.NewLen(32),
+				ExpiresAt: time.Now().UTC().Add(challengeTtl).Unix(),
+			},
+		}
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(service.challengeKey())
+		if err != nil {
+			return nil, err
+		}
+		challenge.Type, challenge.Token, challenge.Difficulty, challenge.ExpiresIn = config.ChallengePow, token, claims.Difficulty, int(challengeTtl.Seconds())
+	}
+	return challenge, nil
+}
+
+// VerifyChallenge lets the request through if the endpoint isn't guarded or the client solved its challenge,
+// a proof-of-work can only be used once.
+func (service *UserService) VerifyChallenge(ctx context.Context, response *models.ChallengeResponse) error {
+	switch service.challengeMode(response.Endpoint) {
+	case config.ChallengeCaptcha:
+		if response.CaptchaToken == "" {
+			return apierror.NewCatalogError(apierror.CodeChallengeRequired, ErrChallengeRequired.Error())
+		}
+		ok, err := service.captcha.Verify(ctx, response.CaptchaToken, response.Ip)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return apierror.NewCatalogError(apierror.CodeChallengeFailed, ErrChallengeFailed.Error())
+		}
+	case config.ChallengePow:
+		if response.Token == "" || response.Solution == "" {
+			return apierror.NewCatalogError(apierror.CodeChallengeRequired, ErrChallengeRequired.Error())
+		}
+		claims := new(models.ChallengeClaims)
+		_, err := jwt.ParseWithClaims(response.Token, claims, func(token *jwt.Token) (interface{}, error) {
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, ErrChallengeFailed
+			}
+			return service.challengeKey(), nil
+		})
+		if err != nil || claims.Endpoint != response.Endpoint || len(response.Solution) > maxChallengeSolution ||
+			!solvesChallenge(response.Token, response.Solution, claims.Difficulty) {
+			return apierror.NewCatalogError(apierror.CodeChallengeFailed, ErrChallengeFailed.Error())
+		}
+		return service.repository.UseChallenge(ctx, &models.UsedChallenge{Jti: claims.Id, ExpirationTime: time.Unix(claims.ExpiresAt, 0).UTC()})
+	}
+	return nil
+}
+
+// solvesChallenge tells if sha256(token + ":" + solution) starts with difficulty zero bits.
+func solvesChallenge(token string, solution string, difficulty int) bool {
+	sum := sha256.Sum256([]byte(token + ":" + solution))
+	zeros := 0
+	for _, b := range sum {
+		zeros += bits.LeadingZeros8(b)
+		if b != 0 {
+			break
+		}
+	}
+	return zeros >= difficulty
+}
+
+// challengeKey is derived from JWT_SECURE_STRING so a challenge can never pass as an access token.
+func (service *UserService) challengeKey() []byte {
+	return []byte(service.config.JWTString + ":challenge")
+}
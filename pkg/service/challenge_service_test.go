@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	config "github.com/Kin-dza-dzaa/userApi/configs"
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/Kin-dza-dzaa/userApi/pkg/mocks"
+	"github.com/stretchr/testify/mock"
+)
+
+func solveChallenge(token string, difficulty int) string {
+	for nonce := 0; ; nonce++ {
+		if solution := strconv.Itoa(nonce); solvesChallenge(token, solution, difficulty) {
+			return solution
+		}
+	}
+}
+
+func challengeErrorCode(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.(*apierror.ErrorStruct).ErrorCode
+}
+
+func TestProofOfWorkChallenge(t *testing.T) {
+	repository := mocks.NewRepository(t)
+	service := NewUserService(repository, &config.Config{JWTString: "secret", ChallengeSignUp: config.ChallengePow, ChallengeSignIn: config.ChallengePow, ChallengePowDifficulty: 8})
+	challenge, err := service.NewChallenge(context.TODO(), models.ChallengeSignUp)
+	if err != nil || challenge.Type != config.ChallengePow || challenge.Difficulty != 8 || challenge.Token == "" {
+		t.Fatalf("unexpected challenge %+v, %v", challenge, err)
+	}
+	solution := solveChallenge(challenge.Token, challenge.Difficulty)
+
+	testCases := []struct {
+		name     string
+		response *models.ChallengeResponse
+		errCode  string
+	}{
+		{name: "missing", response: &models.ChallengeResponse{Endpoint: models.ChallengeSignUp}, errCode: apierror.CodeChallengeRequired},
+		{name: "other_endpoint", response: &models.ChallengeResponse{Endpoint: models.ChallengeSignIn, Token: challenge.Token, Solution: solution}, errCode: apierror.CodeChallengeFailed},
+		{name: "wrong_solution", response: &models.ChallengeResponse{Endpoint: models.ChallengeSignUp, Token: challenge.Token, Solution: solution + "x"}, errCode: apierror.CodeChallengeFailed},
+		{name: "tampered", response: &models.ChallengeResponse{Endpoint: models.ChallengeSignUp, Token: challenge.Token + "x", Solution: solution}, errCode: apierror.CodeChallengeFailed},
+		{name: "solved", response: &models.ChallengeResponse{Endpoint: models.ChallengeSignUp, Token: challenge.Token, Solution: solution}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.errCode == "" {
+				repository.On("UseChallenge", mock.Anything, mock.MatchedBy(func(used *models.UsedChallenge) bool {
+					return used.Jti != "" && !used.ExpirationTime.IsZero()
+				})).Return(nil).Once()
+			}
+			if code := challengeErrorCode(service.VerifyChallenge(context.TODO(), tc.response)); code != tc.errCode {
+				t.Fatalf("expected %q, got %q", tc.errCode, code)
+			}
+		})
+	}
+}
+
+func TestCaptchaChallenge(t *testing.T) {
+	// the stub stands in for the siteverify endpoint of hCaptcha and Turnstile
+	stub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.PostFormValue("secret") != "captcha-secret" || r.PostFormValue("remoteip") != "203.0.113.7" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"success": ` + strconv.FormatBool(r.PostFormValue("response") == "passed") + `}`))
+	}))
+	defer stub.Close()
+	service := NewUserService(mocks.NewRepository(t), &config.Config{
+		ChallengeSignUp:  config.ChallengeCaptcha,
+		ChallengeSignIn:  config.ChallengeOff,
+		CaptchaProvider:  config.CaptchaTurnstile,
+		CaptchaSiteKey:   "site-key",
+		CaptchaSecret:    "captcha-secret",
+		CaptchaVerifyUrl: stub.URL,
+	})
+	challenge, err := service.NewChallenge(context.TODO(), models.ChallengeSignUp)
+	if err != nil || challenge.Type != config.ChallengeCaptcha || challenge.Provider != config.CaptchaTurnstile || challenge.SiteKey != "site-key" {
+		t.Fatalf("unexpected challenge %+v, %v", challenge, err)
+	}
+	testCases := []struct {
+		name     string
+		response *models.ChallengeResponse
+		errCode  string
+	}{
+		{name: "passed", response: &models.ChallengeResponse{Endpoint: models.ChallengeSignUp, CaptchaToken: "passed", Ip: "203.0.113.7"}},
+		{name: "rejected", response: &models.ChallengeResponse{Endpoint: models.ChallengeSignUp, CaptchaToken: "bot", Ip: "203.0.113.7"}, errCode: apierror.CodeChallengeFailed},
+		{name: "missing", response: &models.ChallengeResponse{Endpoint: models.ChallengeSignUp}, errCode: apierror.CodeChallengeRequired},
+		{name: "not_guarded", response: &models.ChallengeResponse{Endpoint: models.ChallengeSignIn}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if code := challengeErrorCode(service.VerifyChallenge(context.TODO(), tc.response)); code != tc.errCode {
+				t.Fatalf("expected %q, got %q", tc.errCode, code)
+			}
+		})
+	}
+}
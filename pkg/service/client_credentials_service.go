@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/golang-jwt/jwt"
+)
+
+// ClientAssertionTypeJwtBearer is the only client_assertion_type of private_key_jwt (RFC 7523).
+const ClientAssertionTypeJwtBearer = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+
+var (
+	ErrGrantTypeNotAllowed    = errors.New("grant type is not allowed for this client")
+	ErrClientAssertionInvalid = errors.New("client assertion is invalid, expired or was already used")
+)
+
+// clientCredentials issues an access token to a service account, sub and client_id of the token are the client id.
+// No refresh token is issued, the client can always authenticate again.
+func (service *UserService) clientCredentials(ctx context.Context, request *models.TokenRequest) (*models.TokenResponse, error) {
+	var client *models.OAuthClient
+	var err error
+	if request.ClientAssertionType != "" {
+		client, err = service.authenticateClientAssertion(ctx, request)
+	} else {
+		client, err = service.authenticateClient(ctx, request.ClientId, request.ClientSecret)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if client.Public() || !contains(client.GrantTypes, GrantTypeClientCredentials) {
+		return nil, apierror.NewOAuthError(apierror.OAuthUnauthorizedClient, ErrGrantTypeNotAllowed.Error())
+	}
+	scopes := strings.Fields(request.Scope)
+	if len(scopes) == 0 {
+		scopes = client.Scopes
+	}
+	for _, scope := range scopes {
+		if !contains(client.Scopes, scope) {
+			return nil, apierror.NewOAuthError(apierror.OAuthInvalidScope, ErrScopeNotAllowed.Error())
+		}
+	}
+	scope := strings.Join(scopes, " ")
+	claims := &models.MyJwtClaims{
+		ClientId:       client.ClientId,
+		Scope:          scope,
+		StandardClaims: jwt.StandardClaims{Subject: client.ClientId, Audience: service.audience(client)},
+	}
+	accessToken, err := service.signAccessToken(claims)
+	if err != nil {
+		return nil, err
+	}
+	return &models.TokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(service.config.AccessTokenTtl().Seconds()),
+		Scope:       scope,
+	}, nil
+}
+
+// authenticateClientAssertion verifies a private_key_jwt assertion: iss and sub are the client id, aud is the token
+// endpoint or the issuer and the jti is remembered on the denylist until the assertion expires so it can't be replayed.
+func (service *UserService) authenticateClientAssertion(ctx context.Context, request *models.TokenRequest) (*models.OAuthClient, error) {
+	invalid := apierror.NewOAuthError(apierror.OAuthInvalidClient, ErrClientAssertionInvalid.Error())
+	if request.ClientAssertionType != ClientAssertionTypeJwtBearer || request.ClientSecret != "" {
+		return nil, invalid
+	}
+	claims := new(jwt.StandardClaims)
+	if _, _, err := new(jwt.Parser).ParseUnverified(request.ClientAssertion, claims); err != nil {
+		return nil, invalid
+	}
+	if claims.Subject == "" || claims.Issuer != claims.Subject || (request.ClientId != "" && request.ClientId != claims.Subject) {
+		return nil, invalid
+	}
+	client := &models.OAuthClient{ClientId: claims.Subject}
+	if err := service.repository.GetClient(ctx, client); err != nil {
+		return nil, err
+	}
+	if client.PublicKey == "" {
+		return nil, invalid
+	}
+	publicKey, err := jwt.ParseRSAPublicKeyFromPEM([]byte(client.PublicKey))
+	if err != nil {
+		return nil, err
+	}
+	_, err = jwt.ParseWithClaims(request.ClientAssertion, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+			return nil, ErrClientAssertionInvalid
+		}
+		return publicKey, nil
+	})
+	now := time.Now().UTC()
+	audience := claims.Audience == service.config.Issuer+"/oauth/token" || claims.Audience == service.config.Issuer
+	if err != nil || !audience || claims.Id == "" || !claims.VerifyExpiresAt(now.Unix(), true) {
+		return nil, invalid
+	}
+	assertion := &models.RevokedToken{Jti: "assertion:" + client.ClientId + ":" + claims.Id, ExpirationTime: time.Unix(claims.ExpiresAt, 0).UTC()}
+	added := new(bool)
+	if err := service.repository.AddRevokedToken(ctx, assertion, added); err != nil {
+		return nil, err
+	}
+	if !*added {
+		return nil, invalid
+	}
+	return client, nil
+}
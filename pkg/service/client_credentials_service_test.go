@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/mock"
+)
+
+func (suite *OAuthServiceSuite) expectServiceAccount(secret string, publicKey string) {
+	suite.repository.On("GetClient", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		client := args.Get(1).(*models.OAuthClient)
+		client.Name = "batch"
+		client.Scopes = []string{"words:read", "words:write"}
+		client.GrantTypes = []string{GrantTypeClientCredentials}
+		client.PublicKey = publicKey
+		if secret != "" {
+			client.SecretHash = hashToken(secret)
+		}
+	}).Return(nil).Once()
+}
+
+func (suite *OAuthServiceSuite) TestClientCredentialsSecret() {
+	testCases := []struct {
+		name    string
+		request models.TokenRequest
+		scope   string
+		errCode string
+	}{
+		{
+			name:    "all_scopes",
+			request: models.TokenRequest{GrantType: GrantTypeClientCredentials, ClientId: "batch", ClientSecret: "secret"},
+			scope:   "words:read words:write",
+		},
+		{
+			name:    "narrowed_scope",
+			request: models.TokenRequest{GrantType: GrantTypeClientCredentials, ClientId: "batch", ClientSecret: "secret", Scope: "words:read"},
+			scope:   "words:read",
+		},
+		{
+			name:    "scope_not_allowed",
+			request: models.TokenRequest{GrantType: GrantTypeClientCredentials, ClientId: "batch", ClientSecret: "secret", Scope: "admin"},
+			errCode: apierror.OAuthInvalidScope,
+		},
+		{
+			name:    "wrong_secret",
+			request: models.TokenRequest{GrantType: GrantTypeClientCredentials, ClientId: "batch", ClientSecret: "wrong"},
+			errCode: apierror.OAuthInvalidClient,
+		},
+	}
+	for _, tc := range testCases {
+		suite.T().Run(tc.name, func(t *testing.T) {
+			suite.expectServiceAccount("secret", "")
+			response, err := suite.service.Token(context.TODO(), &tc.request)
+			if tc.errCode != "" {
+				suite.Equal(tc.errCode, err.(*apierror.OAuthError).ErrorCode)
+				return
+			}
+			suite.Nil(err)
+			suite.Empty(response.RefreshToken)
+			suite.Equal(tc.scope, response.Scope)
+			claims := new(models.MyJwtClaims)
+			_, err = jwt.ParseWithClaims(response.AccessToken, claims, func(token *jwt.Token) (interface{}, error) {
+				return []byte(suite.service.config.JWTString), nil
+			})
+			suite.Nil(err)
+			suite.Equal("batch", claims.Subject)
+			suite.Equal("batch", claims.ClientId)
+			suite.Empty(claims.UserId)
+			suite.Equal(tc.scope, claims.Scope)
+		})
+	}
+}
+
+func (suite *OAuthServiceSuite) TestClientCredentialsNotAllowed() {
+	suite.expectClient("secret")
+	_, err := suite.service.Token(context.TODO(), &models.TokenRequest{GrantType: GrantTypeClientCredentials, ClientId: "wordapi", ClientSecret: "secret"})
+	suite.Equal(apierror.OAuthUnauthorizedClient, err.(*apierror.OAuthError).ErrorCode)
+}
+
+func (suite *OAuthServiceSuite) TestClientCredentialsAssertion() {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	suite.Require().Nil(err)
+	publicKey, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	suite.Require().Nil(err)
+	publicPem := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey}))
+	assertion := func(audience string) string {
+		signed, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.StandardClaims{
+			Issuer:    "batch",
+			Subject:   "batch",
+			Audience:  audience,
+			Id:        "assertion-id",
+			ExpiresAt: time.Now().Add(time.Minute).Unix(),
+		}).SignedString(key)
+		suite.Require().Nil(err)
+		return signed
+	}
+	tokenEndpoint := suite.service.config.Issuer + "/oauth/token"
+	testCases := []struct {
+		name      string
+		assertion string
+		replayed  bool
+		errCode   string
+	}{
+		{
+			name:      "valid",
+			assertion: assertion(tokenEndpoint),
+		},
+		{
+			name:      "replayed",
+			assertion: assertion(tokenEndpoint),
+			replayed:  true,
+			errCode:   apierror.OAuthInvalidClient,
+		},
+		{
+			name:      "wrong_audience",
+			assertion: assertion("http://other"),
+			errCode:   apierror.OAuthInvalidClient,
+		},
+	}
+	for _, tc := range testCases {
+		suite.T().Run(tc.name, func(t *testing.T) {
+			suite.expectServiceAccount("", publicPem)
+			if tc.errCode == "" || tc.replayed {
+				suite.repository.On("AddRevokedToken", mock.Anything, mock.MatchedBy(func(token *models.RevokedToken) bool {
+					return token.Jti == "assertion:batch:assertion-id"
+				}), mock.Anything).Run(func(args mock.Arguments) {
+					*args.Get(2).(*bool) = !tc.replayed
+				}).Return(nil).Once()
+			}
+			response, err := suite.service.Token(context.TODO(), &models.TokenRequest{GrantType: GrantTypeClientCredentials, ClientAssertionType: ClientAssertionTypeJwtBearer, ClientAssertion: tc.assertion})
+			if tc.errCode != "" {
+				suite.Equal(tc.errCode, err.(*apierror.OAuthError).ErrorCode)
+				return
+			}
+			suite.Nil(err)
+			suite.NotEmpty(response.AccessToken)
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/dchest/uniuri"
+)
+
+// GrantTypeDeviceCode is the grant type of the device authorization grant (RFC 8628).
+const GrantTypeDeviceCode = "urn:ietf:params:oauth:grant-type:device_code"
+
+const (
+	deviceCodeTtl      = time.Minute * 10
+	devicePollInterval = 5
+	// no vowels so user codes don't spell words, no digits so they can't be confused with letters
+	userCodeChars = "BCDFGHJKLMNPQRSTVWXZ"
+)
+
+var (
+	ErrDeviceAuthorizationPending = errors.New("the user hasn't approved the device yet")
+	ErrDeviceSlowDown             = errors.New("polling too fast, the interval was increased by 5 seconds")
+	ErrDeviceCodeExpired          = errors.New("device code expired")
+	ErrDeviceAccessDenied         = errors.New("the user denied the device")
+	ErrDeviceCodeMismatch         = errors.New("device code was issued to another client")
+	ErrDeviceNotConfirmed         = errors.New("the approval names another client than the one of the user code")
+)
+
+// AuthorizeDevice starts the device flow, the client shows the user code and verification uri to the user and polls
+// the token endpoint with the device code. Only the hash of the device code is stored.
+func (service *UserService) AuthorizeDevice(ctx context.Context, request *models.DeviceAuthorizationRequest) (*models.DeviceAuthorizationResponse, error) {
+	client, err := service.authenticateClient(ctx, request.ClientId, request.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+	if !contains(client.GrantTypes, GrantTypeDeviceCode) {
+		return nil, apierror.NewOAuthError(apierror.OAuthUnauthorizedClient, ErrGrantTypeNotAllowed.Error())
+	}
+	for _, scope := range strings.Fields(request.Scope) {
+		if !contains(client.Scopes, scope) {
+			return nil, apierror.NewOAuthError(apierror.OAuthInvalidScope, ErrScopeNotAllowed.Error())
+		}
+	}
+	userCode := uniuri.NewLenChars(8, []byte(userCodeChars))
+	device := &models.DeviceAuthorization{
+		DeviceCode:     uniuri.NewLen(40),
+		UserCode:       userCode[:4] + "-" + userCode[4:],
+		ClientId:       client.ClientId,
+		Scope:          strings.Join(strings.Fields(request.Scope), " "),
+		Status:         models.DeviceStatusPending,
+		Interval:       devicePollInterval,
+		ExpirationTime: time.Now().UTC().Add(deviceCodeTtl),
+	}
+	device.DeviceCodeHash = hashToken(device.DeviceCode)
+	if err := service.repository.AddDeviceAuthorization(ctx, device); err != nil {
+		return nil, err
+	}
+	verificationUri := service.config.SpaUrl + "/device"
+	return &models.DeviceAuthorizationResponse{
+		DeviceCode:              device.DeviceCode,
+		UserCode:                device.UserCode,
+		VerificationUri:         verificationUri,
+		VerificationUriComplete: verificationUri + "?user_code=" + device.UserCode,
+		ExpiresIn:               int(deviceCodeTtl.Seconds()),
+		Interval:                device.Interval,
+	}, nil
+}
+
+// GetDevicePrompt shows the client and the scopes of a pending user code, the user has to see them before approving.
+func (service *UserService) GetDevicePrompt(ctx context.Context, userCode string) (*models.DevicePrompt, error) {
+	device := &models.DeviceAuthorization{UserCode: normalizeUserCode(userCode)}
+	if err := service.repository.GetDeviceAuthorization(ctx, device); err != nil {
+		return nil, err
+	}
+	return &models.DevicePrompt{UserCode: device.UserCode, ClientId: device.ClientId, ClientName: device.ClientName, Scopes: strings.Fields(device.Scope)}, nil
+}
+
+// ApproveDevice approves or denies a pending user code on behalf of the signed in user. An approval has to name the
+// client of the user code, which GetDevicePrompt showed, so a user code passed on by someone else isn't approved blindly.
+func (service *UserService) ApproveDevice(ctx context.Context, approval *models.DeviceApproval) error {
+	device := &models.DeviceAuthorization{
+		UserCode: normalizeUserCode(approval.UserCode),
+		UserId:   approval.UserId,
+		Status:   models.DeviceStatusDenied,
+	}
+	if approval.Approve {
+		prompt := &models.DeviceAuthorization{UserCode: device.UserCode}
+		if err := service.repository.GetDeviceAuthorization(ctx, prompt); err != nil {
+			return err
+		}
+		if approval.ClientId != prompt.ClientId {
+			return apierror.NewCatalogError(apierror.CodeDeviceNotConfirmed, ErrDeviceNotConfirmed.Error())
+		}
+		device.Status, device.ClientId = models.DeviceStatusApproved, approval.ClientId
+	}
+	return service.repository.DecideDeviceAuthorization(ctx, device)
+}
+
+// exchangeDeviceCode answers a poll of the device, the device code is consumed once tokens are issued.
+func (service *UserService) exchangeDeviceCode(ctx context.Context, request *models.TokenRequest) (*models.TokenResponse, error) {
+	client, err := service.authenticateClient(ctx, request.ClientId, request.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+	device := &models.DeviceAuthorization{DeviceCodeHash: hashToken(request.DeviceCode)}
+	if err := service.repository.PollDeviceAuthorization(ctx, device); err != nil {
+		return nil, err
+	}
+	if device.ClientId != client.ClientId {
+		return nil, apierror.NewOAuthError(apierror.OAuthInvalidGrant, ErrDeviceCodeMismatch.Error())
+	}
+	if time.Now().UTC().After(device.ExpirationTime) {
+		return nil, apierror.NewOAuthError(apierror.OAuthExpiredToken, ErrDeviceCodeExpired.Error())
+	}
+	switch device.Status {
+	case models.DeviceStatusPending:
+		if device.SlowDown {
+			return nil, apierror.NewOAuthError(apierror.OAuthSlowDown, ErrDeviceSlowDown.Error())
+		}
+		return nil, apierror.NewOAuthError(apierror.OAuthAuthorizationPending, ErrDeviceAuthorizationPending.Error())
+	case models.DeviceStatusApproved:
+		if err := service.repository.DeleteDeviceAuthorization(ctx, device); err != nil {
+			return nil, err
+		}
+		return service.issueOAuthTokens(ctx, client, device.UserId, device.Scope, "", time.Time{}, time.Now().UTC())
+	default:
+		return nil, apierror.NewOAuthError(apierror.OAuthAccessDenied, ErrDeviceAccessDenied.Error())
+	}
+}
+
+// normalizeUserCode accepts user codes typed in lower case, with spaces or without the dash.
+func normalizeUserCode(userCode string) string {
+	userCode = strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(userCode))
+	if len(userCode) != 8 {
+		return userCode
+	}
+	return userCode[:4] + "-" + userCode[4:]
+}
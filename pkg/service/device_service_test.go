@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+func (suite *OAuthServiceSuite) expectDeviceClient() {
+	suite.repository.On("GetClient", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		client := args.Get(1).(*models.OAuthClient)
+		client.Name = "cli"
+		client.Scopes = []string{ScopeOpenId, ScopeProfile}
+		client.GrantTypes = []string{GrantTypeDeviceCode, GrantTypeRefreshToken}
+	}).Return(nil).Once()
+}
+
+func (suite *OAuthServiceSuite) TestAuthorizeDevice() {
+	suite.expectDeviceClient()
+	suite.repository.On("AddDeviceAuthorization", mock.Anything, mock.MatchedBy(func(device *models.DeviceAuthorization) bool {
+		return device.ClientId == "cli" && device.Status == models.DeviceStatusPending && device.DeviceCodeHash == hashToken(device.DeviceCode)
+	})).Return(nil).Once()
+	response, err := suite.service.AuthorizeDevice(context.TODO(), &models.DeviceAuthorizationRequest{ClientId: "cli", Scope: "openid"})
+	suite.Require().Nil(err)
+	suite.Regexp(regexp.MustCompile("^["+userCodeChars+"]{4}-["+userCodeChars+"]{4}$"), response.UserCode)
+	suite.Equal(suite.service.config.SpaUrl+"/device", response.VerificationUri)
+	suite.Equal(devicePollInterval, response.Interval)
+
+	suite.expectDeviceClient()
+	_, err = suite.service.AuthorizeDevice(context.TODO(), &models.DeviceAuthorizationRequest{ClientId: "cli", Scope: "email"})
+	suite.Equal(apierror.OAuthInvalidScope, err.(*apierror.OAuthError).ErrorCode)
+
+	suite.expectClient("")
+	_, err = suite.service.AuthorizeDevice(context.TODO(), &models.DeviceAuthorizationRequest{ClientId: "wordApi"})
+	suite.Equal(apierror.OAuthUnauthorizedClient, err.(*apierror.OAuthError).ErrorCode)
+}
+
+func (suite *OAuthServiceSuite) expectDevicePrompt() {
+	suite.repository.On("GetDeviceAuthorization", mock.Anything, mock.MatchedBy(func(device *models.DeviceAuthorization) bool {
+		return device.UserCode == "BCDF-GHJK"
+	})).Run(func(args mock.Arguments) {
+		device := args.Get(1).(*models.DeviceAuthorization)
+		device.ClientId, device.ClientName, device.Scope = "cli", "Words CLI", "openid profile"
+	}).Return(nil).Once()
+}
+
+func (suite *OAuthServiceSuite) TestGetDevicePrompt() {
+	suite.expectDevicePrompt()
+	prompt, err := suite.service.GetDevicePrompt(context.TODO(), "bcdf ghjk")
+	suite.Nil(err)
+	suite.Equal(&models.DevicePrompt{UserCode: "BCDF-GHJK", ClientId: "cli", ClientName: "Words CLI", Scopes: []string{"openid", "profile"}}, prompt)
+}
+
+func (suite *OAuthServiceSuite) TestApproveDevice() {
+	userId := uuid.New()
+	suite.expectDevicePrompt()
+	suite.repository.On("DecideDeviceAuthorization", mock.Anything, mock.MatchedBy(func(device *models.DeviceAuthorization) bool {
+		return device.UserCode == "BCDF-GHJK" && device.UserId == userId && device.Status == models.DeviceStatusApproved && device.ClientId == "cli"
+	})).Return(nil).Once()
+	suite.Nil(suite.service.ApproveDevice(context.TODO(), &models.DeviceApproval{UserCode: "bcdf ghjk", UserId: userId, ClientId: "cli", Approve: true}))
+
+	suite.expectDevicePrompt()
+	err := suite.service.ApproveDevice(context.TODO(), &models.DeviceApproval{UserCode: "BCDF-GHJK", UserId: userId, ClientId: "other", Approve: true})
+	suite.Equal(apierror.CodeDeviceNotConfirmed, err.(*apierror.ErrorStruct).ErrorCode)
+
+	suite.repository.On("DecideDeviceAuthorization", mock.Anything, mock.MatchedBy(func(device *models.DeviceAuthorization) bool {
+		return device.UserCode == "BCDF-GHJK" && device.Status == models.DeviceStatusDenied
+	})).Return(nil).Once()
+	suite.Nil(suite.service.ApproveDevice(context.TODO(), &models.DeviceApproval{UserCode: "BCDF-GHJK", UserId: userId}))
+}
+
+func (suite *OAuthServiceSuite) TestExchangeDeviceCode() {
+	userId := uuid.New()
+	testCases := []struct {
+		name       string
+		device     models.DeviceAuthorization
+		beforeTest func()
+		errCode    string
+	}{
+		{
+			name:    "pending",
+			device:  models.DeviceAuthorization{ClientId: "cli", Status: models.DeviceStatusPending, ExpirationTime: time.Now().Add(time.Minute)},
+			errCode: apierror.OAuthAuthorizationPending,
+		},
+		{
+			name:    "slow_down",
+			device:  models.DeviceAuthorization{ClientId: "cli", Status: models.DeviceStatusPending, SlowDown: true, ExpirationTime: time.Now().Add(time.Minute)},
+			errCode: apierror.OAuthSlowDown,
+		},
+		{
+			name:    "denied",
+			device:  models.DeviceAuthorization{ClientId: "cli", Status: models.DeviceStatusDenied, ExpirationTime: time.Now().Add(time.Minute)},
+			errCode: apierror.OAuthAccessDenied,
+		},
+		{
+			name:    "expired",
+			device:  models.DeviceAuthorization{ClientId: "cli", Status: models.DeviceStatusPending, ExpirationTime: time.Now().Add(-time.Minute)},
+			errCode: apierror.OAuthExpiredToken,
+		},
+		{
+			name:    "other_client",
+			device:  models.DeviceAuthorization{ClientId: "other", Status: models.DeviceStatusApproved, ExpirationTime: time.Now().Add(time.Minute)},
+			errCode: apierror.OAuthInvalidGrant,
+		},
+		{
+			name:   "approved",
+			device: models.DeviceAuthorization{ClientId: "cli", Status: models.DeviceStatusApproved, UserId: userId, Scope: "profile", ExpirationTime: time.Now().Add(time.Minute)},
+			beforeTest: func() {
+				suite.repository.On("DeleteDeviceAuthorization", mock.Anything, mock.Anything).Return(nil).Once()
+				suite.repository.On("GetUserById", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+					args.Get(1).(*models.User).Status = models.UserStatusActive
+				}).Return(nil).Once()
+				suite.repository.On("AddOAuthRefreshToken", mock.Anything, mock.MatchedBy(func(token *models.OAuthRefreshToken) bool {
+					return token.UserId == userId && token.ClientId == "cli"
+				})).Return(nil).Once()
+			},
+		},
+	}
+	for _, tc := range testCases {
+		suite.T().Run(tc.name, func(t *testing.T) {
+			suite.expectDeviceClient()
+			suite.repository.On("PollDeviceAuthorization", mock.Anything, mock.MatchedBy(func(device *models.DeviceAuthorization) bool {
+				return device.DeviceCodeHash == hashToken("device")
+			})).Run(func(args mock.Arguments) {
+				device := args.Get(1).(*models.DeviceAuthorization)
+				hash := device.DeviceCodeHash
+				*device = tc.device
+				device.DeviceCodeHash = hash
+			}).Return(nil).Once()
+			if tc.beforeTest != nil {
+				tc.beforeTest()
+			}
+			response, err := suite.service.Token(context.TODO(), &models.TokenRequest{GrantType: GrantTypeDeviceCode, ClientId: "cli", DeviceCode: "device"})
+			if tc.errCode != "" {
+				suite.Equal(tc.errCode, err.(*apierror.OAuthError).ErrorCode)
+				return
+			}
+			suite.Nil(err)
+			suite.NotEmpty(response.AccessToken)
+			suite.NotEmpty(response.RefreshToken)
+			suite.Equal("profile", response.Scope)
+		})
+	}
+}
+
+func TestNormalizeUserCode(t *testing.T) {
+	for userCode, normalized := range map[string]string{"bcdf-ghjk": "BCDF-GHJK", "BCDFGHJK": "BCDF-GHJK", " bcdf ghjk ": "BCDF-GHJK", "BCD": "BCD"} {
+		if got := normalizeUserCode(userCode); got != normalized {
+			t.Errorf("normalizeUserCode(%q) = %q", userCode, got)
+		}
+	}
+}
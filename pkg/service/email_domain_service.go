@@ -0,0 +1,144 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"errors"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	config "github.com/Kin-dza-dzaa/userApi/configs"
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+)
+
+var ErrEmailDomainNotAllowed = errors.New("sign ups from this email domain aren't allowed")
+
+//go:embed disposable_domains.txt
+var bundledDisposableDomains []byte
+
+var disposableDomains = parseDomainList(bundledDisposableDomains)
+
+// MxResolver looks up the mail servers of a domain, *net.Resolver implements it.
+type MxResolver interface {
+	LookupMX(ctx context.Context, name string) ([]*net.MX, error)
+}
+
+// domainListFile keeps the domains of EMAIL_DOMAIN_LIST_LOCATION, it is read again when its modification time changes.
+type domainListFile struct {
+	mutex   sync.Mutex
+	modTime time.Time
+	domains map[string]bool
+}
+
+// checkEmailDomain decides if email may sign up. Admin rules come first, then the lists of the mode and the MX check.
+func (service *UserService) checkEmailDomain(ctx context.Context, email string) error {
+	mode := service.config.EmailDomainMode
+	if mode == config.EmailDomainOff {
+		return nil
+	}
+	domain := email[strings.LastIndex(email, "@")+1:]
+	rule, matched := new(models.EmailDomainRule), new(bool)
+	if err := service.repository.MatchEmailDomainRule(ctx, domain, rule, matched); err != nil {
+		return err
+	}
+	listed, err := service.domainListed(domain)
+	if err != nil {
+		return err
+	}
+	var allowed bool
+	switch {
+	case *matched:
+		allowed = rule.Kind == models.EmailDomainAllow
+	case mode == config.EmailDomainAllowlist:
+		allowed = listed
+	default:
+		allowed = !listed && !matchesDomain(disposableDomains, domain)
+	}
+	if !allowed {
+		return apierror.NewCatalogError(apierror.CodeEmailDomainNotAllowed, ErrEmailDomainNotAllowed.Error())
+	}
+	if service.config.EmailDomainCheckMx {
+		return service.checkMx(ctx, domain)
+	}
+	return nil
+}
+
+// checkMx rejects domains that don't exist or have no mail servers, DNS failures let the sign up through.
+func (service *UserService) checkMx(ctx context.Context, domain string) error {
+	records, err := service.resolver.LookupMX(ctx, domain)
+	var dnsErr *net.DNSError
+	if err != nil && !(errors.As(err, &dnsErr) && dnsErr.IsNotFound) {
+		return nil
+	}
+	for _, record := range records {
+		// a single "." is a null MX, the domain says it accepts no mail
+		if record.Host != "." && record.Host != "" {
+			return nil
+		}
+	}
+	return apierror.NewCatalogError(apierror.CodeEmailDomainNotAllowed, ErrEmailDomainNotAllowed.Error())
+}
+
+// domainListed tells if domain is in the list file, there is no list without EMAIL_DOMAIN_LIST_LOCATION.
+func (service *UserService) domainListed(domain string) (bool, error) {
+	location := service.config.EmailDomainListLocation
+	if location == "" {
+		return false, nil
+	}
+	list := &service.domainList
+	list.mutex.Lock()
+	defer list.mutex.Unlock()
+	info, err := os.Stat(location)
+	if err != nil {
+		return false, err
+	}
+	if list.domains == nil || !info.ModTime().Equal(list.modTime) {
+		data, err := os.ReadFile(location)
+		if err != nil {
+			return false, err
+		}
+		list.domains, list.modTime = parseDomainList(data), info.ModTime()
+	}
+	return matchesDomain(list.domains, domain), nil
+}
+
+func (service *UserService) GetEmailDomainRules(ctx context.Context) ([]models.EmailDomainRule, error) {
+	return service.repository.GetEmailDomainRules(ctx)
+}
+
+// PutEmailDomainRule creates the rule or changes its kind.
+func (service *UserService) PutEmailDomainRule(ctx context.Context, rule *models.EmailDomainRule) error {
+	rule.CreatedAt = time.Now().UTC()
+	return service.repository.PutEmailDomainRule(ctx, rule)
+}
+
+func (service *UserService) DeleteEmailDomainRule(ctx context.Context, rule *models.EmailDomainRule) error {
+	return service.repository.DeleteEmailDomainRule(ctx, rule)
+}
+
+// parseDomainList reads one domain per line, blank lines and lines starting with "#" are skipped.
+func parseDomainList(data []byte) map[string]bool {
+	domains := make(map[string]bool)
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		domain := strings.ToLower(strings.TrimSpace(string(line)))
+		if domain != "" && !strings.HasPrefix(domain, "#") {
+			domains[domain] = true
+		}
+	}
+	return domains
+}
+
+// matchesDomain tells if domain or one of its parents is in domains.
+func matchesDomain(domains map[string]bool, domain string) bool {
+	for _, parent := range models.ParentDomains(domain) {
+		if domains[parent] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	config "github.com/Kin-dza-dzaa/userApi/configs"
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/Kin-dza-dzaa/userApi/pkg/mocks"
+	"github.com/stretchr/testify/mock"
+)
+
+func allowNoEmailDomainRules(repository *mocks.Repository) {
+	repository.On("MatchEmailDomainRule", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+}
+
+// fakeResolver answers MX lookups from a map, a missing domain is NXDOMAIN.
+type fakeResolver map[string][]*net.MX
+
+func (resolver fakeResolver) LookupMX(ctx context.Context, name string) ([]*net.MX, error) {
+	records, ok := resolver[name]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return records, nil
+}
+
+func TestCheckEmailDomain(t *testing.T) {
+	listLocation := filepath.Join(t.TempDir(), "domains.txt")
+	if err := os.WriteFile(listLocation, []byte("# company domains\nexample.com\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	resolver := fakeResolver{
+		"example.com": {{Host: "mx.example.com."}},
+		"example.org": {{Host: "mx.example.org."}},
+		"nomail.org":  {{Host: "."}},
+	}
+	testCases := []struct {
+		name    string
+		mode    string
+		list    string
+		checkMx bool
+		email   string
+		rule    *models.EmailDomainRule
+		allowed bool
+	}{
+		{name: "off", mode: config.EmailDomainOff, email: "bob@mailinator.com", allowed: true},
+		{name: "blocklist_allows", mode: config.EmailDomainBlocklist, email: "bob@example.org", allowed: true},
+		{name: "blocklist_bundled", mode: config.EmailDomainBlocklist, email: "bob@mailinator.com"},
+		{name: "blocklist_bundled_subdomain", mode: config.EmailDomainBlocklist, email: "bob@eu.mailinator.com"},
+		{name: "blocklist_file", mode: config.EmailDomainBlocklist, list: listLocation, email: "bob@mail.example.com"},
+		{name: "blocklist_rule_block", mode: config.EmailDomainBlocklist, email: "bob@example.org", rule: &models.EmailDomainRule{Domain: "example.org", Kind: models.EmailDomainBlock}},
+		{name: "blocklist_rule_allow", mode: config.EmailDomainBlocklist, email: "bob@mailinator.com", rule: &models.EmailDomainRule{Domain: "mailinator.com", Kind: models.EmailDomainAllow}, allowed: true},
+		{name: "allowlist_file", mode: config.EmailDomainAllowlist, list: listLocation, email: "bob@example.com", allowed: true},
+		{name: "allowlist_unlisted", mode: config.EmailDomainAllowlist, list: listLocation, email: "bob@example.org"},
+		{name: "allowlist_rule_allow", mode: config.EmailDomainAllowlist, email: "bob@example.org", rule: &models.EmailDomainRule{Domain: "example.org", Kind: models.EmailDomainAllow}, allowed: true},
+		{name: "mx", mode: config.EmailDomainBlocklist, checkMx: true, email: "bob@example.org", allowed: true},
+		{name: "mx_nxdomain", mode: config.EmailDomainBlocklist, checkMx: true, email: "bob@nowhere.org"},
+		{name: "mx_null", mode: config.EmailDomainBlocklist, checkMx: true, email: "bob@nomail.org"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repository := mocks.NewRepository(t)
+			if tc.mode != config.EmailDomainOff {
+				repository.On("MatchEmailDomainRule", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+					if tc.rule != nil {
+						*args.Get(2).(*models.EmailDomainRule), *args.Get(3).(*bool) = *tc.rule, true
+					}
+				}).Return(nil).Once()
+			}
+			service := NewUserService(repository, &config.Config{EmailDomainMode: tc.mode, EmailDomainListLocation: tc.list, EmailDomainCheckMx: tc.checkMx})
+			service.resolver = resolver
+			err := service.checkEmailDomain(context.TODO(), tc.email)
+			if tc.allowed && err != nil {
+				t.Fatalf("expected the domain to be allowed, got %v", err)
+			}
+			if !tc.allowed && (err == nil || err.(*apierror.ErrorStruct).ErrorCode != apierror.CodeEmailDomainNotAllowed) {
+				t.Fatalf("expected %s, got %v", apierror.CodeEmailDomainNotAllowed, err)
+			}
+		})
+	}
+}
+
+func TestDomainListReload(t *testing.T) {
+	listLocation := filepath.Join(t.TempDir(), "domains.txt")
+	if err := os.WriteFile(listLocation, []byte("example.com\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	service := NewUserService(mocks.NewRepository(t), &config.Config{EmailDomainListLocation: listLocation})
+	if listed, err := service.domainListed("example.com"); err != nil || !listed {
+		t.Fatalf("expected example.com to be listed, got %v, %v", listed, err)
+	}
+	if err := os.WriteFile(listLocation, []byte("example.org\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	// file systems with a coarse modification time wouldn't notice a change within the same tick
+	if err := os.Chtimes(listLocation, time.Now(), time.Now().Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if listed, err := service.domainListed("example.com"); err != nil || listed {
+		t.Fatalf("expected example.com to be dropped, got %v, %v", listed, err)
+	}
+	if listed, err := service.domainListed("example.org"); err != nil || !listed {
+		t.Fatalf("expected example.org to be listed, got %v, %v", listed, err)
+	}
+}
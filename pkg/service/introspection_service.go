@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+)
+
+const (
+	TokenTypeHintAccessToken  = "access_token"
+	TokenTypeHintRefreshToken = "refresh_token"
+
+	TokenTypePersonalAccessToken = "personal_access_token"
+)
+
+var ErrPublicClientIntrospection = errors.New("public clients can't introspect tokens")
+
+// IntrospectToken implements RFC 7662, only confidential clients may introspect. Personal access tokens are accepted
+// like access tokens, refresh tokens of other clients are reported as inactive, anything that isn't a valid token
+// yields {"active": false} rather than an error.
+func (service *UserService) IntrospectToken(ctx context.Context, request *models.IntrospectionRequest) (*models.Introspection, error) {
+	client, err := service.authenticateClient(ctx, request.ClientId, request.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+	if client.Public() {
+		return nil, apierror.NewOAuthError(apierror.OAuthInvalidClient, ErrPublicClientIntrospection.Error())
+	}
+	if request.TokenTypeHint != TokenTypeHintRefreshToken {
+		claims, err := service.issuedTokenClaims(ctx, request.Token)
+		if err == nil {
+			tokenType := "Bearer"
+			if strings.HasPrefix(request.Token, PersonalAccessTokenPrefix) {
+				tokenType = TokenTypePersonalAccessToken
+			}
+			introspection := &models.Introspection{
+				Active:    true,
+				Scope:     claims.Scope,
+				ClientId:  claims.ClientId,
+				TokenType: tokenType,
+				Exp:       claims.ExpiresAt,
+				Iat:       claims.IssuedAt,
+				Nbf:       claims.NotBefore,
+				Sub:       claims.UserId,
+				Aud:       claims.Audience,
+				Iss:       claims.Issuer,
+				Username:  claims.UserName,
+				Jti:       claims.Id,
+			}
+			// tokens of service accounts have no user
+			if introspection.Sub == "" {
+				introspection.Sub = claims.Subject
+			}
+			return introspection, nil
+		}
+		if _, ok := err.(*apierror.ErrorStruct); !ok {
+			return nil, err
+		}
+	}
+	token := &models.OAuthRefreshToken{TokenHash: hashToken(request.Token)}
+	active := new(bool)
+	if err := service.repository.GetOAuthRefreshToken(ctx, token, active); err != nil {
+		return nil, err
+	}
+	if !*active || token.ClientId != client.ClientId {
+		return &models.Introspection{Active: false}, nil
+	}
+	return &models.Introspection{
+		Active:    true,
+		Scope:     token.Scope,
+		ClientId:  token.ClientId,
+		TokenType: TokenTypeHintRefreshToken,
+		Exp:       token.ExpirationTime.Unix(),
+		Sub:       token.UserId.String(),
+	}, nil
+}
+
+// RevokeToken implements RFC 7009: refresh tokens and access tokens issued to the client are deleted or put on the denylist.
+// Unknown or already invalid tokens aren't an error, and neither are tokens of other clients or session tokens, a client
+// can't sign users out of anything it wasn't given (RFC 7009 section 2.1).
+func (service *UserService) RevokeToken(ctx context.Context, request *models.RevocationRequest) error {
+	client, err := service.authenticateClient(ctx, request.ClientId, request.ClientSecret)
+	if err != nil {
+		return err
+	}
+	if request.TokenTypeHint != TokenTypeHintAccessToken {
+		deleted := new(bool)
+		if err := service.repository.DeleteOAuthRefreshToken(ctx, &models.OAuthRefreshToken{TokenHash: hashToken(request.Token), ClientId: client.ClientId}, deleted); err != nil {
+			return err
+		}
+		if *deleted {
+			return nil
+		}
+	}
+	claims, err := service.parseAccessToken(ctx, request.Token, false)
+	if err != nil {
+		if _, ok := err.(*apierror.ErrorStruct); ok {
+			return nil
+		}
+		return err
+	}
+	if claims.ClientId != client.ClientId {
+		return nil
+	}
+	return service.revokeClaims(ctx, claims)
+}
+
+// RevokeAccessToken puts the jti of a valid access token on the denylist until the token expires, it is used on logout too.
+func (service *UserService) RevokeAccessToken(ctx context.Context, accessToken string) error {
+	claims, err := service.parseAccessToken(ctx, accessToken, false)
+	if err != nil {
+		if _, ok := err.(*apierror.ErrorStruct); ok {
+			return nil
+		}
+		return err
+	}
+	return service.revokeClaims(ctx, claims)
+}
+
+func (service *UserService) revokeClaims(ctx context.Context, claims *models.MyJwtClaims) error {
+	if claims.Id == "" {
+		return nil
+	}
+	return service.repository.AddRevokedToken(ctx, &models.RevokedToken{Jti: claims.Id, ExpirationTime: time.Unix(claims.ExpiresAt, 0).UTC()}, new(bool))
+}
+
+// issuedTokenClaims authenticates a personal access token or an access token issued for any audience.
+func (service *UserService) issuedTokenClaims(ctx context.Context, token string) (*models.MyJwtClaims, error) {
+	if strings.HasPrefix(token, PersonalAccessTokenPrefix) {
+		return service.Authenticate(ctx, token)
+	}
+	return service.parseAccessToken(ctx, token, false)
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+func (suite *OAuthServiceSuite) TestIntrospectToken() {
+	user := &models.User{UserId: uuid.New()}
+	suite.Require().Nil(suite.service.generateScopedToken(context.TODO(), user, nil, "openid email"))
+	delegated := &models.User{UserId: user.UserId}
+	suite.Require().Nil(suite.service.generateScopedToken(context.TODO(), delegated, &models.OAuthClient{ClientId: "wordapi", Audience: "https://words.example.com"}, "openid"))
+	testCases := []struct {
+		name       string
+		request    models.IntrospectionRequest
+		secret     string
+		beforeTest func()
+		expected   *models.Introspection
+		errCode    string
+	}{
+		{
+			name:    "access_token",
+			request: models.IntrospectionRequest{ClientId: "wordapi", ClientSecret: "secret", Token: user.Jwt},
+			secret:  "secret",
+			beforeTest: func() {
+				suite.repository.On("IfTokenRevoked", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+			},
+			expected: &models.Introspection{Active: true, Scope: "openid email", TokenType: "Bearer", Sub: user.UserId.String(), Aud: suite.service.config.Audience(), Iss: suite.service.config.Issuer},
+		},
+		{
+			name:    "access_token_of_client_audience",
+			request: models.IntrospectionRequest{ClientId: "wordapi", ClientSecret: "secret", Token: delegated.Jwt},
+			secret:  "secret",
+			beforeTest: func() {
+				suite.repository.On("IfTokenRevoked", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+			},
+			expected: &models.Introspection{Active: true, Scope: "openid", ClientId: "wordapi", TokenType: "Bearer", Sub: user.UserId.String(), Aud: "https://words.example.com", Iss: suite.service.config.Issuer},
+		},
+		{
+			name:    "revoked_access_token",
+			request: models.IntrospectionRequest{ClientId: "wordapi", ClientSecret: "secret", Token: user.Jwt},
+			secret:  "secret",
+			beforeTest: func() {
+				suite.repository.On("IfTokenRevoked", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+					*args.Get(2).(*bool) = true
+				}).Return(nil).Once()
+				suite.repository.On("GetOAuthRefreshToken", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+			},
+			expected: &models.Introspection{Active: false},
+		},
+		{
+			name:    "refresh_token",
+			request: models.IntrospectionRequest{ClientId: "wordapi", ClientSecret: "secret", Token: "refresh", TokenTypeHint: TokenTypeHintRefreshToken},
+			secret:  "secret",
+			beforeTest: func() {
+				suite.repository.On("GetOAuthRefreshToken", mock.Anything, mock.MatchedBy(func(token *models.OAuthRefreshToken) bool {
+					return token.TokenHash == hashToken("refresh")
+				}), mock.Anything).Run(func(args mock.Arguments) {
+					token := args.Get(1).(*models.OAuthRefreshToken)
+					token.ClientId = "wordapi"
+					token.UserId = user.UserId
+					token.Scope = "openid"
+					token.ExpirationTime = time.Unix(2000000000, 0)
+					*args.Get(2).(*bool) = true
+				}).Return(nil).Once()
+			},
+			expected: &models.Introspection{Active: true, Scope: "openid", ClientId: "wordapi", TokenType: TokenTypeHintRefreshToken, Exp: 2000000000, Sub: user.UserId.String()},
+		},
+		{
+			name:    "refresh_token_of_other_client",
+			request: models.IntrospectionRequest{ClientId: "wordapi", ClientSecret: "secret", Token: "refresh", TokenTypeHint: TokenTypeHintRefreshToken},
+			secret:  "secret",
+			beforeTest: func() {
+				suite.repository.On("GetOAuthRefreshToken", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+					args.Get(1).(*models.OAuthRefreshToken).ClientId = "other"
+					*args.Get(2).(*bool) = true
+				}).Return(nil).Once()
+			},
+			expected: &models.Introspection{Active: false},
+		},
+		{
+			name:    "public_client",
+			request: models.IntrospectionRequest{ClientId: "wordapi", Token: user.Jwt},
+			errCode: apierror.OAuthInvalidClient,
+		},
+		{
+			name:    "wrong_secret",
+			request: models.IntrospectionRequest{ClientId: "wordapi", ClientSecret: "wrong", Token: user.Jwt},
+			secret:  "secret",
+			errCode: apierror.OAuthInvalidClient,
+		},
+	}
+	for _, tc := range testCases {
+		suite.T().Run(tc.name, func(t *testing.T) {
+			suite.expectClient(tc.secret)
+			if tc.beforeTest != nil {
+				tc.beforeTest()
+			}
+			introspection, err := suite.service.IntrospectToken(context.TODO(), &tc.request)
+			if tc.errCode != "" {
+				suite.Equal(tc.errCode, err.(*apierror.OAuthError).ErrorCode)
+				return
+			}
+			suite.Nil(err)
+			if tc.expected.Active && tc.expected.TokenType == "Bearer" {
+				suite.NotEmpty(introspection.Jti)
+				tc.expected.Jti, tc.expected.Exp, tc.expected.Iat, tc.expected.Nbf = introspection.Jti, introspection.Exp, introspection.Iat, introspection.Iat
+			}
+			suite.Equal(tc.expected, introspection)
+		})
+	}
+}
+
+func (suite *OAuthServiceSuite) TestRevokeToken() {
+	user := &models.User{UserId: uuid.New()}
+	suite.Require().Nil(suite.service.generateScopedToken(context.TODO(), user, &models.OAuthClient{ClientId: "wordapi"}, "openid"))
+
+	suite.expectClient("")
+	suite.repository.On("DeleteOAuthRefreshToken", mock.Anything, &models.OAuthRefreshToken{TokenHash: hashToken("refresh"), ClientId: "wordapi"}, mock.Anything).Run(func(args mock.Arguments) {
+		*args.Get(2).(*bool) = true
+	}).Return(nil).Once()
+	suite.Nil(suite.service.RevokeToken(context.TODO(), &models.RevocationRequest{ClientId: "wordapi", Token: "refresh"}))
+
+	suite.expectClient("")
+	suite.repository.On("IfTokenRevoked", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+	suite.repository.On("AddRevokedToken", mock.Anything, mock.MatchedBy(func(token *models.RevokedToken) bool {
+		return token.Jti != "" && token.ExpirationTime.After(time.Now())
+	}), mock.Anything).Return(nil).Once()
+	suite.Nil(suite.service.RevokeToken(context.TODO(), &models.RevocationRequest{ClientId: "wordapi", Token: user.Jwt, TokenTypeHint: TokenTypeHintAccessToken}))
+
+	suite.expectClient("")
+	suite.repository.On("DeleteOAuthRefreshToken", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+	suite.Nil(suite.service.RevokeToken(context.TODO(), &models.RevocationRequest{ClientId: "wordapi", Token: "unknown"}))
+}
+
+func (suite *OAuthServiceSuite) TestRevokeTokenOfOtherClient() {
+	session := &models.User{UserId: uuid.New()}
+	suite.Require().Nil(suite.service.generateToken(context.TODO(), session))
+	other := &models.User{UserId: uuid.New()}
+	suite.Require().Nil(suite.service.generateScopedToken(context.TODO(), other, &models.OAuthClient{ClientId: "cli"}, "openid"))
+	// neither token is denylisted, AddRevokedToken isn't expected
+	for _, token := range []string{session.Jwt, other.Jwt} {
+		suite.expectClient("")
+		suite.repository.On("IfTokenRevoked", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+		suite.Nil(suite.service.RevokeToken(context.TODO(), &models.RevocationRequest{ClientId: "wordapi", Token: token, TokenTypeHint: TokenTypeHintAccessToken}))
+	}
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"math/big"
+	"os"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/golang-jwt/jwt"
+)
+
+type signingKey struct {
+	key *rsa.PrivateKey
+	kid string
+}
+
+// idTokenKey loads the RSA key used to sign id tokens, without OIDC_PRIVATE_KEY_LOCATION an ephemeral key is generated,
+// which is fine for development but invalidates issued id tokens on every restart.
+func (service *UserService) idTokenKey() (*signingKey, error) {
+	service.keyOnce.Do(func() {
+		var key *rsa.PrivateKey
+		if service.config.OidcPrivateKeyLocation != "" {
+			pemBytes, err := os.ReadFile(service.config.OidcPrivateKeyLocation)
+			if err != nil {
+				service.keyErr = err
+				return
+			}
+			if key, service.keyErr = jwt.ParseRSAPrivateKeyFromPEM(pemBytes); service.keyErr != nil {
+				return
+			}
+		} else {
+			if key, service.keyErr = rsa.GenerateKey(rand.Reader, 2048); service.keyErr != nil {
+				return
+			}
+		}
+		thumbprint := sha256.Sum256(key.PublicKey.N.Bytes())
+		service.signingKey = &signingKey{
+			key: key,
+			kid: base64.RawURLEncoding.EncodeToString(thumbprint[:12]),
+		}
+	})
+	return service.signingKey, service.keyErr
+}
+
+func (service *UserService) Jwks(ctx context.Context) (*models.Jwks, error) {
+	signingKey, err := service.idTokenKey()
+	if err != nil {
+		return nil, err
+	}
+	return &models.Jwks{
+		Keys: []models.Jwk{
+			{
+				Kty: "RSA",
+				Use: "sig",
+				Alg: jwt.SigningMethodRS256.Alg(),
+				Kid: signingKey.kid,
+				N:   base64.RawURLEncoding.EncodeToString(signingKey.key.PublicKey.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(signingKey.key.PublicKey.E)).Bytes()),
+			},
+		},
+	}, nil
+}
+
+func (service *UserService) signIdToken(claims *models.IdTokenClaims) (string, error) {
+	signingKey, err := service.idTokenKey()
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = signingKey.kid
+	return token.SignedString(signingKey.key)
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"html"
+	"time"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/dchest/uniuri"
+	"github.com/rs/zerolog"
+)
+
+const secureAccountTtl = 7 * 24 * time.Hour
+
+// notifyNewDevice remembers the device of the request the user signed in with and emails them when it is one they
+// haven't signed in from before. Calls made outside of a request have no device and are skipped. The notice is best
+// effort, the session is written by then and failures are logged instead of failing the sign in.
+func (service *UserService) notifyNewDevice(ctx context.Context, user *models.User) {
+	if err := service.rememberDevice(ctx, user); err != nil {
+		zerolog.Ctx(ctx).Err(err).Str("user_id", user.UserId.String()).Msg("new device notice not sent")
+	}
+}
+
+func (service *UserService) rememberDevice(ctx context.Context, user *models.User) error {
+	meta := models.RequestMetaFromContext(ctx)
+	if meta.Ip == "" && meta.UserAgent == "" {
+		return nil
+	}
+	device := &models.KnownDevice{
+		UserId:      user.UserId,
+		Fingerprint: deviceFingerprint(meta.UserAgent, meta.Ip),
+		UserAgent:   meta.UserAgent,
+		Ip:          meta.Ip,
+		SeenAt:      time.Now().UTC(),
+	}
+	isNew := new(bool)
+	if err := service.repository.TouchKnownDevice(ctx, device, isNew); err != nil {
+		return err
+	}
+	if !*isNew {
+		return nil
+	}
+	code := &models.SecureAccountCode{
+		Code:           uniuri.NewLen(32),
+		UserId:         user.UserId,
+		Fingerprint:    device.Fingerprint,
+		ExpirationTime: device.SeenAt.Add(secureAccountTtl),
+	}
+	code.CodeHash = hashToken(code.Code)
+	if err := service.repository.AddSecureAccountCode(ctx, code); err != nil {
+		return err
+	}
+	return service.sendNewDeviceNotice(user, device, code)
+}
+
+// SecureAccount is the "secure my account" link of a new device email, it signs the user out everywhere and forgets
+// the device so signing in from it notifies the user again.
+func (service *UserService) SecureAccount(ctx context.Context, code *models.SecureAccountCode) error {
+	code.CodeHash = hashToken(code.Code)
+	if err := service.repository.ConsumeSecureAccountCode(ctx, code); err != nil {
+		return err
+	}
+	if err := service.repository.RevokeSessions(ctx, &models.User{UserId: code.UserId}); err != nil {
+		return err
+	}
+	return service.repository.ForgetKnownDevice(ctx, &models.KnownDevice{UserId: code.UserId, Fingerprint: code.Fingerprint})
+}
+
+func deviceFingerprint(userAgent string, ip string) string {
+	return hashToken(userAgent + "\n" + ip)
+}
+
+// sendNewDeviceNotice escapes the user agent, it comes from the client and the template isn't escaped.
+func (service *UserService) sendNewDeviceNotice(user *models.User, device *models.KnownDevice, code *models.SecureAccountCode) error {
+	userAgent := device.UserAgent
+	if userAgent == "" {
+		userAgent = "Unknown device"
+	}
+	return service.sendTemplate(user.Email, "New sign in to WordDict", service.config.NewDeviceTemplateLocation, map[string]string{
+		"Device":           html.EscapeString(userAgent),
+		"Ip":               html.EscapeString(device.Ip),
+		"Time":             device.SeenAt.Truncate(time.Minute).Format("2 January 2006, 15:04 MST"),
+		"SecureAccountUrl": service.config.SpaUrl + "/secure-account/" + code.Code,
+	})
+}
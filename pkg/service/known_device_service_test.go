@@ -0,0 +1,107 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	config "github.com/Kin-dza-dzaa/userApi/configs"
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/Kin-dza-dzaa/userApi/pkg/mocks"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestSignInNewDevice(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("123456789"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	userId := uuid.New()
+	ctx := models.WithRequestMeta(context.TODO(), &models.RequestMeta{Ip: "203.0.113.7", UserAgent: "<script>Firefox</script>"})
+	for _, isNew := range []bool{false, true} {
+		repository := mocks.NewRepository(t)
+		mailer := mocks.NewMailer(t)
+		service := NewUserService(repository, &config.Config{SpaUrl: "http://spa", NewDeviceTemplateLocation: "./../../internal/templates/new_device_template.html"})
+		service.mailer = mailer
+		allowNoRoles(repository)
+		repository.On("GetSignInUser", mock.Anything, mock.Anything).Return(&models.User{UserId: userId, Password: string(hash), Status: models.UserStatusActive, ExpirationTime: time.Now().Add(time.Hour)}, nil).Once()
+		repository.On("TouchKnownDevice", mock.Anything, mock.MatchedBy(func(device *models.KnownDevice) bool {
+			return device.UserId == userId && device.Fingerprint == deviceFingerprint("<script>Firefox</script>", "203.0.113.7")
+		}), mock.Anything).Run(func(args mock.Arguments) {
+			*args.Get(2).(*bool) = isNew
+		}).Return(nil).Once()
+		if isNew {
+			var code string
+			repository.On("AddSecureAccountCode", mock.Anything, mock.MatchedBy(func(secure *models.SecureAccountCode) bool {
+				code = secure.Code
+				return secure.UserId == userId && secure.CodeHash == hashToken(secure.Code)
+			})).Return(nil).Once()
+			mailer.On("Send", "test@gmail.com", "New sign in to WordDict", mock.MatchedBy(func(body string) bool {
+				return strings.Contains(body, "http://spa/secure-account/"+code) && strings.Contains(body, "&lt;script&gt;Firefox") && strings.Contains(body, "203.0.113.7")
+			})).Return(nil).Once()
+		}
+		if err := service.SignInUser(ctx, &models.User{Email: "test@gmail.com", Password: "123456789"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestSignInNewDeviceMailFails(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("123456789"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	userId := uuid.New()
+	var logs bytes.Buffer
+	ctx := zerolog.New(&logs).WithContext(models.WithRequestMeta(context.TODO(), &models.RequestMeta{Ip: "203.0.113.7", UserAgent: "Firefox"}))
+	repository := mocks.NewRepository(t)
+	mailer := mocks.NewMailer(t)
+	service := NewUserService(repository, &config.Config{SpaUrl: "http://spa", NewDeviceTemplateLocation: "./../../internal/templates/new_device_template.html"})
+	service.mailer = mailer
+	allowNoRoles(repository)
+	repository.On("GetSignInUser", mock.Anything, mock.Anything).Return(&models.User{UserId: userId, Password: string(hash), Status: models.UserStatusActive, ExpirationTime: time.Now().Add(time.Hour)}, nil).Once()
+	repository.On("TouchKnownDevice", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		*args.Get(2).(*bool) = true
+	}).Return(nil).Once()
+	repository.On("AddSecureAccountCode", mock.Anything, mock.Anything).Return(nil).Once()
+	mailer.On("Send", "test@gmail.com", "New sign in to WordDict", mock.Anything).Return(errors.New("smtp: connection refused")).Once()
+	user := &models.User{Email: "test@gmail.com", Password: "123456789"}
+	if err := service.SignInUser(ctx, user); err != nil {
+		t.Fatalf("a failed notice must not fail the sign in, got %v", err)
+	}
+	if user.Jwt == "" {
+		t.Fatal("expected the tokens of the session")
+	}
+	if !strings.Contains(logs.String(), "smtp: connection refused") {
+		t.Fatalf("expected the mail error to be logged, got %q", logs.String())
+	}
+}
+
+func TestSecureAccount(t *testing.T) {
+	repository := mocks.NewRepository(t)
+	service := NewUserService(repository, &config.Config{})
+	userId := uuid.New()
+	repository.On("ConsumeSecureAccountCode", mock.Anything, mock.MatchedBy(func(code *models.SecureAccountCode) bool {
+		return code.CodeHash == hashToken("code")
+	})).Run(func(args mock.Arguments) {
+		code := args.Get(1).(*models.SecureAccountCode)
+		code.UserId, code.Fingerprint = userId, "fingerprint"
+	}).Return(nil).Once()
+	repository.On("RevokeSessions", mock.Anything, &models.User{UserId: userId}).Return(nil).Once()
+	repository.On("ForgetKnownDevice", mock.Anything, &models.KnownDevice{UserId: userId, Fingerprint: "fingerprint"}).Return(nil).Once()
+	if err := service.SecureAccount(context.TODO(), &models.SecureAccountCode{Code: "code"}); err != nil {
+		t.Fatal(err)
+	}
+
+	repository.On("ConsumeSecureAccountCode", mock.Anything, mock.Anything).Return(apierror.NewCatalogError(apierror.CodeSecureAccountInvalid, "")).Once()
+	if err := service.SecureAccount(context.TODO(), &models.SecureAccountCode{Code: "used"}); err.(*apierror.ErrorStruct).ErrorCode != apierror.CodeSecureAccountInvalid {
+		t.FailNow()
+	}
+}
@@ -0,0 +1,30 @@
+package service
+
+import (
+	config "github.com/Kin-dza-dzaa/userApi/configs"
+	"gopkg.in/gomail.v2"
+)
+
+type Mailer interface {
+	Send(to string, subject string, body string) error
+}
+
+type SmtpMailer struct {
+	config *config.Config
+}
+
+func (mailer *SmtpMailer) Send(to string, subject string, body string) error {
+	d := gomail.NewDialer("smtp.gmail.com", 465, mailer.config.SmtpUserName, mailer.config.SmtpPassword)
+	msg := gomail.NewMessage()
+	msg.SetHeader("From", mailer.config.SmtpUserName)
+	msg.SetHeader("To", to)
+	msg.SetHeader("Subject", subject)
+	msg.SetBody("text/html", body)
+	return d.DialAndSend(msg)
+}
+
+func NewSmtpMailer(config *config.Config) *SmtpMailer {
+	return &SmtpMailer{
+		config: config,
+	}
+}
@@ -0,0 +1,271 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/dchest/uniuri"
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+)
+
+const (
+	GrantTypeAuthorizationCode = "authorization_code"
+	GrantTypeRefreshToken      = "refresh_token"
+	GrantTypeClientCredentials = "client_credentials"
+
+	ScopeOpenId  = "openid"
+	ScopeProfile = "profile"
+	ScopeEmail   = "email"
+
+	authorizationCodeTtl = time.Minute * 10
+)
+
+var (
+	ErrRedirectUriMismatch        = errors.New("redirect_uri is not registered for this client")
+	ErrUnsupportedResponseType    = errors.New("only response_type=code is supported")
+	ErrCodeChallengeRequired      = errors.New("code_challenge with code_challenge_method=S256 is required")
+	ErrScopeNotAllowed            = errors.New("requested scope is not allowed for this client")
+	ErrClientAuthenticationFailed = errors.New("client authentication failed")
+	ErrAuthorizationCodeMismatch  = errors.New("authorization code was issued to another client or redirect_uri")
+	ErrCodeVerifierMismatch       = errors.New("code_verifier doesn't match code_challenge")
+	ErrUnsupportedGrantType       = errors.New("grant type is not supported")
+)
+
+// ValidateAuthorizationRequest checks client and redirect_uri first, errors about them must not be redirected,
+// all the other errors can be sent back to the client's redirect_uri. The client needs authorization_code in grant_types.
+func (service *UserService) ValidateAuthorizationRequest(ctx context.Context, request *models.AuthorizationRequest) (*models.OAuthClient, error) {
+	client := &models.OAuthClient{ClientId: request.ClientId}
+	if err := service.repository.GetClient(ctx, client); err != nil {
+		return nil, err
+	}
+	if !contains(client.RedirectUris, request.RedirectUri) {
+		return nil, apierror.NewOAuthError(apierror.OAuthInvalidRequest, ErrRedirectUriMismatch.Error())
+	}
+	if request.ResponseType != "code" {
+		return client, apierror.NewOAuthError(apierror.OAuthUnsupportedResponseType, ErrUnsupportedResponseType.Error())
+	}
+	if !contains(client.GrantTypes, GrantTypeAuthorizationCode) {
+		return client, apierror.NewOAuthError(apierror.OAuthUnauthorizedClient, ErrGrantTypeNotAllowed.Error())
+	}
+	if request.CodeChallenge == "" || request.CodeChallengeMethod != "S256" {
+		return client, apierror.NewOAuthError(apierror.OAuthInvalidRequest, ErrCodeChallengeRequired.Error())
+	}
+	for _, scope := range strings.Fields(request.Scope) {
+		if !contains(client.Scopes, scope) {
+			return client, apierror.NewOAuthError(apierror.OAuthInvalidScope, ErrScopeNotAllowed.Error())
+		}
+	}
+	return client, nil
+}
+
+func (service *UserService) HasConsent(ctx context.Context, consent *models.Consent) (bool, error) {
+	result := new(bool)
+	if err := service.repository.IfConsentExists(ctx, consent, result); err != nil {
+		return false, err
+	}
+	return *result, nil
+}
+
+func (service *UserService) GrantConsent(ctx context.Context, consent *models.Consent) error {
+	consent.GrantedAt = time.Now().UTC()
+	return service.repository.AddConsent(ctx, consent)
+}
+
+// IssueAuthorizationCode stores a single-use code, only its hash is kept in the database.
+func (service *UserService) IssueAuthorizationCode(ctx context.Context, code *models.AuthorizationCode) error {
+	code.Code = uniuri.NewLen(32)
+	code.CodeHash = hashToken(code.Code)
+	code.ExpirationTime = time.Now().UTC().Add(authorizationCodeTtl)
+	return service.repository.AddAuthorizationCode(ctx, code)
+}
+
+// Token implements the token endpoint, request.ClientId and request.ClientSecret must already be taken from basic auth or the form.
+func (service *UserService) Token(ctx context.Context, request *models.TokenRequest) (*models.TokenResponse, error) {
+	switch request.GrantType {
+	case GrantTypeAuthorizationCode:
+		return service.exchangeAuthorizationCode(ctx, request)
+	case GrantTypeRefreshToken:
+		return service.refreshOAuthToken(ctx, request)
+	case GrantTypeClientCredentials:
+		return service.clientCredentials(ctx, request)
+	case GrantTypeDeviceCode:
+		return service.exchangeDeviceCode(ctx, request)
+	default:
+		return nil, apierror.NewOAuthError(apierror.OAuthUnsupportedGrantType, ErrUnsupportedGrantType.Error())
+	}
+}
+
+// GetUserInfo takes a token issued to any client, whatever its audience.
+func (service *UserService) GetUserInfo(ctx context.Context, accessToken string) (*models.UserInfo, error) {
+	claims, err := service.parseAccessToken(ctx, accessToken, false)
+	if err != nil {
+		return nil, apierror.NewOAuthError(apierror.OAuthInvalidToken, ErrAccessTokenInvalid.Error())
+	}
+	user := new(models.User)
+	if user.UserId, err = uuid.Parse(claims.UserId); err != nil {
+		return nil, apierror.NewOAuthError(apierror.OAuthInvalidToken, ErrAccessTokenInvalid.Error())
+	}
+	if err := service.repository.GetUserById(ctx, user); err != nil {
+		return nil, err
+	}
+	verified := user.EmailVerified()
+	return &models.UserInfo{
+		Sub:               user.UserId.String(),
+		PreferredUsername: user.UserName,
+		Email:             user.Email,
+		EmailVerified:     &verified,
+	}, nil
+}
+
+func (service *UserService) exchangeAuthorizationCode(ctx context.Context, request *models.TokenRequest) (*models.TokenResponse, error) {
+	client, err := service.authenticateClient(ctx, request.ClientId, request.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+	if !contains(client.GrantTypes, GrantTypeAuthorizationCode) {
+		return nil, apierror.NewOAuthError(apierror.OAuthUnauthorizedClient, ErrGrantTypeNotAllowed.Error())
+	}
+	code := &models.AuthorizationCode{CodeHash: hashToken(request.Code)}
+	if err := service.repository.ConsumeAuthorizationCode(ctx, code); err != nil {
+		return nil, err
+	}
+	if code.ClientId != client.ClientId || code.RedirectUri != request.RedirectUri {
+		return nil, apierror.NewOAuthError(apierror.OAuthInvalidGrant, ErrAuthorizationCodeMismatch.Error())
+	}
+	if !verifyCodeChallenge(request.CodeVerifier, code.CodeChallenge) {
+		return nil, apierror.NewOAuthError(apierror.OAuthInvalidGrant, ErrCodeVerifierMismatch.Error())
+	}
+	return service.issueOAuthTokens(ctx, client, code.UserId, code.Scope, code.Nonce, code.AuthTime, time.Now().UTC())
+}
+
+func (service *UserService) refreshOAuthToken(ctx context.Context, request *models.TokenRequest) (*models.TokenResponse, error) {
+	client, err := service.authenticateClient(ctx, request.ClientId, request.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+	// checked before the token is consumed, a refused client doesn't burn it
+	if !contains(client.GrantTypes, GrantTypeRefreshToken) {
+		return nil, apierror.NewOAuthError(apierror.OAuthUnauthorizedClient, ErrGrantTypeNotAllowed.Error())
+	}
+	token := &models.OAuthRefreshToken{TokenHash: hashToken(request.RefreshToken), ClientId: client.ClientId}
+	if err := service.repository.ConsumeOAuthRefreshToken(ctx, token); err != nil {
+		return nil, err
+	}
+	return service.issueOAuthTokens(ctx, client, token.UserId, token.Scope, "", token.AuthTime, token.GrantedAt)
+}
+
+// issueOAuthTokens issues an access and a refresh token, the refresh token lasts RefreshTokenTtl but never past
+// SessionMaxLifetime from grantedAt, so rotating it doesn't keep a grant alive forever.
+func (service *UserService) issueOAuthTokens(ctx context.Context, client *models.OAuthClient, userId uuid.UUID, scope string, nonce string, authTime time.Time, grantedAt time.Time) (*models.TokenResponse, error) {
+	user := &models.User{UserId: userId}
+	if err := service.repository.GetUserById(ctx, user); err != nil {
+		return nil, err
+	}
+	if user.Status != models.UserStatusActive {
+		return nil, apierror.NewOAuthError(apierror.OAuthInvalidGrant, ErrAccountInactive.Error())
+	}
+	if err := service.generateScopedToken(ctx, user, client, scope); err != nil {
+		return nil, err
+	}
+	refreshToken := &models.OAuthRefreshToken{
+		Token:          uniuri.NewLen(64),
+		ClientId:       client.ClientId,
+		UserId:         user.UserId,
+		Scope:          scope,
+		AuthTime:       authTime,
+		GrantedAt:      grantedAt,
+		ExpirationTime: service.sessionExpiration(grantedAt, true, time.Now().UTC()),
+	}
+	refreshToken.TokenHash = hashToken(refreshToken.Token)
+	if err := service.repository.AddOAuthRefreshToken(ctx, refreshToken); err != nil {
+		return nil, err
+	}
+	response := &models.TokenResponse{
+		AccessToken:  user.Jwt,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(service.config.AccessTokenTtl().Seconds()),
+		RefreshToken: refreshToken.Token,
+		Scope:        scope,
+	}
+	scopes := strings.Fields(scope)
+	if contains(scopes, ScopeOpenId) {
+		now := time.Now().UTC()
+		claims := &models.IdTokenClaims{
+			Nonce: nonce,
+			StandardClaims: jwt.StandardClaims{
+				Issuer:    service.config.Issuer,
+				Subject:   user.UserId.String(),
+				Audience:  client.ClientId,
+				IssuedAt:  now.Unix(),
+				ExpiresAt: now.Add(service.config.AccessTokenTtl()).Unix(),
+			},
+		}
+		if !authTime.IsZero() {
+			claims.AuthTime = authTime.Unix()
+		}
+		if contains(scopes, ScopeEmail) {
+			claims.Email = user.Email
+			verified := user.EmailVerified()
+			claims.EmailVerified = &verified
+		}
+		if contains(scopes, ScopeProfile) {
+			claims.PreferredUsername = user.UserName
+		}
+		idToken, err := service.signIdToken(claims)
+		if err != nil {
+			return nil, err
+		}
+		response.IdToken = idToken
+	}
+	return response, nil
+}
+
+// authenticateClient checks the secret of confidential clients, public clients must not send one.
+func (service *UserService) authenticateClient(ctx context.Context, clientId string, clientSecret string) (*models.OAuthClient, error) {
+	client := &models.OAuthClient{ClientId: clientId}
+	if err := service.repository.GetClient(ctx, client); err != nil {
+		return nil, err
+	}
+	if client.Public() {
+		if clientSecret != "" {
+			return nil, apierror.NewOAuthError(apierror.OAuthInvalidClient, ErrClientAuthenticationFailed.Error())
+		}
+		return client, nil
+	}
+	if subtle.ConstantTimeCompare([]byte(hashToken(clientSecret)), []byte(client.SecretHash)) != 1 {
+		return nil, apierror.NewOAuthError(apierror.OAuthInvalidClient, ErrClientAuthenticationFailed.Error())
+	}
+	return client, nil
+}
+
+func verifyCodeChallenge(verifier string, challenge string) bool {
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	return subtle.ConstantTimeCompare([]byte(base64.RawURLEncoding.EncodeToString(sum[:])), []byte(challenge)) == 1
+}
+
+// hashToken is used for high-entropy secrets (codes, refresh tokens, client secrets) that don't need bcrypt.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
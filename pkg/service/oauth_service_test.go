@@ -0,0 +1,293 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	config "github.com/Kin-dza-dzaa/userApi/configs"
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/Kin-dza-dzaa/userApi/pkg/mocks"
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	testVerifier = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	testRedirect = "http://localhost:3000/callback"
+)
+
+type OAuthServiceSuite struct {
+	suite.Suite
+	service    *UserService
+	repository *mocks.Repository
+}
+
+func (suite *OAuthServiceSuite) SetupTest() {
+	logger := zerolog.New(nil).With().Timestamp().Caller().Logger()
+	config, err := config.ReadConfig(&logger)
+	if err != nil {
+		suite.FailNow(err.Error())
+	}
+	suite.repository = mocks.NewRepository(suite.T())
+	allowNoRoles(suite.repository)
+	suite.service = NewUserService(suite.repository, config)
+}
+
+// expectClient registers a client with the default grant_types of oauth_clients.
+func (suite *OAuthServiceSuite) expectClient(secret string) {
+	suite.expectClientWithGrants(secret, []string{GrantTypeAuthorizationCode, GrantTypeRefreshToken})
+}
+
+func (suite *OAuthServiceSuite) expectClientWithGrants(secret string, grantTypes []string) {
+	suite.repository.On("GetClient", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		client := args.Get(1).(*models.OAuthClient)
+		client.Name = "wordApi"
+		client.RedirectUris = []string{testRedirect}
+		client.Scopes = []string{ScopeOpenId, ScopeEmail, ScopeProfile}
+		client.GrantTypes = grantTypes
+		if secret != "" {
+			client.SecretHash = hashToken(secret)
+		}
+	}).Return(nil).Once()
+}
+
+func (suite *OAuthServiceSuite) TestValidateAuthorizationRequest() {
+	testCases := []struct {
+		name       string
+		request    models.AuthorizationRequest
+		grantTypes []string
+		withClient bool
+		errCode    string
+	}{
+		{
+			name:       "valid",
+			request:    models.AuthorizationRequest{ResponseType: "code", RedirectUri: testRedirect, Scope: "openid email", CodeChallenge: "challenge", CodeChallengeMethod: "S256"},
+			withClient: true,
+		},
+		{
+			name:    "unregistered_redirect_uri",
+			request: models.AuthorizationRequest{ResponseType: "code", RedirectUri: "http://evil.com", CodeChallenge: "challenge", CodeChallengeMethod: "S256"},
+			errCode: apierror.OAuthInvalidRequest,
+		},
+		{
+			name:       "missing_pkce",
+			request:    models.AuthorizationRequest{ResponseType: "code", RedirectUri: testRedirect, Scope: "openid"},
+			withClient: true,
+			errCode:    apierror.OAuthInvalidRequest,
+		},
+		{
+			name:       "plain_pkce",
+			request:    models.AuthorizationRequest{ResponseType: "code", RedirectUri: testRedirect, CodeChallenge: "challenge", CodeChallengeMethod: "plain"},
+			withClient: true,
+			errCode:    apierror.OAuthInvalidRequest,
+		},
+		{
+			name:       "scope_not_allowed",
+			request:    models.AuthorizationRequest{ResponseType: "code", RedirectUri: testRedirect, Scope: "openid admin", CodeChallenge: "challenge", CodeChallengeMethod: "S256"},
+			withClient: true,
+			errCode:    apierror.OAuthInvalidScope,
+		},
+		{
+			name:       "client_credentials_only",
+			request:    models.AuthorizationRequest{ResponseType: "code", RedirectUri: testRedirect, Scope: "openid", CodeChallenge: "challenge", CodeChallengeMethod: "S256"},
+			grantTypes: []string{GrantTypeClientCredentials},
+			withClient: true,
+			errCode:    apierror.OAuthUnauthorizedClient,
+		},
+	}
+	for _, tc := range testCases {
+		suite.T().Run(tc.name, func(t *testing.T) {
+			if tc.grantTypes != nil {
+				suite.expectClientWithGrants("", tc.grantTypes)
+			} else {
+				suite.expectClient("")
+			}
+			client, err := suite.service.ValidateAuthorizationRequest(context.TODO(), &tc.request)
+			suite.Equal(tc.withClient, client != nil)
+			if tc.errCode == "" {
+				suite.Nil(err)
+				return
+			}
+			suite.Equal(tc.errCode, err.(*apierror.OAuthError).ErrorCode)
+		})
+	}
+}
+
+func (suite *OAuthServiceSuite) TestExchangeAuthorizationCode() {
+	userId := uuid.New()
+	challenge := sha256.Sum256([]byte(testVerifier))
+	testCases := []struct {
+		name       string
+		request    models.TokenRequest
+		grantTypes []string
+		consumed   bool
+		errCode    string
+	}{
+		{
+			name:     "valid",
+			request:  models.TokenRequest{GrantType: GrantTypeAuthorizationCode, ClientId: "wordapi", ClientSecret: "secret", Code: "code", RedirectUri: testRedirect, CodeVerifier: testVerifier},
+			consumed: true,
+		},
+		{
+			name:    "wrong_secret",
+			request: models.TokenRequest{GrantType: GrantTypeAuthorizationCode, ClientId: "wordapi", ClientSecret: "wrong", Code: "code", RedirectUri: testRedirect, CodeVerifier: testVerifier},
+			errCode: apierror.OAuthInvalidClient,
+		},
+		{
+			name:     "wrong_verifier",
+			request:  models.TokenRequest{GrantType: GrantTypeAuthorizationCode, ClientId: "wordapi", ClientSecret: "secret", Code: "code", RedirectUri: testRedirect, CodeVerifier: testVerifier[1:] + "A"},
+			consumed: true,
+			errCode:  apierror.OAuthInvalidGrant,
+		},
+		{
+			name:     "wrong_redirect_uri",
+			request:  models.TokenRequest{GrantType: GrantTypeAuthorizationCode, ClientId: "wordapi", ClientSecret: "secret", Code: "code", RedirectUri: "http://localhost:3000/other", CodeVerifier: testVerifier},
+			consumed: true,
+			errCode:  apierror.OAuthInvalidGrant,
+		},
+		{
+			name:       "client_credentials_only",
+			request:    models.TokenRequest{GrantType: GrantTypeAuthorizationCode, ClientId: "wordapi", ClientSecret: "secret", Code: "code", RedirectUri: testRedirect, CodeVerifier: testVerifier},
+			grantTypes: []string{GrantTypeClientCredentials},
+			errCode:    apierror.OAuthUnauthorizedClient,
+		},
+	}
+	for _, tc := range testCases {
+		suite.T().Run(tc.name, func(t *testing.T) {
+			if tc.grantTypes != nil {
+				suite.expectClientWithGrants("secret", tc.grantTypes)
+			} else {
+				suite.expectClient("secret")
+			}
+			if tc.consumed {
+				suite.repository.On("ConsumeAuthorizationCode", mock.Anything, mock.MatchedBy(func(code *models.AuthorizationCode) bool {
+					return code.CodeHash == hashToken("code")
+				})).Run(func(args mock.Arguments) {
+					code := args.Get(1).(*models.AuthorizationCode)
+					code.ClientId = "wordapi"
+					code.UserId = userId
+					code.RedirectUri = testRedirect
+					code.Scope = "openid email"
+					code.Nonce = "nonce"
+					code.CodeChallenge = base64.RawURLEncoding.EncodeToString(challenge[:])
+				}).Return(nil).Once()
+			}
+			if tc.errCode == "" {
+				suite.repository.On("GetUserById", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+					user := args.Get(1).(*models.User)
+					user.Email = "test@gmail.com"
+					user.Status = models.UserStatusActive
+				}).Return(nil).Once()
+				suite.repository.On("AddOAuthRefreshToken", mock.Anything, mock.Anything).Return(nil).Once()
+			}
+			response, err := suite.service.Token(context.TODO(), &tc.request)
+			if tc.errCode != "" {
+				suite.Equal(tc.errCode, err.(*apierror.OAuthError).ErrorCode)
+				return
+			}
+			suite.Nil(err)
+			suite.NotEmpty(response.AccessToken)
+			suite.NotEmpty(response.RefreshToken)
+			signingKey, err := suite.service.idTokenKey()
+			suite.Nil(err)
+			claims := new(models.IdTokenClaims)
+			_, err = jwt.ParseWithClaims(response.IdToken, claims, func(token *jwt.Token) (interface{}, error) {
+				return &signingKey.key.PublicKey, nil
+			})
+			suite.Nil(err)
+			suite.Equal(userId.String(), claims.Subject)
+			suite.Equal("wordapi", claims.Audience)
+			suite.Equal("nonce", claims.Nonce)
+			suite.Equal("test@gmail.com", claims.Email)
+		})
+	}
+}
+
+func (suite *OAuthServiceSuite) TestRefreshOAuthToken() {
+	userId := uuid.New()
+	now := time.Now().UTC()
+	maxLifetime := suite.service.config.SessionMaxLifetime()
+	testCases := []struct {
+		name       string
+		grantTypes []string
+		grantedAt  time.Time
+		expiration time.Time
+		consumed   bool
+		errCode    string
+	}{
+		{
+			name:       "valid",
+			grantTypes: []string{GrantTypeAuthorizationCode, GrantTypeRefreshToken},
+			grantedAt:  now.Add(-time.Hour),
+			expiration: now.Add(suite.service.config.RefreshTokenTtl()),
+			consumed:   true,
+		},
+		{
+			name:       "near_max_lifetime",
+			grantTypes: []string{GrantTypeAuthorizationCode, GrantTypeRefreshToken},
+			grantedAt:  now.Add(-maxLifetime + time.Hour),
+			expiration: now.Add(time.Hour),
+			consumed:   true,
+		},
+		{
+			name:       "client_credentials_only",
+			grantTypes: []string{GrantTypeClientCredentials},
+			errCode:    apierror.OAuthUnauthorizedClient,
+		},
+		{
+			name:       "authorization_code_only",
+			grantTypes: []string{GrantTypeAuthorizationCode},
+			errCode:    apierror.OAuthUnauthorizedClient,
+		},
+	}
+	for _, tc := range testCases {
+		suite.T().Run(tc.name, func(t *testing.T) {
+			suite.expectClientWithGrants("secret", tc.grantTypes)
+			if tc.consumed {
+				suite.repository.On("ConsumeOAuthRefreshToken", mock.Anything, mock.MatchedBy(func(token *models.OAuthRefreshToken) bool {
+					return token.TokenHash == hashToken("refresh") && token.ClientId == "wordapi"
+				})).Run(func(args mock.Arguments) {
+					token := args.Get(1).(*models.OAuthRefreshToken)
+					token.UserId, token.Scope, token.GrantedAt = userId, "email", tc.grantedAt
+				}).Return(nil).Once()
+				suite.repository.On("GetUserById", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+					args.Get(1).(*models.User).Status = models.UserStatusActive
+				}).Return(nil).Once()
+				suite.repository.On("AddOAuthRefreshToken", mock.Anything, mock.MatchedBy(func(token *models.OAuthRefreshToken) bool {
+					return token.GrantedAt.Equal(tc.grantedAt) && token.ExpirationTime.After(tc.expiration.Add(-time.Minute)) && token.ExpirationTime.Before(tc.expiration.Add(time.Minute))
+				})).Return(nil).Once()
+			}
+			response, err := suite.service.Token(context.TODO(), &models.TokenRequest{GrantType: GrantTypeRefreshToken, ClientId: "wordapi", ClientSecret: "secret", RefreshToken: "refresh"})
+			if tc.errCode != "" {
+				suite.Equal(tc.errCode, err.(*apierror.OAuthError).ErrorCode)
+				return
+			}
+			suite.Nil(err)
+			suite.NotEmpty(response.RefreshToken)
+		})
+	}
+}
+
+func (suite *OAuthServiceSuite) TestUnsupportedGrantType() {
+	_, err := suite.service.Token(context.TODO(), &models.TokenRequest{GrantType: "password"})
+	suite.Equal(apierror.OAuthUnsupportedGrantType, err.(*apierror.OAuthError).ErrorCode)
+}
+
+func (suite *OAuthServiceSuite) TestJwks() {
+	jwks, err := suite.service.Jwks(context.TODO())
+	suite.Nil(err)
+	suite.Len(jwks.Keys, 1)
+	suite.Equal("RS256", jwks.Keys[0].Alg)
+	suite.Equal("AQAB", jwks.Keys[0].E)
+}
+
+func TestOAuthService(t *testing.T) {
+	suite.Run(t, new(OAuthServiceSuite))
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+)
+
+func (service *UserService) GetProfile(ctx context.Context, user *models.User) (*models.Profile, error) {
+	profile := &models.Profile{UserId: user.UserId}
+	if err := service.repository.GetProfile(ctx, profile); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// UpdateProfile returns the profile after the update.
+func (service *UserService) UpdateProfile(ctx context.Context, update *models.ProfileUpdate) (*models.Profile, error) {
+	if err := service.repository.UpdateProfile(ctx, update); err != nil {
+		return nil, err
+	}
+	return service.GetProfile(ctx, &models.User{UserId: update.UserId})
+}
+
+// UserNameAvailable tells if nobody has a user name with the same canonical form, the name itself is validated by the caller.
+func (service *UserService) UserNameAvailable(ctx context.Context, user *models.User) (bool, error) {
+	exists := new(bool)
+	if err := service.repository.IfUserNameExists(ctx, user, exists); err != nil {
+		return false, err
+	}
+	return !*exists, nil
+}
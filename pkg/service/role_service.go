@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+)
+
+func (service *UserService) GetRoles(ctx context.Context) ([]models.Role, error) {
+	return service.repository.GetRoles(ctx)
+}
+
+// PutRole creates or replaces the role, the permissions must already exist.
+func (service *UserService) PutRole(ctx context.Context, role *models.Role) error {
+	role.CreatedAt = time.Now().UTC()
+	return service.repository.PutRole(ctx, role)
+}
+
+func (service *UserService) DeleteRole(ctx context.Context, role *models.Role) error {
+	return service.repository.DeleteRole(ctx, role)
+}
+
+func (service *UserService) GetPermissions(ctx context.Context) ([]models.Permission, error) {
+	return service.repository.GetPermissions(ctx)
+}
+
+func (service *UserService) PutPermission(ctx context.Context, permission *models.Permission) error {
+	return service.repository.PutPermission(ctx, permission)
+}
+
+func (service *UserService) DeletePermission(ctx context.Context, permission *models.Permission) error {
+	return service.repository.DeletePermission(ctx, permission)
+}
+
+// GetUserRoles fills user.Roles and user.Permissions, tokens issued before a change keep the old roles until they expire.
+func (service *UserService) GetUserRoles(ctx context.Context, user *models.User) error {
+	if err := service.repository.GetUserById(ctx, user); err != nil {
+		return err
+	}
+	return service.repository.GetUserAccess(ctx, user)
+}
+
+func (service *UserService) GrantRole(ctx context.Context, userRole *models.UserRole) error {
+	userRole.GrantedAt = time.Now().UTC()
+	return service.repository.AddUserRole(ctx, userRole)
+}
+
+func (service *UserService) RevokeRole(ctx context.Context, userRole *models.UserRole) error {
+	return service.repository.DeleteUserRole(ctx, userRole)
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	config "github.com/Kin-dza-dzaa/userApi/configs"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/Kin-dza-dzaa/userApi/pkg/mocks"
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+// allowNoRoles lets tests that don't care about roles issue tokens, users have no roles and keep every scope.
+func allowNoRoles(repository *mocks.Repository) {
+	repository.On("GetUserAccess", mock.Anything, mock.Anything).Return(nil).Maybe()
+	repository.On("GrantableScopes", mock.Anything, mock.Anything, mock.Anything).Return(func(ctx context.Context, user *models.User, scopes []string) []string {
+		return scopes
+	}, nil).Maybe()
+}
+
+func parseClaims(t *testing.T, service *UserService, token string) *models.MyJwtClaims {
+	claims := new(models.MyJwtClaims)
+	if _, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte(service.config.JWTString), nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return claims
+}
+
+func TestSessionTokenCarriesRoles(t *testing.T) {
+	repository := mocks.NewRepository(t)
+	service := NewUserService(repository, &config.Config{JWTString: "secret"})
+	user := &models.User{UserId: uuid.New()}
+	repository.On("GetUserAccess", mock.Anything, user).Run(func(args mock.Arguments) {
+		user := args.Get(1).(*models.User)
+		user.Roles, user.Permissions = []string{"admin"}, []string{models.PermissionRolesManage, "words:write"}
+	}).Return(nil).Once()
+	if err := service.generateToken(context.TODO(), user); err != nil {
+		t.Fatal(err)
+	}
+	claims := parseClaims(t, service, user.Jwt)
+	if !claims.HasRole("admin") || !claims.HasPermission(models.PermissionRolesManage) || !claims.HasPermission("words:write") || claims.HasPermission("words") {
+		t.Fatalf("unexpected claims %+v", claims)
+	}
+}
+
+func TestDelegatedTokenCarriesNoRoles(t *testing.T) {
+	repository := mocks.NewRepository(t)
+	service := NewUserService(repository, &config.Config{JWTString: "secret"})
+	user := &models.User{UserId: uuid.New()}
+	repository.On("GetUserAccess", mock.Anything, user).Run(func(args mock.Arguments) {
+		user := args.Get(1).(*models.User)
+		user.Roles, user.Permissions = []string{"admin"}, []string{models.PermissionRolesManage}
+	}).Return(nil).Once()
+	repository.On("GrantableScopes", mock.Anything, user, []string{"openid"}).Return([]string{"openid"}, nil).Once()
+	if err := service.generateScopedToken(context.TODO(), user, &models.OAuthClient{ClientId: "cli"}, "openid"); err != nil {
+		t.Fatal(err)
+	}
+	claims := parseClaims(t, service, user.Jwt)
+	if claims.HasRole("admin") || len(claims.Roles) != 0 || claims.ClientId != "cli" {
+		t.Fatalf("unexpected claims %+v", claims)
+	}
+}
+
+func TestScopedTokenDropsPermissionsOfOtherRoles(t *testing.T) {
+	repository := mocks.NewRepository(t)
+	service := NewUserService(repository, &config.Config{JWTString: "secret"})
+	user := &models.User{UserId: uuid.New()}
+	repository.On("GetUserAccess", mock.Anything, user).Return(nil).Once()
+	repository.On("GrantableScopes", mock.Anything, user, []string{"openid", models.PermissionRolesManage}).Return([]string{"openid"}, nil).Once()
+	if err := service.generateScopedToken(context.TODO(), user, nil, "openid roles:manage"); err != nil {
+		t.Fatal(err)
+	}
+	claims := parseClaims(t, service, user.Jwt)
+	if claims.Scope != "openid" || claims.HasPermission(models.PermissionRolesManage) || len(claims.Roles) != 0 {
+		t.Fatalf("unexpected claims %+v", claims)
+	}
+}
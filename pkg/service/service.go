@@ -5,6 +5,7 @@ import (
 	config "github.com/Kin-dza-dzaa/userApi/configs"
 	"github.com/Kin-dza-dzaa/userApi/internal/models"
 	"github.com/Kin-dza-dzaa/userApi/pkg/repositories"
+	"github.com/google/uuid"
 )
 
 type Service interface {
@@ -12,8 +13,60 @@ type Service interface {
 	SignInUser(context.Context, *models.User) error
 	VerifyUser(context.Context, *models.User) error
 	GetAccessToken(context.Context, *models.User) error
+	Reauthenticate(context.Context, *models.User) error
+	ParseAccessToken(context.Context, string) (*models.MyJwtClaims, error)
+	ValidateAuthorizationRequest(context.Context, *models.AuthorizationRequest) (*models.OAuthClient, error)
+	HasConsent(context.Context, *models.Consent) (bool, error)
+	GrantConsent(context.Context, *models.Consent) error
+	IssueAuthorizationCode(context.Context, *models.AuthorizationCode) error
+	Token(context.Context, *models.TokenRequest) (*models.TokenResponse, error)
+	GetUserInfo(context.Context, string) (*models.UserInfo, error)
+	Jwks(context.Context) (*models.Jwks, error)
+	IntrospectToken(context.Context, *models.IntrospectionRequest) (*models.Introspection, error)
+	RevokeToken(context.Context, *models.RevocationRequest) error
+	RevokeAccessToken(context.Context, string) error
+	CreatePersonalAccessToken(context.Context, *models.PersonalAccessToken) error
+	GetPersonalAccessTokens(context.Context, *models.User) ([]models.PersonalAccessToken, error)
+	DeletePersonalAccessToken(context.Context, *models.PersonalAccessToken) error
+	Authenticate(context.Context, string) (*models.MyJwtClaims, error)
+	AuthorizeDevice(context.Context, *models.DeviceAuthorizationRequest) (*models.DeviceAuthorizationResponse, error)
+	GetDevicePrompt(context.Context, string) (*models.DevicePrompt, error)
+	ApproveDevice(context.Context, *models.DeviceApproval) error
+	GetRoles(context.Context) ([]models.Role, error)
+	PutRole(context.Context, *models.Role) error
+	DeleteRole(context.Context, *models.Role) error
+	GetPermissions(context.Context) ([]models.Permission, error)
+	PutPermission(context.Context, *models.Permission) error
+	DeletePermission(context.Context, *models.Permission) error
+	GetUserRoles(context.Context, *models.User) error
+	GrantRole(context.Context, *models.UserRole) error
+	RevokeRole(context.Context, *models.UserRole) error
+	SearchUsers(context.Context, uuid.UUID, *models.UserFilter) (*models.UserPage, error)
+	GetAdminUser(context.Context, uuid.UUID, *models.AdminUser) error
+	AdminVerifyUser(context.Context, *models.AdminAction) error
+	SuspendUser(context.Context, *models.AdminAction) error
+	UnsuspendUser(context.Context, *models.AdminAction) error
+	LockUser(context.Context, *models.AdminAction) error
+	UnlockUser(context.Context, *models.AdminAction) error
+	ForceLogout(context.Context, *models.AdminAction) error
+	TriggerPasswordReset(context.Context, *models.AdminAction) error
+	AdminDeleteUser(context.Context, *models.AdminAction) error
+	ResetPassword(context.Context, *models.PasswordReset) error
+	SecureAccount(context.Context, *models.SecureAccountCode) error
+	GetProfile(context.Context, *models.User) (*models.Profile, error)
+	UpdateProfile(context.Context, *models.ProfileUpdate) (*models.Profile, error)
+	UserNameAvailable(context.Context, *models.User) (bool, error)
+	GetEmailDomainRules(context.Context) ([]models.EmailDomainRule, error)
+	PutEmailDomainRule(context.Context, *models.EmailDomainRule) error
+	DeleteEmailDomainRule(context.Context, *models.EmailDomainRule) error
+	NewChallenge(context.Context, string) (*models.Challenge, error)
+	VerifyChallenge(context.Context, *models.ChallengeResponse) error
+	BeginSocialLogin(context.Context, string) (*models.SocialLogin, error)
+	CompleteSocialLogin(context.Context, *models.SocialCallback) (*models.User, error)
+	GetUserActivity(context.Context, *models.User, *models.AuditFilter) (*models.AuditPage, error)
+	GetAuditEvents(context.Context, *models.AuditFilter) (*models.AuditPage, error)
 }
 
 func NewService(repository repository.Repository, config *config.Config) Service {
-	return NewUserService(repository, config)
+	return newAuditService(NewUserService(repository, config))
 }
\ No newline at end of file
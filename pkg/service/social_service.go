@@ -0,0 +1,451 @@
+package service
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	config "github.com/Kin-dza-dzaa/userApi/configs"
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/dto"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/dchest/uniuri"
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+)
+
+const (
+	SocialProviderOidc   = "oidc"
+	SocialProviderGithub = "github"
+
+	socialStateTtl = time.Minute * 10
+	// maxSocialResponse bounds what is read from a provider, discovery documents and JWKS are a few kilobytes.
+	maxSocialResponse = 1 << 20
+	// unusablePassword is stored for users created by social login, it isn't a bcrypt hash so no password matches it.
+	unusablePassword = "!"
+)
+
+var (
+	ErrSocialProviderUnknown = errors.New("social login provider is not configured")
+	ErrSocialStateInvalid    = errors.New("social login state expired or doesn't match")
+	ErrSocialExchangeFailed  = errors.New("provider rejected the authorization code")
+	ErrSocialIdTokenInvalid  = errors.New("id token of the provider is invalid")
+	ErrSocialEmailUnverified = errors.New("provider didn't return a verified email")
+	ErrSocialUserNameTaken   = errors.New("no free user name could be derived from the email")
+)
+
+var githubEndpoints = models.SocialEndpoints{
+	Issuer:                "https://github.com",
+	AuthorizationEndpoint: "https://github.com/login/oauth/authorize",
+	TokenEndpoint:         "https://github.com/login/oauth/access_token",
+	UserInfoEndpoint:      "https://api.github.com/user",
+}
+
+// socialCache keeps discovery documents by provider name and signing keys by jwks_uri and kid.
+type socialCache struct {
+	mutex     sync.Mutex
+	endpoints map[string]*models.SocialEndpoints
+	keys      map[string]*rsa.PublicKey
+}
+
+type socialTokenResponse struct {
+	models.TokenResponse
+	Error string `json:"error"`
+}
+
+// BeginSocialLogin builds the authorization url of the provider, the returned state token must be kept by the client
+// (the handler stores it in a cookie) and passed to CompleteSocialLogin.
+func (service *UserService) BeginSocialLogin(ctx context.Context, providerName string) (*models.SocialLogin, error) {
+	provider, ok := service.config.SocialProvider(providerName)
+	if !ok {
+		return nil, apierror.NewCatalogError(apierror.CodeSocialProviderUnknown, ErrSocialProviderUnknown.Error())
+	}
+	endpoints, err := service.socialEndpoints(ctx, provider)
+	if err != nil {
+		return nil, err
+	}
+	claims := &models.SocialStateClaims{
+		Provider: provider.Name,
+		State:    uniuri.NewLen(32),
+		Nonce:    uniuri.NewLen(32),
+		Verifier: uniuri.NewLen(64),
+		StandardClaims: jwt.StandardClaims{
+			ExpiresAt: time.Now().UTC().Add(socialStateTtl).Unix(),
+		},
+	}
+	stateToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(service.socialStateKey())
+	if err != nil {
+		return nil, err
+	}
+	authUrl, err := url.Parse(endpoints.AuthorizationEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	challenge := sha256.Sum256([]byte(claims.Verifier))
+	query := authUrl.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", provider.ClientId)
+	query.Set("redirect_uri", service.socialRedirectUri(provider))
+	query.Set("scope", strings.Join(socialScopes(provider), " "))
+	query.Set("state", claims.State)
+	query.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	query.Set("code_challenge_method", "S256")
+	if provider.Type != SocialProviderGithub {
+		query.Set("nonce", claims.Nonce)
+	}
+	authUrl.RawQuery = query.Encode()
+	return &models.SocialLogin{AuthUrl: authUrl.String(), StateToken: stateToken}, nil
+}
+
+// CompleteSocialLogin exchanges the code, finds or creates the user with the verified email of the provider
+// and starts a session the same way SignInUser does.
+func (service *UserService) CompleteSocialLogin(ctx context.Context, callback *models.SocialCallback) (*models.User, error) {
+	provider, ok := service.config.SocialProvider(callback.Provider)
+	if !ok {
+		return nil, apierror.NewCatalogError(apierror.CodeSocialProviderUnknown, ErrSocialProviderUnknown.Error())
+	}
+	claims := new(models.SocialStateClaims)
+	_, err := jwt.ParseWithClaims(callback.StateToken, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, ErrSocialStateInvalid
+		}
+		return service.socialStateKey(), nil
+	})
+	if err != nil || claims.Provider != provider.Name || subtle.ConstantTimeCompare([]byte(claims.State), []byte(callback.State)) != 1 {
+		return nil, apierror.NewCatalogError(apierror.CodeSocialLoginFailed, ErrSocialStateInvalid.Error())
+	}
+	endpoints, err := service.socialEndpoints(ctx, provider)
+	if err != nil {
+		return nil, err
+	}
+	token, err := service.exchangeSocialCode(ctx, provider, endpoints, callback.Code, claims.Verifier)
+	if err != nil {
+		return nil, err
+	}
+	var identity *models.Identity
+	if provider.Type == SocialProviderGithub {
+		identity, err = service.githubIdentity(ctx, endpoints, token.AccessToken)
+	} else {
+		identity, err = service.verifySocialIdToken(ctx, provider, endpoints, token.IdToken, claims.Nonce)
+	}
+	if err != nil {
+		return nil, err
+	}
+	identity.Provider = provider.Name
+	if !identity.EmailVerified || identity.Email == "" {
+		return nil, apierror.NewCatalogError(apierror.CodeSocialEmailUnverified, ErrSocialEmailUnverified.Error())
+	}
+	return service.linkIdentity(ctx, identity)
+}
+
+// linkIdentity signs in the user the identity is linked to, otherwise links it to the user with the same email
+// or creates a verified user. A pending sign up with that email is claimed, the provider has proven the email.
+func (service *UserService) linkIdentity(ctx context.Context, identity *models.Identity) (*models.User, error) {
+	linked := new(bool)
+	if err := service.repository.GetIdentity(ctx, identity, linked); err != nil {
+		return nil, err
+	}
+	user := &models.User{UserId: identity.UserId}
+	if *linked {
+		if err := service.repository.GetUserById(ctx, user); err != nil {
+			return nil, err
+		}
+		DbUser, err := service.repository.GetSignInUser(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+		return user, service.startSession(ctx, user, DbUser)
+	}
+
+	user.Email = service.normalizeEmail(identity.Email)
+	exists := new(bool)
+	if err := service.repository.GetUserByEmail(ctx, user, exists); err != nil {
+		return nil, err
+	}
+	DbUser := new(models.User)
+	switch {
+	case *exists && user.Status != models.UserStatusPending:
+		var err error
+		if DbUser, err = service.repository.GetSignInUser(ctx, user); err != nil {
+			return nil, err
+		}
+	case *exists:
+		// The password of a pending sign up is thrown away. Whoever registered it never proved they own the email,
+		// the provider just did. Keeping it would let someone pre-register a victim's email and sign in to the account
+		// once the victim verifies it through the provider. Pending users can't sign in, so no session used it, and
+		// the real owner can set a password through the password reset, which goes to the same email.
+		user.Password = unusablePassword
+		if err := service.repository.ClaimUnverifiedUser(ctx, user); err != nil {
+			return nil, err
+		}
+		DbUser.UserId, DbUser.Status = user.UserId, models.UserStatusActive
+	default:
+		if err := service.createSocialUser(ctx, user); err != nil {
+			return nil, err
+		}
+		DbUser.UserId, DbUser.Status = user.UserId, models.UserStatusActive
+	}
+	identity.UserId = user.UserId
+	identity.CreatedAt = time.Now().UTC()
+	if err := service.repository.AddIdentity(ctx, identity); err != nil {
+		return nil, err
+	}
+	return user, service.startSession(ctx, user, DbUser)
+}
+
+// createSocialUser adds a verified user without a usable password, the user name is derived from the email. It goes
+// through the validation of sign up and the canonical uniqueness check, a name that fails the validation is replaced by user_<digits>.
+func (service *UserService) createSocialUser(ctx context.Context, user *models.User) error {
+	user.UserId = uuid.New()
+	user.RegistrationTime = time.Now().UTC()
+	user.Password = unusablePassword
+	user.Status = models.UserStatusActive
+	for attempt := 0; ; attempt++ {
+		candidate, err := dto.UserNameDto{UserName: socialUserName(user.Email)}.IntoUser()
+		if err != nil {
+			candidate = &models.User{UserName: socialUserName("")}
+		}
+		available, err := service.UserNameAvailable(ctx, candidate)
+		if err != nil {
+			return err
+		}
+		if !available {
+			if attempt < 3 {
+				continue
+			}
+			return apierror.NewCatalogError(apierror.CodeUserNameTaken, ErrSocialUserNameTaken.Error())
+		}
+		user.UserName = candidate.UserName
+		err = service.repository.AddUser(ctx, user)
+		if Err, ok := err.(*apierror.ErrorStruct); ok && Err.ErrorCode == apierror.CodeUserNameTaken && attempt < 3 {
+			continue
+		}
+		return err
+	}
+}
+
+func (service *UserService) exchangeSocialCode(ctx context.Context, provider *config.SocialProvider, endpoints *models.SocialEndpoints, code string, verifier string) (*socialTokenResponse, error) {
+	form := url.Values{
+		"grant_type":    {GrantTypeAuthorizationCode},
+		"code":          {code},
+		"redirect_uri":  {service.socialRedirectUri(provider)},
+		"client_id":     {provider.ClientId},
+		"client_secret": {provider.ClientSecret},
+		"code_verifier": {verifier},
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoints.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-type", "application/x-www-form-urlencoded")
+	token := new(socialTokenResponse)
+	if err := service.doSocialRequest(request, token); err != nil && !errors.Is(err, errSocialStatus) {
+		return nil, err
+	}
+	if token.Error != "" || token.AccessToken == "" {
+		return nil, apierror.NewCatalogError(apierror.CodeSocialLoginFailed, ErrSocialExchangeFailed.Error())
+	}
+	return token, nil
+}
+
+func (service *UserService) verifySocialIdToken(ctx context.Context, provider *config.SocialProvider, endpoints *models.SocialEndpoints, idToken string, nonce string) (*models.Identity, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(idToken, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+			return nil, ErrSocialIdTokenInvalid
+		}
+		kid, _ := token.Header["kid"].(string)
+		return service.socialKey(ctx, endpoints.JwksUri, kid)
+	})
+	if err != nil || !claims.VerifyIssuer(endpoints.Issuer, true) || !claims.VerifyAudience(provider.ClientId, true) || !claims.VerifyExpiresAt(time.Now().Unix(), true) || claims["nonce"] != nonce {
+		return nil, apierror.NewCatalogError(apierror.CodeSocialLoginFailed, ErrSocialIdTokenInvalid.Error())
+	}
+	identity := new(models.Identity)
+	identity.Subject, _ = claims["sub"].(string)
+	identity.Email, _ = claims["email"].(string)
+	// some providers send email_verified as a string
+	switch verified := claims["email_verified"].(type) {
+	case bool:
+		identity.EmailVerified = verified
+	case string:
+		identity.EmailVerified = verified == "true"
+	}
+	if identity.Subject == "" {
+		return nil, apierror.NewCatalogError(apierror.CodeSocialLoginFailed, ErrSocialIdTokenInvalid.Error())
+	}
+	return identity, nil
+}
+
+// githubIdentity uses the GitHub api since GitHub doesn't issue id tokens, the email is the primary verified one.
+func (service *UserService) githubIdentity(ctx context.Context, endpoints *models.SocialEndpoints, accessToken string) (*models.Identity, error) {
+	var profile struct {
+		Id int64 `json:"id"`
+	}
+	if err := service.getSocialJson(ctx, endpoints.UserInfoEndpoint, accessToken, &profile); err != nil {
+		return nil, err
+	}
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := service.getSocialJson(ctx, endpoints.UserInfoEndpoint+"/emails", accessToken, &emails); err != nil {
+		return nil, err
+	}
+	identity := &models.Identity{Subject: strconv.FormatInt(profile.Id, 10)}
+	for _, email := range emails {
+		if email.Primary {
+			identity.Email, identity.EmailVerified = email.Email, email.Verified
+		}
+	}
+	return identity, nil
+}
+
+// socialEndpoints returns the configured endpoints of the provider, for OIDC providers the missing ones are discovered.
+func (service *UserService) socialEndpoints(ctx context.Context, provider *config.SocialProvider) (*models.SocialEndpoints, error) {
+	service.social.mutex.Lock()
+	defer service.social.mutex.Unlock()
+	if endpoints, ok := service.social.endpoints[provider.Name]; ok {
+		return endpoints, nil
+	}
+	endpoints := new(models.SocialEndpoints)
+	if provider.Type == SocialProviderGithub {
+		*endpoints = githubEndpoints
+	} else {
+		if err := service.getSocialJson(ctx, strings.TrimSuffix(provider.Issuer, "/")+"/.well-known/openid-configuration", "", endpoints); err != nil {
+			return nil, err
+		}
+		if endpoints.Issuer != provider.Issuer {
+			return nil, fmt.Errorf("issuer of %s discovery document is %q", provider.Name, endpoints.Issuer)
+		}
+	}
+	if provider.AuthorizationEndpoint != "" {
+		endpoints.AuthorizationEndpoint = provider.AuthorizationEndpoint
+	}
+	if provider.TokenEndpoint != "" {
+		endpoints.TokenEndpoint = provider.TokenEndpoint
+	}
+	if provider.UserInfoEndpoint != "" {
+		endpoints.UserInfoEndpoint = provider.UserInfoEndpoint
+	}
+	if service.social.endpoints == nil {
+		service.social.endpoints = make(map[string]*models.SocialEndpoints)
+	}
+	service.social.endpoints[provider.Name] = endpoints
+	return endpoints, nil
+}
+
+// socialKey returns the RSA key of a provider, the JWKS is fetched again when kid is unknown so key rotation is picked up.
+func (service *UserService) socialKey(ctx context.Context, jwksUri string, kid string) (*rsa.PublicKey, error) {
+	service.social.mutex.Lock()
+	defer service.social.mutex.Unlock()
+	if key, ok := service.social.keys[jwksUri+"#"+kid]; ok {
+		return key, nil
+	}
+	jwks := new(models.Jwks)
+	if err := service.getSocialJson(ctx, jwksUri, "", jwks); err != nil {
+		return nil, err
+	}
+	if service.social.keys == nil {
+		service.social.keys = make(map[string]*rsa.PublicKey)
+	}
+	for _, jwk := range jwks.Keys {
+		if jwk.Kty != "RSA" {
+			continue
+		}
+		n, errN := base64.RawURLEncoding.DecodeString(jwk.N)
+		e, errE := base64.RawURLEncoding.DecodeString(jwk.E)
+		if errN != nil || errE != nil {
+			continue
+		}
+		service.social.keys[jwksUri+"#"+jwk.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+	if key, ok := service.social.keys[jwksUri+"#"+kid]; ok {
+		return key, nil
+	}
+	return nil, ErrSocialIdTokenInvalid
+}
+
+var errSocialStatus = errors.New("provider responded with an error status")
+
+func (service *UserService) getSocialJson(ctx context.Context, location string, accessToken string, result interface{}) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return err
+	}
+	if accessToken != "" {
+		request.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+	return service.doSocialRequest(request, result)
+}
+
+// doSocialRequest decodes a json response into result, errSocialStatus is returned along with the decoded body on non 2xx statuses.
+func (service *UserService) doSocialRequest(request *http.Request, result interface{}) error {
+	request.Header.Set("Accept", "application/json")
+	response, err := service.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if err := json.NewDecoder(io.LimitReader(response.Body, maxSocialResponse)).Decode(result); err != nil {
+		return fmt.Errorf("%s: %w", request.URL.String(), err)
+	}
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("%s: %w", request.URL.String(), errSocialStatus)
+	}
+	return nil
+}
+
+// socialStateKey is derived from JWT_SECURE_STRING so a state token can never pass as an access token.
+func (service *UserService) socialStateKey() []byte {
+	return []byte(service.config.JWTString + ":social_state")
+}
+
+func (service *UserService) socialRedirectUri(provider *config.SocialProvider) string {
+	return service.config.Issuer + "/user/social/" + provider.Name + "/callback"
+}
+
+func socialScopes(provider *config.SocialProvider) []string {
+	if len(provider.Scopes) != 0 {
+		return provider.Scopes
+	}
+	if provider.Type == SocialProviderGithub {
+		return []string{"read:user", "user:email"}
+	}
+	return []string{ScopeOpenId, ScopeEmail, ScopeProfile}
+}
+
+// socialUserName keeps letters, digits and underscores of the local part of the email and adds a random suffix.
+func socialUserName(email string) string {
+	local := email
+	if at := strings.LastIndex(email, "@"); at != -1 {
+		local = email[:at]
+	}
+	name := strings.Map(func(r rune) rune {
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return -1
+	}, local)
+	name = strings.Trim(name, "_")
+	if len(name) > 24 {
+		name = name[:24]
+	}
+	if len(name) < 2 {
+		name = "user"
+	}
+	return name + "_" + uniuri.NewLenChars(6, []byte("0123456789"))
+}
@@ -0,0 +1,304 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	config "github.com/Kin-dza-dzaa/userApi/configs"
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/Kin-dza-dzaa/userApi/pkg/mocks"
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// mockIssuer is a minimal OIDC provider, it signs id tokens with the claims set by the test.
+type mockIssuer struct {
+	server    *httptest.Server
+	key       *rsa.PrivateKey
+	challenge string
+	claims    jwt.MapClaims
+}
+
+func newMockIssuer() *mockIssuer {
+	issuer := new(mockIssuer)
+	issuer.key, _ = rsa.GenerateKey(rand.Reader, 2048)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(models.SocialEndpoints{
+			Issuer:                issuer.server.URL,
+			AuthorizationEndpoint: issuer.server.URL + "/authorize",
+			TokenEndpoint:         issuer.server.URL + "/token",
+			JwksUri:               issuer.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(models.Jwks{Keys: []models.Jwk{{
+			Kty: "RSA",
+			Kid: "mock",
+			N:   base64.RawURLEncoding.EncodeToString(issuer.key.PublicKey.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(issuer.key.PublicKey.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		verifier := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		if r.PostForm.Get("code") != "code" || base64.RawURLEncoding.EncodeToString(verifier[:]) != issuer.challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, issuer.claims)
+		token.Header["kid"] = "mock"
+		idToken, _ := token.SignedString(issuer.key)
+		json.NewEncoder(w).Encode(map[string]string{"access_token": "access", "token_type": "Bearer", "id_token": idToken})
+	})
+	issuer.server = httptest.NewServer(mux)
+	return issuer
+}
+
+type SocialServiceSuite struct {
+	suite.Suite
+	service    *UserService
+	repository *mocks.Repository
+	issuer     *mockIssuer
+}
+
+func (suite *SocialServiceSuite) SetupSuite() {
+	suite.issuer = newMockIssuer()
+}
+
+func (suite *SocialServiceSuite) TearDownSuite() {
+	suite.issuer.server.Close()
+}
+
+func (suite *SocialServiceSuite) SetupTest() {
+	logger := zerolog.New(nil).With().Timestamp().Caller().Logger()
+	config, err := config.ReadConfig(&logger)
+	if err != nil {
+		suite.FailNow(err.Error())
+	}
+	config.SocialProviders = append(config.SocialProviders, configSocialProvider(suite.issuer.server.URL))
+	suite.repository = mocks.NewRepository(suite.T())
+	allowNoRoles(suite.repository)
+	suite.service = NewUserService(suite.repository, config)
+}
+
+func configSocialProvider(issuer string) config.SocialProvider {
+	return config.SocialProvider{Name: "mock", Issuer: issuer, ClientId: "userapi", ClientSecret: "secret"}
+}
+
+// begin starts a login and sets the claims the mock issuer puts into the id token.
+func (suite *SocialServiceSuite) begin(email string, emailVerified bool) *models.SocialCallback {
+	login, err := suite.service.BeginSocialLogin(context.TODO(), "mock")
+	suite.Require().Nil(err)
+	authUrl, err := url.Parse(login.AuthUrl)
+	suite.Require().Nil(err)
+	query := authUrl.Query()
+	suite.Equal(suite.issuer.server.URL+"/authorize", authUrl.Scheme+"://"+authUrl.Host+authUrl.Path)
+	suite.Equal(suite.service.config.Issuer+"/user/social/mock/callback", query.Get("redirect_uri"))
+	suite.issuer.challenge = query.Get("code_challenge")
+	suite.issuer.claims = jwt.MapClaims{
+		"iss":            suite.issuer.server.URL,
+		"aud":            []string{"userapi"},
+		"sub":            "42",
+		"exp":            time.Now().Add(time.Minute).Unix(),
+		"nonce":          query.Get("nonce"),
+		"email":          email,
+		"email_verified": emailVerified,
+	}
+	return &models.SocialCallback{Provider: "mock", Code: "code", State: query.Get("state"), StateToken: login.StateToken}
+}
+
+func (suite *SocialServiceSuite) TestUnknownProvider() {
+	_, err := suite.service.BeginSocialLogin(context.TODO(), "unknown")
+	suite.Equal(apierror.CodeSocialProviderUnknown, err.(*apierror.ErrorStruct).ErrorCode)
+}
+
+func (suite *SocialServiceSuite) TestCompleteSocialLogin() {
+	userId := uuid.New()
+	testCases := []struct {
+		name          string
+		emailVerified bool
+		tamper        func(callback *models.SocialCallback)
+		beforeTest    func(repository *mocks.Repository)
+		errCode       string
+	}{
+		{
+			name:          "new_user",
+			emailVerified: true,
+			beforeTest: func(repository *mocks.Repository) {
+				repository.On("GetIdentity", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+				repository.On("GetUserByEmail", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+				repository.On("IfUserNameExists", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+				repository.On("AddUser", mock.Anything, mock.MatchedBy(func(user *models.User) bool {
+					return user.Status == models.UserStatusActive && user.Password == unusablePassword && len(user.UserName) >= 8
+				})).Return(nil).Once()
+				repository.On("AddIdentity", mock.Anything, mock.MatchedBy(func(identity *models.Identity) bool {
+					return identity.Provider == "mock" && identity.Subject == "42"
+				})).Return(nil).Once()
+				repository.On("UpdateRefreshToken", mock.Anything, mock.Anything).Return(nil).Once()
+			},
+		},
+		{
+			name:          "user_name_taken",
+			emailVerified: true,
+			beforeTest: func(repository *mocks.Repository) {
+				repository.On("GetIdentity", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+				repository.On("GetUserByEmail", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+				repository.On("IfUserNameExists", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+					*args.Get(2).(*bool) = true
+				}).Return(nil).Once()
+				repository.On("IfUserNameExists", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+				repository.On("AddUser", mock.Anything, mock.Anything).Return(nil).Once()
+				repository.On("AddIdentity", mock.Anything, mock.Anything).Return(nil).Once()
+				repository.On("UpdateRefreshToken", mock.Anything, mock.Anything).Return(nil).Once()
+			},
+		},
+		{
+			name:          "linked_identity",
+			emailVerified: true,
+			beforeTest: func(repository *mocks.Repository) {
+				repository.On("GetIdentity", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+					args.Get(1).(*models.Identity).UserId = userId
+					*args.Get(2).(*bool) = true
+				}).Return(nil).Once()
+				repository.On("GetUserById", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+					args.Get(1).(*models.User).Email = "other@gmail.com"
+				}).Return(nil).Once()
+				repository.On("GetSignInUser", mock.Anything, mock.Anything).Return(&models.User{UserId: userId, RefreshToken: "refresh", ExpirationTime: time.Now().Add(time.Hour), Status: models.UserStatusActive}, nil).Once()
+			},
+		},
+		{
+			name:          "claims_pending_sign_up",
+			emailVerified: true,
+			beforeTest: func(repository *mocks.Repository) {
+				repository.On("GetIdentity", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+				repository.On("GetUserByEmail", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+					user := args.Get(1).(*models.User)
+					user.UserId, user.Status = userId, models.UserStatusPending
+					*args.Get(2).(*bool) = true
+				}).Return(nil).Once()
+				repository.On("ClaimUnverifiedUser", mock.Anything, mock.MatchedBy(func(user *models.User) bool {
+					return user.UserId == userId && user.Password == unusablePassword
+				})).Return(nil).Once()
+				repository.On("AddIdentity", mock.Anything, mock.Anything).Return(nil).Once()
+				repository.On("UpdateRefreshToken", mock.Anything, mock.Anything).Return(nil).Once()
+			},
+		},
+		{
+			name:    "email_unverified",
+			errCode: apierror.CodeSocialEmailUnverified,
+		},
+		{
+			name:          "state_mismatch",
+			emailVerified: true,
+			tamper: func(callback *models.SocialCallback) {
+				callback.State = "other"
+			},
+			errCode: apierror.CodeSocialLoginFailed,
+		},
+		{
+			name:          "wrong_code",
+			emailVerified: true,
+			tamper: func(callback *models.SocialCallback) {
+				callback.Code = "other"
+			},
+			errCode: apierror.CodeSocialLoginFailed,
+		},
+	}
+	for _, tc := range testCases {
+		suite.T().Run(tc.name, func(t *testing.T) {
+			callback := suite.begin("test@gmail.com", tc.emailVerified)
+			if tc.tamper != nil {
+				tc.tamper(callback)
+			}
+			if tc.beforeTest != nil {
+				tc.beforeTest(suite.repository)
+			}
+			user, err := suite.service.CompleteSocialLogin(context.TODO(), callback)
+			if tc.errCode != "" {
+				suite.Equal(tc.errCode, err.(*apierror.ErrorStruct).ErrorCode)
+				return
+			}
+			suite.Nil(err)
+			suite.NotEmpty(user.Jwt)
+			suite.NotEmpty(user.RefreshToken)
+		})
+	}
+}
+
+func (suite *SocialServiceSuite) TestWrongNonce() {
+	callback := suite.begin("test@gmail.com", true)
+	suite.issuer.claims["nonce"] = "other"
+	_, err := suite.service.CompleteSocialLogin(context.TODO(), callback)
+	suite.Equal(apierror.CodeSocialLoginFailed, err.(*apierror.ErrorStruct).ErrorCode)
+}
+
+// TestClaimPendingSignUp checks that the password of a pending sign up doesn't survive a social login with its email,
+// whoever registered it could otherwise sign in to the account the provider has just verified.
+func (suite *SocialServiceSuite) TestClaimPendingSignUp() {
+	userId := uuid.New()
+	hash, err := bcrypt.GenerateFromPassword([]byte("registrant password"), bcrypt.MinCost)
+	suite.Require().Nil(err)
+	stored := &models.User{UserId: userId, Email: "test@gmail.com", Password: string(hash), Status: models.UserStatusPending}
+	suite.repository.On("GetIdentity", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+	suite.repository.On("GetUserByEmail", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		user := args.Get(1).(*models.User)
+		user.UserId, user.Status = stored.UserId, stored.Status
+		*args.Get(2).(*bool) = true
+	}).Return(nil).Once()
+	suite.repository.On("ClaimUnverifiedUser", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		stored.Password, stored.Status = args.Get(1).(*models.User).Password, models.UserStatusActive
+	}).Return(nil).Once()
+	suite.repository.On("AddIdentity", mock.Anything, mock.Anything).Return(nil).Once()
+	suite.repository.On("UpdateRefreshToken", mock.Anything, mock.Anything).Return(nil).Once()
+	_, err = suite.service.CompleteSocialLogin(context.TODO(), suite.begin("test@gmail.com", true))
+	suite.Require().Nil(err)
+	suite.Equal(unusablePassword, stored.Password)
+
+	suite.repository.On("GetSignInUser", mock.Anything, mock.Anything).Return(stored, nil).Once()
+	err = suite.service.SignInUser(context.TODO(), &models.User{Email: "test@gmail.com", Password: "registrant password"})
+	suite.Equal(apierror.CodeWrongPassword, err.(*apierror.ErrorStruct).ErrorCode)
+}
+
+func (suite *SocialServiceSuite) TestProviderResponseTooLarge() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"issuer":"`))
+		w.Write(bytes.Repeat([]byte("a"), maxSocialResponse))
+		w.Write([]byte(`"}`))
+	}))
+	defer server.Close()
+	endpoints := new(models.SocialEndpoints)
+	err := suite.service.getSocialJson(context.TODO(), server.URL, "", endpoints)
+	suite.NotNil(err)
+	suite.Empty(endpoints.Issuer)
+}
+
+func TestSocialUserName(t *testing.T) {
+	for email, prefix := range map[string]string{"john.doe+tag@gmail.com": "johndoetag_", "a@gmail.com": "user_", "_bob_@gmail.com": "bob_"} {
+		name := socialUserName(email)
+		if len(name) != len(prefix)+6 || name[:len(prefix)] != prefix {
+			t.Errorf("socialUserName(%q) = %q", email, name)
+		}
+	}
+}
+
+func TestSocialService(t *testing.T) {
+	suite.Run(t, new(SocialServiceSuite))
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/dchest/uniuri"
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+)
+
+// PersonalAccessTokenPrefix tells personal access tokens apart from JWTs and makes leaked tokens easy to scan for.
+const PersonalAccessTokenPrefix = "pat_"
+
+// CreatePersonalAccessToken generates the secret, it is returned in token.Token once and only its hash is stored.
+func (service *UserService) CreatePersonalAccessToken(ctx context.Context, token *models.PersonalAccessToken) error {
+	token.TokenId = uuid.New()
+	token.Token = PersonalAccessTokenPrefix + uniuri.NewLen(40)
+	token.TokenHash = hashToken(token.Token)
+	token.CreatedAt = time.Now().UTC()
+	return service.repository.AddPersonalAccessToken(ctx, token)
+}
+
+func (service *UserService) GetPersonalAccessTokens(ctx context.Context, user *models.User) ([]models.PersonalAccessToken, error) {
+	return service.repository.GetPersonalAccessTokens(ctx, user)
+}
+
+func (service *UserService) DeletePersonalAccessToken(ctx context.Context, token *models.PersonalAccessToken) error {
+	return service.repository.DeletePersonalAccessToken(ctx, token)
+}
+
+// Authenticate accepts an access token or a personal access token sent as a bearer token,
+// claims of a personal access token carry its id as jti and its scopes.
+func (service *UserService) Authenticate(ctx context.Context, token string) (*models.MyJwtClaims, error) {
+	if !strings.HasPrefix(token, PersonalAccessTokenPrefix) {
+		return service.ParseAccessToken(ctx, token)
+	}
+	personalToken := &models.PersonalAccessToken{TokenHash: hashToken(token)}
+	found := new(bool)
+	if err := service.repository.UsePersonalAccessToken(ctx, personalToken, found); err != nil {
+		return nil, err
+	}
+	if !*found {
+		return nil, apierror.NewCatalogError(apierror.CodeAccessTokenInvalid, ErrAccessTokenInvalid.Error())
+	}
+	// the scopes are checked on every use, a personal access token loses permissions together with the user
+	scopes, err := service.grantableScopes(ctx, &models.User{UserId: personalToken.UserId}, personalToken.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	claims := &models.MyJwtClaims{
+		UserId: personalToken.UserId.String(),
+		Scope:  strings.Join(scopes, " "),
+		StandardClaims: jwt.StandardClaims{
+			Id:       personalToken.TokenId.String(),
+			IssuedAt: personalToken.CreatedAt.Unix(),
+		},
+	}
+	if personalToken.ExpirationTime != nil {
+		claims.ExpiresAt = personalToken.ExpirationTime.Unix()
+	}
+	return claims, nil
+}
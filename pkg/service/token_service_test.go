@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	config "github.com/Kin-dza-dzaa/userApi/configs"
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
+	"github.com/Kin-dza-dzaa/userApi/internal/models"
+	"github.com/Kin-dza-dzaa/userApi/pkg/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreatePersonalAccessToken(t *testing.T) {
+	repository := mocks.NewRepository(t)
+	service := NewUserService(repository, &config.Config{})
+	token := &models.PersonalAccessToken{UserId: uuid.New(), Name: "import"}
+	repository.On("AddPersonalAccessToken", mock.Anything, mock.MatchedBy(func(token *models.PersonalAccessToken) bool {
+		return token.TokenHash == hashToken(token.Token)
+	})).Return(nil).Once()
+	if err := service.CreatePersonalAccessToken(context.TODO(), token); err != nil {
+		t.FailNow()
+	}
+	if !strings.HasPrefix(token.Token, PersonalAccessTokenPrefix) || token.TokenId == uuid.Nil {
+		t.FailNow()
+	}
+}
+
+func TestAuthenticatePersonalAccessToken(t *testing.T) {
+	userId, tokenId := uuid.New(), uuid.New()
+	expirationTime := time.Now().Add(time.Hour).UTC()
+	testCases := []struct {
+		name  string
+		found bool
+		err   error
+	}{
+		{
+			name:  "valid",
+			found: true,
+		},
+		{
+			name: "expired_or_unknown",
+			err:  apierror.NewCatalogError(apierror.CodeAccessTokenInvalid, ErrAccessTokenInvalid.Error()),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repository := mocks.NewRepository(t)
+			service := NewUserService(repository, &config.Config{})
+			repository.On("UsePersonalAccessToken", mock.Anything, mock.MatchedBy(func(token *models.PersonalAccessToken) bool {
+				return token.TokenHash == hashToken("pat_secret")
+			}), mock.Anything).Run(func(args mock.Arguments) {
+				if tc.found {
+					token := args.Get(1).(*models.PersonalAccessToken)
+					token.TokenId, token.UserId, token.Scopes, token.ExpirationTime = tokenId, userId, []string{"words:write", "roles:manage"}, &expirationTime
+					*args.Get(2).(*bool) = true
+				}
+			}).Return(nil).Once()
+			if tc.found {
+				repository.On("GrantableScopes", mock.Anything, &models.User{UserId: userId}, []string{"words:write", "roles:manage"}).Return([]string{"words:write"}, nil).Once()
+			}
+			claims, err := service.Authenticate(context.TODO(), "pat_secret")
+			if tc.err != nil {
+				if err.Error() != tc.err.Error() {
+					t.FailNow()
+				}
+				return
+			}
+			if err != nil || claims.UserId != userId.String() || claims.Id != tokenId.String() || claims.Scope != "words:write" || claims.ExpiresAt != expirationTime.Unix() {
+				t.FailNow()
+			}
+		})
+	}
+}
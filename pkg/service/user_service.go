@@ -4,7 +4,10 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"net"
 	"net/http"
+	"strings"
+	"sync"
 	"text/template"
 	"time"
 
@@ -16,26 +19,68 @@ import (
 	"github.com/golang-jwt/jwt"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
-	"gopkg.in/gomail.v2"
+)
+
+const (
+	bcryptCost = 14
+	// dummyHash is compared against when the user doesn't exist, so hardened sign in takes the same time either way
+	dummyHash = "$2a$14$sOAdOX0oVwtZ.ywaBZUljuzrDQLA.LfBbOYGNWed2LkbyRFlufExW"
+	// sessionSlideStep is the least a refresh has to extend a session by to be stored, refreshes in a row don't each write
+	sessionSlideStep = time.Minute
 )
 
 var (
 	ErrWrongPassowrd = errors.New("wrong password")
+	ErrWrongEmailOrPassword = errors.New("wrong email or password")
+	ErrAccessTokenInvalid = errors.New("access token expired or invalid")
+	ErrAccountSuspended = errors.New("account is suspended")
+	ErrAccountLocked = errors.New("account is locked")
+	ErrAccountInactive = errors.New("account is not active")
 )
 
 type UserService struct {
 	repository repository.Repository
 	config     *config.Config
+	mailer     Mailer
+	keyOnce    sync.Once
+	signingKey *signingKey
+	keyErr     error
+	httpClient *http.Client
+	social     socialCache
+	resolver   MxResolver
+	captcha    CaptchaVerifier
+	domainList domainListFile
+}
+
+// normalizeEmail puts emails in the form they are stored and looked up in, see models.EmailPolicy.
+func (service *UserService) normalizeEmail(email string) string {
+	return models.NormalizeEmail(email, models.EmailPolicy{
+		LowercaseLocalPart: service.config.EmailLocalPart != config.EmailLocalPartPreserve,
+		FoldGmail:          service.config.EmailFoldGmail,
+	})
 }
 
 func (service *UserService) SignUpUser(ctx context.Context, user *models.User) error {
+	user.Email = service.normalizeEmail(user.Email)
+	if err := service.checkEmailDomain(ctx, user.Email); err != nil {
+		return err
+	}
 	if err := service.hashPassword(user); err != nil {
 		return err
 	}
 	user.UserId = uuid.New()
 	user.RegistrationTime = time.Now().UTC()
 	user.VerificationCode = uniuri.New()
-	user.Verified = false
+	user.Status = models.UserStatusPending
+	if service.config.HardenedAuth {
+		verified := new(bool)
+		if err := service.repository.IfVerifiedUserExists(ctx, user, verified); err != nil {
+			return err
+		}
+		if *verified {
+			return service.sendSignUpNotice(user)
+		}
+	}
 	exists := new(bool)
 	err := service.repository.IfUnverifiedUserExists(ctx, user, exists)
 	if err != nil {
@@ -48,6 +93,9 @@ func (service *UserService) SignUpUser(ctx context.Context, user *models.User) e
 		}
 	} else {
 		if err := service.repository.AddUser(ctx, user); err != nil {
+			if Err, ok := err.(*apierror.ErrorStruct); ok && Err.ErrorCode == apierror.CodeEmailTaken && service.config.HardenedAuth {
+				return service.sendSignUpNotice(user)
+			}
 			return err
 		}
 	}
@@ -60,52 +108,269 @@ func (service *UserService) SignUpUser(ctx context.Context, user *models.User) e
 }
 
 func (service *UserService) SignInUser(ctx context.Context, user *models.User) error {
-	DbUser, err := service.repository.GetVerifiedUser(ctx, user)
+	user.Email = service.normalizeEmail(user.Email)
+	DbUser, err := service.repository.GetSignInUser(ctx, user)
 	if err != nil {
+		if Err, ok := err.(*apierror.ErrorStruct); ok && Err.ErrorCode == apierror.CodeWrongEmail && service.config.HardenedAuth {
+			bcrypt.CompareHashAndPassword([]byte(dummyHash), []byte(user.Password))
+			return apierror.NewCatalogError(apierror.CodeInvalidCredentials, ErrWrongEmailOrPassword.Error())
+		}
 		return err
 	}
 	if err := bcrypt.CompareHashAndPassword([]byte(DbUser.Password), []byte(user.Password)); err != nil {
-		return apierror.NewErrorStruct(ErrWrongPassowrd.Error(), "error", http.StatusBadRequest)
+		if service.config.HardenedAuth {
+			return apierror.NewCatalogError(apierror.CodeInvalidCredentials, ErrWrongEmailOrPassword.Error())
+		}
+		return apierror.NewCatalogError(apierror.CodeWrongPassword, ErrWrongPassowrd.Error())
+	}
+	user.Amr = []string{models.AmrPassword}
+	if err := service.startSession(ctx, user, DbUser); err != nil {
+		return err
 	}
-	if time.Now().After(DbUser.ExpirationTime) {
-		user.UserId = DbUser.UserId
+	service.notifyNewDevice(ctx, user)
+	return nil
+}
+
+// Reauthenticate checks the password of a signed in user and issues an access token with a new auth_time. Only that
+// token carries it, refreshes on the same device take it from there. The refresh token stays as it is.
+func (service *UserService) Reauthenticate(ctx context.Context, user *models.User) error {
+	password := user.Password
+	if err := service.repository.GetPassword(ctx, user); err != nil {
+		return err
+	}
+	if err := accountStatusError(user.Status); err != nil {
+		return err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		return apierror.NewCatalogError(apierror.CodeWrongPassword, ErrWrongPassowrd.Error())
+	}
+	user.AuthTime, user.Amr = time.Now().UTC(), []string{models.AmrPassword}
+	return service.generateToken(ctx, user)
+}
+
+// startSession reuses the refresh token of DbUser while it is valid and issues a new access token, only active users get a session.
+// The session is shared by the devices of the user, it stays remembered if any sign in asked for it.
+func (service *UserService) startSession(ctx context.Context, user *models.User, DbUser *models.User) error {
+	if err := accountStatusError(DbUser.Status); err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	user.UserId = DbUser.UserId
+	user.AuthTime = now
+	if now.After(DbUser.ExpirationTime) {
 		user.RefreshToken = uniuri.NewLen(512)
-		user.ExpirationTime = time.Now().UTC().AddDate(0, 6, 0)
+		user.SessionStartedAt = now
+		user.ExpirationTime = service.sessionExpiration(user.SessionStartedAt, user.RememberMe, now)
 		if err := service.repository.UpdateRefreshToken(ctx, user); err != nil {
 			return err
 		}
-	} else {
-		user.UserId = DbUser.UserId
-		user.RefreshToken = DbUser.RefreshToken
+		return service.generateToken(ctx, user)
+	}
+	user.RefreshToken = DbUser.RefreshToken
+	user.SessionStartedAt = DbUser.SessionStartedAt
+	user.ExpirationTime = service.sessionExpiration(user.SessionStartedAt, user.RememberMe || DbUser.RememberMe, now)
+	if user.ExpirationTime.Sub(DbUser.ExpirationTime) < sessionSlideStep {
 		user.ExpirationTime = DbUser.ExpirationTime
 	}
-	return service.generateToken(user)
+	if user.ExpirationTime != DbUser.ExpirationTime || user.RememberMe && !DbUser.RememberMe {
+		if err := service.repository.UpdateRefreshToken(ctx, user); err != nil {
+			return err
+		}
+	}
+	return service.generateToken(ctx, user)
+}
+
+// sessionExpiration slides a session forward from now, remembered sessions last RefreshTokenTtl and the others SessionTtl,
+// neither outlives SessionMaxLifetime from its start.
+func (service *UserService) sessionExpiration(startedAt time.Time, rememberMe bool, now time.Time) time.Time {
+	ttl := service.config.SessionTtl()
+	if rememberMe {
+		ttl = service.config.RefreshTokenTtl()
+	}
+	expiration := now.Add(ttl)
+	if limit := startedAt.Add(service.config.SessionMaxLifetime()); expiration.After(limit) {
+		return limit
+	}
+	return expiration
 }
 
 func (service *UserService) VerifyUser(ctx context.Context, user *models.User) error {
 	user.RefreshToken = uniuri.NewLen(512)
-	user.ExpirationTime = time.Now().UTC().AddDate(0, 6, 0)
+	user.SessionStartedAt = time.Now().UTC()
+	user.ExpirationTime = service.sessionExpiration(user.SessionStartedAt, user.RememberMe, user.SessionStartedAt)
 	if err := service.repository.VerifyUser(ctx, user); err != nil {
 		return err
 	}
+	return service.GetAccessToken(ctx, user)
+}
+
+// GetAccessToken checks the status before the expiration, a suspended user is told so even after the suspension ended the session.
+// Every refresh slides the expiration of the session forward. The token gets the start of the session as auth_time, or
+// the later auth_time of user.PreviousJwt: the refresh token is shared by the devices of the user, a re-authentication
+// isn't, so it is carried forward only by the token of the device that made it.
+func (service *UserService) GetAccessToken(ctx context.Context, user *models.User) error {
 	err := service.repository.GetUUid(ctx, user)
 	if err != nil {
 		return err
 	}
-	return service.generateToken(user)
+	if err := accountStatusError(user.Status); err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	if !now.Before(user.ExpirationTime) {
+		return apierror.NewCatalogError(apierror.CodeRefreshTokenInvalid, repository.ErrUserDoesntExists.Error())
+	}
+	if expiration := service.sessionExpiration(user.SessionStartedAt, user.RememberMe, now); expiration.Sub(user.ExpirationTime) >= sessionSlideStep {
+		user.ExpirationTime = expiration
+		if err := service.repository.ExtendSession(ctx, user); err != nil {
+			return err
+		}
+	}
+	user.AuthTime = user.SessionStartedAt
+	if err := service.carryAuthTime(ctx, user); err != nil {
+		return err
+	}
+	return service.generateToken(ctx, user)
 }
 
-func (service *UserService) GetAccessToken(ctx context.Context, user *models.User) error {
-	err := service.repository.GetUUid(ctx, user)
+// carryAuthTime takes auth_time and amr of user.PreviousJwt when it is a session token of the same user issued by this
+// service and its auth_time is after user.AuthTime. The token may have expired, it is what the device had before the refresh.
+func (service *UserService) carryAuthTime(ctx context.Context, user *models.User) error {
+	if user.PreviousJwt == "" {
+		return nil
+	}
+	claims := new(models.MyJwtClaims)
+	_, err := jwt.ParseWithClaims(user.PreviousJwt, claims, service.accessTokenKey)
+	if Err, ok := err.(*jwt.ValidationError); err != nil && (!ok || Err.Errors != jwt.ValidationErrorExpired) {
+		return nil
+	}
+	if !claims.VerifyIssuer(service.config.Issuer, true) || !claims.VerifyAudience(service.config.Audience(), true) || claims.ClientId != "" || claims.UserId != user.UserId.String() {
+		return nil
+	}
+	authTime := time.Unix(claims.AuthTime, 0).UTC()
+	if claims.AuthTime == 0 || !authTime.After(user.AuthTime) {
+		return nil
+	}
+	if revoked, err := service.tokenRevoked(ctx, claims); err != nil || revoked {
+		return err
+	}
+	user.AuthTime, user.Amr = authTime, claims.Amr
+	return nil
+}
+
+// accountStatusError is nil for active users, suspended and locked users get their own codes.
+func accountStatusError(status string) error {
+	switch status {
+	case models.UserStatusActive:
+		return nil
+	case models.UserStatusSuspended:
+		return apierror.NewCatalogError(apierror.CodeAccountSuspended, ErrAccountSuspended.Error())
+	case models.UserStatusLocked:
+		return apierror.NewCatalogError(apierror.CodeAccountLocked, ErrAccountLocked.Error())
+	default:
+		return apierror.NewCatalogError(apierror.CodeAccountInactive, ErrAccountInactive.Error())
+	}
+}
+
+// ParseAccessToken accepts only the tokens this service issued for itself, iss is ISSUER and aud is its own audience.
+func (service *UserService) ParseAccessToken(ctx context.Context, token string) (*models.MyJwtClaims, error) {
+	return service.parseAccessToken(ctx, token, true)
+}
+
+// parseAccessToken verifies the signature, iss and, with ownAudience, aud, then checks the denylist. Introspection,
+// revocation and userinfo take tokens issued for the audience of any client.
+func (service *UserService) parseAccessToken(ctx context.Context, token string, ownAudience bool) (*models.MyJwtClaims, error) {
+	claims := new(models.MyJwtClaims)
+	_, err := jwt.ParseWithClaims(token, claims, service.accessTokenKey)
+	if err != nil || !claims.VerifyIssuer(service.config.Issuer, true) || (ownAudience && !claims.VerifyAudience(service.config.Audience(), true)) {
+		return nil, apierror.NewCatalogError(apierror.CodeAccessTokenInvalid, ErrAccessTokenInvalid.Error())
+	}
+	revoked, err := service.tokenRevoked(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, apierror.NewCatalogError(apierror.CodeAccessTokenInvalid, ErrAccessTokenInvalid.Error())
+	}
+	return claims, nil
+}
+
+func (service *UserService) accessTokenKey(token *jwt.Token) (interface{}, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		return nil, ErrAccessTokenInvalid
+	}
+	return []byte(service.config.JWTString), nil
+}
+
+// tokenRevoked checks the denylist, tokens without jti can't be revoked one by one.
+func (service *UserService) tokenRevoked(ctx context.Context, claims *models.MyJwtClaims) (bool, error) {
+	if claims.Id == "" {
+		return false, nil
+	}
+	revoked := new(bool)
+	// client tokens have no user, uuid.Nil matches no one
+	userId, _ := uuid.Parse(claims.UserId)
+	if err := service.repository.IfTokenRevoked(ctx, &models.RevokedToken{Jti: claims.Id, UserId: userId, IssuedAt: time.Unix(claims.IssuedAt, 0).UTC()}, revoked); err != nil {
+		return false, err
+	}
+	return *revoked, nil
+}
+
+// generateToken issues a session access token, it carries the roles of the user and all their permissions as scope.
+func (service *UserService) generateToken(ctx context.Context, user *models.User) error {
+	if err := service.repository.GetUserAccess(ctx, user); err != nil {
+		return err
+	}
+	return service.signUserToken(user, nil, strings.Join(user.Permissions, " "))
+}
+
+// generateScopedToken issues an access token delegated to a client, scopes naming a permission the user doesn't have are dropped.
+func (service *UserService) generateScopedToken(ctx context.Context, user *models.User, client *models.OAuthClient, scope string) error {
+	if err := service.repository.GetUserAccess(ctx, user); err != nil {
+		return err
+	}
+	scopes, err := service.grantableScopes(ctx, user, strings.Fields(scope))
 	if err != nil {
 		return err
 	}
-	return service.generateToken(user)
+	return service.signUserToken(user, client, strings.Join(scopes, " "))
 }
 
-func (service *UserService) generateToken(user *models.User) error {
+func (service *UserService) grantableScopes(ctx context.Context, user *models.User, scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return scopes, nil
+	}
+	return service.repository.GrantableScopes(ctx, user, scopes)
+}
+
+// signUserToken sets a new csrf token, client is nil for session tokens. A client only gets username with the profile scope
+// and email_verified with the email scope.
+func (service *UserService) signUserToken(user *models.User, client *models.OAuthClient, scope string) error {
 	user.CsrfToken = uniuri.NewLen(32)
-	jwt, err := jwt.NewWithClaims(jwt.SigningMethodHS256, models.MyJwtClaims{UserId: user.UserId.String(), XCSRFToken: user.CsrfToken, StandardClaims: jwt.StandardClaims{ExpiresAt: time.Now().UTC().Add(time.Minute * 5).Unix()}}).SignedString([]byte(service.config.JWTString))
+	claims := &models.MyJwtClaims{
+		UserId:         user.UserId.String(),
+		XCSRFToken:     user.CsrfToken,
+		Scope:          scope,
+		StandardClaims: jwt.StandardClaims{Subject: user.UserId.String(), Audience: service.audience(client)},
+	}
+	// roles only go into first-party session tokens, a delegated token never acts with the user's roles
+	if client == nil {
+		claims.Roles = user.Roles
+	} else {
+		claims.ClientId = client.ClientId
+	}
+	scopes := strings.Fields(scope)
+	if client == nil || contains(scopes, ScopeProfile) {
+		claims.UserName = user.UserName
+	}
+	if client == nil || contains(scopes, ScopeEmail) {
+		verified := user.EmailVerified()
+		claims.EmailVerified = &verified
+	}
+	if !user.AuthTime.IsZero() {
+		claims.AuthTime, claims.Amr = user.AuthTime.Unix(), user.Amr
+	}
+	jwt, err := service.signAccessToken(claims)
 	if err != nil {
 		return err
 	}
@@ -113,8 +378,31 @@ func (service *UserService) generateToken(user *models.User) error {
 	return nil
 }
 
+// signAccessToken sets iss, jti, iat, nbf and exp and signs claims with JWT_SECURE_STRING, user and client tokens are signed alike.
+func (service *UserService) signAccessToken(claims *models.MyJwtClaims) (string, error) {
+	now := time.Now().UTC()
+	claims.Issuer = service.config.Issuer
+	claims.Id = uuid.NewString()
+	claims.IssuedAt = now.Unix()
+	claims.NotBefore = now.Unix()
+	claims.ExpiresAt = now.Add(service.config.AccessTokenTtl()).Unix()
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(service.config.JWTString))
+}
+
+// audience is the aud claim of tokens for client, its own audience or else its client_id. ACCESS_TOKEN_AUDIENCE is what
+// ParseAccessToken accepts, it is kept for first-party session tokens, client is nil, even if a client is set up with it.
+func (service *UserService) audience(client *models.OAuthClient) string {
+	if client == nil {
+		return service.config.Audience()
+	}
+	if client.Audience != "" && client.Audience != service.config.Audience() {
+		return client.Audience
+	}
+	return client.ClientId
+}
+
 func (service *UserService) hashPassword(user *models.User) error {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), 14)
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcryptCost)
 	if err != nil {
 		return err
 	}
@@ -123,30 +411,34 @@ func (service *UserService) hashPassword(user *models.User) error {
 }
 
 func (service *UserService) sendVerificationCode(user *models.User) error {
-	t, err := template.ParseFiles(service.config.TemplateLocation)
+	return service.sendTemplate(user.Email, "Verification on WordDict", service.config.TemplateLocation, map[string]string{"VerificationCode": service.config.SpaUrl + "/verify/" + user.VerificationCode, "UserName": user.UserName})
+}
+
+// sendSignUpNotice warns the owner of an existing account that someone tried to register with their email.
+func (service *UserService) sendSignUpNotice(user *models.User) error {
+	return service.sendTemplate(user.Email, "Sign up attempt on WordDict", service.config.NoticeTemplateLocation, map[string]string{"SignInUrl": service.config.SpaUrl + "/signin"})
+}
+
+func (service *UserService) sendTemplate(to string, subject string, templateLocation string, data map[string]string) error {
+	t, err := template.ParseFiles(templateLocation)
 	if err != nil {
 		return err
 	}
 	body := new(bytes.Buffer)
-	err = t.Execute(body, map[string]string{"VerificationCode": service.config.SpaUrl + "/verify/" + user.VerificationCode, "UserName": user.UserName})
-	if err != nil {
+	if err := t.Execute(body, data); err != nil {
 		return err
 	}
-	d := gomail.NewDialer("smtp.gmail.com", 465, service.config.SmtpUserName, service.config.SmtpPassword)
-	msg := gomail.NewMessage()
-	msg.SetHeader("From", service.config.SmtpUserName)
-	msg.SetHeader("To", user.Email)
-	msg.SetHeader("Subject", "Verification on WordDict")
-	msg.SetBody("text/html", body.String())
-	if err := d.DialAndSend(msg); err != nil {
-		return err
-	}
-	return nil
+	return service.mailer.Send(to, subject, body.String())
 }
 
 func NewUserService(repository repository.Repository, config *config.Config) *UserService {
+	httpClient := &http.Client{Timeout: time.Second * 10}
 	return &UserService{
 		repository: repository,
 		config:     config,
+		mailer:     NewSmtpMailer(config),
+		httpClient: httpClient,
+		resolver:   net.DefaultResolver,
+		captcha:    newCaptchaVerifier(config, httpClient),
 	}
 }
@@ -4,16 +4,21 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 	config "github.com/Kin-dza-dzaa/userApi/configs"
+	"github.com/Kin-dza-dzaa/userApi/internal/apierror"
 	"github.com/Kin-dza-dzaa/userApi/internal/models"
 	"github.com/Kin-dza-dzaa/userApi/pkg/mocks"
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type UserServiceSuite struct {
-	service Service
+	service *UserService
 	suite.Suite
 	repository *mocks.Repository
 }
@@ -25,7 +30,9 @@ func (suite *UserServiceSuite) SetupSuite() {
 		suite.FailNow(err.Error())
 	}
 	suite.repository = mocks.NewRepository(suite.T())
-	suite.service = NewService(suite.repository, config)
+	allowNoRoles(suite.repository)
+	allowNoEmailDomainRules(suite.repository)
+	suite.service = NewUserService(suite.repository, config)
 }
 
 func (suite *UserServiceSuite) TestSignUp() {
@@ -60,6 +67,418 @@ func (suite *UserServiceSuite) TestSignUp() {
 	}
 }
 
+func (suite *UserServiceSuite) TestParseAccessToken() {
+	user := &models.User{UserId: uuid.New(), UserName: "testuser", Status: models.UserStatusActive}
+	if err := suite.service.generateToken(context.TODO(), user); err != nil {
+		suite.FailNow(err.Error())
+	}
+	suite.repository.On("IfTokenRevoked", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+	claims, err := suite.service.ParseAccessToken(context.TODO(), user.Jwt)
+	suite.Nil(err)
+	suite.Equal(user.UserId.String(), claims.UserId)
+	suite.Equal(user.UserId.String(), claims.Subject)
+	suite.Equal(suite.service.config.Issuer, claims.Issuer)
+	suite.Equal(suite.service.config.Audience(), claims.Audience)
+	suite.Equal(claims.IssuedAt, claims.NotBefore)
+	suite.Equal("testuser", claims.UserName)
+	suite.Equal(true, *claims.EmailVerified)
+	suite.Equal(user.CsrfToken, claims.XCSRFToken)
+	suite.NotEmpty(claims.Id)
+
+	suite.repository.On("IfTokenRevoked", mock.Anything, &models.RevokedToken{Jti: claims.Id, UserId: user.UserId, IssuedAt: time.Unix(claims.IssuedAt, 0).UTC()}, mock.Anything).Run(func(args mock.Arguments) {
+		*args.Get(2).(*bool) = true
+	}).Return(nil).Once()
+	_, err = suite.service.ParseAccessToken(context.TODO(), user.Jwt)
+	suite.Equal(apierror.NewCatalogError(apierror.CodeAccessTokenInvalid, ErrAccessTokenInvalid.Error()), err)
+
+	_, err = suite.service.ParseAccessToken(context.TODO(), user.Jwt+"tampered")
+	suite.Equal(apierror.NewCatalogError(apierror.CodeAccessTokenInvalid, ErrAccessTokenInvalid.Error()), err)
+}
+
+func (suite *UserServiceSuite) TestParseAccessTokenIssuer() {
+	other := *suite.service.config
+	other.Issuer = "http://other.example.com"
+	user := &models.User{UserId: uuid.New()}
+	suite.Require().Nil(NewUserService(suite.repository, &other).generateToken(context.TODO(), user))
+	_, err := suite.service.ParseAccessToken(context.TODO(), user.Jwt)
+	suite.Equal(apierror.NewCatalogError(apierror.CodeAccessTokenInvalid, ErrAccessTokenInvalid.Error()), err)
+}
+
+func (suite *UserServiceSuite) TestParseAccessTokenWithoutIssuer() {
+	other := *suite.service.config
+	other.AccessTokenAudience, other.Issuer = suite.service.config.Audience(), ""
+	user := &models.User{UserId: uuid.New()}
+	suite.Require().Nil(NewUserService(suite.repository, &other).generateToken(context.TODO(), user))
+	_, err := suite.service.ParseAccessToken(context.TODO(), user.Jwt)
+	suite.Equal(apierror.NewCatalogError(apierror.CodeAccessTokenInvalid, ErrAccessTokenInvalid.Error()), err)
+}
+
+func (suite *UserServiceSuite) TestParseAccessTokenForeignAudience() {
+	user := &models.User{UserId: uuid.New()}
+	suite.Require().Nil(suite.service.generateScopedToken(context.TODO(), user, &models.OAuthClient{ClientId: "wordapi", Audience: "https://words.example.com"}, "openid"))
+	_, err := suite.service.ParseAccessToken(context.TODO(), user.Jwt)
+	suite.Equal(apierror.NewCatalogError(apierror.CodeAccessTokenInvalid, ErrAccessTokenInvalid.Error()), err)
+}
+
+func (suite *UserServiceSuite) TestScopedTokenAudience() {
+	testCases := []struct {
+		name     string
+		client   *models.OAuthClient
+		audience string
+	}{
+		{
+			name:     "client_audience",
+			client:   &models.OAuthClient{ClientId: "wordapi", Audience: "https://words.example.com"},
+			audience: "https://words.example.com",
+		},
+		{
+			name:     "defaults_to_client_id",
+			client:   &models.OAuthClient{ClientId: "wordapi"},
+			audience: "wordapi",
+		},
+		{
+			name:     "own_audience_is_kept_for_sessions",
+			client:   &models.OAuthClient{ClientId: "wordapi", Audience: suite.service.config.Audience()},
+			audience: "wordapi",
+		},
+	}
+	for _, tc := range testCases {
+		suite.T().Run(tc.name, func(t *testing.T) {
+			user := &models.User{UserId: uuid.New()}
+			suite.Require().Nil(suite.service.generateScopedToken(context.TODO(), user, tc.client, "openid"))
+			suite.Equal(tc.audience, parseClaims(t, suite.service, user.Jwt).Audience)
+			_, err := suite.service.ParseAccessToken(context.TODO(), user.Jwt)
+			suite.Equal(apierror.NewCatalogError(apierror.CodeAccessTokenInvalid, ErrAccessTokenInvalid.Error()), err)
+		})
+	}
+}
+
+func (suite *UserServiceSuite) TestScopedTokenClaims() {
+	client := &models.OAuthClient{ClientId: "wordapi", Audience: "https://words.example.com"}
+	testCases := []struct {
+		name     string
+		scope    string
+		personal bool
+	}{
+		{
+			name:  "openid",
+			scope: "openid",
+		},
+		{
+			name:     "profile_email",
+			scope:    "openid profile email",
+			personal: true,
+		},
+	}
+	for _, tc := range testCases {
+		suite.T().Run(tc.name, func(t *testing.T) {
+			user := &models.User{UserId: uuid.New(), UserName: "testuser", Status: models.UserStatusActive}
+			suite.Require().Nil(suite.service.generateScopedToken(context.TODO(), user, client, tc.scope))
+			claims := parseClaims(t, suite.service, user.Jwt)
+			suite.Equal("https://words.example.com", claims.Audience)
+			suite.Equal(user.UserId.String(), claims.Subject)
+			suite.Equal(tc.personal, claims.UserName != "")
+			suite.Equal(tc.personal, claims.EmailVerified != nil)
+		})
+	}
+}
+
+func (suite *UserServiceSuite) TestGetAccessTokenStatus() {
+	testCases := []struct{
+		name string
+		status string
+		expirationTime time.Time
+		errCode string
+	}{
+		{
+			name: "active",
+			status: models.UserStatusActive,
+			expirationTime: time.Now().Add(time.Hour),
+		},
+		{
+			name: "suspended",
+			status: models.UserStatusSuspended,
+			errCode: apierror.CodeAccountSuspended,
+		},
+		{
+			name: "locked",
+			status: models.UserStatusLocked,
+			errCode: apierror.CodeAccountLocked,
+		},
+		{
+			name: "expired",
+			status: models.UserStatusActive,
+			expirationTime: time.Now().Add(-time.Hour),
+			errCode: apierror.CodeRefreshTokenInvalid,
+		},
+	}
+	for _, tc := range testCases {
+		suite.T().Run(tc.name, func(t *testing.T) {
+			suite.repository.On("GetUUid", mock.Anything, mock.MatchedBy(func(user *models.User) bool {
+				return user.RefreshToken == tc.name
+			})).Run(func(args mock.Arguments) {
+				user := args.Get(1).(*models.User)
+				user.UserId, user.Status, user.ExpirationTime = uuid.New(), tc.status, tc.expirationTime
+			}).Return(nil).Once()
+			user := &models.User{RefreshToken: tc.name}
+			err := suite.service.GetAccessToken(context.TODO(), user)
+			if tc.errCode != "" {
+				suite.Equal(tc.errCode, err.(*apierror.ErrorStruct).ErrorCode)
+				return
+			}
+			suite.Nil(err)
+			suite.NotEmpty(user.Jwt)
+		})
+	}
+}
+
+func (suite *UserServiceSuite) TestGetAccessTokenSlides() {
+	maxLifetime := suite.service.config.SessionMaxLifetime()
+	testCases := []struct {
+		name       string
+		startedAt  time.Time
+		rememberMe bool
+		expected   func(startedAt time.Time) time.Time
+	}{
+		{
+			name:      "session",
+			startedAt: time.Now().UTC().Add(-time.Hour),
+			expected: func(time.Time) time.Time {
+				return time.Now().UTC().Add(suite.service.config.SessionTtl())
+			},
+		},
+		{
+			name:       "remembered",
+			startedAt:  time.Now().UTC().Add(-time.Hour),
+			rememberMe: true,
+			expected: func(time.Time) time.Time {
+				return time.Now().UTC().Add(suite.service.config.RefreshTokenTtl())
+			},
+		},
+		{
+			name:       "max_lifetime",
+			startedAt:  time.Now().UTC().Add(time.Hour - maxLifetime),
+			rememberMe: true,
+			expected: func(startedAt time.Time) time.Time {
+				return startedAt.Add(maxLifetime)
+			},
+		},
+	}
+	for _, tc := range testCases {
+		suite.T().Run(tc.name, func(t *testing.T) {
+			suite.repository.On("GetUUid", mock.Anything, mock.MatchedBy(func(user *models.User) bool {
+				return user.RefreshToken == tc.name
+			})).Run(func(args mock.Arguments) {
+				user := args.Get(1).(*models.User)
+				user.UserId, user.Status, user.ExpirationTime = uuid.New(), models.UserStatusActive, time.Now().UTC().Add(time.Minute*30)
+				user.SessionStartedAt, user.RememberMe = tc.startedAt, tc.rememberMe
+			}).Return(nil).Once()
+			suite.repository.On("ExtendSession", mock.Anything, mock.MatchedBy(func(user *models.User) bool {
+				return user.RefreshToken == tc.name
+			})).Return(nil).Once()
+			user := &models.User{RefreshToken: tc.name}
+			suite.Nil(suite.service.GetAccessToken(context.TODO(), user))
+			suite.WithinDuration(tc.expected(tc.startedAt), user.ExpirationTime, time.Second)
+			suite.Equal(tc.startedAt.Unix(), parseClaims(t, suite.service, user.Jwt).AuthTime)
+		})
+	}
+}
+
+// TestGetAccessTokenKeepsReauthTime has two devices share a session, device A re-authenticates and only its refreshed
+// tokens keep the new auth_time, device B's refreshed token still has the start of the session.
+func (suite *UserServiceSuite) TestGetAccessTokenKeepsReauthTime() {
+	userId := uuid.New()
+	startedAt := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	hash, err := bcrypt.GenerateFromPassword([]byte("123456789"), bcrypt.MinCost)
+	suite.Require().Nil(err)
+	deviceB := &models.User{UserId: userId, AuthTime: startedAt, Amr: []string{models.AmrPassword}}
+	suite.Require().Nil(suite.service.generateToken(context.TODO(), deviceB))
+
+	suite.repository.On("GetPassword", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		user := args.Get(1).(*models.User)
+		user.Password, user.Status = string(hash), models.UserStatusActive
+	}).Return(nil).Once()
+	deviceA := &models.User{UserId: userId, Password: "123456789"}
+	suite.Require().Nil(suite.service.Reauthenticate(context.TODO(), deviceA))
+	reauthenticatedAt := parseClaims(suite.T(), suite.service, deviceA.Jwt).AuthTime
+
+	expired := jwt.NewWithClaims(jwt.SigningMethodHS256, &models.MyJwtClaims{
+		UserId:   userId.String(),
+		AuthTime: reauthenticatedAt,
+		StandardClaims: jwt.StandardClaims{
+			Issuer:    suite.service.config.Issuer,
+			Audience:  suite.service.config.Audience(),
+			ExpiresAt: time.Now().Add(-time.Minute).Unix(),
+		},
+	})
+	expiredJwt, err := expired.SignedString([]byte(suite.service.config.JWTString))
+	suite.Require().Nil(err)
+	otherUser := &models.User{UserId: uuid.New(), AuthTime: time.Now().UTC()}
+	suite.Require().Nil(suite.service.generateToken(context.TODO(), otherUser))
+
+	testCases := []struct {
+		name        string
+		previousJwt string
+		authTime    int64
+		// the expired token was made without jti, it can't be on the denylist
+		revokedCheck bool
+	}{
+		{name: "device_a", previousJwt: deviceA.Jwt, authTime: reauthenticatedAt, revokedCheck: true},
+		{name: "device_a_expired_token", previousJwt: expiredJwt, authTime: reauthenticatedAt},
+		{name: "device_b", previousJwt: deviceB.Jwt, authTime: startedAt.Unix()},
+		{name: "no_access_token", authTime: startedAt.Unix()},
+		{name: "token_of_other_user", previousJwt: otherUser.Jwt, authTime: startedAt.Unix()},
+		{name: "forged_token", previousJwt: expiredJwt + "x", authTime: startedAt.Unix()},
+	}
+	for _, tc := range testCases {
+		suite.T().Run(tc.name, func(t *testing.T) {
+			suite.repository.On("GetUUid", mock.Anything, mock.MatchedBy(func(user *models.User) bool {
+				return user.RefreshToken == "shared"
+			})).Run(func(args mock.Arguments) {
+				user := args.Get(1).(*models.User)
+				user.UserId, user.Status, user.ExpirationTime = userId, models.UserStatusActive, time.Now().UTC().Add(suite.service.config.SessionTtl())
+				user.SessionStartedAt = startedAt
+			}).Return(nil).Once()
+			if tc.revokedCheck {
+				suite.repository.On("IfTokenRevoked", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+			}
+			user := &models.User{RefreshToken: "shared", PreviousJwt: tc.previousJwt}
+			suite.Nil(suite.service.GetAccessToken(context.TODO(), user))
+			suite.Equal(tc.authTime, parseClaims(t, suite.service, user.Jwt).AuthTime)
+		})
+	}
+}
+
+func (suite *UserServiceSuite) TestSignInRememberMe() {
+	hash, err := bcrypt.GenerateFromPassword([]byte("123456789"), bcrypt.MinCost)
+	suite.Require().Nil(err)
+	for _, rememberMe := range []bool{false, true} {
+		email := "session@gmail.com"
+		ttl := suite.service.config.SessionTtl()
+		if rememberMe {
+			email, ttl = "remembered@gmail.com", suite.service.config.RefreshTokenTtl()
+		}
+		suite.repository.On("GetSignInUser", mock.Anything, mock.MatchedBy(func(user *models.User) bool {
+			return user.Email == email
+		})).Return(&models.User{UserId: uuid.New(), Password: string(hash), Status: models.UserStatusActive}, nil).Once()
+		suite.repository.On("UpdateRefreshToken", mock.Anything, mock.MatchedBy(func(user *models.User) bool {
+			return user.Email == email && user.RememberMe == rememberMe
+		})).Return(nil).Once()
+		user := &models.User{Email: email, Password: "123456789", RememberMe: rememberMe}
+		suite.Nil(suite.service.SignInUser(context.TODO(), user))
+		suite.WithinDuration(time.Now().UTC(), user.SessionStartedAt, time.Second)
+		suite.WithinDuration(time.Now().UTC().Add(ttl), user.ExpirationTime, time.Second)
+		claims := parseClaims(suite.T(), suite.service, user.Jwt)
+		suite.Equal(user.SessionStartedAt.Unix(), claims.AuthTime)
+		suite.Equal([]string{models.AmrPassword}, claims.Amr)
+	}
+}
+
+func (suite *UserServiceSuite) TestReauthenticate() {
+	hash, err := bcrypt.GenerateFromPassword([]byte("123456789"), bcrypt.MinCost)
+	suite.Require().Nil(err)
+	testCases := []struct {
+		name     string
+		password string
+		errCode  string
+	}{
+		{
+			name:     "right_password",
+			password: "123456789",
+		},
+		{
+			name:     "wrong_password",
+			password: "987654321",
+			errCode:  apierror.CodeWrongPassword,
+		},
+	}
+	for _, tc := range testCases {
+		suite.T().Run(tc.name, func(t *testing.T) {
+			userId := uuid.New()
+			suite.repository.On("GetPassword", mock.Anything, mock.MatchedBy(func(user *models.User) bool {
+				return user.UserId == userId
+			})).Run(func(args mock.Arguments) {
+				user := args.Get(1).(*models.User)
+				user.Password, user.Status = string(hash), models.UserStatusActive
+			}).Return(nil).Once()
+			user := &models.User{UserId: userId, Password: tc.password}
+			err := suite.service.Reauthenticate(context.TODO(), user)
+			if tc.errCode != "" {
+				suite.Equal(tc.errCode, err.(*apierror.ErrorStruct).ErrorCode)
+				return
+			}
+			suite.Nil(err)
+			claims := parseClaims(t, suite.service, user.Jwt)
+			suite.InDelta(time.Now().Unix(), claims.AuthTime, 1)
+			suite.Equal([]string{models.AmrPassword}, claims.Amr)
+		})
+	}
+}
+
+func (suite *UserServiceSuite) TestSignInSuspended() {
+	hash, err := bcrypt.GenerateFromPassword([]byte("123456789"), bcrypt.MinCost)
+	suite.Require().Nil(err)
+	suite.repository.On("GetSignInUser", mock.Anything, mock.Anything).Return(&models.User{Password: string(hash), Status: models.UserStatusSuspended}, nil).Once()
+	err = suite.service.SignInUser(context.TODO(), &models.User{Email: "suspended@gmail.com", Password: "123456789"})
+	suite.Equal(apierror.NewCatalogError(apierror.CodeAccountSuspended, ErrAccountSuspended.Error()), err)
+}
+
+func (suite *UserServiceSuite) TestSignInNormalizesEmail() {
+	suite.repository.On("GetSignInUser", mock.Anything, mock.MatchedBy(func(user *models.User) bool {
+		return user.Email == "bob@example.com"
+	})).Return(nil, apierror.NewCatalogError(apierror.CodeWrongEmail, "wrong email")).Once()
+	err := suite.service.SignInUser(context.TODO(), &models.User{Email: "Bob@Example.COM", Password: "123456789"})
+	suite.Equal(apierror.CodeWrongEmail, err.(*apierror.ErrorStruct).ErrorCode)
+}
+
 func TestUserService(t *testing.T) {
 	suite.Run(t, new(UserServiceSuite))
 }
+
+type HardenedServiceSuite struct {
+	suite.Suite
+	service *UserService
+	repository *mocks.Repository
+	mailer *mocks.Mailer
+}
+
+func (suite *HardenedServiceSuite) SetupTest() {
+	logger := zerolog.New(nil).With().Timestamp().Caller().Logger()
+	config, err := config.ReadConfig(&logger)
+	if err != nil {
+		suite.FailNow(err.Error())
+	}
+	config.HardenedAuth = true
+	config.NoticeTemplateLocation = "./../../internal/templates/signup_notice_template.html"
+	suite.repository = mocks.NewRepository(suite.T())
+	allowNoRoles(suite.repository)
+	allowNoEmailDomainRules(suite.repository)
+	suite.mailer = mocks.NewMailer(suite.T())
+	suite.service = NewUserService(suite.repository, config)
+	suite.service.mailer = suite.mailer
+}
+
+func (suite *HardenedServiceSuite) TestSignInUnknownEmail() {
+	suite.repository.On("GetSignInUser", mock.Anything, mock.Anything).Return(nil, apierror.NewCatalogError(apierror.CodeWrongEmail, "wrong email")).Once()
+	err := suite.service.SignInUser(context.TODO(), &models.User{Email: "unknown@gmail.com", Password: "123456789"})
+	suite.Equal(apierror.NewCatalogError(apierror.CodeInvalidCredentials, ErrWrongEmailOrPassword.Error()), err)
+}
+
+func (suite *HardenedServiceSuite) TestSignInWrongPassword() {
+	suite.repository.On("GetSignInUser", mock.Anything, mock.Anything).Return(&models.User{Password: dummyHash, Status: models.UserStatusActive}, nil).Once()
+	err := suite.service.SignInUser(context.TODO(), &models.User{Email: "known@gmail.com", Password: "123456789"})
+	suite.Equal(apierror.NewCatalogError(apierror.CodeInvalidCredentials, ErrWrongEmailOrPassword.Error()), err)
+}
+
+func (suite *HardenedServiceSuite) TestSignUpExistingEmail() {
+	suite.repository.On("IfVerifiedUserExists", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		*args.Get(2).(*bool) = true
+	}).Return(nil).Once()
+	suite.mailer.On("Send", "known@gmail.com", "Sign up attempt on WordDict", mock.Anything).Return(nil).Once()
+	err := suite.service.SignUpUser(context.TODO(), &models.User{Email: "known@gmail.com", UserName: "someone", Password: "123456789"})
+	suite.Nil(err)
+}
+
+func TestHardenedService(t *testing.T) {
+	suite.Run(t, new(HardenedServiceSuite))
+}